@@ -1,15 +1,68 @@
 package call
 
-import "github.com/nusr/gojs/types"
+import (
+	"math"
+	"sort"
+	"strconv"
 
+	"github.com/nusr/gojs/flow"
+	"github.com/nusr/gojs/types"
+)
+
+// maxArrayLength is the largest length an array can have, 2^32-1.
+const maxArrayLength = math.MaxUint32
+
+// maxCopyLength is the most elements a copy of an array can hold, the
+// limit of V8's backing stores.
+const maxCopyLength = 134217725
+
+// maxDenseGap is how far past the end of the dense elements an index may be
+// written before the array switches to sparse storage.
+const maxDenseGap = 1024
+
+// arrayHole marks an index that has no element.
+type arrayHole struct{}
+
+// arrayImpl stores elements densely in a slice, and in a map once an index
+// is written far past the end. Keys that are not array indices are ordinary
+// properties.
 type arrayImpl struct {
-	value []any
+	*instanceImpl
+	dense  []any
+	sparse map[int64]any // indices at or past len(dense)
+	sorted []int64       // the keys of sparse in order, nil once stale
+	length int64
 }
 
-func NewArray() types.Property {
+func NewArray(interpreter types.Interpreter) types.Object {
+	return NewArrayFrom(interpreter, []any{})
+}
+
+func NewArrayFrom(interpreter types.Interpreter, values []any) types.Object {
 	return &arrayImpl{
-		value: []any{},
+		instanceImpl: NewObject(realmOf(interpreter).arrayPrototype).(*instanceImpl),
+		dense:        values,
+		length:       int64(len(values)),
+	}
+}
+
+// newArrayWithLength creates an array of length holes.
+func newArrayWithLength(interpreter types.Interpreter, length int64) *arrayImpl {
+	array := NewArray(interpreter).(*arrayImpl)
+	array.length = length
+	return array
+}
+
+// IsArray reports whether value is an array, or a proxy for one.
+func IsArray(value any) bool {
+	if proxy, ok := asProxy(value); ok {
+		if proxy.handler == nil {
+			ThrowTypeError(proxy.interpreter, "Cannot perform 'IsArray' on a proxy that has been revoked")
+		}
+		return IsArray(proxy.target)
 	}
+	_, ok := value.(*arrayImpl)
+	return ok
 }
 
 func convertAnyToInt(index any) int64 {
@@ -25,38 +78,381 @@ func convertAnyToInt(index any) int64 {
 	case int64:
 		return data
 	case float32:
+		if float32(int64(data)) != data {
+			return -1
+		}
 		return int64(data)
 	case float64:
+		if float64(int64(data)) != data {
+			return -1
+		}
 		return int64(data)
+	case string:
+		if i, ok := ArrayIndex(data); ok {
+			return i
+		}
+		return -1
 	default:
 		return -1
 	}
 }
 
-func (array *arrayImpl) Get(index any) any {
-	i := convertAnyToInt(index)
-	if i >= 0 && i <= int64(len(array.value)-1) {
-		return array.value[i]
+// arrayIndex returns the index a key names, if it is an array index.
+func arrayIndex(key any) (int64, bool) {
+	i := convertAnyToInt(key)
+	return i, i >= 0 && i < maxArrayLength
+}
+
+// toArrayLength validates a new length, throwing a RangeError when it is not
+// an integer in range.
+func toArrayLength(interpreter types.Interpreter, value any) int64 {
+	number := ToNumber(nil, value)
+	if number < 0 || number > maxArrayLength || number != math.Trunc(number) {
+		ThrowRangeError(interpreter, "Invalid array length")
+	}
+	return int64(number)
+}
+
+// copyLength checks that an array of length elements can be allocated for
+// a copy.
+func copyLength(interpreter types.Interpreter, length int64) int64 {
+	if length > maxCopyLength {
+		ThrowRangeError(interpreter, "Invalid array length")
+	}
+	return length
+}
+
+// setLength truncates the array or extends it with holes.
+func (array *arrayImpl) setLength(length int64) {
+	if length < int64(len(array.dense)) {
+		for i := length; i < int64(len(array.dense)); i++ {
+			array.dense[i] = nil
+		}
+		array.dense = array.dense[:length]
+	}
+	if length < array.length {
+		for i := range array.sparse {
+			if i >= length {
+				delete(array.sparse, i)
+			}
+		}
+	}
+	array.length = length
+}
+
+// element returns the element at index, reporting false for a hole.
+func (array *arrayImpl) element(i int64) (any, bool) {
+	if i < int64(len(array.dense)) {
+		value := array.dense[i]
+		_, hole := value.(arrayHole)
+		return value, !hole
+	}
+	value, ok := array.sparse[i]
+	return value, ok
+}
+
+// setElement stores an element densely when it is near the end of the dense
+// elements, moving the sparse ones it reaches into the slice so that a
+// sparse array filled up from the front becomes dense again.
+func (array *arrayImpl) setElement(i int64, value any) {
+	if i < int64(len(array.dense)) {
+		array.dense[i] = value
+	} else if i-int64(len(array.dense)) <= maxDenseGap {
+		for int64(len(array.dense)) < i {
+			array.dense = append(array.dense, array.takeSparse(int64(len(array.dense))))
+		}
+		array.takeSparse(i)
+		array.dense = append(array.dense, value)
+		for {
+			if _, ok := array.sparse[int64(len(array.dense))]; !ok {
+				break
+			}
+			array.dense = append(array.dense, array.takeSparse(int64(len(array.dense))))
+		}
+		if len(array.sparse) == 0 {
+			array.sparse, array.sorted = nil, nil
+		}
+	} else {
+		if array.sparse == nil {
+			array.sparse = make(map[int64]any)
+		}
+		if _, ok := array.sparse[i]; !ok {
+			array.sorted = nil
+		}
+		array.sparse[i] = value
+	}
+	if i >= array.length {
+		array.length = i + 1
+	}
+}
+
+// takeSparse removes the sparse element at i, returning a hole when there
+// is none.
+func (array *arrayImpl) takeSparse(i int64) any {
+	value, ok := array.sparse[i]
+	if !ok {
+		return arrayHole{}
+	}
+	delete(array.sparse, i)
+	return value
+}
+
+// sparseKeys returns the indices of the sparse elements in order. Some may
+// have been deleted since.
+func (array *arrayImpl) sparseKeys() []int64 {
+	if array.sorted == nil && len(array.sparse) > 0 {
+		array.sorted = make([]int64, 0, len(array.sparse))
+		for i := range array.sparse {
+			array.sorted = append(array.sorted, i)
+		}
+		sort.Slice(array.sorted, func(a, b int) bool {
+			return array.sorted[a] < array.sorted[b]
+		})
+	}
+	return array.sorted
+}
+
+// nextElement returns the first index from k below end that has an
+// element, or end when there is none.
+func (array *arrayImpl) nextElement(k int64, end int64) int64 {
+	for ; k < int64(len(array.dense)) && k < end; k++ {
+		if _, hole := array.dense[k].(arrayHole); !hole {
+			return k
+		}
+	}
+	keys := array.sparseKeys()
+	for i := sort.Search(len(keys), func(i int) bool { return keys[i] >= k }); i < len(keys) && keys[i] < end; i++ {
+		if _, ok := array.sparse[keys[i]]; ok {
+			return keys[i]
+		}
+	}
+	return end
+}
+
+// sparseArray returns object as an array stored sparsely whose prototypes
+// have no elements, so that its holes can be skipped.
+func sparseArray(object types.Object) (*arrayImpl, bool) {
+	array, ok := object.(*arrayImpl)
+	if !ok || array.sparse == nil {
+		return nil, false
+	}
+	for proto := array.GetPrototype(); proto != nil; {
+		current, ok := proto.(types.Object)
+		if _, proxy := asProxy(proto); !ok || proxy {
+			// a proxy may report elements it does not list
+			return nil, false
+		}
+		for _, key := range current.OwnKeys() {
+			if _, ok := arrayIndex(key); ok {
+				return nil, false
+			}
+		}
+		proto = current.GetPrototype()
+	}
+	return array, true
+}
+
+// nextElements returns the step of a loop over the indices of object below
+// end. It is the identity but for a sparse array, where it skips to the
+// next element instead of visiting every hole.
+func nextElements(object types.Object, end int64) func(k int64) int64 {
+	array, ok := sparseArray(object)
+	if !ok {
+		return func(k int64) int64 {
+			return k
+		}
+	}
+	return func(k int64) int64 {
+		return array.nextElement(k, end)
+	}
+}
+
+func (array *arrayImpl) Get(key any) any {
+	if key == "length" {
+		return array.length
+	}
+	if i, ok := arrayIndex(key); ok {
+		if value, ok := array.element(i); ok {
+			return value
+		}
+	}
+	return array.instanceImpl.Get(key)
+}
+
+func (array *arrayImpl) Set(key any, value any) {
+	if key == "length" {
+		array.setLength(toArrayLength(nil, value))
+		return
+	}
+	if i, ok := arrayIndex(key); ok {
+		_, exists := array.element(i)
+		if array.canSet(ToPropertyKey(key), exists) {
+			array.setElement(i, value)
+		}
+		return
+	}
+	array.instanceImpl.Set(key, value)
+}
+
+func (array *arrayImpl) Has(key any) bool {
+	if key == "length" {
+		return true
+	}
+	if i, ok := arrayIndex(key); ok {
+		_, ok := array.element(i)
+		return ok
+	}
+	return array.instanceImpl.Has(key)
+}
+
+// Delete leaves a hole at an index; length can not be deleted.
+func (array *arrayImpl) Delete(key any) bool {
+	if key == "length" {
+		return false
+	}
+	if i, ok := arrayIndex(key); ok {
+		key = ToPropertyKey(key)
+		if _, exists := array.element(i); exists && array.fixed[key] {
+			return false
+		}
+		delete(array.readonly, key)
+		if i < int64(len(array.dense)) {
+			array.dense[i] = arrayHole{}
+		} else {
+			delete(array.sparse, i)
+		}
+		return true
+	}
+	return array.instanceImpl.Delete(key)
+}
+
+func (array *arrayImpl) OwnKeys() []any {
+	keys := make([]any, 0, len(array.dense)+len(array.sparse)+1)
+	for i, item := range array.dense {
+		if _, ok := item.(arrayHole); !ok {
+			keys = append(keys, strconv.Itoa(i))
+		}
+	}
+	for _, i := range array.sparseKeys() {
+		if _, ok := array.sparse[i]; ok {
+			keys = append(keys, strconv.FormatInt(i, 10))
+		}
+	}
+	keys = append(keys, "length")
+	return append(keys, array.instanceImpl.OwnKeys()...)
+}
+
+func (array *arrayImpl) IsEnumerable(key any) bool {
+	if _, ok := arrayIndex(key); ok {
+		return array.Has(key)
 	}
-	return nil
+	return key != "length" && array.instanceImpl.IsEnumerable(key)
 }
 
-func (array *arrayImpl) Set(index any, value any) {
-	i := convertAnyToInt(index)
-	if i >= 0 && i <= int64(len(array.value)-1) {
-		array.value[i] = value
-	} else if i > int64(len(array.value)-1) {
-		t := make([]any, i+1)
-		copy(t, array.value)
-		array.value = t
-		array.value[i] = value
+func (array *arrayImpl) getOwnProperty(key any) (propertyDescriptor, bool) {
+	if key == "length" {
+		return dataDescriptor(array.length, true, false, false), true
+	}
+	if i, ok := arrayIndex(key); ok {
+		value, ok := array.element(i)
+		if !ok {
+			return propertyDescriptor{}, false
+		}
+		key = ToPropertyKey(key)
+		return dataDescriptor(value, !array.readonly[key], true, !array.fixed[key]), true
 	}
+	return array.instanceImpl.getOwnProperty(key)
 }
 
-func (array *arrayImpl) Has(index any) bool {
-	i := convertAnyToInt(index)
-	if i >= 0 && i <= int64(len(array.value)-1) {
+// defineOwnProperty fails for attributes arrays can not store: elements
+// are always enumerable and length is always writable.
+func (array *arrayImpl) defineOwnProperty(key any, desc propertyDescriptor) bool {
+	i, index := arrayIndex(key)
+	if key != "length" && !index {
+		return array.instanceImpl.defineOwnProperty(key, desc)
+	}
+	current, exists := array.getOwnProperty(key)
+	if !compatibleDescriptor(array.extensible, desc, current, exists) {
+		return false
+	}
+	if !exists {
+		current = dataDescriptor(nil, false, false, false)
+	}
+	current.update(desc)
+	if desc.isAccessor() || current.enumerable != index || (!index && !current.writable) {
+		return false
+	}
+	if !index {
+		if desc.hasValue {
+			array.setLength(toArrayLength(nil, desc.value))
+		}
 		return true
 	}
-	return false
+	array.setElement(i, current.value)
+	key = ToPropertyKey(key)
+	array.readonly[key] = !current.writable
+	array.fixed[key] = !current.configurable
+	return true
+}
+
+func newArrayConstructor(realm *realm) types.Object {
+	array := func(interpreter types.Interpreter, params []any) any {
+		if len(params) == 1 {
+			if _, ok := toFloat(params[0]); ok {
+				return newArrayWithLength(interpreter, toArrayLength(interpreter, params[0]))
+			}
+		}
+		return NewArrayFrom(interpreter, append([]any{}, params...))
+	}
+	constructor := newConstructor(realm, "Array", func(interpreter types.Interpreter, this any, params []any) any {
+		return array(interpreter, params)
+	}, array).(*nativeImpl)
+	constructor.define("isArray", newNative(realm, "isArray", func(interpreter types.Interpreter, this any, params []any) any {
+		return IsArray(GetArgument(params, 0))
+	}), false)
+	constructor.define("from", newNative(realm, "from", func(interpreter types.Interpreter, this any, params []any) any {
+		items := GetArgument(params, 0)
+		mapper := GetArgument(params, 1)
+		if mapper != nil {
+			checkCallable(interpreter, mapper)
+		}
+		mapValue := func(value any, k int64) any {
+			if mapper == nil {
+				return value
+			}
+			return Invoke(interpreter, mapper, GetArgument(params, 2), []any{value, k})
+		}
+		result := NewArray(interpreter)
+		if GetProperty(interpreter, items, SymbolIterator) == nil {
+			object := ToObject(interpreter, items)
+			length := lengthOf(interpreter, object)
+			for k := int64(0); k < length; k++ {
+				result.Set(k, mapValue(object.Get(k), k))
+			}
+			result.Set("length", length)
+			return result
+		}
+		iterator := GetIterator(interpreter, items)
+		var k int64
+		for {
+			value, ok := iterator.Step(interpreter)
+			if !ok {
+				return result
+			}
+			if reason, threw := recoverThrow(func() {
+				value = mapValue(value, k)
+			}); threw {
+				iterator.Close(interpreter)
+				panic(flow.NewThrow(reason))
+			}
+			result.Set(k, value)
+			k++
+		}
+	}), false)
+	constructor.define("of", newNative(realm, "of", func(interpreter types.Interpreter, this any, params []any) any {
+		return NewArrayFrom(interpreter, append([]any{}, params...))
+	}), false)
+	constructor.define("prototype", realm.arrayPrototype, false)
+	realm.arrayPrototype.define("constructor", constructor, false)
+	return constructor
 }
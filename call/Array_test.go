@@ -51,7 +51,7 @@ func TestArray(t *testing.T) {
 			nil,
 		},
 	}
-	arr := NewArray()
+	arr := NewArray(nil)
 	for _, item := range tests {
 		if item.actionType == "set" {
 			arr.Set(item.index, item.value)
@@ -65,3 +65,60 @@ func TestArray(t *testing.T) {
 		}
 	}
 }
+
+func TestArrayLength(t *testing.T) {
+	arr := NewArray(nil)
+	arr.Set(int64(2), "c")
+	if arr.Get("length") != int64(3) || arr.Has(int64(0)) {
+		t.Errorf("expect length 3 with holes, actual: %v", arr.Get("length"))
+	}
+	arr.Set("length", int64(1))
+	if arr.Get("length") != int64(1) || arr.Get(int64(2)) != nil {
+		t.Errorf("expect truncated array, actual: %v", arr.Get("length"))
+	}
+	arr.Set(int64(0), "a")
+	arr.Delete(int64(0))
+	if arr.Has(int64(0)) || arr.Get("length") != int64(1) {
+		t.Errorf("expect hole at 0, actual: %v", arr.Get(int64(0)))
+	}
+}
+
+func TestArraySparse(t *testing.T) {
+	arr := NewArray(nil).(*arrayImpl)
+	arr.Set(int64(1e9), "a")
+	arr.Set(int64(2000), "b")
+	if len(arr.dense) != 0 || arr.Get("length") != int64(1e9+1) {
+		t.Errorf("expect sparse storage, actual: %d dense elements", len(arr.dense))
+	}
+	if arr.Get(int64(1e9)) != "a" || arr.Get("2000") != "b" || arr.Has(int64(4)) {
+		t.Errorf("expect sparse elements, actual: %v %v", arr.Get(int64(1e9)), arr.Get("2000"))
+	}
+	keys := arr.OwnKeys()
+	if len(keys) != 3 || keys[0] != "2000" || keys[1] != "1000000000" || keys[2] != "length" {
+		t.Errorf("expect sorted keys, actual: %v", keys)
+	}
+	arr.Set("length", int64(2001))
+	if arr.Has(int64(1e9)) || arr.Get(int64(2000)) != "b" {
+		t.Errorf("expect truncated sparse array, actual: %v", arr.OwnKeys())
+	}
+	arr.Set(int64(-1), "c")
+	arr.Set("foo", "d")
+	if arr.Get("-1") != "c" || arr.Get("foo") != "d" || arr.Get("length") != int64(2001) {
+		t.Errorf("expect properties, actual: %v", arr.OwnKeys())
+	}
+}
+
+func TestArrayDensify(t *testing.T) {
+	arr := NewArray(nil).(*arrayImpl)
+	arr.Set(int64(5000), "a")
+	for i := int64(0); i < 5000; i++ {
+		arr.Set(i, i)
+	}
+	if arr.sparse != nil || len(arr.dense) != 5001 || arr.Get(int64(5000)) != "a" {
+		t.Errorf("expect dense storage, actual: %d dense elements", len(arr.dense))
+	}
+	arr.Set(int64(5001), "b")
+	if arr.sparse != nil || arr.Get("length") != int64(5002) {
+		t.Errorf("expect dense append, actual: %v", arr.Get("length"))
+	}
+}
@@ -0,0 +1,398 @@
+package call
+
+import (
+	"encoding/binary"
+	"math"
+
+	"github.com/nusr/gojs/types"
+)
+
+// maxAllocation is the largest buffer a script can allocate.
+const maxAllocation = 1 << 30
+
+// arrayBufferImpl is an ArrayBuffer or SharedArrayBuffer. A resizable
+// buffer allocates its maximum capacity up front, so resizing never moves
+// the bytes the embedder may hold.
+type arrayBufferImpl struct {
+	*instanceImpl
+	data          []byte
+	maxByteLength int64 // -1 unless the buffer is resizable or growable
+	shared        bool
+	detached      bool
+}
+
+// NewArrayBuffer creates an ArrayBuffer over data without copying it, so
+// the embedder and scripts see each other's writes.
+func NewArrayBuffer(interpreter types.Interpreter, data []byte) types.Object {
+	return newArrayBuffer(interpreter, data, -1, false)
+}
+
+// NewSharedArrayBuffer creates a SharedArrayBuffer over data without
+// copying it.
+func NewSharedArrayBuffer(interpreter types.Interpreter, data []byte) types.Object {
+	return newArrayBuffer(interpreter, data, -1, true)
+}
+
+// Bytes returns the bytes of an ArrayBuffer or SharedArrayBuffer, or the
+// part of its buffer a typed array or DataView covers, without copying.
+func Bytes(value any) ([]byte, bool) {
+	switch data := value.(type) {
+	case *arrayBufferImpl:
+		return data.data, !data.detached
+	case *typedArrayImpl:
+		if data.outOfBounds() {
+			return nil, false
+		}
+		return data.buffer.data[data.offset : data.offset+data.length()*data.kind.size], true
+	case *dataViewImpl:
+		if data.buffer.detached || data.offset > int64(len(data.buffer.data)) {
+			return nil, false
+		}
+		return data.buffer.data[data.offset : data.offset+data.size()], true
+	}
+	return nil, false
+}
+
+func newArrayBuffer(interpreter types.Interpreter, data []byte, maxByteLength int64, shared bool) *arrayBufferImpl {
+	realm := realmOf(interpreter)
+	prototype := realm.arrayBufferPrototype
+	if shared {
+		prototype = realm.sharedArrayBufferPrototype
+	}
+	return &arrayBufferImpl{
+		instanceImpl:  NewObject(prototype).(*instanceImpl),
+		data:          data,
+		maxByteLength: maxByteLength,
+		shared:        shared,
+	}
+}
+
+// allocateArrayBuffer creates a zeroed buffer, with room to grow to
+// maxByteLength unless that is -1.
+func allocateArrayBuffer(interpreter types.Interpreter, length int64, maxByteLength int64, shared bool) *arrayBufferImpl {
+	capacity := max(length, maxByteLength)
+	if capacity > maxAllocation {
+		ThrowRangeError(interpreter, "Array buffer allocation failed")
+	}
+	return newArrayBuffer(interpreter, make([]byte, length, capacity), maxByteLength, shared)
+}
+
+// toIndex converts a value to an offset or length, reporting false when it
+// is negative or too large.
+func toIndex(interpreter types.Interpreter, value any) (int64, bool) {
+	index := toIntegerOrInfinity(interpreter, value)
+	if index < 0 || index > maxSafeInteger {
+		return 0, false
+	}
+	return int64(index), true
+}
+
+func (buffer *arrayBufferImpl) Get(key any) any {
+	switch key {
+	case "byteLength":
+		return int64(len(buffer.data))
+	case "maxByteLength":
+		if buffer.maxByteLength < 0 {
+			return int64(len(buffer.data))
+		}
+		return buffer.maxByteLength
+	case "resizable":
+		if !buffer.shared {
+			return buffer.maxByteLength >= 0
+		}
+	case "growable":
+		if buffer.shared {
+			return buffer.maxByteLength >= 0
+		}
+	case "detached":
+		if !buffer.shared {
+			return buffer.detached
+		}
+	}
+	return buffer.instanceImpl.Get(key)
+}
+
+// resize changes the length of a resizable buffer within its capacity,
+// zeroing the bytes it grows into.
+func (buffer *arrayBufferImpl) resize(length int64) {
+	old := int64(len(buffer.data))
+	buffer.data = buffer.data[:length]
+	for i := old; i < length; i++ {
+		buffer.data[i] = 0
+	}
+}
+
+func (buffer *arrayBufferImpl) detach() {
+	buffer.data = nil
+	buffer.detached = true
+}
+
+func thisArrayBuffer(interpreter types.Interpreter, this any, name string, shared bool) *arrayBufferImpl {
+	buffer, ok := this.(*arrayBufferImpl)
+	kind := "ArrayBuffer"
+	if shared {
+		kind = "SharedArrayBuffer"
+	}
+	if !ok || buffer.shared != shared {
+		ThrowTypeError(interpreter, "Method %s.prototype.%s called on incompatible receiver %s", kind, name, describe(this))
+	}
+	if buffer.detached {
+		ThrowTypeError(interpreter, "Cannot perform %s.prototype.%s on a detached ArrayBuffer", kind, name)
+	}
+	return buffer
+}
+
+func newArrayBufferPrototype(realm *realm, shared bool) *instanceImpl {
+	prototype := NewObject(realm.objectPrototype).(*instanceImpl)
+	kind := "ArrayBuffer"
+	if shared {
+		kind = "SharedArrayBuffer"
+	}
+	prototype.define("slice", newNative(realm, "slice", func(interpreter types.Interpreter, this any, params []any) any {
+		buffer := thisArrayBuffer(interpreter, this, "slice", shared)
+		length := int64(len(buffer.data))
+		start := relativeIndex(interpreter, GetArgument(params, 0), length)
+		end := endIndex(interpreter, GetArgument(params, 1), length)
+		result := allocateArrayBuffer(interpreter, max(end-start, 0), -1, shared)
+		if start < end {
+			copy(result.data, buffer.data[start:end])
+		}
+		return result
+	}), false)
+	if shared {
+		prototype.define("grow", newNative(realm, "grow", func(interpreter types.Interpreter, this any, params []any) any {
+			buffer := thisArrayBuffer(interpreter, this, "grow", true)
+			if buffer.maxByteLength < 0 {
+				ThrowTypeError(interpreter, "Method SharedArrayBuffer.prototype.grow called on incompatible receiver %s", describe(this))
+			}
+			length, ok := toIndex(interpreter, GetArgument(params, 0))
+			if !ok || length < int64(len(buffer.data)) || length > buffer.maxByteLength {
+				ThrowRangeError(interpreter, "SharedArrayBuffer.prototype.grow: Invalid length parameter")
+			}
+			buffer.resize(length)
+			return nil
+		}), false)
+	} else {
+		prototype.define("resize", newNative(realm, "resize", func(interpreter types.Interpreter, this any, params []any) any {
+			buffer := thisArrayBuffer(interpreter, this, "resize", false)
+			if buffer.maxByteLength < 0 {
+				ThrowTypeError(interpreter, "Method ArrayBuffer.prototype.resize called on incompatible receiver %s", describe(this))
+			}
+			length, ok := toIndex(interpreter, GetArgument(params, 0))
+			if !ok || length > buffer.maxByteLength {
+				ThrowRangeError(interpreter, "ArrayBuffer.prototype.resize: Invalid length parameter")
+			}
+			buffer.resize(length)
+			return nil
+		}), false)
+		transfer := func(name string, fixed bool) {
+			prototype.define(name, newNative(realm, name, func(interpreter types.Interpreter, this any, params []any) any {
+				buffer := thisArrayBuffer(interpreter, this, name, false)
+				length := int64(len(buffer.data))
+				if value := GetArgument(params, 0); value != nil {
+					var ok bool
+					if length, ok = toIndex(interpreter, value); !ok {
+						ThrowRangeError(interpreter, "Invalid array buffer length")
+					}
+				}
+				maxByteLength := buffer.maxByteLength
+				if fixed {
+					maxByteLength = -1
+				} else if maxByteLength >= 0 && length > maxByteLength {
+					ThrowRangeError(interpreter, "ArrayBuffer.prototype.%s: Invalid length parameter", name)
+				}
+				result := allocateArrayBuffer(interpreter, length, maxByteLength, false)
+				copy(result.data, buffer.data)
+				buffer.detach()
+				return result
+			}), false)
+		}
+		transfer("transfer", false)
+		transfer("transferToFixedLength", true)
+	}
+	prototype.define(SymbolToStringTag, kind, false)
+	return prototype
+}
+
+func newArrayBufferConstructor(realm *realm, shared bool) types.Object {
+	kind := "ArrayBuffer"
+	prototype := realm.arrayBufferPrototype
+	if shared {
+		kind = "SharedArrayBuffer"
+		prototype = realm.sharedArrayBufferPrototype
+	}
+	constructor := newConstructor(realm, kind, func(interpreter types.Interpreter, this any, params []any) any {
+		ThrowTypeError(interpreter, "Constructor %s requires 'new'", kind)
+		return nil
+	}, func(interpreter types.Interpreter, params []any) any {
+		length, ok := toIndex(interpreter, GetArgument(params, 0))
+		if !ok {
+			ThrowRangeError(interpreter, "Invalid array buffer length")
+		}
+		maxByteLength := int64(-1)
+		if options, ok := GetArgument(params, 1).(types.Object); ok {
+			if value := options.Get("maxByteLength"); value != nil {
+				maxByteLength, ok = toIndex(interpreter, value)
+				if !ok || length > maxByteLength {
+					ThrowRangeError(interpreter, "Invalid array buffer max length")
+				}
+			}
+		}
+		return allocateArrayBuffer(interpreter, length, maxByteLength, shared)
+	}).(*nativeImpl)
+	if !shared {
+		constructor.define("isView", newNative(realm, "isView", func(interpreter types.Interpreter, this any, params []any) any {
+			switch GetArgument(params, 0).(type) {
+			case *typedArrayImpl, *dataViewImpl:
+				return true
+			}
+			return false
+		}), false)
+	}
+	constructor.define("prototype", prototype, false)
+	prototype.define("constructor", constructor, false)
+	return constructor
+}
+
+// dataViewImpl reads and writes numbers of any type and byte order in an
+// ArrayBuffer.
+type dataViewImpl struct {
+	*instanceImpl
+	buffer     *arrayBufferImpl
+	offset     int64
+	byteLength int64 // -1 when the view tracks a resizable buffer
+}
+
+// size returns the length of the view, 0 when its buffer was detached or
+// shrunk below it.
+func (view *dataViewImpl) size() int64 {
+	length := int64(len(view.buffer.data))
+	if view.buffer.detached || view.offset > length {
+		return 0
+	}
+	if view.byteLength < 0 {
+		return length - view.offset
+	}
+	if view.offset+view.byteLength > length {
+		return 0
+	}
+	return view.byteLength
+}
+
+func (view *dataViewImpl) Get(key any) any {
+	switch key {
+	case "buffer":
+		return view.buffer
+	case "byteLength":
+		return view.size()
+	case "byteOffset":
+		return view.offset
+	}
+	return view.instanceImpl.Get(key)
+}
+
+// element returns the bytes of a value of size at the index a script
+// passed, or throws when they are outside the view.
+func (view *dataViewImpl) element(interpreter types.Interpreter, value any, size int64) []byte {
+	index, ok := toIndex(interpreter, value)
+	if !ok || index+size > view.size() {
+		ThrowRangeError(interpreter, "Offset is outside the bounds of the DataView")
+	}
+	start := view.offset + index
+	return view.buffer.data[start : start+size]
+}
+
+func byteOrder(littleEndian any) binary.ByteOrder {
+	if ToBoolean(littleEndian) {
+		return binary.LittleEndian
+	}
+	return binary.BigEndian
+}
+
+func newDataViewPrototype(realm *realm) *instanceImpl {
+	prototype := NewObject(realm.objectPrototype).(*instanceImpl)
+	thisView := func(interpreter types.Interpreter, this any, name string) *dataViewImpl {
+		view, ok := this.(*dataViewImpl)
+		if !ok {
+			ThrowTypeError(interpreter, "Method DataView.prototype.%s called on incompatible receiver %s", name, describe(this))
+		}
+		if view.buffer.detached {
+			ThrowTypeError(interpreter, "Cannot perform DataView.prototype.%s on a detached ArrayBuffer", name)
+		}
+		return view
+	}
+	for _, kind := range typedArrayKinds {
+		if kind.name == "Uint8ClampedArray" {
+			continue
+		}
+		name := kind.name[:len(kind.name)-len("Array")]
+		prototype.define("get"+name, newNative(realm, "get"+name, func(interpreter types.Interpreter, this any, params []any) any {
+			view := thisView(interpreter, this, "get"+name)
+			data := view.element(interpreter, GetArgument(params, 0), kind.size)
+			return kind.get(data, byteOrder(GetArgument(params, 1)))
+		}), false)
+		prototype.define("set"+name, newNative(realm, "set"+name, func(interpreter types.Interpreter, this any, params []any) any {
+			view := thisView(interpreter, this, "set"+name)
+			value := ToNumber(interpreter, GetArgument(params, 1))
+			data := view.element(interpreter, GetArgument(params, 0), kind.size)
+			kind.set(data, byteOrder(GetArgument(params, 2)), value)
+			return nil
+		}), false)
+	}
+	prototype.define(SymbolToStringTag, "DataView", false)
+	return prototype
+}
+
+func newDataViewConstructor(realm *realm) types.Object {
+	constructor := newConstructor(realm, "DataView", func(interpreter types.Interpreter, this any, params []any) any {
+		ThrowTypeError(interpreter, "Constructor DataView requires 'new'")
+		return nil
+	}, func(interpreter types.Interpreter, params []any) any {
+		buffer, ok := GetArgument(params, 0).(*arrayBufferImpl)
+		if !ok {
+			ThrowTypeError(interpreter, "First argument to DataView constructor must be an ArrayBuffer")
+		}
+		offset, ok := toIndex(interpreter, GetArgument(params, 1))
+		if buffer.detached {
+			ThrowTypeError(interpreter, "Cannot perform DataView constructor on a detached ArrayBuffer")
+		}
+		length := int64(len(buffer.data))
+		if !ok || offset > length {
+			ThrowRangeError(interpreter, "Start offset %s is outside the bounds of the buffer", NumberToString(ToNumber(interpreter, GetArgument(params, 1))))
+		}
+		byteLength := int64(-1)
+		if value := GetArgument(params, 2); value != nil {
+			byteLength, ok = toIndex(interpreter, value)
+			if !ok || offset+byteLength > length {
+				ThrowRangeError(interpreter, "Invalid DataView length %s", NumberToString(ToNumber(interpreter, value)))
+			}
+		} else if buffer.maxByteLength < 0 {
+			byteLength = length - offset
+		}
+		return &dataViewImpl{
+			instanceImpl: NewObject(realm.dataViewPrototype).(*instanceImpl),
+			buffer:       buffer,
+			offset:       offset,
+			byteLength:   byteLength,
+		}
+	}).(*nativeImpl)
+	constructor.define("prototype", realm.dataViewPrototype, false)
+	realm.dataViewPrototype.define("constructor", constructor, false)
+	return constructor
+}
+
+// float32Value rounds a number to the nearest float32.
+func float32Value(value float64) float64 {
+	return float64(float32(value))
+}
+
+// clampByte converts a number for a Uint8ClampedArray, rounding half to even.
+func clampByte(value float64) uint8 {
+	if math.IsNaN(value) || value <= 0 {
+		return 0
+	}
+	if value >= 255 {
+		return 255
+	}
+	return uint8(math.RoundToEven(value))
+}
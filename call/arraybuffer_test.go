@@ -0,0 +1,57 @@
+package call
+
+import (
+	"testing"
+)
+
+func TestNewArrayBuffer(t *testing.T) {
+	data := []byte{1, 2, 3, 4}
+	buffer := NewArrayBuffer(nil, data)
+	if length := buffer.Get("byteLength"); length != int64(4) {
+		t.Fatalf("expect byteLength= 4, actual= %v", length)
+	}
+	kind := typedArrayKinds[3] // Int16Array
+	array := typedArrayOnBuffer(nil, kind, buffer.(*arrayBufferImpl), int64(2), nil)
+	array.Set(int64(0), int64(-2))
+	if data[2] != 0xfe || data[3] != 0xff {
+		t.Errorf("expect writes to reach the Go slice, actual= %v", data)
+	}
+	data[0] = 9
+	if value := typedArrayOnBuffer(nil, typedArrayKinds[1], buffer.(*arrayBufferImpl), nil, nil).Get(int64(0)); value != int64(9) {
+		t.Errorf("expect Go writes to reach the script, actual= %v", value)
+	}
+}
+
+func TestBytes(t *testing.T) {
+	data := []byte{1, 2, 3, 4}
+	buffer := NewSharedArrayBuffer(nil, data).(*arrayBufferImpl)
+	view := &dataViewImpl{instanceImpl: NewObject(realmOf(nil).dataViewPrototype).(*instanceImpl), buffer: buffer, offset: 1, byteLength: 2}
+	tests := []struct {
+		name  string
+		value any
+		want  []byte
+		ok    bool
+	}{
+		{"buffer", buffer, data, true},
+		{"typed array", typedArrayOnBuffer(nil, typedArrayKinds[1], buffer, int64(1), int64(3)), data[1:], true},
+		{"data view", view, data[1:3], true},
+		{"object", NewInstance(nil), nil, false},
+		{"string", "abc", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, ok := Bytes(tt.value)
+			if ok != tt.ok || string(actual) != string(tt.want) {
+				t.Errorf("expect= %v %v, actual= %v %v", tt.want, tt.ok, actual, ok)
+			}
+			if ok && &actual[0] != &tt.want[0] {
+				t.Errorf("expect the bytes to be shared, not copied")
+			}
+		})
+	}
+	detached := newArrayBuffer(nil, []byte{1}, -1, false)
+	detached.detach()
+	if _, ok := Bytes(detached); ok {
+		t.Errorf("expect a detached buffer to have no bytes")
+	}
+}
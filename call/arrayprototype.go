@@ -0,0 +1,829 @@
+package call
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/nusr/gojs/types"
+)
+
+// maxSafeInteger bounds the length of array-like objects, 2^53-1.
+const maxSafeInteger = 1<<53 - 1
+
+// joining holds the arrays being joined, so cycles print as "".
+var joining = struct {
+	sync.Mutex
+	objects map[types.Object]bool
+}{objects: make(map[types.Object]bool)}
+
+// toIntegerOrInfinity converts a value to an integer, keeping infinities.
+func toIntegerOrInfinity(interpreter types.Interpreter, value any) float64 {
+	number := ToNumber(interpreter, value)
+	if math.IsNaN(number) {
+		return 0
+	}
+	return math.Trunc(number)
+}
+
+// toLength clamps a value to a valid length, [0, 2^53-1].
+func toLength(interpreter types.Interpreter, value any) int64 {
+	length := toIntegerOrInfinity(interpreter, value)
+	if length <= 0 {
+		return 0
+	}
+	return int64(math.Min(length, maxSafeInteger))
+}
+
+// lengthOf reads the length of an array-like object.
+func lengthOf(interpreter types.Interpreter, object types.Object) int64 {
+	return toLength(interpreter, object.Get("length"))
+}
+
+// relativeIndex resolves a possibly negative index against length, clamping
+// it to [0, length].
+func relativeIndex(interpreter types.Interpreter, value any, length int64) int64 {
+	relative := toIntegerOrInfinity(interpreter, value)
+	if relative < 0 {
+		return int64(math.Max(float64(length)+relative, 0))
+	}
+	return int64(math.Min(relative, float64(length)))
+}
+
+// endIndex is relativeIndex with undefined meaning length.
+func endIndex(interpreter types.Interpreter, value any, length int64) int64 {
+	if value == nil {
+		return length
+	}
+	return relativeIndex(interpreter, value, length)
+}
+
+func checkCallable(interpreter types.Interpreter, callback any) {
+	if _, ok := callback.(types.Function); !ok {
+		ThrowTypeError(interpreter, "%s is not a function", describe(callback))
+	}
+}
+
+// SameValueZero compares like === except that NaN equals NaN.
+func SameValueZero(left any, right any) bool {
+	if a, b, ok := toFloat2(left, right); ok {
+		return a == b || (math.IsNaN(a) && math.IsNaN(b))
+	}
+	return left == right
+}
+
+// compareStrings orders strings by UTF-16 code units, as JavaScript does.
+func compareStrings(a string, b string) int {
+	x := toUTF16(a)
+	y := toUTF16(b)
+	for i := 0; i < len(x) && i < len(y); i++ {
+		if x[i] != y[i] {
+			if x[i] < y[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return len(x) - len(y)
+}
+
+func isConcatSpreadable(value any) bool {
+	object, ok := value.(types.Object)
+	if !ok {
+		return false
+	}
+	if spreadable := object.Get(SymbolIsConcatSpreadable); spreadable != nil {
+		return ToBoolean(spreadable)
+	}
+	return IsArray(object)
+}
+
+// flattenIntoArray copies the elements of source into target from index,
+// descending into nested arrays depth levels deep.
+func flattenIntoArray(interpreter types.Interpreter, target types.Object, source types.Object, index int64, depth float64, mapper any, thisArg any) int64 {
+	length := lengthOf(interpreter, source)
+	for k := int64(0); k < length; k++ {
+		if !HasProperty(source, k) {
+			continue
+		}
+		element := source.Get(k)
+		if mapper != nil {
+			element = Invoke(interpreter, mapper, thisArg, []any{element, k, source})
+		}
+		if nested, ok := element.(types.Object); ok && depth > 0 && IsArray(nested) {
+			index = flattenIntoArray(interpreter, target, nested, index, depth-1, nil, nil)
+			continue
+		}
+		target.Set(index, element)
+		index++
+	}
+	return index
+}
+
+// sortValues sorts values stably with comparator, undefined last.
+func sortValues(interpreter types.Interpreter, values []any, comparator any) []any {
+	var list []any
+	var undefined int
+	for _, value := range values {
+		if value == nil {
+			undefined++
+		} else {
+			list = append(list, value)
+		}
+	}
+	if comparator != nil {
+		sort.SliceStable(list, func(a, b int) bool {
+			return ToNumber(interpreter, Invoke(interpreter, comparator, nil, []any{list[a], list[b]})) < 0
+		})
+	} else {
+		keys := make([]string, len(list))
+		for i, value := range list {
+			keys[i] = ToString(interpreter, value)
+		}
+		indices := make([]int, len(list))
+		for i := range indices {
+			indices[i] = i
+		}
+		sort.SliceStable(indices, func(a, b int) bool {
+			return compareStrings(keys[indices[a]], keys[indices[b]]) < 0
+		})
+		sorted := make([]any, len(list))
+		for i, index := range indices {
+			sorted[i] = list[index]
+		}
+		list = sorted
+	}
+	for ; undefined > 0; undefined-- {
+		list = append(list, nil)
+	}
+	return list
+}
+
+// joinElements joins the strings toString makes of the elements of object
+// below length, leaving null and undefined empty. Only the elements of a
+// sparse array are visited, and a result longer than a string can be is a
+// RangeError.
+func joinElements(interpreter types.Interpreter, object types.Object, length int64, separator string, toString func(element any) string) string {
+	if length == 0 {
+		return ""
+	}
+	if float64(len(toUTF16(separator)))*float64(length-1) > maxStringLength {
+		ThrowRangeError(interpreter, "Invalid string length")
+	}
+	var result strings.Builder
+	last := int64(0)
+	next := nextElements(object, length)
+	for k := next(0); k < length; k = next(k + 1) {
+		if element := object.Get(k); !types.IsNullish(element) {
+			text := toString(element)
+			result.WriteString(strings.Repeat(separator, int(k-last)))
+			result.WriteString(text)
+			last = k
+			if result.Len() > maxStringLength {
+				ThrowRangeError(interpreter, "Invalid string length")
+			}
+		}
+	}
+	result.WriteString(strings.Repeat(separator, int(length-1-last)))
+	return result.String()
+}
+
+func checkComparator(interpreter types.Interpreter, comparator any) {
+	if _, ok := comparator.(types.Function); comparator != nil && !ok {
+		ThrowTypeError(interpreter, "The comparison function must be either a function or undefined")
+	}
+}
+
+// findIndex runs predicate over the indices from start towards end,
+// returning the first match or -1.
+func findIndex(interpreter types.Interpreter, object types.Object, params []any, reverse bool) (int64, any) {
+	length := lengthOf(interpreter, object)
+	predicate := GetArgument(params, 0)
+	checkCallable(interpreter, predicate)
+	for i := int64(0); i < length; i++ {
+		k := i
+		if reverse {
+			k = length - 1 - i
+		}
+		value := object.Get(k)
+		if ToBoolean(Invoke(interpreter, predicate, GetArgument(params, 1), []any{value, k, object})) {
+			return k, value
+		}
+	}
+	return -1, nil
+}
+
+// reduce folds the elements, visiting them from the end when reverse is set.
+func reduce(interpreter types.Interpreter, object types.Object, params []any, reverse bool) any {
+	length := lengthOf(interpreter, object)
+	callback := GetArgument(params, 0)
+	checkCallable(interpreter, callback)
+	index := func(i int64) int64 {
+		if reverse {
+			return length - 1 - i
+		}
+		return i
+	}
+	var i int64
+	var accumulator any
+	if len(params) >= 2 {
+		accumulator = params[1]
+	} else {
+		for ; i < length && !HasProperty(object, index(i)); i++ {
+		}
+		if i >= length {
+			ThrowTypeError(interpreter, "Reduce of empty array with no initial value")
+		}
+		accumulator = object.Get(index(i))
+		i++
+	}
+	for ; i < length; i++ {
+		k := index(i)
+		if HasProperty(object, k) {
+			accumulator = Invoke(interpreter, callback, nil, []any{accumulator, object.Get(k), k, object})
+		}
+	}
+	return accumulator
+}
+
+func newArrayPrototype(realm *realm) *instanceImpl {
+	prototype := NewObject(realm.objectPrototype).(*instanceImpl)
+	method := func(name string, fn func(interpreter types.Interpreter, object types.Object, params []any) any) types.Method {
+		native := newNative(realm, name, func(interpreter types.Interpreter, this any, params []any) any {
+			if types.IsNullish(this) {
+				ThrowTypeError(interpreter, "Array.prototype.%s called on null or undefined", name)
+			}
+			return fn(interpreter, ToObject(interpreter, this), params)
+		})
+		prototype.define(name, native, false)
+		return native
+	}
+	// iterate calls callback for every element that is present.
+	iterate := func(interpreter types.Interpreter, object types.Object, params []any, fn func(k int64, value any, result any) bool) {
+		length := lengthOf(interpreter, object)
+		callback := GetArgument(params, 0)
+		checkCallable(interpreter, callback)
+		next := nextElements(object, length)
+		for k := next(0); k < length; k = next(k + 1) {
+			if !HasProperty(object, k) {
+				continue
+			}
+			value := object.Get(k)
+			if !fn(k, value, Invoke(interpreter, callback, GetArgument(params, 1), []any{value, k, object})) {
+				return
+			}
+		}
+	}
+
+	method("at", func(interpreter types.Interpreter, object types.Object, params []any) any {
+		length := lengthOf(interpreter, object)
+		relative := toIntegerOrInfinity(interpreter, GetArgument(params, 0))
+		if relative < 0 {
+			relative += float64(length)
+		}
+		if relative < 0 || relative >= float64(length) {
+			return nil
+		}
+		return object.Get(int64(relative))
+	})
+	method("concat", func(interpreter types.Interpreter, object types.Object, params []any) any {
+		result := NewArray(interpreter)
+		var n int64
+		for _, item := range append([]any{object}, params...) {
+			if !isConcatSpreadable(item) {
+				result.Set(n, item)
+				n++
+				continue
+			}
+			element := item.(types.Object)
+			length := lengthOf(interpreter, element)
+			if n+length > maxSafeInteger {
+				ThrowTypeError(interpreter, "Invalid array length")
+			}
+			for k := int64(0); k < length; k++ {
+				if HasProperty(element, k) {
+					result.Set(n, element.Get(k))
+				}
+				n++
+			}
+		}
+		result.Set("length", n)
+		return result
+	})
+	method("copyWithin", func(interpreter types.Interpreter, object types.Object, params []any) any {
+		length := lengthOf(interpreter, object)
+		to := relativeIndex(interpreter, GetArgument(params, 0), length)
+		from := relativeIndex(interpreter, GetArgument(params, 1), length)
+		final := endIndex(interpreter, GetArgument(params, 2), length)
+		count := final - from
+		if length-to < count {
+			count = length - to
+		}
+		direction := int64(1)
+		if from < to && to < from+count {
+			direction = -1
+			from += count - 1
+			to += count - 1
+		}
+		for ; count > 0; count-- {
+			if HasProperty(object, from) {
+				object.Set(to, object.Get(from))
+			} else {
+				object.Delete(to)
+			}
+			from += direction
+			to += direction
+		}
+		return object
+	})
+	method("entries", func(interpreter types.Interpreter, object types.Object, params []any) any {
+		return newArrayIterator(interpreter, object, arrayIteratorEntries)
+	})
+	method("every", func(interpreter types.Interpreter, object types.Object, params []any) any {
+		result := true
+		iterate(interpreter, object, params, func(k int64, value any, matched any) bool {
+			result = ToBoolean(matched)
+			return result
+		})
+		return result
+	})
+	method("fill", func(interpreter types.Interpreter, object types.Object, params []any) any {
+		length := lengthOf(interpreter, object)
+		final := endIndex(interpreter, GetArgument(params, 2), length)
+		for k := relativeIndex(interpreter, GetArgument(params, 1), length); k < final; k++ {
+			object.Set(k, GetArgument(params, 0))
+		}
+		return object
+	})
+	method("filter", func(interpreter types.Interpreter, object types.Object, params []any) any {
+		result := NewArray(interpreter)
+		var n int64
+		iterate(interpreter, object, params, func(k int64, value any, selected any) bool {
+			if ToBoolean(selected) {
+				result.Set(n, value)
+				n++
+			}
+			return true
+		})
+		return result
+	})
+	method("find", func(interpreter types.Interpreter, object types.Object, params []any) any {
+		_, value := findIndex(interpreter, object, params, false)
+		return value
+	})
+	method("findIndex", func(interpreter types.Interpreter, object types.Object, params []any) any {
+		k, _ := findIndex(interpreter, object, params, false)
+		return k
+	})
+	method("findLast", func(interpreter types.Interpreter, object types.Object, params []any) any {
+		_, value := findIndex(interpreter, object, params, true)
+		return value
+	})
+	method("findLastIndex", func(interpreter types.Interpreter, object types.Object, params []any) any {
+		k, _ := findIndex(interpreter, object, params, true)
+		return k
+	})
+	method("flat", func(interpreter types.Interpreter, object types.Object, params []any) any {
+		depth := float64(1)
+		if value := GetArgument(params, 0); value != nil {
+			depth = math.Max(toIntegerOrInfinity(interpreter, value), 0)
+		}
+		result := NewArray(interpreter)
+		flattenIntoArray(interpreter, result, object, 0, depth, nil, nil)
+		return result
+	})
+	method("flatMap", func(interpreter types.Interpreter, object types.Object, params []any) any {
+		mapper := GetArgument(params, 0)
+		if _, ok := mapper.(types.Function); !ok {
+			ThrowTypeError(interpreter, "flatMap mapper function is not callable")
+		}
+		result := NewArray(interpreter)
+		flattenIntoArray(interpreter, result, object, 0, 1, mapper, GetArgument(params, 1))
+		return result
+	})
+	method("forEach", func(interpreter types.Interpreter, object types.Object, params []any) any {
+		iterate(interpreter, object, params, func(k int64, value any, result any) bool {
+			return true
+		})
+		return nil
+	})
+	method("includes", func(interpreter types.Interpreter, object types.Object, params []any) any {
+		length := lengthOf(interpreter, object)
+		search := GetArgument(params, 0)
+		next := nextElements(object, length)
+		for k := relativeIndex(interpreter, GetArgument(params, 1), length); k < length; k++ {
+			if skip := next(k); skip > k {
+				// the holes skipped read as undefined
+				if search == nil {
+					return true
+				}
+				if k = skip; k >= length {
+					break
+				}
+			}
+			if SameValueZero(object.Get(k), search) {
+				return true
+			}
+		}
+		return false
+	})
+	method("indexOf", func(interpreter types.Interpreter, object types.Object, params []any) any {
+		length := lengthOf(interpreter, object)
+		next := nextElements(object, length)
+		for k := next(relativeIndex(interpreter, GetArgument(params, 1), length)); k < length; k = next(k + 1) {
+			if HasProperty(object, k) && StrictEquals(object.Get(k), GetArgument(params, 0)) {
+				return k
+			}
+		}
+		return int64(-1)
+	})
+	method("join", func(interpreter types.Interpreter, object types.Object, params []any) any {
+		length := lengthOf(interpreter, object)
+		separator := ","
+		if value := GetArgument(params, 0); value != nil {
+			separator = ToString(interpreter, value)
+		}
+		joining.Lock()
+		cyclic := joining.objects[object]
+		joining.objects[object] = true
+		joining.Unlock()
+		if cyclic {
+			return ""
+		}
+		defer func() {
+			joining.Lock()
+			delete(joining.objects, object)
+			joining.Unlock()
+		}()
+		return joinSurrogates(joinElements(interpreter, object, length, separator, func(element any) string {
+			return ToString(interpreter, element)
+		}))
+	})
+	method("keys", func(interpreter types.Interpreter, object types.Object, params []any) any {
+		return newArrayIterator(interpreter, object, arrayIteratorKeys)
+	})
+	method("lastIndexOf", func(interpreter types.Interpreter, object types.Object, params []any) any {
+		length := lengthOf(interpreter, object)
+		k := length - 1
+		if len(params) > 1 {
+			relative := toIntegerOrInfinity(interpreter, params[1])
+			if relative < 0 {
+				relative += float64(length)
+			}
+			k = int64(math.Max(math.Min(relative, float64(length-1)), -1))
+		}
+		for ; k >= 0; k-- {
+			if HasProperty(object, k) && StrictEquals(object.Get(k), GetArgument(params, 0)) {
+				return k
+			}
+		}
+		return int64(-1)
+	})
+	method("map", func(interpreter types.Interpreter, object types.Object, params []any) any {
+		result := newArrayWithLength(interpreter, lengthOf(interpreter, object))
+		iterate(interpreter, object, params, func(k int64, value any, mapped any) bool {
+			result.Set(k, mapped)
+			return true
+		})
+		return result
+	})
+	method("pop", func(interpreter types.Interpreter, object types.Object, params []any) any {
+		length := lengthOf(interpreter, object)
+		if length == 0 {
+			object.Set("length", int64(0))
+			return nil
+		}
+		element := object.Get(length - 1)
+		object.Delete(length - 1)
+		object.Set("length", length-1)
+		return element
+	})
+	method("push", func(interpreter types.Interpreter, object types.Object, params []any) any {
+		length := lengthOf(interpreter, object)
+		if length+int64(len(params)) > maxSafeInteger {
+			ThrowTypeError(interpreter, "Pushing %d elements on an array-like of length %d is disallowed, as the total surpasses 2**53-1", len(params), length)
+		}
+		for _, item := range params {
+			object.Set(length, item)
+			length++
+		}
+		object.Set("length", length)
+		return length
+	})
+	method("reduce", func(interpreter types.Interpreter, object types.Object, params []any) any {
+		return reduce(interpreter, object, params, false)
+	})
+	method("reduceRight", func(interpreter types.Interpreter, object types.Object, params []any) any {
+		return reduce(interpreter, object, params, true)
+	})
+	method("reverse", func(interpreter types.Interpreter, object types.Object, params []any) any {
+		length := lengthOf(interpreter, object)
+		if array, ok := sparseArray(object); ok {
+			var indices []int64
+			var values []any
+			for k := array.nextElement(0, length); k < length; k = array.nextElement(k+1, length) {
+				indices = append(indices, k)
+				values = append(values, array.Get(k))
+			}
+			for _, k := range indices {
+				array.Delete(k)
+			}
+			for i, k := range indices {
+				array.Set(length-1-k, values[i])
+			}
+			return array
+		}
+		for lower, upper := int64(0), length-1; lower < upper; lower, upper = lower+1, upper-1 {
+			lowerExists := HasProperty(object, lower)
+			upperExists := HasProperty(object, upper)
+			lowerValue := object.Get(lower)
+			upperValue := object.Get(upper)
+			if upperExists {
+				object.Set(lower, upperValue)
+			} else {
+				object.Delete(lower)
+			}
+			if lowerExists {
+				object.Set(upper, lowerValue)
+			} else {
+				object.Delete(upper)
+			}
+		}
+		return object
+	})
+	method("shift", func(interpreter types.Interpreter, object types.Object, params []any) any {
+		length := lengthOf(interpreter, object)
+		if length == 0 {
+			object.Set("length", int64(0))
+			return nil
+		}
+		first := object.Get(int64(0))
+		for k := int64(1); k < length; k++ {
+			if HasProperty(object, k) {
+				object.Set(k-1, object.Get(k))
+			} else {
+				object.Delete(k - 1)
+			}
+		}
+		object.Delete(length - 1)
+		object.Set("length", length-1)
+		return first
+	})
+	method("slice", func(interpreter types.Interpreter, object types.Object, params []any) any {
+		length := lengthOf(interpreter, object)
+		final := endIndex(interpreter, GetArgument(params, 1), length)
+		result := NewArray(interpreter)
+		start := relativeIndex(interpreter, GetArgument(params, 0), length)
+		next := nextElements(object, final)
+		for k := next(start); k < final; k = next(k + 1) {
+			if HasProperty(object, k) {
+				result.Set(k-start, object.Get(k))
+			}
+		}
+		result.Set("length", max(final-start, 0))
+		return result
+	})
+	method("some", func(interpreter types.Interpreter, object types.Object, params []any) any {
+		result := false
+		iterate(interpreter, object, params, func(k int64, value any, matched any) bool {
+			result = ToBoolean(matched)
+			return !result
+		})
+		return result
+	})
+	method("sort", func(interpreter types.Interpreter, object types.Object, params []any) any {
+		comparator := GetArgument(params, 0)
+		checkComparator(interpreter, comparator)
+		length := lengthOf(interpreter, object)
+		next := nextElements(object, length)
+		var values []any
+		for k := next(0); k < length; k = next(k + 1) {
+			if HasProperty(object, k) {
+				values = append(values, object.Get(k))
+			}
+		}
+		values = sortValues(interpreter, values, comparator)
+		for k, value := range values {
+			object.Set(int64(k), value)
+		}
+		for k := next(int64(len(values))); k < length; k = next(k + 1) {
+			object.Delete(k)
+		}
+		return object
+	})
+	method("splice", func(interpreter types.Interpreter, object types.Object, params []any) any {
+		length := lengthOf(interpreter, object)
+		start := relativeIndex(interpreter, GetArgument(params, 0), length)
+		var deleteCount int64
+		switch len(params) {
+		case 0:
+		case 1:
+			deleteCount = length - start
+		default:
+			count := math.Max(toIntegerOrInfinity(interpreter, params[1]), 0)
+			deleteCount = int64(math.Min(count, float64(length-start)))
+		}
+		var items []any
+		if len(params) > 2 {
+			items = params[2:]
+		}
+		itemCount := int64(len(items))
+		if length+itemCount-deleteCount > maxSafeInteger {
+			ThrowTypeError(interpreter, "Invalid array length")
+		}
+		removed := NewArray(interpreter)
+		for k := int64(0); k < deleteCount; k++ {
+			if HasProperty(object, start+k) {
+				removed.Set(k, object.Get(start+k))
+			}
+		}
+		removed.Set("length", deleteCount)
+		move := func(from int64, to int64) {
+			if HasProperty(object, from) {
+				object.Set(to, object.Get(from))
+			} else {
+				object.Delete(to)
+			}
+		}
+		if itemCount < deleteCount {
+			for k := start; k < length-deleteCount; k++ {
+				move(k+deleteCount, k+itemCount)
+			}
+			for k := length; k > length-deleteCount+itemCount; k-- {
+				object.Delete(k - 1)
+			}
+		} else if itemCount > deleteCount {
+			for k := length - deleteCount; k > start; k-- {
+				move(k+deleteCount-1, k+itemCount-1)
+			}
+		}
+		for i, item := range items {
+			object.Set(start+int64(i), item)
+		}
+		object.Set("length", length-deleteCount+itemCount)
+		return removed
+	})
+	method("toLocaleString", func(interpreter types.Interpreter, object types.Object, params []any) any {
+		length := lengthOf(interpreter, object)
+		return joinElements(interpreter, object, length, ",", func(element any) string {
+			return ToString(interpreter, Invoke(interpreter, GetProperty(interpreter, element, "toLocaleString"), element, params))
+		})
+	})
+	method("toReversed", func(interpreter types.Interpreter, object types.Object, params []any) any {
+		length := copyLength(interpreter, lengthOf(interpreter, object))
+		values := make([]any, length)
+		for k := range values {
+			values[k] = object.Get(length - 1 - int64(k))
+		}
+		return NewArrayFrom(interpreter, values)
+	})
+	method("toSorted", func(interpreter types.Interpreter, object types.Object, params []any) any {
+		comparator := GetArgument(params, 0)
+		checkComparator(interpreter, comparator)
+		length := copyLength(interpreter, lengthOf(interpreter, object))
+		values := make([]any, length)
+		for k := range values {
+			values[k] = object.Get(int64(k))
+		}
+		return NewArrayFrom(interpreter, sortValues(interpreter, values, comparator))
+	})
+	method("toSpliced", func(interpreter types.Interpreter, object types.Object, params []any) any {
+		length := lengthOf(interpreter, object)
+		start := relativeIndex(interpreter, GetArgument(params, 0), length)
+		var skipCount int64
+		switch len(params) {
+		case 0:
+		case 1:
+			skipCount = length - start
+		default:
+			count := math.Max(toIntegerOrInfinity(interpreter, params[1]), 0)
+			skipCount = int64(math.Min(count, float64(length-start)))
+		}
+		var items []any
+		if len(params) > 2 {
+			items = params[2:]
+		}
+		newLength := length - skipCount + int64(len(items))
+		if newLength > maxSafeInteger {
+			ThrowTypeError(interpreter, "Invalid array length")
+		}
+		values := make([]any, 0, copyLength(interpreter, newLength))
+		for k := int64(0); k < start; k++ {
+			values = append(values, object.Get(k))
+		}
+		values = append(values, items...)
+		for k := start + skipCount; k < length; k++ {
+			values = append(values, object.Get(k))
+		}
+		return NewArrayFrom(interpreter, values)
+	})
+	method("toString", func(interpreter types.Interpreter, object types.Object, params []any) any {
+		if fn, ok := object.Get("join").(types.Function); ok {
+			return Invoke(interpreter, fn, object, nil)
+		}
+		return defaultToString(interpreter, object)
+	})
+	method("unshift", func(interpreter types.Interpreter, object types.Object, params []any) any {
+		length := lengthOf(interpreter, object)
+		count := int64(len(params))
+		if count > 0 {
+			if length+count > maxSafeInteger {
+				ThrowTypeError(interpreter, "Invalid array length")
+			}
+			for k := length; k > 0; k-- {
+				if HasProperty(object, k-1) {
+					object.Set(k+count-1, object.Get(k-1))
+				} else {
+					object.Delete(k + count - 1)
+				}
+			}
+			for i, item := range params {
+				object.Set(int64(i), item)
+			}
+		}
+		object.Set("length", length+count)
+		return length + count
+	})
+	values := method("values", func(interpreter types.Interpreter, object types.Object, params []any) any {
+		return newArrayIterator(interpreter, object, arrayIteratorValues)
+	})
+	method("with", func(interpreter types.Interpreter, object types.Object, params []any) any {
+		length := lengthOf(interpreter, object)
+		relative := toIntegerOrInfinity(interpreter, GetArgument(params, 0))
+		index := relative
+		if index < 0 {
+			index += float64(length)
+		}
+		if index < 0 || index >= float64(length) {
+			ThrowRangeError(interpreter, "Invalid index : %s", NumberToString(relative))
+		}
+		list := make([]any, copyLength(interpreter, length))
+		for k := range list {
+			if int64(k) == int64(index) {
+				list[k] = GetArgument(params, 1)
+			} else {
+				list[k] = object.Get(int64(k))
+			}
+		}
+		return NewArrayFrom(interpreter, list)
+	})
+	prototype.define(SymbolIterator, values, false)
+	return prototype
+}
+
+type arrayIteratorKind int
+
+const (
+	arrayIteratorKeys arrayIteratorKind = iota
+	arrayIteratorValues
+	arrayIteratorEntries
+)
+
+// arrayIteratorImpl walks an array-like object by index, reading its
+// length on every step.
+type arrayIteratorImpl struct {
+	*instanceImpl
+	object types.Object
+	kind   arrayIteratorKind
+	index  int64
+}
+
+func newArrayIterator(interpreter types.Interpreter, object types.Object, kind arrayIteratorKind) types.Object {
+	return &arrayIteratorImpl{
+		instanceImpl: NewObject(realmOf(interpreter).arrayIteratorPrototype).(*instanceImpl),
+		object:       object,
+		kind:         kind,
+	}
+}
+
+func newArrayIteratorPrototype(realm *realm) types.Object {
+	prototype := NewObject(realm.objectPrototype).(*instanceImpl)
+	prototype.define("next", newNative(realm, "next", func(interpreter types.Interpreter, this any, params []any) any {
+		iterator, ok := this.(*arrayIteratorImpl)
+		if !ok {
+			ThrowTypeError(interpreter, "next method called on incompatible receiver %s", describe(this))
+		}
+		if iterator.object == nil {
+			return NewIteratorResult(interpreter, nil, true)
+		}
+		k := iterator.index
+		if k >= lengthOf(interpreter, iterator.object) {
+			iterator.object = nil
+			return NewIteratorResult(interpreter, nil, true)
+		}
+		iterator.index++
+		switch iterator.kind {
+		case arrayIteratorKeys:
+			return NewIteratorResult(interpreter, k, false)
+		case arrayIteratorEntries:
+			return NewIteratorResult(interpreter, NewArrayFrom(interpreter, []any{k, iterator.object.Get(k)}), false)
+		}
+		return NewIteratorResult(interpreter, iterator.object.Get(k), false)
+	}), false)
+	prototype.define(SymbolIterator, newNative(realm, "[Symbol.iterator]", func(interpreter types.Interpreter, this any, params []any) any {
+		return this
+	}), false)
+	prototype.define(SymbolToStringTag, "Array Iterator", false)
+	return prototype
+}
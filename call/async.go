@@ -0,0 +1,96 @@
+package call
+
+import (
+	"github.com/nusr/gojs/flow"
+	"github.com/nusr/gojs/types"
+)
+
+// awaitSignal is the value an async body suspends with on await.
+type awaitSignal struct {
+	value any
+}
+
+// runAsync starts body as an async function and returns the promise of its
+// result. The body runs synchronously up to its first await.
+func runAsync(interpreter types.Interpreter, body func(interpreter types.Interpreter) any) types.Object {
+	promise := newPromise(interpreter)
+	co := newCoroutine(interpreter, body)
+	co.async = true
+	var step func(kind resumeKind, value any)
+	step = func(kind resumeKind, value any) {
+		var result any
+		var done bool
+		if reason, threw := recoverThrow(func() {
+			result, done = co.Resume(interpreter, kind, value)
+		}); threw {
+			promise.Reject(interpreter, reason)
+			return
+		}
+		if done {
+			promise.Resolve(interpreter, result)
+			return
+		}
+		awaitValue(interpreter, result.(awaitSignal).value, func(value any) {
+			step(resumeNext, value)
+		}, func(reason any) {
+			step(resumeThrow, reason)
+		})
+	}
+	step(resumeNext, nil)
+	return promise
+}
+
+// awaitValue calls onFulfilled or onRejected from a microtask once value
+// settles.
+func awaitValue(interpreter types.Interpreter, value any, onFulfilled func(value any), onRejected func(reason any)) {
+	promise := PromiseResolve(interpreter, value).(*promiseImpl)
+	promise.Then(interpreter, NewNative(interpreter, "", func(interpreter types.Interpreter, this any, params []any) any {
+		onFulfilled(GetArgument(params, 0))
+		return nil
+	}), NewNative(interpreter, "", func(interpreter types.Interpreter, this any, params []any) any {
+		onRejected(GetArgument(params, 0))
+		return nil
+	}))
+}
+
+// Await suspends the running async function until value settles and
+// returns its result, throwing a rejection reason. At the top level the
+// event loop runs until then.
+func Await(interpreter types.Interpreter, value any) any {
+	co, ok := interpreter.GetCoroutine().(*coroutine)
+	if !ok {
+		return awaitTopLevel(interpreter, value)
+	}
+	if !co.async {
+		panic("await is only valid in async functions and the top level bodies of modules")
+	}
+	return co.await(value)
+}
+
+func awaitTopLevel(interpreter types.Interpreter, value any) any {
+	var result any
+	rejected := false
+	settled := false
+	awaitValue(interpreter, value, func(value any) {
+		result = value
+		settled = true
+	}, func(reason any) {
+		result = reason
+		rejected = true
+		settled = true
+	})
+	// the callbacks run meanwhile are not called from the top level
+	position := interpreter.StackTrace()[0]
+	interpreter.SetPosition(0, 0)
+	if !interpreter.GetEventLoop().RunUntil(func() bool {
+		return settled
+	}) {
+		// the script never continues, as with an unsettled top-level await
+		panic(flow.Exit{})
+	}
+	interpreter.SetPosition(position.Line, position.Column)
+	if rejected {
+		panic(flow.NewThrow(result))
+	}
+	return result
+}
@@ -0,0 +1,248 @@
+package call
+
+import (
+	"runtime"
+
+	"github.com/nusr/gojs/types"
+)
+
+type asyncGeneratorRequest struct {
+	kind    resumeKind
+	value   any
+	promise *promiseImpl
+}
+
+// asyncGeneratorImpl queues next, return and throw calls and runs them one
+// at a time, each settling the promise it returned.
+type asyncGeneratorImpl struct {
+	*instanceImpl
+	coroutine *coroutine
+	function  types.Object // keeps the scopes the coroutine refers to weakly alive
+	queue     []asyncGeneratorRequest
+	running   bool // executing or awaiting
+}
+
+func newAsyncGenerator(interpreter types.Interpreter, function types.Object, body func(interpreter types.Interpreter) any) types.Object {
+	co := newCoroutine(interpreter, body)
+	co.async = true
+	generator := &asyncGeneratorImpl{
+		instanceImpl: NewObject(realmOf(interpreter).asyncGeneratorPrototype).(*instanceImpl),
+		coroutine:    co,
+		function:     function,
+	}
+	runtime.AddCleanup(generator, (*coroutine).Close, co)
+	return generator
+}
+
+func (generator *asyncGeneratorImpl) enqueue(interpreter types.Interpreter, kind resumeKind, value any) types.Object {
+	promise := newPromise(interpreter)
+	generator.queue = append(generator.queue, asyncGeneratorRequest{
+		kind:    kind,
+		value:   value,
+		promise: promise,
+	})
+	generator.resumeNext(interpreter)
+	return promise
+}
+
+// settle completes the oldest request.
+func (generator *asyncGeneratorImpl) settle(interpreter types.Interpreter, rejected bool, value any) {
+	request := generator.queue[0]
+	generator.queue = generator.queue[1:]
+	if rejected {
+		request.promise.Reject(interpreter, value)
+	} else {
+		request.promise.Resolve(interpreter, value)
+	}
+}
+
+func (generator *asyncGeneratorImpl) resumeNext(interpreter types.Interpreter) {
+	for !generator.running && len(generator.queue) > 0 {
+		request := generator.queue[0]
+		co := generator.coroutine
+		if !co.started && request.kind != resumeNext {
+			co.done = true
+		}
+		if !co.done {
+			generator.running = true
+			generator.step(interpreter, request.kind, request.value)
+			continue
+		}
+		switch request.kind {
+		case resumeNext:
+			generator.settle(interpreter, false, NewIteratorResult(interpreter, nil, true))
+		case resumeThrow:
+			generator.settle(interpreter, true, request.value)
+		case resumeReturn:
+			generator.running = true
+			awaitValue(interpreter, request.value, func(value any) {
+				generator.running = false
+				generator.settle(interpreter, false, NewIteratorResult(interpreter, value, true))
+				generator.resumeNext(interpreter)
+			}, func(reason any) {
+				generator.running = false
+				generator.settle(interpreter, true, reason)
+				generator.resumeNext(interpreter)
+			})
+		}
+	}
+}
+
+// step resumes the body until it yields, awaits or completes.
+func (generator *asyncGeneratorImpl) step(interpreter types.Interpreter, kind resumeKind, value any) {
+	var result any
+	var done bool
+	reason, threw := recoverThrow(func() {
+		result, done = generator.coroutine.Resume(interpreter, kind, value)
+	})
+	// the body refers to the scopes of the function weakly
+	runtime.KeepAlive(generator)
+	if !threw && !done {
+		if signal, ok := result.(awaitSignal); ok {
+			awaitValue(interpreter, signal.value, func(value any) {
+				generator.step(interpreter, resumeNext, value)
+			}, func(reason any) {
+				generator.step(interpreter, resumeThrow, reason)
+			})
+			return
+		}
+	}
+	generator.running = false
+	if threw {
+		generator.settle(interpreter, true, reason)
+	} else {
+		generator.settle(interpreter, false, NewIteratorResult(interpreter, result, done))
+	}
+	generator.resumeNext(interpreter)
+}
+
+func newAsyncGeneratorPrototype(realm *realm) types.Object {
+	prototype := NewObject(realm.objectPrototype).(*instanceImpl)
+	resume := func(name string, kind resumeKind) types.Method {
+		return newNative(realm, name, func(interpreter types.Interpreter, this any, params []any) any {
+			generator, ok := this.(*asyncGeneratorImpl)
+			if !ok {
+				promise := newPromise(interpreter)
+				promise.Reject(interpreter, NewError(interpreter, "TypeError", "AsyncGenerator.prototype."+name+" called on incompatible receiver", nil))
+				return promise
+			}
+			return generator.enqueue(interpreter, kind, GetArgument(params, 0))
+		})
+	}
+	prototype.define("next", resume("next", resumeNext), false)
+	prototype.define("return", resume("return", resumeReturn), false)
+	prototype.define("throw", resume("throw", resumeThrow), false)
+	prototype.define(SymbolAsyncIterator, newNative(realm, "[Symbol.asyncIterator]", func(interpreter types.Interpreter, this any, params []any) any {
+		return this
+	}), false)
+	prototype.define(SymbolToStringTag, "AsyncGenerator", false)
+	return prototype
+}
+
+// await suspends an async body until value settles.
+func (co *coroutine) await(value any) any {
+	return co.Yield(awaitSignal{value: value})
+}
+
+// asyncSuspend yields value from an async generator. The value of a return
+// request is awaited before the body unwinds.
+func (co *coroutine) asyncSuspend(value any) resumption {
+	message := co.suspendWith(value)
+	if message.kind == resumeReturn {
+		message.value = co.await(message.value)
+	}
+	return message
+}
+
+func (co *coroutine) asyncYield(value any) any {
+	return co.receive(co.asyncSuspend(co.await(value)))
+}
+
+// asyncYieldDelegate implements yield* in an async generator, awaiting every
+// result of the inner async iterator.
+func (co *coroutine) asyncYieldDelegate(interpreter types.Interpreter, iterable any) any {
+	iterator := GetAsyncIterator(interpreter, iterable)
+	message := resumption{kind: resumeNext}
+	for {
+		var result any
+		switch message.kind {
+		case resumeNext:
+			result = iterator.invoke(interpreter, iterator.next, []any{message.value})
+		case resumeThrow:
+			method := GetProperty(interpreter, iterator.object, "throw")
+			if method == nil {
+				iterator.Close(interpreter)
+				ThrowTypeError(interpreter, "The iterator does not provide a 'throw' method")
+			}
+			result = iterator.invoke(interpreter, method, []any{message.value})
+		case resumeReturn:
+			method := GetProperty(interpreter, iterator.object, "return")
+			if method == nil {
+				return co.receive(message)
+			}
+			result = iterator.invoke(interpreter, method, []any{message.value})
+		default:
+			return co.receive(message)
+		}
+		object, ok := result.(types.Property)
+		if !ok {
+			ThrowTypeError(interpreter, "Iterator result is not an object")
+		}
+		if ToBoolean(object.Get("done")) {
+			if message.kind == resumeReturn {
+				return co.receive(resumption{kind: resumeReturn, value: object.Get("value")})
+			}
+			return object.Get("value")
+		}
+		message = co.asyncSuspend(object.Get("value"))
+	}
+}
+
+// newAsyncFromSyncIterator adapts a sync iterator for for await, awaiting
+// the values it produces.
+func newAsyncFromSyncIterator(interpreter types.Interpreter, iterator *Iterator) types.Object {
+	object := NewInstance(interpreter).(*instanceImpl)
+	continuation := func(interpreter types.Interpreter, result any) types.Object {
+		value, ok := result.(types.Property)
+		if !ok {
+			ThrowTypeError(interpreter, "Iterator result is not an object")
+		}
+		done := ToBoolean(value.Get("done"))
+		wrapper := PromiseResolve(interpreter, value.Get("value")).(*promiseImpl)
+		return wrapper.Then(interpreter, NewNative(interpreter, "", func(interpreter types.Interpreter, this any, params []any) any {
+			return NewIteratorResult(interpreter, GetArgument(params, 0), done)
+		}), nil)
+	}
+	method := func(name string, fn func(interpreter types.Interpreter, params []any) types.Object) {
+		object.define(name, NewNative(interpreter, name, func(interpreter types.Interpreter, this any, params []any) any {
+			var result types.Object
+			if reason, threw := recoverThrow(func() {
+				result = fn(interpreter, params)
+			}); threw {
+				promise := newPromise(interpreter)
+				promise.Reject(interpreter, reason)
+				return promise
+			}
+			return result
+		}), false)
+	}
+	method("next", func(interpreter types.Interpreter, params []any) types.Object {
+		return continuation(interpreter, Invoke(interpreter, iterator.next, iterator.object, params))
+	})
+	method("return", func(interpreter types.Interpreter, params []any) types.Object {
+		fn := GetProperty(interpreter, iterator.object, "return")
+		if fn == nil {
+			return PromiseResolve(interpreter, NewIteratorResult(interpreter, GetArgument(params, 0), true))
+		}
+		return continuation(interpreter, Invoke(interpreter, fn, iterator.object, params))
+	})
+	method("throw", func(interpreter types.Interpreter, params []any) types.Object {
+		fn := GetProperty(interpreter, iterator.object, "throw")
+		if fn == nil {
+			iterator.Close(interpreter)
+			ThrowTypeError(interpreter, "The iterator does not provide a 'throw' method")
+		}
+		return continuation(interpreter, Invoke(interpreter, fn, iterator.object, params))
+	})
+	return object
+}
@@ -1,89 +1,265 @@
 package call
 
 import (
-	"github.com/nusr/gojs/environment"
+	"sort"
+
 	"github.com/nusr/gojs/statement"
 	"github.com/nusr/gojs/types"
 )
 
 type instanceImpl struct {
-	value map[any]any
+	value      map[any]any
+	keys       []any
+	hidden     map[any]bool
+	readonly   map[any]bool // non-writable keys
+	fixed      map[any]bool // non-configurable keys
+	proto      types.Property
+	extensible bool
+	self       types.Object // the object embedding the instance, once held weakly
+}
+
+func NewInstance(interpreter types.Interpreter) types.Object {
+	return NewObject(realmOf(interpreter).objectPrototype)
 }
 
-func NewInstance() types.Property {
+func NewObject(proto types.Property) types.Object {
 	return &instanceImpl{
-		value: make(map[any]any),
+		value:      make(map[any]any),
+		hidden:     make(map[any]bool),
+		readonly:   make(map[any]bool),
+		fixed:      make(map[any]bool),
+		proto:      proto,
+		extensible: true,
 	}
 }
 
 func (instance *instanceImpl) Get(key any) any {
+	key = ToPropertyKey(key)
 	if val, ok := instance.value[key]; ok {
 		return val
 	}
+	if instance.proto != nil {
+		return instance.proto.Get(key)
+	}
 	return nil
 }
 
+// Set assigns a property, keeping the attributes of an existing one; writes
+// to read-only properties and new keys of a non-extensible object are
+// ignored.
 func (instance *instanceImpl) Set(key any, value any) {
+	key = ToPropertyKey(key)
+	_, exists := instance.value[key]
+	if !instance.canSet(key, exists) {
+		return
+	}
+	if exists {
+		instance.value[key] = value
+		return
+	}
+	instance.define(key, value, true)
+}
+
+// canSet reports whether an assignment may write key: an existing property
+// must be writable and a new one needs an extensible object.
+func (instance *instanceImpl) canSet(key any, exists bool) bool {
+	if exists {
+		return !instance.readonly[key]
+	}
+	return instance.extensible
+}
+
+func (instance *instanceImpl) define(key any, value any, enumerable bool) {
+	key = ToPropertyKey(key)
+	if _, ok := instance.value[key]; !ok {
+		instance.keys = append(instance.keys, key)
+	}
 	instance.value[key] = value
+	if enumerable {
+		delete(instance.hidden, key)
+	} else {
+		instance.hidden[key] = true
+	}
 }
 
 func (instance *instanceImpl) Has(key any) bool {
-	if _, ok := instance.value[key]; ok {
+	if _, ok := instance.value[ToPropertyKey(key)]; ok {
 		return true
 	}
 	return false
 }
 
-type classImpl struct {
-	methods []statement.Statement
-	value   map[any]any
+func (instance *instanceImpl) Delete(key any) bool {
+	key = ToPropertyKey(key)
+	if _, ok := instance.value[key]; !ok {
+		return true
+	}
+	if instance.fixed[key] {
+		return false
+	}
+	delete(instance.value, key)
+	delete(instance.hidden, key)
+	delete(instance.readonly, key)
+	for i, item := range instance.keys {
+		if item == key {
+			instance.keys = append(instance.keys[:i], instance.keys[i+1:]...)
+			break
+		}
+	}
+	return true
+}
+
+func (instance *instanceImpl) OwnKeys() []any {
+	return sortKeys(instance.keys)
+}
+
+func (instance *instanceImpl) IsEnumerable(key any) bool {
+	key = ToPropertyKey(key)
+	return instance.Has(key) && !instance.hidden[key]
+}
+
+func (instance *instanceImpl) GetPrototype() types.Property {
+	return instance.proto
+}
+
+func (instance *instanceImpl) getOwnProperty(key any) (propertyDescriptor, bool) {
+	key = ToPropertyKey(key)
+	value, ok := instance.value[key]
+	if !ok {
+		return propertyDescriptor{}, false
+	}
+	return dataDescriptor(value, !instance.readonly[key], !instance.hidden[key], !instance.fixed[key]), true
 }
 
-func NewClass(methods []statement.Statement) types.Class {
-	return &classImpl{
-		methods: methods,
-		value:   make(map[any]any),
+func (instance *instanceImpl) defineOwnProperty(key any, desc propertyDescriptor) bool {
+	key = ToPropertyKey(key)
+	current, exists := instance.getOwnProperty(key)
+	if !compatibleDescriptor(instance.extensible, desc, current, exists) {
+		return false
 	}
+	if desc.isAccessor() {
+		ThrowTypeError(nil, "Accessor properties are not supported")
+	}
+	if !exists {
+		current = dataDescriptor(nil, false, false, false)
+	}
+	current.update(desc)
+	instance.define(key, current.value, current.enumerable)
+	instance.readonly[key] = !current.writable
+	instance.fixed[key] = !current.configurable
+	return true
 }
 
-func (class *classImpl) SetMethods(methods []statement.Statement) {
-	class.methods = methods
+func (instance *instanceImpl) isExtensible() bool {
+	return instance.extensible
 }
 
-func (class *classImpl) Call(interpreter types.Interpreter, params []any) any {
-	env := environment.New(interpreter.GetGlobal())
-	instance := NewInstance()
-	env.Define("this", instance)
-	for _, item := range class.methods {
-		if val, ok := item.(statement.FunctionStatement); ok {
-			if val.Name.Lexeme == "constructor" {
-				t := NewFunction(val.Body, val.Params, env)
-				t.Call(interpreter, params)
+func (instance *instanceImpl) preventExtensions() bool {
+	instance.extensible = false
+	return true
+}
+
+func (instance *instanceImpl) setPrototype(proto types.Property) bool {
+	instance.proto = proto
+	return true
+}
+
+// sortKeys orders property keys as integer indices ascending, then strings
+// and symbols in insertion order.
+func sortKeys(keys []any) []any {
+	var indices []int64
+	var indexKeys = make(map[int64]any)
+	var names []any
+	var symbols []any
+	for _, key := range keys {
+		if _, ok := key.(*types.Symbol); ok {
+			symbols = append(symbols, key)
+		} else if i, ok := ArrayIndex(key); ok {
+			indices = append(indices, i)
+			indexKeys[i] = key
+		} else {
+			names = append(names, key)
+		}
+	}
+	sort.Slice(indices, func(a, b int) bool {
+		return indices[a] < indices[b]
+	})
+	result := make([]any, 0, len(keys))
+	for _, i := range indices {
+		result = append(result, indexKeys[i])
+	}
+	result = append(result, names...)
+	return append(result, symbols...)
+}
+
+type classImpl struct {
+	*instanceImpl
+	name        string
+	source      string            // the text of the definition
+	env         types.Environment // the scope defining the class
+	constructor *statement.FunctionStatement
+	fields      []statement.VariableStatement
+}
+
+// NewClass creates a class with its instance members in the scope env; name
+// is empty for an anonymous class expression. The methods are installed on
+// the prototype, with computed keys evaluated once here.
+func NewClass(interpreter types.Interpreter, name string, source string, methods []statement.Statement, env types.Environment) types.Class {
+	class := &classImpl{
+		instanceImpl: NewObject(realmOf(interpreter).functionPrototype).(*instanceImpl),
+		source:       source,
+		env:          env,
+	}
+	class.defineOwnProperty("length", dataDescriptor(int64(0), false, false, true))
+	class.defineOwnProperty("name", dataDescriptor("", false, false, true))
+	SetFunctionName(class, name)
+	prototype := NewObject(realmOf(interpreter).objectPrototype).(*instanceImpl)
+	prototype.define("constructor", class, false)
+	class.define("prototype", prototype, false)
+	for _, item := range methods {
+		switch val := item.(type) {
+		case statement.FunctionStatement:
+			if val.Key != nil {
+				prototype.define(interpreter.Evaluate(val.Key), NewMethod(interpreter, val, env), false)
+			} else if val.Name.Lexeme == "constructor" {
+				class.constructor = &val
+				class.define("length", int64(len(val.Params)), false)
 			} else {
-				instance.Set(val.Name.Lexeme, NewFunction(val.Body, val.Params, env))
+				prototype.define(val.Name.Lexeme, NewMethod(interpreter, val, env), false)
 			}
-		} else if val, ok := item.(statement.VariableStatement); ok {
-			var init any
-			if val.Initializer != nil {
-				init = interpreter.Evaluate(val.Initializer)
-			}
-			instance.Set(val.Name.Lexeme, init)
+		case statement.VariableStatement:
+			class.fields = append(class.fields, val)
 		}
 	}
-	return instance
+	return class
 }
 
-func (class *classImpl) String() string {
-	return ""
+func (class *classImpl) Construct(interpreter types.Interpreter, params []any) any {
+	return class.Call(interpreter, params)
 }
 
-func (instance *classImpl) Get(key any) any {
-	if val, ok := instance.value[key]; ok {
-		return val
+// Call creates an instance, initializing the fields before the constructor
+// runs.
+func (class *classImpl) Call(interpreter types.Interpreter, params []any) any {
+	interpreter.PushFrame("new " + class.name)
+	defer interpreter.PopFrame()
+	proto, _ := class.Get("prototype").(types.Property)
+	instance := NewObject(proto).(*instanceImpl)
+	for _, field := range class.fields {
+		var init any
+		if field.Initializer != nil {
+			init = interpreter.Evaluate(field.Initializer)
+		}
+		instance.Set(field.Name.Lexeme, init)
 	}
-	return nil
+	if class.constructor != nil {
+		// the constructor runs in the frame of the class
+		constructor := NewFunction(interpreter, class.constructor.Body, class.constructor.Params, class.env).(*functionImpl)
+		constructor.execute(interpreter, constructor.bind(instance, params))
+	}
+	return instance
 }
 
-func (instance *classImpl) Set(key any, value any) {
-	instance.value[key] = value
+func (class *classImpl) String() string {
+	return class.source
 }
@@ -46,7 +46,7 @@ func TestInstance(t *testing.T) {
 			nil,
 		},
 	}
-	arr := NewInstance()
+	arr := NewInstance(nil)
 	for _, item := range tests {
 		if item.actionType == "set" {
 			arr.Set(item.index, item.value)
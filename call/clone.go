@@ -0,0 +1,206 @@
+package call
+
+import (
+	"github.com/nusr/gojs/types"
+)
+
+// cloner copies values the way structuredClone does, keeping the identity
+// of objects reached twice so cycles survive.
+type cloner struct {
+	interpreter types.Interpreter
+	memory      map[types.Object]types.Object
+	transfer    map[*arrayBufferImpl]bool
+}
+
+// cloneName names an object the way V8 does when it can not be cloned.
+func cloneName(realm *realm, object types.Object) string {
+	if function, ok := object.(types.Function); ok {
+		return function.String()
+	}
+	name, _ := inspectConstructor(realm, object)
+	if name == "" {
+		name = "Object"
+	}
+	return "#<" + name + ">"
+}
+
+func (cloner *cloner) clone(value any) any {
+	switch data := value.(type) {
+	case *types.Symbol:
+		throwDOMException(cloner.interpreter, "DataCloneError", "%s could not be cloned.", data.String())
+	case types.Object:
+		if result, ok := cloner.memory[data]; ok {
+			return result
+		}
+		return cloner.object(data)
+	}
+	return value
+}
+
+// object clones an object of a kind that can be cloned, recording the
+// copy before its contents so they can refer back to it.
+func (cloner *cloner) object(object types.Object) types.Object {
+	if _, ok := asProxy(object); ok {
+		throwDOMException(cloner.interpreter, "DataCloneError", "#<Object> could not be cloned.")
+	}
+	switch data := object.(type) {
+	case types.Function:
+		throwDOMException(cloner.interpreter, "DataCloneError", "%s could not be cloned.", cloneName(realmOf(cloner.interpreter), object))
+	case *arrayImpl:
+		result := newArrayWithLength(cloner.interpreter, data.length)
+		cloner.memory[object] = result
+		cloner.properties(object, result)
+		return result
+	case *mapImpl:
+		result := newMap(cloner.interpreter)
+		if data.isSet {
+			result = newSet(cloner.interpreter)
+		}
+		cloner.memory[object] = result
+		var entries [][2]any
+		for cursor := data.data.cursor(); ; {
+			entry, ok := cursor.next()
+			if !ok {
+				break
+			}
+			entries = append(entries, [2]any{entry.key, entry.value})
+		}
+		for _, entry := range entries {
+			result.data.set(cloner.clone(entry[0]), cloner.clone(entry[1]))
+		}
+		return result
+	case *dateImpl:
+		result := newDateObject(cloner.interpreter, data.time)
+		cloner.memory[object] = result
+		return result
+	case *regexpImpl:
+		result := NewRegExp(cloner.interpreter, ToString(nil, data.Get("source")), ToString(nil, data.Get("flags")))
+		cloner.memory[object] = result
+		return result
+	case *numberImpl:
+		result := newNumberObject(cloner.interpreter, data.value)
+		cloner.memory[object] = result
+		return result
+	case *stringImpl:
+		result := newStringObject(cloner.interpreter, data.value)
+		cloner.memory[object] = result
+		return result
+	case *errorImpl:
+		return cloner.error(data)
+	case *arrayBufferImpl:
+		return cloner.arrayBuffer(data)
+	case *typedArrayImpl:
+		if data.outOfBounds() {
+			throwDOMException(cloner.interpreter, "DataCloneError", "%s could not be cloned.", cloneName(realmOf(cloner.interpreter), object))
+		}
+		buffer := cloner.clone(data.buffer).(*arrayBufferImpl)
+		result := newTypedArray(cloner.interpreter, data.kind, buffer, data.offset, data.count)
+		cloner.memory[object] = result
+		return result
+	case *dataViewImpl:
+		if data.buffer.detached {
+			throwDOMException(cloner.interpreter, "DataCloneError", "%s could not be cloned.", cloneName(realmOf(cloner.interpreter), object))
+		}
+		result := &dataViewImpl{
+			instanceImpl: NewObject(realmOf(cloner.interpreter).dataViewPrototype).(*instanceImpl),
+			buffer:       cloner.clone(data.buffer).(*arrayBufferImpl),
+			offset:       data.offset,
+			byteLength:   data.byteLength,
+		}
+		cloner.memory[object] = result
+		return result
+	case *instanceImpl:
+		result := NewInstance(cloner.interpreter)
+		cloner.memory[object] = result
+		cloner.properties(object, result)
+		return result
+	}
+	throwDOMException(cloner.interpreter, "DataCloneError", "%s could not be cloned.", cloneName(realmOf(cloner.interpreter), object))
+	return nil
+}
+
+// properties copies the own enumerable string keys of an object or array.
+func (cloner *cloner) properties(object types.Object, result types.Object) {
+	for _, key := range object.OwnKeys() {
+		if _, ok := key.(string); ok && object.IsEnumerable(key) {
+			result.Set(key, cloner.clone(object.Get(key)))
+		}
+	}
+}
+
+// error clones the name, message, stack and cause of an error, as the
+// other properties are lost.
+func (cloner *cloner) error(object *errorImpl) types.Object {
+	prototypes := realmOf(cloner.interpreter).errorPrototypes
+	name, _ := object.Get("name").(string)
+	if _, ok := prototypes[name]; !ok || name == "AggregateError" {
+		name = "Error"
+	}
+	result := &errorImpl{
+		instanceImpl: NewObject(prototypes[name]).(*instanceImpl),
+	}
+	cloner.memory[object] = result
+	if object.Has("message") {
+		result.define("message", ToString(cloner.interpreter, object.Get("message")), false)
+	}
+	if stack, ok := object.Get("stack").(string); ok {
+		result.define("stack", stack, false)
+	}
+	if object.Has("cause") {
+		result.define("cause", cloner.clone(object.Get("cause")), false)
+	}
+	return result
+}
+
+// arrayBuffer copies a buffer, or takes its bytes when it is in the
+// transfer list and will be detached. A SharedArrayBuffer is cloned by
+// sharing its memory.
+func (cloner *cloner) arrayBuffer(buffer *arrayBufferImpl) types.Object {
+	if buffer.detached {
+		throwDOMException(cloner.interpreter, "DataCloneError", "%s could not be cloned.", cloneName(realmOf(cloner.interpreter), buffer))
+	}
+	var result *arrayBufferImpl
+	switch {
+	case buffer.shared:
+		result = newArrayBuffer(cloner.interpreter, buffer.data, buffer.maxByteLength, true)
+	case cloner.transfer[buffer]:
+		result = newArrayBuffer(cloner.interpreter, buffer.data, buffer.maxByteLength, false)
+	default:
+		result = allocateArrayBuffer(cloner.interpreter, int64(len(buffer.data)), buffer.maxByteLength, false)
+		copy(result.data, buffer.data)
+	}
+	cloner.memory[buffer] = result
+	return result
+}
+
+func newStructuredClone(realm *realm) types.Function {
+	return newNative(realm, "structuredClone", func(interpreter types.Interpreter, this any, params []any) any {
+		if len(params) == 0 {
+			ThrowTypeError(interpreter, "The value argument must be specified")
+		}
+		cloner := &cloner{
+			interpreter: interpreter,
+			memory:      make(map[types.Object]types.Object),
+			transfer:    make(map[*arrayBufferImpl]bool),
+		}
+		if options, ok := GetArgument(params, 1).(types.Object); ok {
+			if list := options.Get("transfer"); list != nil {
+				for _, item := range iterableToList(interpreter, list) {
+					buffer, ok := item.(*arrayBufferImpl)
+					if !ok || buffer.shared {
+						ThrowTypeError(cloner.interpreter, "Found invalid object in transferList")
+					}
+					if cloner.transfer[buffer] {
+						throwDOMException(cloner.interpreter, "DataCloneError", "Transfer list contains duplicate ArrayBuffer")
+					}
+					cloner.transfer[buffer] = true
+				}
+			}
+		}
+		result := cloner.clone(params[0])
+		for buffer := range cloner.transfer {
+			buffer.detach()
+		}
+		return result
+	})
+}
@@ -0,0 +1,536 @@
+package call
+
+import (
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nusr/gojs/flow"
+	"github.com/nusr/gojs/types"
+)
+
+// ConsoleOutput holds where each level of console output is written; a nil
+// writer discards it.
+type ConsoleOutput struct {
+	Log   io.Writer
+	Info  io.Writer
+	Debug io.Writer
+	Warn  io.Writer
+	Error io.Writer
+}
+
+// NewConsoleOutput sends log, info and debug to stdout and warn and error to
+// stderr, as Node does.
+func NewConsoleOutput(stdout io.Writer, stderr io.Writer) ConsoleOutput {
+	return ConsoleOutput{
+		Log:   stdout,
+		Info:  stdout,
+		Debug: stdout,
+		Warn:  stderr,
+		Error: stderr,
+	}
+}
+
+// consoleImpl is the state of a console: the group indentation, counters
+// and timers.
+type consoleImpl struct {
+	output ConsoleOutput
+	indent string
+	counts map[string]int64
+	timers map[string]time.Time
+}
+
+// write prints a line, indenting every line of it by the current group.
+func (console *consoleImpl) write(writer io.Writer, text string) {
+	if writer == nil {
+		return
+	}
+	if console.indent != "" {
+		text = console.indent + strings.ReplaceAll(text, "\n", "\n"+console.indent)
+	}
+	// lone surrogates cannot be written as UTF-8
+	io.WriteString(writer, toWellFormed(text)+"\n")
+}
+
+// warning reports misuse of the console the way Node's process warnings do.
+func (console *consoleImpl) warning(message string) {
+	if console.output.Warn != nil {
+		io.WriteString(console.output.Warn, "Warning: "+message+"\n")
+	}
+}
+
+func consoleLabel(interpreter types.Interpreter, params []any) string {
+	label := GetArgument(params, 0)
+	if label == nil {
+		return "default"
+	}
+	return ToString(interpreter, label)
+}
+
+// NewConsole creates a console object writing to output.
+func NewConsole(interpreter types.Interpreter, output ConsoleOutput) types.Object {
+	return newConsole(realmOf(interpreter), output)
+}
+
+func newConsole(realm *realm, output ConsoleOutput) types.Object {
+	console := &consoleImpl{
+		output: output,
+		counts: make(map[string]int64),
+		timers: make(map[string]time.Time),
+	}
+	object := NewObject(realm.objectPrototype).(*instanceImpl)
+	method := func(name string, fn NativeFunction) {
+		object.Set(name, newNative(realm, name, fn))
+	}
+	printer := func(name string, writer io.Writer) {
+		method(name, func(interpreter types.Interpreter, this any, params []any) any {
+			console.write(writer, formatLog(interpreter, params))
+			return nil
+		})
+	}
+	printer("log", output.Log)
+	printer("info", output.Info)
+	printer("debug", output.Debug)
+	printer("warn", output.Warn)
+	printer("error", output.Error)
+	printer("dirxml", output.Log)
+	method("dir", func(interpreter types.Interpreter, this any, params []any) any {
+		options := readInspectOptions(interpreter, GetArgument(params, 1))
+		console.write(output.Log, inspect(interpreter, GetArgument(params, 0), options))
+		return nil
+	})
+	method("trace", func(interpreter types.Interpreter, this any, params []any) any {
+		header := "Trace"
+		if message := formatLog(interpreter, params); message != "" {
+			header += ": " + message
+		}
+		console.write(output.Error, formatStack(interpreter, header))
+		return nil
+	})
+	method("assert", func(interpreter types.Interpreter, this any, params []any) any {
+		if ToBoolean(GetArgument(params, 0)) {
+			return nil
+		}
+		args := []any{"Assertion failed"}
+		if len(params) > 1 {
+			args = append([]any{"Assertion failed: " + ToString(interpreter, params[1])}, params[2:]...)
+		}
+		console.write(output.Warn, formatLog(interpreter, args))
+		return nil
+	})
+	group := func(interpreter types.Interpreter, this any, params []any) any {
+		if len(params) > 0 {
+			console.write(output.Log, formatLog(interpreter, params))
+		}
+		console.indent += "  "
+		return nil
+	}
+	method("group", group)
+	method("groupCollapsed", group)
+	method("groupEnd", func(interpreter types.Interpreter, this any, params []any) any {
+		console.indent = console.indent[:max(len(console.indent)-2, 0)]
+		return nil
+	})
+	method("count", func(interpreter types.Interpreter, this any, params []any) any {
+		label := consoleLabel(interpreter, params)
+		console.counts[label]++
+		console.write(output.Log, label+": "+strconv.FormatInt(console.counts[label], 10))
+		return nil
+	})
+	method("countReset", func(interpreter types.Interpreter, this any, params []any) any {
+		label := consoleLabel(interpreter, params)
+		if _, ok := console.counts[label]; !ok {
+			console.warning("Count for '" + label + "' does not exist")
+			return nil
+		}
+		console.counts[label] = 0
+		return nil
+	})
+	method("time", func(interpreter types.Interpreter, this any, params []any) any {
+		label := consoleLabel(interpreter, params)
+		if _, ok := console.timers[label]; ok {
+			console.warning("Label '" + label + "' already exists for console.time()")
+			return nil
+		}
+		console.timers[label] = interpreter.GetEventLoop().Now()
+		return nil
+	})
+	timeLog := func(name string) NativeFunction {
+		return func(interpreter types.Interpreter, this any, params []any) any {
+			label := consoleLabel(interpreter, params)
+			start, ok := console.timers[label]
+			if !ok {
+				console.warning("No such label '" + label + "' for console." + name + "()")
+				return nil
+			}
+			elapsed := interpreter.GetEventLoop().Now().Sub(start)
+			args := []any{"%s: %s", label, formatDuration(float64(elapsed) / float64(time.Millisecond))}
+			if name == "timeLog" {
+				args = append(args, params[min(len(params), 1):]...)
+			} else {
+				delete(console.timers, label)
+			}
+			console.write(output.Log, formatLog(interpreter, args))
+			return nil
+		}
+	}
+	method("timeLog", timeLog("timeLog"))
+	method("timeEnd", timeLog("timeEnd"))
+	method("table", func(interpreter types.Interpreter, this any, params []any) any {
+		data := GetArgument(params, 0)
+		properties := GetArgument(params, 1)
+		if properties != nil && !IsArray(properties) {
+			ThrowTypeError(interpreter, "The \"properties\" argument must be an instance of Array. Received %s", received(properties))
+		}
+		object, ok := data.(types.Object)
+		if !ok {
+			console.write(output.Log, formatLog(interpreter, []any{data}))
+			return nil
+		}
+		console.write(output.Log, consoleTable(interpreter, object, properties))
+		return nil
+	})
+	object.define(SymbolToStringTag, "console", false)
+	return object
+}
+
+// formatDuration formats a time in milliseconds as console.timeEnd does.
+func formatDuration(ms float64) string {
+	hours, minutes, seconds := 0.0, 0.0, 0.0
+	if ms >= 1000 {
+		if ms >= 60*1000 {
+			if ms >= 60*60*1000 {
+				hours = math.Floor(ms / (60 * 60 * 1000))
+				ms = math.Mod(ms, 60*60*1000)
+			}
+			minutes = math.Floor(ms / (60 * 1000))
+			ms = math.Mod(ms, 60*1000)
+		}
+		seconds = ms / 1000
+	}
+	if hours != 0 || minutes != 0 {
+		fixed := strings.Split(strconv.FormatFloat(seconds, 'f', 3, 64), ".")
+		pad := func(value string) string {
+			return strings.Repeat("0", max(2-len(value), 0)) + value
+		}
+		result := NumberToString(minutes)
+		unit := "m:ss.mmm"
+		if hours != 0 {
+			result = NumberToString(hours) + ":" + pad(result)
+			unit = "h:mm:ss.mmm"
+		}
+		return result + ":" + pad(fixed[0]) + "." + fixed[1] + " (" + unit + ")"
+	}
+	if seconds != 0 {
+		return strconv.FormatFloat(seconds, 'f', 3, 64) + "s"
+	}
+	value, _ := strconv.ParseFloat(strconv.FormatFloat(ms, 'f', 3, 64), 64)
+	return NumberToString(value) + "ms"
+}
+
+// formatLog joins console arguments as Node's util.format does, replacing
+// the format specifiers of a leading string.
+func formatLog(interpreter types.Interpreter, params []any) string {
+	var builder strings.Builder
+	next := 0
+	separator := ""
+	if first, ok := GetArgument(params, 0).(string); ok {
+		if len(params) == 1 {
+			return first
+		}
+		last := 0
+		for i := 0; i < len(first)-1; i++ {
+			if first[i] != '%' {
+				continue
+			}
+			i++
+			specifier := first[i]
+			if next+1 == len(params) {
+				if specifier == '%' {
+					builder.WriteString(first[last:i])
+					last = i + 1
+				}
+				continue
+			}
+			var text string
+			switch specifier {
+			case 's':
+				next++
+				text = formatString(interpreter, params[next])
+			case 'j':
+				next++
+				text = formatJSON(interpreter, params[next])
+			case 'd':
+				next++
+				text = formatNumber(interpreter, params[next], func(value any) float64 {
+					return ToNumber(interpreter, value)
+				})
+			case 'i':
+				next++
+				text = formatNumber(interpreter, params[next], func(value any) float64 {
+					return ToNumber(interpreter, realmOf(interpreter).parseIntFunction.Call(interpreter, []any{value}))
+				})
+			case 'f':
+				next++
+				text = formatNumber(interpreter, params[next], func(value any) float64 {
+					return ToNumber(interpreter, realmOf(interpreter).parseFloatFunction.Call(interpreter, []any{value}))
+				})
+			case 'O':
+				next++
+				text = Inspect(interpreter, params[next])
+			case 'o':
+				next++
+				options := defaultInspectOptions
+				options.showHidden = true
+				options.depth = 4
+				text = inspect(interpreter, params[next], options)
+			case 'c':
+				next++
+			case '%':
+				builder.WriteString(first[last:i])
+				last = i + 1
+				continue
+			default:
+				continue
+			}
+			if last != i-1 {
+				builder.WriteString(first[last : i-1])
+			}
+			builder.WriteString(text)
+			last = i + 1
+		}
+		if last != 0 {
+			next++
+			separator = " "
+			if last < len(first) {
+				builder.WriteString(first[last:])
+			}
+		}
+	}
+	for ; next < len(params); next++ {
+		builder.WriteString(separator)
+		if text, ok := params[next].(string); ok {
+			builder.WriteString(text)
+		} else {
+			builder.WriteString(Inspect(interpreter, params[next]))
+		}
+		separator = " "
+	}
+	return builder.String()
+}
+
+// formatString converts a %s argument; an object with its built-in
+// toString is inspected instead.
+func formatString(interpreter types.Interpreter, value any) string {
+	switch value.(type) {
+	case int64, float64, types.NaN:
+		return Inspect(interpreter, value)
+	case *types.Symbol:
+		return Inspect(interpreter, value)
+	}
+	if object, ok := value.(types.Object); ok && hasBuiltinToString(object) {
+		options := defaultInspectOptions
+		options.depth = 0
+		return inspect(interpreter, value, options)
+	}
+	return ToString(interpreter, value)
+}
+
+func hasBuiltinToString(object types.Object) bool {
+	if object.Has("toString") {
+		return false
+	}
+	_, ok := object.Get("toString").(*nativeImpl)
+	return ok || object.Get("toString") == nil
+}
+
+func formatNumber(interpreter types.Interpreter, value any, convert func(value any) float64) string {
+	if _, ok := value.(*types.Symbol); ok {
+		return "NaN"
+	}
+	return Inspect(interpreter, convert(value))
+}
+
+// formatJSON converts a %j argument, showing a cycle as [Circular].
+func formatJSON(interpreter types.Interpreter, value any) string {
+	text := "undefined"
+	reason, threw := recoverThrow(func() {
+		root := NewInstance(interpreter)
+		root.Set("", value)
+		if result, ok := newJSONStringifier(interpreter, nil, nil).property(root, "", false); ok {
+			text = result
+		}
+	})
+	if threw {
+		if message, ok := GetProperty(interpreter, reason, "message").(string); ok && strings.Contains(message, "circular structure") {
+			return "[Circular]"
+		}
+		panic(flow.NewThrow(reason))
+	}
+	return text
+}
+
+const (
+	tableIndexKey     = "(index)"
+	tableIterationKey = "(iteration index)"
+	tableKeyKey       = "Key"
+	tableValuesKey    = "Values"
+)
+
+// consoleTable lays out the rows of console.table: the entries of a Map or
+// Set, or the properties of each element of an array or object.
+func consoleTable(interpreter types.Interpreter, data types.Object, properties any) string {
+	cell := func(value any) string {
+		options := defaultInspectOptions
+		options.depth = 0
+		options.maxArrayLength = 3
+		options.breakLength = math.Inf(1)
+		if object, ok := value.(types.Object); ok && !IsArray(object) && len(enumerableKeys(object)) > 2 {
+			options.depth = -1
+		}
+		return inspect(interpreter, value, options)
+	}
+	indices := func(length int) []string {
+		result := make([]string, length)
+		for i := range result {
+			result[i] = strconv.Itoa(i)
+		}
+		return result
+	}
+	var entries [][2]any
+	keyed := false
+	switch data := data.(type) {
+	case *mapImpl:
+		keyed = !data.isSet
+		cursor := data.data.cursor()
+		for entry, ok := cursor.next(); ok; entry, ok = cursor.next() {
+			entries = append(entries, [2]any{entry.key, entry.value})
+		}
+		if data.isSet {
+			for i := range entries {
+				entries[i][1] = entries[i][0]
+			}
+		}
+	case *mapIteratorImpl:
+		entries = iteratorEntries(data)
+		keyed = data.kind == arrayIteratorEntries
+		if data.kind == arrayIteratorKeys {
+			for i := range entries {
+				entries[i][1] = entries[i][0]
+			}
+		}
+	default:
+		return objectTable(interpreter, data, properties, cell)
+	}
+	var keys, values []string
+	for _, entry := range entries {
+		keys = append(keys, cell(entry[0]))
+		values = append(values, cell(entry[1]))
+	}
+	if keyed {
+		return cliTable([]string{tableIterationKey, tableKeyKey, tableValuesKey}, [][]string{indices(len(entries)), keys, values})
+	}
+	return cliTable([]string{tableIterationKey, tableValuesKey}, [][]string{indices(len(entries)), values})
+}
+
+func objectTable(interpreter types.Interpreter, data types.Object, properties any, cell func(value any) string) string {
+	var filter []any
+	if properties != nil {
+		array := properties.(types.Object)
+		length := lengthOf(interpreter, array)
+		for i := int64(0); i < length; i++ {
+			filter = append(filter, array.Get(strconv.FormatInt(i, 10)))
+		}
+	}
+	rows := enumerableKeys(data)
+	columns := make(map[any][]string)
+	var names []any
+	hasPrimitives := false
+	primitives := make([]string, len(rows))
+	for i, row := range rows {
+		item := data.Get(row)
+		object, isObject := item.(types.Object)
+		if properties == nil && !isObject {
+			hasPrimitives = true
+			primitives[i] = cell(item)
+			continue
+		}
+		keys := filter
+		if properties == nil {
+			for _, key := range enumerableKeys(object) {
+				keys = append(keys, key)
+			}
+		}
+		for _, key := range keys {
+			key = ToPropertyKey(key)
+			if _, ok := columns[key]; !ok {
+				names = append(names, key)
+				columns[key] = make([]string, len(rows))
+			}
+			if isObject && object.Has(key) {
+				columns[key][i] = cell(object.Get(key))
+			}
+		}
+	}
+	head := []string{tableIndexKey}
+	body := [][]string{rows}
+	for _, name := range sortKeys(names) {
+		head = append(head, ToString(interpreter, name))
+		body = append(body, columns[name])
+	}
+	if hasPrimitives {
+		head = append(head, tableValuesKey)
+		body = append(body, primitives)
+	}
+	return cliTable(head, body)
+}
+
+// cliTable draws a table with box characters.
+func cliTable(head []string, columns [][]string) string {
+	widths := make([]int, len(head))
+	rows := 0
+	for i, name := range head {
+		widths[i] = textLength(name)
+		rows = max(rows, len(columns[i]))
+	}
+	for i, column := range columns {
+		for _, value := range column {
+			widths[i] = max(widths[i], textLength(value))
+		}
+	}
+	renderRow := func(row func(i int) string) string {
+		var builder strings.Builder
+		builder.WriteString("│ ")
+		for i := range head {
+			value := row(i)
+			builder.WriteString(value + strings.Repeat(" ", widths[i]-textLength(value)))
+			if i != len(head)-1 {
+				builder.WriteString(" │ ")
+			}
+		}
+		builder.WriteString(" │")
+		return builder.String()
+	}
+	divider := make([]string, len(head))
+	for i, width := range widths {
+		divider[i] = strings.Repeat("─", width+2)
+	}
+	var builder strings.Builder
+	builder.WriteString("┌" + strings.Join(divider, "┬") + "┐\n")
+	builder.WriteString(renderRow(func(i int) string {
+		return head[i]
+	}) + "\n")
+	builder.WriteString("├" + strings.Join(divider, "┼") + "┤\n")
+	for j := 0; j < rows; j++ {
+		builder.WriteString(renderRow(func(i int) string {
+			if j < len(columns[i]) {
+				return columns[i][j]
+			}
+			return ""
+		}) + "\n")
+	}
+	builder.WriteString("└" + strings.Join(divider, "┴") + "┘")
+	return builder.String()
+}
@@ -0,0 +1,373 @@
+package call
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/nusr/gojs/token"
+	"github.com/nusr/gojs/types"
+)
+
+// NumberToString formats a number the way Number.prototype.toString does.
+func NumberToString(value float64) string {
+	if math.IsNaN(value) {
+		return "NaN"
+	}
+	if value == 0 {
+		return "0"
+	}
+	if math.IsInf(value, 1) {
+		return "Infinity"
+	}
+	if math.IsInf(value, -1) {
+		return "-Infinity"
+	}
+	if value < 0 {
+		return "-" + NumberToString(-value)
+	}
+	text := strconv.FormatFloat(value, 'e', -1, 64)
+	index := strings.IndexByte(text, 'e')
+	digits := strings.Replace(text[:index], ".", "", 1)
+	exponent, _ := strconv.Atoi(text[index+1:])
+	k := len(digits)
+	n := exponent + 1
+	if k <= n && n <= 21 {
+		return digits + strings.Repeat("0", n-k)
+	}
+	if 0 < n && n <= 21 {
+		return digits[:n] + "." + digits[n:]
+	}
+	if -6 < n && n <= 0 {
+		return "0." + strings.Repeat("0", -n) + digits
+	}
+	sign := "+"
+	if n-1 < 0 {
+		sign = "-"
+	}
+	e := strconv.Itoa(int(math.Abs(float64(n - 1))))
+	if k == 1 {
+		return digits + "e" + sign + e
+	}
+	return digits[:1] + "." + digits[1:] + "e" + sign + e
+}
+
+// ToPropertyKey converts a value into a string or symbol property key.
+func ToPropertyKey(key any) any {
+	switch data := key.(type) {
+	case string:
+		return data
+	case *types.Symbol:
+		return data
+	case float64:
+		return NumberToString(data)
+	case float32:
+		return NumberToString(float64(data))
+	case int, int8, int16, int32, int64:
+		return strconv.FormatInt(convertAnyToInt(data), 10)
+	default:
+		return token.ConvertAnyToString(key)
+	}
+}
+
+// ToBoolean reports whether a value is truthy.
+func ToBoolean(value any) bool {
+	switch data := value.(type) {
+	case nil, types.Null:
+		return false
+	case bool:
+		return data
+	case string:
+		return data != ""
+	case int64:
+		return data != 0
+	case float64:
+		return data != 0 && !math.IsNaN(data)
+	case types.NaN:
+		return false
+	default:
+		return true
+	}
+}
+
+// ArrayIndex returns the index of a canonical array index key.
+func ArrayIndex(key any) (int64, bool) {
+	switch data := key.(type) {
+	case int64:
+		return data, data >= 0
+	case int:
+		return int64(data), data >= 0
+	case float64:
+		if data >= 0 && data == math.Trunc(data) && data < math.MaxUint32 {
+			return int64(data), true
+		}
+	case string:
+		if data == "" || (len(data) > 1 && data[0] == '0') {
+			return 0, false
+		}
+		i, err := strconv.ParseInt(data, 10, 64)
+		if err == nil && i >= 0 && i < math.MaxUint32 {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// ToPrimitive converts an object to a primitive value, preferring a number
+// or a string according to hint.
+func ToPrimitive(interpreter types.Interpreter, value any, hint string) any {
+	if _, ok := value.(types.Property); !ok {
+		return value
+	}
+	if method := GetProperty(interpreter, value, SymbolToPrimitive); method != nil {
+		result := Invoke(interpreter, method, value, []any{hint})
+		if _, ok := result.(types.Property); ok {
+			ThrowTypeError(interpreter, "Cannot convert object to primitive value")
+		}
+		return result
+	}
+	return ordinaryToPrimitive(interpreter, value, hint)
+}
+
+// ordinaryToPrimitive tries valueOf and toString, in the order the hint
+// prefers.
+func ordinaryToPrimitive(interpreter types.Interpreter, value any, hint string) any {
+	names := []string{"valueOf", "toString"}
+	if hint == "string" {
+		names = []string{"toString", "valueOf"}
+	}
+	for _, name := range names {
+		method := GetProperty(interpreter, value, name)
+		if _, ok := method.(types.Function); !ok {
+			continue
+		}
+		result := Invoke(interpreter, method, value, nil)
+		if _, ok := result.(types.Property); !ok {
+			return result
+		}
+	}
+	return defaultToString(interpreter, value)
+}
+
+// defaultToString is the string form of objects without a toString method.
+func defaultToString(interpreter types.Interpreter, value any) string {
+	if function, ok := value.(types.Function); ok {
+		return function.String()
+	}
+	return objectToString(interpreter, value)
+}
+
+// ToObject converts a value to an object, throwing for null and undefined.
+func ToObject(interpreter types.Interpreter, value any) types.Object {
+	switch data := value.(type) {
+	case nil, types.Null:
+		ThrowTypeError(interpreter, "Cannot convert undefined or null to object")
+	case string:
+		return newStringObject(interpreter, data)
+	case int64, float64, types.NaN:
+		return newNumberObject(interpreter, ToNumber(nil, data))
+	case types.Object:
+		return data
+	}
+	return NewInstance(interpreter)
+}
+
+// ToUint32 converts a value to an unsigned 32-bit integer, wrapping
+// around like the >>> operator.
+func ToUint32(interpreter types.Interpreter, value any) uint32 {
+	return wrapUint32(ToNumber(interpreter, value))
+}
+
+// wrapUint32 is ToUint32 of a number.
+func wrapUint32(number float64) uint32 {
+	if math.IsNaN(number) || math.IsInf(number, 0) {
+		return 0
+	}
+	number = math.Mod(math.Trunc(number), 1<<32)
+	if number < 0 {
+		number += 1 << 32
+	}
+	return uint32(number)
+}
+
+// ToString converts a value to a string the way String(value) does.
+func ToString(interpreter types.Interpreter, value any) string {
+	switch data := value.(type) {
+	case nil:
+		return "undefined"
+	case string:
+		return data
+	case float64:
+		return NumberToString(data)
+	case *types.Symbol:
+		ThrowTypeError(interpreter, "Cannot convert a Symbol value to a string")
+	case types.Property:
+		return ToString(interpreter, ToPrimitive(interpreter, data, "string"))
+	}
+	return token.ConvertAnyToString(value)
+}
+
+// ToNumber converts a value to a number the way Number(value) does.
+func ToNumber(interpreter types.Interpreter, value any) float64 {
+	switch data := value.(type) {
+	case nil:
+		return math.NaN()
+	case types.Null:
+		return 0
+	case bool:
+		if data {
+			return 1
+		}
+		return 0
+	case string:
+		return StringToNumber(data)
+	case *types.Symbol:
+		ThrowTypeError(interpreter, "Cannot convert a Symbol value to a number")
+	case types.Property:
+		return ToNumber(interpreter, ToPrimitive(interpreter, data, "number"))
+	}
+	if number, ok := toFloat(value); ok {
+		return number
+	}
+	return math.NaN()
+}
+
+var decimalLiteral = regexp.MustCompile(`^[+-]?(\d+\.?\d*|\.\d+)([eE][+-]?\d+)?$`)
+
+// StringToNumber parses a string as a numeric literal, giving NaN when it
+// is not one.
+func StringToNumber(text string) float64 {
+	text = strings.TrimFunc(text, isJSWhiteSpace)
+	switch text {
+	case "":
+		return 0
+	case "Infinity", "+Infinity":
+		return math.Inf(1)
+	case "-Infinity":
+		return math.Inf(-1)
+	}
+	if len(text) > 2 && text[0] == '0' {
+		base := 0
+		switch text[1] {
+		case 'x', 'X':
+			base = 16
+		case 'o', 'O':
+			base = 8
+		case 'b', 'B':
+			base = 2
+		}
+		if base != 0 {
+			if value, err := strconv.ParseUint(text[2:], base, 64); err == nil {
+				return float64(value)
+			}
+			return math.NaN()
+		}
+	}
+	if !decimalLiteral.MatchString(text) {
+		return math.NaN()
+	}
+	value, _ := strconv.ParseFloat(text, 64)
+	return value
+}
+
+// TypeOf returns the result of the typeof operator.
+func TypeOf(value any) string {
+	switch value.(type) {
+	case nil:
+		return "undefined"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case int64, float64, types.NaN:
+		return "number"
+	case *types.Symbol:
+		return "symbol"
+	case types.Function:
+		return "function"
+	}
+	return "object"
+}
+
+// InstanceOf implements the instanceof operator.
+func InstanceOf(interpreter types.Interpreter, value any, target any) bool {
+	if _, ok := target.(types.Property); !ok {
+		ThrowTypeError(interpreter, "Right-hand side of 'instanceof' is not an object")
+	}
+	if method := GetProperty(interpreter, target, SymbolHasInstance); method != nil {
+		return ToBoolean(Invoke(interpreter, method, target, []any{value}))
+	}
+	if _, ok := target.(types.Function); !ok {
+		ThrowTypeError(interpreter, "Right-hand side of 'instanceof' is not callable")
+	}
+	prototype := GetProperty(interpreter, target, "prototype")
+	object, ok := value.(types.Object)
+	if !ok {
+		return false
+	}
+	for proto := object.GetPrototype(); proto != nil; {
+		if proto == prototype {
+			return true
+		}
+		next, ok := proto.(types.Object)
+		if !ok {
+			break
+		}
+		proto = next.GetPrototype()
+	}
+	return false
+}
+
+func toFloat(value any) (float64, bool) {
+	switch data := value.(type) {
+	case int64:
+		return float64(data), true
+	case float64:
+		return data, true
+	case types.NaN:
+		return math.NaN(), true
+	}
+	return 0, false
+}
+
+// StrictEquals implements the === operator.
+func StrictEquals(left any, right any) bool {
+	a, ok1 := toFloat(left)
+	b, ok2 := toFloat(right)
+	if ok1 && ok2 {
+		return a == b
+	}
+	return left == right
+}
+
+// LooseEquals implements the == operator.
+func LooseEquals(interpreter types.Interpreter, left any, right any) bool {
+	if types.IsNullish(left) || types.IsNullish(right) {
+		return types.IsNullish(left) && types.IsNullish(right)
+	}
+	_, object1 := left.(types.Property)
+	_, object2 := right.(types.Property)
+	if object1 && object2 {
+		return left == right
+	}
+	if object1 {
+		return LooseEquals(interpreter, ToPrimitive(interpreter, left, "default"), right)
+	}
+	if object2 {
+		return LooseEquals(interpreter, left, ToPrimitive(interpreter, right, "default"))
+	}
+	if types.IsSymbol(left) || types.IsSymbol(right) {
+		return left == right
+	}
+	if a, b, ok := toFloat2(left, right); ok {
+		return a == b
+	}
+	return ToString(interpreter, left) == ToString(interpreter, right)
+}
+
+func toFloat2(left any, right any) (float64, float64, bool) {
+	a, ok1 := toFloat(left)
+	b, ok2 := toFloat(right)
+	return a, b, ok1 && ok2
+}
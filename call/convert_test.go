@@ -0,0 +1,48 @@
+package call
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNumberToString(t *testing.T) {
+	tests := []struct {
+		value float64
+		want  string
+	}{
+		{0, "0"},
+		{1, "1"},
+		{-1.5, "-1.5"},
+		{0.30000000000000004, "0.30000000000000004"},
+		{123e-20, "1.23e-18"},
+		{1e21, "1e+21"},
+		{1e20, "100000000000000000000"},
+		{0.000001, "0.000001"},
+		{0.0000001, "1e-7"},
+		{math.NaN(), "NaN"},
+		{math.Inf(-1), "-Infinity"},
+	}
+	for _, tt := range tests {
+		if got := NumberToString(tt.value); got != tt.want {
+			t.Errorf("NumberToString(%v) actual = %v, expect= %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestToPropertyKey(t *testing.T) {
+	tests := []struct {
+		key  any
+		want any
+	}{
+		{int64(1), "1"},
+		{1.5, "1.5"},
+		{"a", "a"},
+		{true, "true"},
+		{SymbolIterator, SymbolIterator},
+	}
+	for _, tt := range tests {
+		if got := ToPropertyKey(tt.key); got != tt.want {
+			t.Errorf("ToPropertyKey(%v) actual = %v, expect= %v", tt.key, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package call
+
+import (
+	"sync"
+
+	"github.com/nusr/gojs/flow"
+	"github.com/nusr/gojs/types"
+)
+
+type resumeKind int
+
+const (
+	resumeExit resumeKind = iota // the zero value, received once resume is closed
+	resumeNext
+	resumeThrow
+	resumeReturn
+)
+
+type resumption struct {
+	kind  resumeKind
+	value any
+}
+
+type suspension struct {
+	value any
+	done  bool
+	err   any
+}
+
+// coroutineReturn unwinds a coroutine body for return(), running finally
+// blocks on the way out.
+type coroutineReturn struct {
+	value any
+}
+
+// coroutine runs a function body on its own goroutine. Control is handed
+// back and forth over unbuffered channels, so only one side runs at a time.
+type coroutine struct {
+	interpreter types.Interpreter
+	body        func(interpreter types.Interpreter) any
+	resume      chan resumption
+	suspend     chan suspension
+	exited      chan struct{}
+	closeOnce   sync.Once
+	started     bool
+	running     bool
+	done        bool
+	async       bool // suspended by await rather than yield
+}
+
+func newCoroutine(interpreter types.Interpreter, body func(interpreter types.Interpreter) any) *coroutine {
+	return &coroutine{
+		interpreter: interpreter,
+		body:        body,
+		resume:      make(chan resumption),
+		suspend:     make(chan suspension),
+		exited:      make(chan struct{}),
+	}
+}
+
+func (co *coroutine) start() {
+	co.started = true
+	co.interpreter.AddCoroutine(co)
+	fork := co.interpreter.Fork(co)
+	go func() {
+		defer close(co.exited)
+		message := <-co.resume
+		if message.kind == resumeExit {
+			return
+		}
+		var result suspension
+		defer func() {
+			if err := recover(); err != nil {
+				switch data := err.(type) {
+				case flow.Exit:
+					return
+				case coroutineReturn:
+					result = suspension{value: data.value, done: true}
+				default:
+					result = suspension{err: err, done: true}
+				}
+			}
+			co.suspend <- result
+		}()
+		result = suspension{value: co.body(fork), done: true}
+	}()
+}
+
+// Resume continues the coroutine and reports the next yielded value, or the
+// completion value with done set. A throw inside the body is re-panicked on
+// the calling goroutine.
+func (co *coroutine) Resume(interpreter types.Interpreter, kind resumeKind, value any) (any, bool) {
+	if co.running {
+		ThrowTypeError(interpreter, "Generator is already running")
+	}
+	if !co.started && kind != resumeNext {
+		co.done = true
+	}
+	if co.done {
+		switch kind {
+		case resumeThrow:
+			panic(flow.NewThrow(value))
+		case resumeReturn:
+			return value, true
+		}
+		return nil, true
+	}
+	if !co.started {
+		co.start()
+	}
+	co.running = true
+	co.resume <- resumption{kind: kind, value: value}
+	result := <-co.suspend
+	co.running = false
+	if result.done {
+		co.done = true
+		co.interpreter.RemoveCoroutine(co)
+		<-co.exited
+	}
+	if result.err != nil {
+		panic(result.err)
+	}
+	return result.value, result.done
+}
+
+// suspendWith hands value to the caller of Resume and waits to be resumed.
+func (co *coroutine) suspendWith(value any) resumption {
+	co.suspend <- suspension{value: value}
+	return <-co.resume
+}
+
+func (co *coroutine) Yield(value any) any {
+	message := co.suspendWith(value)
+	return co.receive(message)
+}
+
+// receive turns a resumption into the result of a yield expression.
+func (co *coroutine) receive(message resumption) any {
+	switch message.kind {
+	case resumeThrow:
+		panic(flow.NewThrow(message.value))
+	case resumeReturn:
+		panic(coroutineReturn{value: message.value})
+	case resumeExit:
+		panic(flow.Exit{})
+	}
+	return message.value
+}
+
+// Close stops a suspended coroutine without running any more script code.
+func (co *coroutine) Close() {
+	co.closeOnce.Do(func() {
+		co.done = true
+		if co.started {
+			close(co.resume)
+			<-co.exited
+			co.interpreter.RemoveCoroutine(co)
+		}
+	})
+}
@@ -0,0 +1,89 @@
+package call
+
+import (
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"fmt"
+	"hash"
+	"strings"
+
+	"github.com/nusr/gojs/types"
+)
+
+// maxRandomValues is the most bytes getRandomValues fills at once.
+const maxRandomValues = 65536
+
+// digestAlgorithms are the hashes of crypto.subtle.digest by their names
+// in upper case.
+var digestAlgorithms = map[string]func() hash.Hash{
+	"SHA-1":   sha1.New,
+	"SHA-256": sha256.New,
+	"SHA-384": sha512.New384,
+	"SHA-512": sha512.New,
+}
+
+func newSubtleCrypto(realm *realm) types.Object {
+	subtle := NewObject(realm.objectPrototype).(*instanceImpl)
+	subtle.define("digest", newNative(realm, "digest", func(interpreter types.Interpreter, this any, params []any) any {
+		promise := newPromise(interpreter)
+		reason, threw := recoverThrow(func() {
+			if len(params) < 2 {
+				ThrowTypeError(interpreter, "Failed to execute 'digest' on 'SubtleCrypto': 2 arguments required, but only %d present.", len(params))
+			}
+			name := params[0]
+			if object, ok := name.(types.Object); ok {
+				if name = GetProperty(interpreter, object, "name"); name == nil {
+					ThrowTypeError(interpreter, "Failed to normalize algorithm: passed algorithm can not be converted to 'Algorithm' because 'name' is required in 'Algorithm'.")
+				}
+			}
+			algorithm, ok := digestAlgorithms[strings.ToUpper(ToString(interpreter, name))]
+			if !ok {
+				throwDOMException(interpreter, "NotSupportedError", "Unrecognized algorithm name")
+			}
+			data, ok := Bytes(params[1])
+			if !ok {
+				ThrowTypeError(interpreter, "Failed to execute 'digest' on 'SubtleCrypto': 2nd argument is not instance of ArrayBuffer, Buffer, TypedArray, or DataView.")
+			}
+			h := algorithm()
+			h.Write(data)
+			promise.Resolve(interpreter, NewArrayBuffer(interpreter, h.Sum(nil)))
+		})
+		if threw {
+			promise.Reject(interpreter, reason)
+		}
+		return promise
+	}), false)
+	subtle.define(SymbolToStringTag, "SubtleCrypto", false)
+	return subtle
+}
+
+func newCrypto(realm *realm) types.Object {
+	crypto := NewObject(realm.objectPrototype).(*instanceImpl)
+	crypto.define("getRandomValues", newNative(realm, "getRandomValues", func(interpreter types.Interpreter, this any, params []any) any {
+		if len(params) == 0 {
+			ThrowTypeError(interpreter, "Failed to execute 'getRandomValues' on 'Crypto': 1 argument required, but only 0 present.")
+		}
+		array, ok := params[0].(*typedArrayImpl)
+		if !ok || strings.HasPrefix(array.kind.name, "Float") {
+			throwDOMException(interpreter, "TypeMismatchError", "The data argument must be an integer-type TypedArray")
+		}
+		data, _ := Bytes(array)
+		if len(data) > maxRandomValues {
+			throwDOMException(interpreter, "QuotaExceededError", "The requested length exceeds 65,536 bytes")
+		}
+		rand.Read(data)
+		return array
+	}), false)
+	crypto.define("randomUUID", newNative(realm, "randomUUID", func(interpreter types.Interpreter, this any, params []any) any {
+		var data [16]byte
+		rand.Read(data[:])
+		data[6] = data[6]&0x0F | 0x40 // version 4
+		data[8] = data[8]&0x3F | 0x80 // variant 10
+		return fmt.Sprintf("%x-%x-%x-%x-%x", data[0:4], data[4:6], data[6:8], data[8:10], data[10:16])
+	}), false)
+	crypto.define("subtle", newSubtleCrypto(realm), false)
+	crypto.define(SymbolToStringTag, "Crypto", false)
+	return crypto
+}
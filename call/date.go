@@ -0,0 +1,492 @@
+package call
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/nusr/gojs/types"
+)
+
+// dateImpl is a Date object. Its time value counts milliseconds since the
+// epoch in UTC, and is NaN for an invalid date.
+type dateImpl struct {
+	*instanceImpl
+	time float64
+}
+
+const (
+	msPerSecond = 1000
+	msPerMinute = 60 * msPerSecond
+	msPerHour   = 60 * msPerMinute
+	msPerDay    = 24 * msPerHour
+	// maxTime is the largest distance of a valid date from the epoch.
+	maxTime = 8.64e15
+)
+
+// The fields of a date, in the order of the arguments of the Date
+// constructor, followed by the day of the week.
+const (
+	fieldYear = iota
+	fieldMonth
+	fieldDate
+	fieldHours
+	fieldMinutes
+	fieldSeconds
+	fieldMilliseconds
+	fieldDay
+)
+
+type dateFields [8]float64
+
+var (
+	dayNames   = []string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"}
+	monthNames = []string{"Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"}
+)
+
+func newDateObject(interpreter types.Interpreter, value float64) *dateImpl {
+	return &dateImpl{
+		instanceImpl: NewObject(realmOf(interpreter).datePrototype).(*instanceImpl),
+		time:         value,
+	}
+}
+
+func thisDate(interpreter types.Interpreter, this any) *dateImpl {
+	if date, ok := this.(*dateImpl); ok {
+		return date
+	}
+	ThrowTypeError(interpreter, "this is not a Date object.")
+	return nil
+}
+
+// now is the current time value of the clock of the event loop.
+func now(interpreter types.Interpreter) float64 {
+	return float64(interpreter.GetEventLoop().Now().UnixMilli())
+}
+
+func isFinite(x float64) bool {
+	return !math.IsNaN(x) && !math.IsInf(x, 0)
+}
+
+func floorDiv(a int64, b int64) int64 {
+	result := a / b
+	if (a%b != 0) && ((a < 0) != (b < 0)) {
+		result--
+	}
+	return result
+}
+
+// daysFromCivil counts the days from the epoch to a date of the proleptic
+// Gregorian calendar, with month in [1, 12].
+func daysFromCivil(year int64, month int64, day int64) int64 {
+	if month <= 2 {
+		year--
+	}
+	era := floorDiv(year, 400)
+	yearOfEra := year - era*400
+	dayOfYear := (153*((month+9)%12)+2)/5 + day - 1
+	dayOfEra := yearOfEra*365 + yearOfEra/4 - yearOfEra/100 + dayOfYear
+	return era*146097 + dayOfEra - 719468
+}
+
+// civilFromDays is the inverse of daysFromCivil.
+func civilFromDays(days int64) (year int64, month int64, day int64) {
+	days += 719468
+	era := floorDiv(days, 146097)
+	dayOfEra := days - era*146097
+	yearOfEra := (dayOfEra - dayOfEra/1460 + dayOfEra/36524 - dayOfEra/146096) / 365
+	dayOfYear := dayOfEra - (365*yearOfEra + yearOfEra/4 - yearOfEra/100)
+	shifted := (5*dayOfYear + 2) / 153
+	day = dayOfYear - (153*shifted+2)/5 + 1
+	month = shifted + 3
+	if month > 12 {
+		month -= 12
+	}
+	year = yearOfEra + era*400
+	if month <= 2 {
+		year++
+	}
+	return year, month, day
+}
+
+// makeDay counts the days from the epoch to a date, with month counted from
+// 0 and allowed to overflow into the year.
+func makeDay(year float64, month float64, date float64) float64 {
+	if !isFinite(year) || !isFinite(month) || !isFinite(date) {
+		return math.NaN()
+	}
+	year, month, date = math.Trunc(year), math.Trunc(month), math.Trunc(date)
+	year += math.Floor(month / 12)
+	month -= math.Floor(month/12) * 12
+	// far outside the range of valid dates
+	if math.Abs(year) > 400000 {
+		return math.NaN()
+	}
+	return float64(daysFromCivil(int64(year), int64(month)+1, 1)) + date - 1
+}
+
+func makeTime(hours float64, minutes float64, seconds float64, milliseconds float64) float64 {
+	if !isFinite(hours) || !isFinite(minutes) || !isFinite(seconds) || !isFinite(milliseconds) {
+		return math.NaN()
+	}
+	return math.Trunc(hours)*msPerHour + math.Trunc(minutes)*msPerMinute + math.Trunc(seconds)*msPerSecond + math.Trunc(milliseconds)
+}
+
+func makeDate(day float64, ms float64) float64 {
+	result := day*msPerDay + ms
+	if !isFinite(result) {
+		return math.NaN()
+	}
+	return result
+}
+
+// timeClip turns a time value out of range into NaN.
+func timeClip(value float64) float64 {
+	if !isFinite(value) || math.Abs(value) > maxTime {
+		return math.NaN()
+	}
+	return math.Trunc(value) + 0
+}
+
+// splitTime breaks a finite time value into its fields.
+func splitTime(value float64) dateFields {
+	days := int64(math.Floor(value / msPerDay))
+	ms := int64(value) - days*msPerDay
+	year, month, date := civilFromDays(days)
+	return dateFields{
+		float64(year),
+		float64(month - 1),
+		float64(date),
+		float64(ms / msPerHour),
+		float64(ms / msPerMinute % 60),
+		float64(ms / msPerSecond % 60),
+		float64(ms % msPerSecond),
+		float64(days + 4 - floorDiv(days+4, 7)*7),
+	}
+}
+
+func joinFields(fields dateFields) float64 {
+	day := makeDay(fields[fieldYear], fields[fieldMonth], fields[fieldDate])
+	return makeDate(day, makeTime(fields[fieldHours], fields[fieldMinutes], fields[fieldSeconds], fields[fieldMilliseconds]))
+}
+
+// offsetAt is the offset of a time zone from UTC at a time value, in
+// milliseconds.
+func offsetAt(location *time.Location, value float64) float64 {
+	_, offset := time.UnixMilli(int64(value)).In(location).Zone()
+	return float64(offset) * msPerSecond
+}
+
+func localTime(location *time.Location, value float64) float64 {
+	return value + offsetAt(location, value)
+}
+
+// utcTime converts a local time value to UTC. A local time repeated when the
+// clocks go back is its earlier instant, and one skipped when they go
+// forward is read with the offset from before the change.
+func utcTime(location *time.Location, value float64) float64 {
+	if !isFinite(value) {
+		return math.NaN()
+	}
+	before := offsetAt(location, value-msPerDay)
+	after := offsetAt(location, value+msPerDay)
+	result := math.NaN()
+	for _, offset := range []float64{before, after} {
+		candidate := value - offset
+		if offsetAt(location, candidate) == offset && !(candidate > result) {
+			result = candidate
+		}
+	}
+	if math.IsNaN(result) {
+		return value - before
+	}
+	return result
+}
+
+// dateFromFields creates the time value of the Date constructor and
+// Date.UTC, where years 0 to 99 mean 1900 to 1999.
+func dateFromFields(interpreter types.Interpreter, params []any) float64 {
+	fields := dateFields{math.NaN(), 0, 1}
+	for i := range params {
+		if i > fieldMilliseconds {
+			break
+		}
+		fields[i] = ToNumber(interpreter, params[i])
+	}
+	if year := fields[fieldYear]; !math.IsNaN(year) {
+		if integer := math.Trunc(year); integer >= 0 && integer <= 99 {
+			fields[fieldYear] = 1900 + integer
+		}
+	}
+	return joinFields(fields)
+}
+
+func formatYear(year int) string {
+	if year < 0 {
+		return fmt.Sprintf("-%04d", -year)
+	}
+	return fmt.Sprintf("%04d", year)
+}
+
+func formatDate(fields dateFields) string {
+	return fmt.Sprintf("%s %s %02d %s", dayNames[int(fields[fieldDay])], monthNames[int(fields[fieldMonth])], int(fields[fieldDate]), formatYear(int(fields[fieldYear])))
+}
+
+func formatTime(fields dateFields) string {
+	return fmt.Sprintf("%02d:%02d:%02d", int(fields[fieldHours]), int(fields[fieldMinutes]), int(fields[fieldSeconds]))
+}
+
+// formatZone prints the offset and the name of the time zone at a time
+// value, such as "GMT+0100 (CET)". The name is the abbreviation of the tz
+// database, where V8 prints a localized long name.
+func formatZone(location *time.Location, value float64) string {
+	name, offset := time.UnixMilli(int64(value)).In(location).Zone()
+	minutes := offset / 60
+	sign := "+"
+	if minutes < 0 {
+		sign = "-"
+		minutes = -minutes
+	}
+	switch {
+	case name == "UTC":
+		name = "Coordinated Universal Time"
+	case name == "" || name[0] == '+' || name[0] == '-':
+		name = fmt.Sprintf("GMT%s%02d:%02d", sign, minutes/60, minutes%60)
+	}
+	return fmt.Sprintf("GMT%s%02d%02d (%s)", sign, minutes/60, minutes%60, name)
+}
+
+func toISOString(value float64) string {
+	fields := splitTime(value)
+	year := int(fields[fieldYear])
+	var prefix string
+	switch {
+	case year < 0:
+		prefix = fmt.Sprintf("-%06d", -year)
+	case year > 9999:
+		prefix = fmt.Sprintf("+%06d", year)
+	default:
+		prefix = fmt.Sprintf("%04d", year)
+	}
+	return fmt.Sprintf("%s-%02d-%02dT%s.%03dZ", prefix, int(fields[fieldMonth])+1, int(fields[fieldDate]), formatTime(fields), int(fields[fieldMilliseconds]))
+}
+
+// localeLocation reads the timeZone option of the toLocaleString methods.
+func localeLocation(interpreter types.Interpreter, options any) *time.Location {
+	if _, ok := options.(types.Property); ok {
+		if value := GetProperty(interpreter, options, "timeZone"); value != nil {
+			name := ToString(interpreter, value)
+			location, err := time.LoadLocation(name)
+			if err != nil || name == "" || name == "Local" {
+				ThrowRangeError(interpreter, "Invalid time zone specified: %s", name)
+			}
+			return location
+		}
+	}
+	return interpreter.Location()
+}
+
+func dateToString(interpreter types.Interpreter, value float64) string {
+	if math.IsNaN(value) {
+		return "Invalid Date"
+	}
+	location := interpreter.Location()
+	fields := splitTime(localTime(location, value))
+	return formatDate(fields) + " " + formatTime(fields) + " " + formatZone(location, value)
+}
+
+func newDatePrototype(realm *realm) *instanceImpl {
+	prototype := NewObject(realm.objectPrototype).(*instanceImpl)
+	method := func(name string, fn func(interpreter types.Interpreter, date *dateImpl, params []any) any) {
+		prototype.define(name, newNative(realm, name, func(interpreter types.Interpreter, this any, params []any) any {
+			return fn(interpreter, thisDate(interpreter, this), params)
+		}), false)
+	}
+	// format prints an invalid date as "Invalid Date", and a valid one in
+	// the local time zone or in UTC
+	format := func(name string, utc bool, print func(interpreter types.Interpreter, fields dateFields, value float64) string) {
+		method(name, func(interpreter types.Interpreter, date *dateImpl, params []any) any {
+			if math.IsNaN(date.time) {
+				return "Invalid Date"
+			}
+			value := date.time
+			if !utc {
+				value = localTime(interpreter.Location(), value)
+			}
+			return print(interpreter, splitTime(value), date.time)
+		})
+	}
+	method("getTime", func(interpreter types.Interpreter, date *dateImpl, params []any) any {
+		return date.time
+	})
+	method("valueOf", func(interpreter types.Interpreter, date *dateImpl, params []any) any {
+		return date.time
+	})
+	method("setTime", func(interpreter types.Interpreter, date *dateImpl, params []any) any {
+		date.time = timeClip(ToNumber(interpreter, GetArgument(params, 0)))
+		return date.time
+	})
+	method("getTimezoneOffset", func(interpreter types.Interpreter, date *dateImpl, params []any) any {
+		if math.IsNaN(date.time) {
+			return date.time
+		}
+		return (date.time - localTime(interpreter.Location(), date.time)) / msPerMinute
+	})
+	fieldNames := []string{"FullYear", "Month", "Date", "Hours", "Minutes", "Seconds", "Milliseconds", "Day"}
+	for field, name := range fieldNames {
+		for _, utc := range []bool{false, true} {
+			prefix := ""
+			if utc {
+				prefix = "UTC"
+			}
+			method("get"+prefix+name, func(interpreter types.Interpreter, date *dateImpl, params []any) any {
+				if math.IsNaN(date.time) {
+					return date.time
+				}
+				value := date.time
+				if !utc {
+					value = localTime(interpreter.Location(), value)
+				}
+				return splitTime(value)[field]
+			})
+			if field == fieldDay {
+				continue
+			}
+			// the setters take the field of their name and the smaller
+			// fields that follow it, up to the day or the milliseconds
+			last := fieldDate
+			if field >= fieldHours {
+				last = fieldMilliseconds
+			}
+			method("set"+prefix+name, func(interpreter types.Interpreter, date *dateImpl, params []any) any {
+				value := date.time
+				if !utc && !math.IsNaN(value) {
+					value = localTime(interpreter.Location(), value)
+				}
+				// setFullYear alone gives an invalid date a time
+				if math.IsNaN(value) && field == fieldYear {
+					value = 0
+				}
+				fields := dateFields{math.NaN()}
+				if !math.IsNaN(value) {
+					fields = splitTime(value)
+				}
+				values := make([]float64, 0, last-field+1)
+				for i := field; i <= last && (i == field || i-field < len(params)); i++ {
+					values = append(values, ToNumber(interpreter, GetArgument(params, i-field)))
+				}
+				if math.IsNaN(value) {
+					return value
+				}
+				copy(fields[field:], values)
+				value = joinFields(fields)
+				if !utc {
+					value = utcTime(interpreter.Location(), value)
+				}
+				date.time = timeClip(value)
+				return date.time
+			})
+		}
+	}
+	method("getYear", func(interpreter types.Interpreter, date *dateImpl, params []any) any {
+		if math.IsNaN(date.time) {
+			return date.time
+		}
+		return splitTime(localTime(interpreter.Location(), date.time))[fieldYear] - 1900
+	})
+	method("toString", func(interpreter types.Interpreter, date *dateImpl, params []any) any {
+		return dateToString(interpreter, date.time)
+	})
+	format("toDateString", false, func(interpreter types.Interpreter, fields dateFields, value float64) string {
+		return formatDate(fields)
+	})
+	format("toTimeString", false, func(interpreter types.Interpreter, fields dateFields, value float64) string {
+		return formatTime(fields) + " " + formatZone(interpreter.Location(), value)
+	})
+	format("toUTCString", true, func(interpreter types.Interpreter, fields dateFields, value float64) string {
+		return fmt.Sprintf("%s, %02d %s %s %s GMT", dayNames[int(fields[fieldDay])], int(fields[fieldDate]), monthNames[int(fields[fieldMonth])], formatYear(int(fields[fieldYear])), formatTime(fields))
+	})
+	prototype.define("toGMTString", prototype.Get("toUTCString"), false)
+	method("toISOString", func(interpreter types.Interpreter, date *dateImpl, params []any) any {
+		if math.IsNaN(date.time) {
+			ThrowRangeError(interpreter, "Invalid time value")
+		}
+		return toISOString(date.time)
+	})
+	locale := func(name string, required string, defaults string) {
+		method(name, func(interpreter types.Interpreter, date *dateImpl, params []any) any {
+			if math.IsNaN(date.time) {
+				return "Invalid Date"
+			}
+			format := newDateTimeFormat(interpreter, GetArgument(params, 0), GetArgument(params, 1), required, defaults)
+			return joinParts(format.parts(date.time))
+		})
+	}
+	locale("toLocaleString", "any", "all")
+	locale("toLocaleDateString", "date", "date")
+	locale("toLocaleTimeString", "time", "time")
+	prototype.define("toJSON", newNative(realm, "toJSON", func(interpreter types.Interpreter, this any, params []any) any {
+		object := ToObject(interpreter, this)
+		if value, ok := toFloat(ToPrimitive(interpreter, object, "number")); ok && !isFinite(value) {
+			return nil
+		}
+		return Invoke(interpreter, object.Get("toISOString"), object, nil)
+	}), false)
+	prototype.define(SymbolToPrimitive, newNative(realm, "[Symbol.toPrimitive]", func(interpreter types.Interpreter, this any, params []any) any {
+		if _, ok := this.(types.Property); !ok {
+			ThrowTypeError(interpreter, "Date.prototype [ @@toPrimitive ] called on non-object")
+		}
+		switch hint := GetArgument(params, 0); hint {
+		case "string", "default":
+			return ordinaryToPrimitive(interpreter, this, "string")
+		case "number":
+			return ordinaryToPrimitive(interpreter, this, "number")
+		default:
+			ThrowTypeError(interpreter, "Invalid hint: %s", ToString(interpreter, hint))
+		}
+		return nil
+	}), false)
+	return prototype
+}
+
+func newDateConstructor(realm *realm) types.Object {
+	constructor := newConstructor(realm, "Date", func(interpreter types.Interpreter, this any, params []any) any {
+		// called as a function, Date ignores its arguments
+		return dateToString(interpreter, now(interpreter))
+	}, func(interpreter types.Interpreter, params []any) any {
+		switch len(params) {
+		case 0:
+			return newDateObject(interpreter, now(interpreter))
+		case 1:
+			if date, ok := params[0].(*dateImpl); ok {
+				return newDateObject(interpreter, date.time)
+			}
+			value := ToPrimitive(interpreter, params[0], "default")
+			if text, ok := value.(string); ok {
+				return newDateObject(interpreter, parseDate(interpreter.Location(), text))
+			}
+			return newDateObject(interpreter, timeClip(ToNumber(interpreter, value)))
+		}
+		return newDateObject(interpreter, timeClip(utcTime(interpreter.Location(), dateFromFields(interpreter, params))))
+	}).(*nativeImpl)
+	constructor.define("now", newNative(realm, "now", func(interpreter types.Interpreter, this any, params []any) any {
+		return now(interpreter)
+	}), false)
+	constructor.define("parse", newNative(realm, "parse", func(interpreter types.Interpreter, this any, params []any) any {
+		return parseDate(interpreter.Location(), ToString(interpreter, GetArgument(params, 0)))
+	}), false)
+	constructor.define("UTC", newNative(realm, "UTC", func(interpreter types.Interpreter, this any, params []any) any {
+		return timeClip(dateFromFields(interpreter, params))
+	}), false)
+	constructor.define("prototype", realm.datePrototype, false)
+	realm.datePrototype.define("constructor", constructor, false)
+	return constructor
+}
+
+// String is how a date prints: in ISO format, as the Node REPL shows it.
+func (date *dateImpl) String() string {
+	if math.IsNaN(date.time) {
+		return "Invalid Date"
+	}
+	return toISOString(date.time)
+}
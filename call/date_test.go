@@ -0,0 +1,84 @@
+package call
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestParseDate(t *testing.T) {
+	location, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip(err)
+	}
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"2026", "2026-01-01T00:00:00.000Z"},
+		{"2026-10-20", "2026-10-20T00:00:00.000Z"},
+		{"2026-10-20T10:11", "2026-10-20T14:11:00.000Z"},
+		{"2026-10-20T10:11:12.3456Z", "2026-10-20T10:11:12.345Z"},
+		{"2026-10-20T10:11:12+02:00", "2026-10-20T08:11:12.000Z"},
+		{"+275760-09-13T00:00:00.000Z", "+275760-09-13T00:00:00.000Z"},
+		{"+275760-09-13T00:00:00.001Z", ""},
+		{"-000000-01-01T00:00:00Z", ""},
+		{"2026-13-01", ""},
+		{"2026-10-20T24:00", "2026-10-21T04:00:00.000Z"},
+		{"2026-10-20T24:00:01", ""},
+		{"2026-10-20 10:11", "2026-10-20T14:11:00.000Z"},
+		{"2000-01-01T10:00:00 GMT", ""},
+		{"Tue, 20 Oct 2026 08:00:00 GMT", "2026-10-20T08:00:00.000Z"},
+		{"Tue, 20 Oct 2026 08:00:00 +0100", "2026-10-20T07:00:00.000Z"},
+		{"Tue Oct 20 2026 10:00:00 GMT+0200 (CEST)", "2026-10-20T08:00:00.000Z"},
+		{"October 20, 2026 10:00 PM", "2026-10-21T02:00:00.000Z"},
+		{"10/20/26", "2026-10-20T04:00:00.000Z"},
+		{"10/20/2026 10:00:00 PST", "2026-10-20T18:00:00.000Z"},
+		{"Jan 1 2000 GMT+2:30", "1999-12-31T21:30:00.000Z"},
+		{"Oct 20", "2001-10-20T04:00:00.000Z"},
+		{"1 Jan 49", "2049-01-01T05:00:00.000Z"},
+		{"foo Jan 1 2000", "2000-01-01T05:00:00.000Z"},
+		{"Mon Jan 1 2000 foo", ""},
+		{"1/2/2020 13:30 pm", ""},
+		{"Jan 1 2000 -1100", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			value := parseDate(location, tt.text)
+			actual := ""
+			if !math.IsNaN(value) {
+				actual = toISOString(value)
+			}
+			if actual != tt.want {
+				t.Errorf("expect= %q, actual= %q", tt.want, actual)
+			}
+		})
+	}
+}
+
+func TestUTCTime(t *testing.T) {
+	location, err := time.LoadLocation("Europe/London")
+	if err != nil {
+		t.Skip(err)
+	}
+	tests := []struct {
+		name  string
+		local string
+		want  string
+	}{
+		{"winter", "2026-01-10T12:00:00Z", "2026-01-10T12:00:00.000Z"},
+		{"summer", "2026-07-10T12:00:00Z", "2026-07-10T11:00:00.000Z"},
+		{"skipped", "2026-03-29T01:30:00Z", "2026-03-29T01:30:00.000Z"},
+		{"repeated", "2026-10-25T01:30:00Z", "2026-10-25T00:30:00.000Z"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			local, _ := time.Parse(time.RFC3339, tt.local)
+			actual := toISOString(utcTime(location, float64(local.UnixMilli())))
+			if actual != tt.want {
+				t.Errorf("expect= %q, actual= %q", tt.want, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,580 @@
+package call
+
+import (
+	"math"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// The date parser follows V8: the date time string format of the
+// specification is read first, and whatever it does not cover goes to a
+// lenient legacy parser that accepts RFC 2822 dates, the output of
+// toString and many other forms.
+
+const dateNone = math.MinInt32
+
+type dateTokenKind int
+
+const (
+	dateEnd dateTokenKind = iota
+	dateNumber
+	dateSymbol
+	dateWord
+	dateSpace
+	dateUnknown
+	dateInvalid
+)
+
+type dateKeyword int
+
+const (
+	keywordNone dateKeyword = iota
+	keywordMonth
+	keywordAmPm
+	keywordZone
+	keywordTimeSeparator
+)
+
+type dateToken struct {
+	kind    dateTokenKind
+	value   int // of a number or a keyword
+	length  int
+	symbol  rune
+	keyword dateKeyword
+}
+
+// dateKeywords are matched on their whole word, except month names that
+// match on their first three letters.
+var dateKeywords = []struct {
+	word    string
+	keyword dateKeyword
+	value   int
+}{
+	{"jan", keywordMonth, 1},
+	{"feb", keywordMonth, 2},
+	{"mar", keywordMonth, 3},
+	{"apr", keywordMonth, 4},
+	{"may", keywordMonth, 5},
+	{"jun", keywordMonth, 6},
+	{"jul", keywordMonth, 7},
+	{"aug", keywordMonth, 8},
+	{"sep", keywordMonth, 9},
+	{"oct", keywordMonth, 10},
+	{"nov", keywordMonth, 11},
+	{"dec", keywordMonth, 12},
+	{"am", keywordAmPm, 0},
+	{"pm", keywordAmPm, 12},
+	{"ut", keywordZone, 0},
+	{"utc", keywordZone, 0},
+	{"z", keywordZone, 0},
+	{"gmt", keywordZone, 0},
+	{"cdt", keywordZone, -5},
+	{"cst", keywordZone, -6},
+	{"edt", keywordZone, -4},
+	{"est", keywordZone, -5},
+	{"mdt", keywordZone, -6},
+	{"mst", keywordZone, -7},
+	{"pdt", keywordZone, -7},
+	{"pst", keywordZone, -8},
+	{"t", keywordTimeSeparator, 0},
+}
+
+// maxSignificantDigits bounds the digits of a number that are read.
+const maxSignificantDigits = 9
+
+func (token dateToken) isSymbol(symbol rune) bool {
+	return token.kind == dateSymbol && token.symbol == symbol
+}
+
+func (token dateToken) isSign() bool {
+	return token.isSymbol('+') || token.isSymbol('-')
+}
+
+func (token dateToken) isFixedLengthNumber(length int) bool {
+	return token.kind == dateNumber && token.length == length
+}
+
+func (token dateToken) isKeyword(keyword dateKeyword) bool {
+	return token.kind == dateWord && token.keyword == keyword
+}
+
+// isZ reports whether the token is the time zone designator Z.
+func (token dateToken) isZ() bool {
+	return token.isKeyword(keywordZone) && token.length == 1 && token.value == 0
+}
+
+func scanDate(text string) []dateToken {
+	source := []rune(text)
+	var tokens []dateToken
+	for i := 0; i < len(source); {
+		c := source[i]
+		start := i
+		switch {
+		case c >= '0' && c <= '9':
+			value := 0
+			for ; i < len(source) && source[i] >= '0' && source[i] <= '9'; i++ {
+				if i-start < maxSignificantDigits {
+					value = value*10 + int(source[i]-'0')
+				}
+			}
+			tokens = append(tokens, dateToken{kind: dateNumber, value: value, length: i - start})
+		case strings.ContainsRune(":-+.)", c):
+			i++
+			tokens = append(tokens, dateToken{kind: dateSymbol, symbol: c, length: 1})
+		case c >= 'A' && !unicode.IsSpace(c):
+			for i < len(source) && source[i] >= 'A' && !unicode.IsSpace(source[i]) {
+				i++
+			}
+			tokens = append(tokens, lookupDateWord(strings.ToLower(string(source[start:i]))))
+		case unicode.IsSpace(c):
+			for i < len(source) && unicode.IsSpace(source[i]) {
+				i++
+			}
+			tokens = append(tokens, dateToken{kind: dateSpace, length: i - start})
+		case c == '(':
+			// comments in parentheses are skipped, nested ones too
+			for depth := 0; i < len(source); {
+				if source[i] == '(' {
+					depth++
+				} else if source[i] == ')' {
+					depth--
+				}
+				i++
+				if depth == 0 {
+					break
+				}
+			}
+			tokens = append(tokens, dateToken{kind: dateUnknown})
+		default:
+			i++
+			tokens = append(tokens, dateToken{kind: dateUnknown})
+		}
+	}
+	return tokens
+}
+
+func lookupDateWord(word string) dateToken {
+	length := len([]rune(word))
+	for _, item := range dateKeywords {
+		if word == item.word || (item.keyword == keywordMonth && length > 3 && strings.HasPrefix(word, item.word)) {
+			return dateToken{kind: dateWord, length: length, keyword: item.keyword, value: item.value}
+		}
+	}
+	return dateToken{kind: dateWord, length: length}
+}
+
+type dateScanner struct {
+	tokens []dateToken
+	index  int
+}
+
+func (scanner *dateScanner) peek() dateToken {
+	if scanner.index < len(scanner.tokens) {
+		return scanner.tokens[scanner.index]
+	}
+	return dateToken{kind: dateEnd}
+}
+
+func (scanner *dateScanner) next() dateToken {
+	token := scanner.peek()
+	if scanner.index < len(scanner.tokens) {
+		scanner.index++
+	}
+	return token
+}
+
+func (scanner *dateScanner) skipSymbol(symbol rune) bool {
+	if scanner.peek().isSymbol(symbol) {
+		scanner.index++
+		return true
+	}
+	return false
+}
+
+func between(x int, low int, high int) bool {
+	return x >= low && x <= high
+}
+
+func isMonth(x int) bool       { return between(x, 1, 12) }
+func isDay(x int) bool         { return between(x, 1, 31) }
+func isHour(x int) bool        { return between(x, 0, 23) }
+func isMinute(x int) bool      { return between(x, 0, 59) }
+func isSecond(x int) bool      { return between(x, 0, 59) }
+func isMillisecond(x int) bool { return between(x, 0, 999) }
+
+// readMilliseconds reads the digits of a fraction of a second.
+func readMilliseconds(token dateToken) int {
+	value, length := token.value, token.length
+	switch {
+	case length == 1:
+		value *= 100
+	case length == 2:
+		value *= 10
+	case length > 3:
+		length = min(length, maxSignificantDigits)
+		for ; length > 3; length-- {
+			value /= 10
+		}
+	}
+	return value
+}
+
+// dayComposer collects the numbers and the month name of a date.
+type dayComposer struct {
+	values     [3]int
+	index      int
+	namedMonth int
+	isoDate    bool
+}
+
+func (day *dayComposer) add(n int) bool {
+	if day.index >= len(day.values) {
+		return false
+	}
+	day.values[day.index] = n
+	day.index++
+	return true
+}
+
+func (day *dayComposer) write() (year int, month int, date int, ok bool) {
+	if day.index < 1 {
+		return 0, 0, 0, false
+	}
+	// the day and month default to 1, and the year to 0, read as 2000
+	for ; day.index < len(day.values); day.index++ {
+		day.values[day.index] = 1
+	}
+	values := day.values
+	switch {
+	case day.namedMonth == dateNone && (day.isoDate || !isDay(values[0])):
+		year, month, date = values[0], values[1], values[2]
+	case day.namedMonth == dateNone:
+		month, date, year = values[0], values[1], values[2]
+	case !isDay(values[0]):
+		month, year, date = day.namedMonth, values[0], values[1]
+	default:
+		month, date, year = day.namedMonth, values[0], values[1]
+	}
+	if !day.isoDate {
+		if between(year, 0, 49) {
+			year += 2000
+		} else if between(year, 50, 99) {
+			year += 1900
+		}
+	}
+	return year, month, date, isMonth(month) && isDay(date)
+}
+
+// timeComposer collects the hours, minutes, seconds and milliseconds.
+type timeComposer struct {
+	values     [4]int
+	index      int
+	hourOffset int // 0 for AM and 12 for PM
+}
+
+func (clock *timeComposer) isEmpty() bool {
+	return clock.index == 0
+}
+
+func (clock *timeComposer) add(n int) bool {
+	if clock.index >= len(clock.values) {
+		return false
+	}
+	clock.values[clock.index] = n
+	clock.index++
+	return true
+}
+
+// addFinal adds the last value given, leaving the rest 0.
+func (clock *timeComposer) addFinal(n int) bool {
+	if !clock.add(n) {
+		return false
+	}
+	clock.index = len(clock.values)
+	return true
+}
+
+func (clock *timeComposer) isExpecting(n int) bool {
+	return (clock.index == 1 && isMinute(n)) || (clock.index == 2 && isSecond(n)) || (clock.index == 3 && isMillisecond(n))
+}
+
+func (clock *timeComposer) write() (values [4]int, ok bool) {
+	values = clock.values
+	hour := values[0]
+	if clock.hourOffset != dateNone {
+		if !between(hour, 0, 12) {
+			return values, false
+		}
+		hour = hour%12 + clock.hourOffset
+		values[0] = hour
+	}
+	if !isHour(hour) || !isMinute(values[1]) || !isSecond(values[2]) || !isMillisecond(values[3]) {
+		// 24:00 is the end of the day
+		if hour != 24 || values[1] != 0 || values[2] != 0 || values[3] != 0 {
+			return values, false
+		}
+	}
+	return values, true
+}
+
+// zoneComposer collects an offset from UTC; without one the date is in
+// local time.
+type zoneComposer struct {
+	sign   int
+	hour   int
+	minute int
+}
+
+func (zone *zoneComposer) set(hours int) {
+	zone.sign = 1
+	if hours < 0 {
+		zone.sign = -1
+		hours = -hours
+	}
+	zone.hour = hours
+	zone.minute = 0
+}
+
+func (zone *zoneComposer) isExpecting(n int) bool {
+	return zone.hour != dateNone && zone.minute == dateNone && isMinute(n)
+}
+
+func (zone *zoneComposer) isUTC() bool {
+	return zone.hour == 0 && zone.minute == 0
+}
+
+func (zone *zoneComposer) isEmpty() bool {
+	return zone.hour == dateNone
+}
+
+// offset is in seconds.
+func (zone *zoneComposer) offset() (int, bool) {
+	if zone.sign == dateNone {
+		return 0, false
+	}
+	hour, minute := max(zone.hour, 0), max(zone.minute, 0)
+	return zone.sign * (hour*3600 + minute*60), true
+}
+
+// parseISODate reads the date time string format of the specification. It
+// returns the first token it does not cover, or an invalid token when the
+// string starts in that format and breaks it.
+func parseISODate(scanner *dateScanner, day *dayComposer, clock *timeComposer, zone *zoneComposer) dateToken {
+	// [('-'|'+')yy]yyyy['-'MM['-'DD]]
+	if scanner.peek().isSign() {
+		sign := scanner.next()
+		if !scanner.peek().isFixedLengthNumber(6) {
+			return sign
+		}
+		year := scanner.next().value
+		if sign.symbol == '-' {
+			if year == 0 {
+				return sign
+			}
+			year = -year
+		}
+		day.add(year)
+	} else if scanner.peek().isFixedLengthNumber(4) {
+		day.add(scanner.next().value)
+	} else {
+		return scanner.next()
+	}
+	if scanner.skipSymbol('-') {
+		if !scanner.peek().isFixedLengthNumber(2) || !isMonth(scanner.peek().value) {
+			return scanner.next()
+		}
+		day.add(scanner.next().value)
+		if scanner.skipSymbol('-') {
+			if !scanner.peek().isFixedLengthNumber(2) || !isDay(scanner.peek().value) {
+				return scanner.next()
+			}
+			day.add(scanner.next().value)
+		}
+	}
+	// 'T'HH':'mm[':'ss['.'sss]][Z|('+'|'-')HH':'mm]
+	if !scanner.peek().isKeyword(keywordTimeSeparator) {
+		if scanner.peek().kind != dateEnd {
+			return scanner.next()
+		}
+	} else {
+		invalid := dateToken{kind: dateInvalid}
+		scanner.next()
+		if !scanner.peek().isFixedLengthNumber(2) || !between(scanner.peek().value, 0, 24) {
+			return invalid
+		}
+		// 24 only as 24:00:00.000
+		hourIs24 := scanner.peek().value == 24
+		clock.add(scanner.next().value)
+		if !scanner.skipSymbol(':') {
+			return invalid
+		}
+		if !scanner.peek().isFixedLengthNumber(2) || !isMinute(scanner.peek().value) || (hourIs24 && scanner.peek().value > 0) {
+			return invalid
+		}
+		clock.add(scanner.next().value)
+		if scanner.skipSymbol(':') {
+			if !scanner.peek().isFixedLengthNumber(2) || !isSecond(scanner.peek().value) || (hourIs24 && scanner.peek().value > 0) {
+				return invalid
+			}
+			clock.add(scanner.next().value)
+			if scanner.skipSymbol('.') {
+				if scanner.peek().kind != dateNumber || (hourIs24 && scanner.peek().value > 0) {
+					return invalid
+				}
+				clock.add(readMilliseconds(scanner.next()))
+			}
+		}
+		if scanner.peek().isZ() {
+			scanner.next()
+			zone.set(0)
+		} else if scanner.peek().isSign() {
+			zone.sign = 1
+			if scanner.next().symbol == '-' {
+				zone.sign = -1
+			}
+			if scanner.peek().isFixedLengthNumber(4) {
+				// the hhmm extension
+				value := scanner.next().value
+				if !isHour(value/100) || !isMinute(value%100) {
+					return invalid
+				}
+				zone.hour, zone.minute = value/100, value%100
+			} else {
+				if !scanner.peek().isFixedLengthNumber(2) || !isHour(scanner.peek().value) {
+					return invalid
+				}
+				zone.hour = scanner.next().value
+				if !scanner.skipSymbol(':') {
+					return invalid
+				}
+				if !scanner.peek().isFixedLengthNumber(2) || !isMinute(scanner.peek().value) {
+					return invalid
+				}
+				zone.minute = scanner.next().value
+			}
+		}
+		if scanner.peek().kind != dateEnd {
+			return invalid
+		}
+	}
+	// without an offset, a date alone is in UTC and a date with a time in
+	// local time
+	if zone.isEmpty() && clock.isEmpty() {
+		zone.set(0)
+	}
+	day.isoDate = true
+	return dateToken{kind: dateEnd}
+}
+
+// parseDate reads a date string to a time value, NaN when it is not a date.
+func parseDate(location *time.Location, text string) float64 {
+	scanner := &dateScanner{tokens: scanDate(text)}
+	day := &dayComposer{namedMonth: dateNone}
+	clock := &timeComposer{hourOffset: dateNone}
+	zone := &zoneComposer{sign: dateNone, hour: dateNone, minute: dateNone}
+	token := parseISODate(scanner, day, clock, zone)
+	if token.kind == dateInvalid {
+		return math.NaN()
+	}
+	hasReadNumber := day.index > 0
+	for ; token.kind != dateEnd; token = scanner.next() {
+		switch {
+		case token.kind == dateNumber:
+			hasReadNumber = true
+			n := token.value
+			if scanner.skipSymbol(':') {
+				if scanner.skipSymbol(':') {
+					// n::
+					if !clock.isEmpty() {
+						return math.NaN()
+					}
+					clock.add(n)
+					clock.add(0)
+				} else {
+					if !clock.add(n) {
+						return math.NaN()
+					}
+					scanner.skipSymbol('.')
+				}
+			} else if scanner.skipSymbol('.') && clock.isExpecting(n) {
+				clock.add(n)
+				if scanner.peek().kind != dateNumber {
+					return math.NaN()
+				}
+				clock.addFinal(readMilliseconds(scanner.next()))
+			} else if zone.isExpecting(n) {
+				zone.minute = n
+			} else if clock.isExpecting(n) {
+				clock.addFinal(n)
+				// the time must end before anything but a zone
+				if next := scanner.peek(); next.kind != dateEnd && next.kind != dateSpace && !next.isZ() && !next.isSign() {
+					return math.NaN()
+				}
+			} else {
+				if !day.add(n) {
+					return math.NaN()
+				}
+				scanner.skipSymbol('-')
+			}
+		case token.kind == dateWord:
+			switch {
+			case token.keyword == keywordAmPm && !clock.isEmpty():
+				clock.hourOffset = token.value
+			case token.keyword == keywordMonth:
+				day.namedMonth = token.value
+				scanner.skipSymbol('-')
+			case token.keyword == keywordZone && hasReadNumber:
+				zone.set(token.value)
+			default:
+				// other words may only come before the first number, apart
+				// from it
+				if hasReadNumber || scanner.peek().kind == dateNumber {
+					return math.NaN()
+				}
+			}
+		case token.isSign() && (zone.isUTC() || !clock.isEmpty()):
+			// an offset after a time or UTC, such as GMT+0100 or -8
+			zone.sign = 1
+			if token.symbol == '-' {
+				zone.sign = -1
+			}
+			n, length := 0, 0
+			if scanner.peek().kind == dateNumber {
+				number := scanner.next()
+				n, length = number.value, number.length
+			}
+			hasReadNumber = true
+			switch {
+			case scanner.peek().isSymbol(':'):
+				zone.hour, zone.minute = n, dateNone
+			case length == 1 || length == 2:
+				zone.hour, zone.minute = n, 0
+			case length == 3 || length == 4:
+				zone.hour, zone.minute = n/100, n%100
+			default:
+				return math.NaN()
+			}
+		case (token.isSign() || token.isSymbol(')')) && hasReadNumber:
+			return math.NaN()
+		}
+	}
+	year, month, date, ok := day.write()
+	if !ok {
+		return math.NaN()
+	}
+	values, ok := clock.write()
+	if !ok {
+		return math.NaN()
+	}
+	value := joinFields(dateFields{
+		float64(year), float64(month - 1), float64(date),
+		float64(values[0]), float64(values[1]), float64(values[2]), float64(values[3]),
+	})
+	if offset, ok := zone.offset(); ok {
+		value -= float64(offset) * msPerSecond
+	} else {
+		value = utcTime(location, value)
+	}
+	return timeClip(value)
+}
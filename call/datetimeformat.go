@@ -0,0 +1,653 @@
+package call
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/nusr/gojs/types"
+)
+
+// dateTimeComponents are the options of DateTimeFormat that choose the
+// fields of a date, in the order resolvedOptions lists them.
+var dateTimeComponents = []struct {
+	name   string
+	values []string
+}{
+	{"weekday", []string{"narrow", "short", "long"}},
+	{"year", []string{"2-digit", "numeric"}},
+	{"month", []string{"2-digit", "numeric", "narrow", "short", "long"}},
+	{"day", []string{"2-digit", "numeric"}},
+	{"hour", []string{"2-digit", "numeric"}},
+	{"minute", []string{"2-digit", "numeric"}},
+	{"second", []string{"2-digit", "numeric"}},
+	{"timeZoneName", []string{"short", "long"}},
+}
+
+var dateTimeStyles = []string{"full", "long", "medium", "short"}
+
+// dateTimeFormatImpl is an Intl.DateTimeFormat. It formats with a CLDR
+// pattern chosen from its options.
+type dateTimeFormatImpl struct {
+	*instanceImpl
+	locale    string
+	data      *localeData
+	location  *time.Location
+	pattern   string
+	dateStyle string
+	timeStyle string
+	realm     *realm
+	bound     types.Function
+}
+
+// newDateTimeFormat creates a DateTimeFormat. When the options choose no
+// field that is required, "date", "time" or "any", the defaults, "date",
+// "time" or "all", are formatted numerically.
+func newDateTimeFormat(interpreter types.Interpreter, locales any, options any, required string, defaults string) *dateTimeFormatImpl {
+	format := &dateTimeFormatImpl{
+		instanceImpl: NewObject(realmOf(interpreter).dateTimeFormatPrototype).(*instanceImpl),
+	}
+	format.realm = realmOf(interpreter)
+	format.locale, format.data = resolveLocale(interpreter, locales)
+	hour12, hasHour12 := getBooleanOption(interpreter, options, "hour12")
+	hourCycle := getOption(interpreter, options, "DateTimeFormat", "hourCycle", []string{"h11", "h12", "h23", "h24"}, "")
+	format.location = localeLocation(interpreter, options)
+	components := make(map[string]string)
+	for _, component := range dateTimeComponents {
+		if value := getOption(interpreter, options, "DateTimeFormat", component.name, component.values, ""); value != "" {
+			components[component.name] = value
+		}
+	}
+	format.dateStyle = getOption(interpreter, options, "DateTimeFormat", "dateStyle", dateTimeStyles, "")
+	format.timeStyle = getOption(interpreter, options, "DateTimeFormat", "timeStyle", dateTimeStyles, "")
+	if format.dateStyle != "" || format.timeStyle != "" {
+		style := "dateStyle"
+		if format.dateStyle == "" {
+			style = "timeStyle"
+		}
+		for _, component := range dateTimeComponents {
+			if _, ok := components[component.name]; ok {
+				ThrowTypeError(interpreter, "Can't set option %s when %s is used", component.name, style)
+			}
+		}
+		if required == "date" && format.timeStyle != "" {
+			ThrowTypeError(interpreter, "Invalid option : timeStyle")
+		}
+		if required == "time" && format.dateStyle != "" {
+			ThrowTypeError(interpreter, "Invalid option : dateStyle")
+		}
+	} else {
+		hasDate := components["weekday"] != "" || components["year"] != "" || components["month"] != "" || components["day"] != ""
+		hasTime := components["hour"] != "" || components["minute"] != "" || components["second"] != ""
+		needDefaults := (required == "time" || !hasDate) && (required == "date" || !hasTime)
+		if needDefaults && (defaults == "date" || defaults == "all") {
+			components["year"], components["month"], components["day"] = "numeric", "numeric", "numeric"
+		}
+		if needDefaults && (defaults == "time" || defaults == "all") {
+			components["hour"], components["minute"], components["second"] = "numeric", "numeric", "numeric"
+		}
+	}
+	// hour12 overrides hourCycle: a locale with a 24-hour clock counts its
+	// 12-hour one from 0, and one with a 12-hour clock counts to 24.
+	switch {
+	case hasHour12 && hour12 && format.data.hour12:
+		hourCycle = "h12"
+	case hasHour12 && hour12:
+		hourCycle = "h11"
+	case hasHour12 && format.data.hour12:
+		hourCycle = "h24"
+	case hasHour12:
+		hourCycle = "h23"
+	}
+	use12 := format.data.hour12
+	if hourCycle != "" {
+		use12 = hourCycle == "h11" || hourCycle == "h12"
+	}
+	if format.dateStyle != "" || format.timeStyle != "" {
+		format.pattern = format.stylePattern(use12)
+	} else {
+		format.pattern = format.componentPattern(components, use12)
+	}
+	if hourCycle != "" {
+		letter := map[string]rune{"h11": 'K', "h12": 'h', "h23": 'H', "h24": 'k'}[hourCycle]
+		format.pattern = mapPattern(format.pattern, func(field rune, width int) string {
+			if strings.ContainsRune("hHKk", field) {
+				return strings.Repeat(string(letter), width)
+			}
+			return strings.Repeat(string(field), width)
+		})
+	}
+	return format
+}
+
+// mapPattern replaces the fields of a CLDR pattern, keeping its quoted
+// literals.
+func mapPattern(pattern string, fn func(field rune, width int) string) string {
+	var builder strings.Builder
+	runes := []rune(pattern)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case r == '\'':
+			j := i + 1
+			for j < len(runes) && runes[j] != '\'' {
+				j++
+			}
+			builder.WriteString(string(runes[i:min(j+1, len(runes))]))
+			i = j + 1
+		case r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z':
+			j := i
+			for j < len(runes) && runes[j] == r {
+				j++
+			}
+			builder.WriteString(fn(r, j-i))
+			i = j
+		default:
+			builder.WriteRune(r)
+			i++
+		}
+	}
+	return builder.String()
+}
+
+// toClock converts the hours of a pattern to a 12-hour or a 24-hour clock,
+// reporting whether it changed.
+func (format *dateTimeFormatImpl) toClock(pattern string, use12 bool) (string, bool) {
+	if strings.ContainsAny(pattern, "hK") == use12 {
+		return pattern, false
+	}
+	if !use12 {
+		pattern = strings.Replace(strings.Replace(pattern, " a", "", 1), "a", "", 1)
+		return mapPattern(pattern, func(field rune, width int) string {
+			if field == 'h' || field == 'K' {
+				return "HH"
+			}
+			return strings.Repeat(string(field), width)
+		}), true
+	}
+	pattern = mapPattern(pattern, func(field rune, width int) string {
+		if field == 'H' {
+			return strings.Repeat("h", width)
+		}
+		return strings.Repeat(string(field), width)
+	})
+	index := strings.IndexRune(pattern, 'h')
+	if strings.HasPrefix(format.data.skeletons["hm"], "a") {
+		return pattern[:index] + "a" + pattern[index:], true
+	}
+	index = strings.LastIndexAny(pattern, "hms") + 1
+	for index < len(pattern) && pattern[index] == pattern[index-1] {
+		index++
+	}
+	return pattern[:index] + " a" + pattern[index:], true
+}
+
+// stylePattern returns the pattern of the dateStyle and timeStyle options.
+// When the time changes clocks, the date is found from its fields the way
+// componentPattern finds it.
+func (format *dateTimeFormatImpl) stylePattern(use12 bool) string {
+	data := format.data
+	date := slicesIndex(dateTimeStyles, format.dateStyle)
+	clock := slicesIndex(dateTimeStyles, format.timeStyle)
+	if clock < 0 {
+		return data.dateStyles[date]
+	}
+	timePattern, changed := format.toClock(data.timeStyles[clock], use12)
+	if date < 0 {
+		return timePattern
+	}
+	datePattern := data.dateStyles[date]
+	if changed {
+		datePattern = format.componentPattern(patternComponents(datePattern), use12)
+	}
+	glue := strings.Replace(data.dateTimeStyles[date], "{1}", datePattern, 1)
+	return strings.Replace(glue, "{0}", timePattern, 1)
+}
+
+func slicesIndex(list []string, value string) int {
+	for i, item := range list {
+		if item == value {
+			return i
+		}
+	}
+	return -1
+}
+
+func (data *localeData) skeleton(key string) (string, bool) {
+	if pattern, ok := data.skeletons[key]; ok {
+		return pattern, true
+	}
+	pattern, ok := commonSkeletons[key]
+	return pattern, ok
+}
+
+// componentPattern finds the pattern of the fields in components, then
+// sets the width of each field to the one asked for.
+func (format *dateTimeFormatImpl) componentPattern(components map[string]string, use12 bool) string {
+	data := format.data
+	month := ""
+	switch components["month"] {
+	case "":
+	case "numeric", "2-digit":
+		month = "M"
+	default:
+		month = "MMM"
+	}
+	var fields []string
+	if components["year"] != "" {
+		fields = append(fields, "y")
+	}
+	if month != "" {
+		fields = append(fields, month)
+	}
+	if components["weekday"] != "" {
+		fields = append(fields, "E")
+	}
+	if components["day"] != "" {
+		fields = append(fields, "d")
+	}
+	date := ""
+	if fields != nil {
+		key := strings.Join(fields, "")
+		pattern, ok := "", false
+		if components["weekday"] == "long" {
+			pattern, ok = data.skeleton(strings.Replace(key, "E", "EEEE", 1))
+		}
+		if !ok {
+			pattern, ok = data.skeleton(key)
+		}
+		if !ok {
+			patterns := make([]string, len(fields))
+			for i, field := range fields {
+				patterns[i], _ = data.skeleton(field)
+			}
+			pattern = strings.Join(patterns, " ")
+		}
+		date = pattern
+	}
+	clock := ""
+	if components["hour"] != "" || components["minute"] != "" || components["second"] != "" {
+		key := ""
+		if components["hour"] != "" {
+			key = "H"
+			if use12 {
+				key = "h"
+			}
+			if components["second"] != "" && components["minute"] == "" {
+				components["minute"] = "2-digit"
+			}
+		}
+		if components["minute"] != "" {
+			key += "m"
+		}
+		if components["second"] != "" {
+			key += "s"
+		}
+		clock, _ = data.skeleton(key)
+		if components["minute"] == "2-digit" && components["timeZoneName"] == "" {
+			if pattern, ok := data.skeleton(strings.Replace(key, "m", "mm", 1)); ok {
+				clock = pattern
+			}
+		}
+		if zone := components["timeZoneName"]; zone != "" {
+			name := "z"
+			if zone == "long" {
+				name = "zzzz"
+			}
+			if data.zoneFirst && key == "H" || data.zoneFirst && key == "h" {
+				clock = name + clock
+			} else if data.zoneFirst {
+				clock = name + " " + clock
+			} else {
+				clock = clock + " " + name
+			}
+		}
+	}
+	pattern := date
+	switch {
+	case date == "":
+		pattern = clock
+	case clock != "":
+		style := 3
+		switch components["month"] {
+		case "long":
+			style = 1
+			if components["weekday"] != "" {
+				style = 0
+			}
+		case "short", "narrow":
+			style = 2
+		}
+		pattern = strings.Replace(data.dateTimeStyles[style], "{1}", date, 1)
+		pattern = strings.Replace(pattern, "{0}", clock, 1)
+	}
+	widths := map[string]int{"narrow": 5, "short": 3, "long": 4}
+	return mapPattern(pattern, func(field rune, width int) string {
+		switch field {
+		case 'y':
+			if components["year"] == "2-digit" {
+				width = 2
+			} else {
+				width = 1
+			}
+		case 'M', 'L':
+			text := widths[components["month"]]
+			if width >= 3 && text > 0 {
+				width = text
+			} else if components["month"] == "2-digit" {
+				width = 2
+			}
+		case 'E', 'c':
+			width = widths[components["weekday"]]
+		case 'd':
+			if components["day"] == "2-digit" {
+				width = 2
+			}
+		case 'h', 'H', 'K', 'k':
+			if components["hour"] == "2-digit" {
+				width = 2
+			}
+		}
+		return strings.Repeat(string(field), width)
+	})
+}
+
+// zoneName names the time zone at a time value, by its offset from GMT
+// unless it is UTC.
+func (format *dateTimeFormatImpl) zoneName(value float64, long bool) string {
+	name, offset := time.UnixMilli(int64(value)).In(format.location).Zone()
+	if offset == 0 && name == "UTC" {
+		if long {
+			return format.data.utc
+		}
+		return "UTC"
+	}
+	if offset == 0 {
+		return format.data.gmt
+	}
+	minutes := offset / 60
+	sign := "+"
+	if minutes < 0 {
+		sign = "-"
+		if format.data.gmtMinus != "" {
+			sign = format.data.gmtMinus
+		}
+		minutes = -minutes
+	}
+	switch {
+	case long:
+		return fmt.Sprintf("%s%s%02d:%02d", format.data.gmt, sign, minutes/60, minutes%60)
+	case minutes%60 != 0:
+		return fmt.Sprintf("%s%s%d:%02d", format.data.gmt, sign, minutes/60, minutes%60)
+	}
+	return fmt.Sprintf("%s%s%d", format.data.gmt, sign, minutes/60)
+}
+
+// parts formats a time value by the fields of the pattern.
+func (format *dateTimeFormatImpl) parts(value float64) []formatPart {
+	data := format.data
+	fields := splitTime(localTime(format.location, value))
+	var parts []formatPart
+	literal := func(text string) {
+		if last := len(parts) - 1; last >= 0 && parts[last].kind == "literal" {
+			parts[last].value += text
+			return
+		}
+		parts = append(parts, formatPart{"literal", text})
+	}
+	number := func(kind string, n int, width int) {
+		parts = append(parts, formatPart{kind, fmt.Sprintf("%0*d", width, n)})
+	}
+	// name writes a month or weekday with the short, long or narrow names
+	// by the width of its field.
+	name := func(kind string, width int, short []string, long []string, narrow []string) {
+		list := short
+		if width == 4 {
+			list = long
+		} else if width >= 5 {
+			list = narrow
+		}
+		n := int(fields[fieldMonth])
+		if kind == "weekday" {
+			n = int(fields[fieldDay])
+		}
+		parts = append(parts, formatPart{kind, list[n]})
+	}
+	runes := []rune(format.pattern)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		if r == '\'' {
+			j := i + 1
+			for j < len(runes) && runes[j] != '\'' {
+				j++
+			}
+			if j == i+1 {
+				literal("'")
+			} else {
+				literal(string(runes[i+1 : j]))
+			}
+			i = j + 1
+			continue
+		}
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z') {
+			literal(string(r))
+			i++
+			continue
+		}
+		j := i
+		for j < len(runes) && runes[j] == r {
+			j++
+		}
+		width := j - i
+		i = j
+		hours := int(fields[fieldHours])
+		switch r {
+		case 'y':
+			year := int(fields[fieldYear])
+			// years before 1 count backwards in the BC era
+			if year <= 0 {
+				year = 1 - year
+			}
+			if width == 2 {
+				number("year", year%100, 2)
+			} else {
+				number("year", year, 1)
+			}
+		case 'M':
+			if width <= 2 {
+				number("month", int(fields[fieldMonth])+1, width)
+			} else {
+				name("month", width, data.shortMonths, data.months, data.narrowMonths)
+			}
+		case 'L':
+			if width <= 2 {
+				number("month", int(fields[fieldMonth])+1, width)
+			} else {
+				short := data.standaloneShortMonths
+				if short == nil {
+					short = data.shortMonths
+				}
+				name("month", width, short, data.months, data.narrowMonths)
+			}
+		case 'd':
+			number("day", int(fields[fieldDate]), width)
+		case 'E':
+			name("weekday", width, data.shortWeekdays, data.weekdays, data.narrowWeekdays)
+		case 'c':
+			short := data.standaloneWeekdays
+			if short == nil {
+				short = data.shortWeekdays
+			}
+			name("weekday", width, short, data.weekdays, data.narrowWeekdays)
+		case 'a':
+			parts = append(parts, formatPart{"dayPeriod", data.dayPeriods[hours/12]})
+		case 'h':
+			if hours %= 12; hours == 0 {
+				hours = 12
+			}
+			number("hour", hours, width)
+		case 'K':
+			number("hour", hours%12, width)
+		case 'H':
+			number("hour", hours, width)
+		case 'k':
+			if hours == 0 {
+				hours = 24
+			}
+			number("hour", hours, width)
+		case 'm':
+			number("minute", int(fields[fieldMinutes]), width)
+		case 's':
+			number("second", int(fields[fieldSeconds]), width)
+		case 'z':
+			parts = append(parts, formatPart{"timeZoneName", format.zoneName(value, width == 4)})
+		}
+	}
+	return parts
+}
+
+// timeZoneName names a time zone by its IANA name, looking up the name of
+// the local one in TZ or /etc/localtime.
+func timeZoneName(location *time.Location) string {
+	if location != time.Local {
+		return location.String()
+	}
+	if name := strings.TrimPrefix(os.Getenv("TZ"), ":"); name != "" {
+		return name
+	}
+	if link, err := os.Readlink("/etc/localtime"); err == nil {
+		if _, name, ok := strings.Cut(link, "zoneinfo/"); ok && name != "Etc/UTC" {
+			return name
+		}
+	}
+	return "UTC"
+}
+
+// timeValue reads the date a format method formats, the current time by
+// default.
+func timeValue(interpreter types.Interpreter, value any) float64 {
+	var x float64
+	if value == nil {
+		x = now(interpreter)
+	} else {
+		x = ToNumber(interpreter, value)
+	}
+	if !isFinite(x) || math.Abs(x) > maxTime {
+		ThrowRangeError(interpreter, "Invalid time value")
+	}
+	return x
+}
+
+func (format *dateTimeFormatImpl) Get(key any) any {
+	if key == "format" {
+		if format.bound == nil {
+			format.bound = newNative(format.realm, "", func(interpreter types.Interpreter, this any, params []any) any {
+				return joinParts(format.parts(timeValue(interpreter, GetArgument(params, 0))))
+			})
+		}
+		return format.bound
+	}
+	return format.instanceImpl.Get(key)
+}
+
+// patternComponents reads the components of the options back from the
+// fields of a pattern.
+func patternComponents(pattern string) map[string]string {
+	components := make(map[string]string)
+	numeric := func(width int) string {
+		if width == 2 {
+			return "2-digit"
+		}
+		return "numeric"
+	}
+	text := map[int]string{3: "short", 4: "long", 5: "narrow"}
+	mapPattern(pattern, func(field rune, width int) string {
+		switch field {
+		case 'E', 'c':
+			components["weekday"] = text[max(width, 3)]
+		case 'y':
+			components["year"] = numeric(width)
+		case 'M', 'L':
+			if width <= 2 {
+				components["month"] = numeric(width)
+			} else {
+				components["month"] = text[width]
+			}
+		case 'd':
+			components["day"] = numeric(width)
+		case 'h', 'H', 'K', 'k':
+			components["hour"] = numeric(width)
+		case 'm':
+			components["minute"] = numeric(width)
+		case 's':
+			components["second"] = numeric(width)
+		case 'z':
+			components["timeZoneName"] = "short"
+			if width == 4 {
+				components["timeZoneName"] = "long"
+			}
+		}
+		return ""
+	})
+	return components
+}
+
+func newDateTimeFormatPrototype(realm *realm) *instanceImpl {
+	prototype := NewObject(realm.objectPrototype).(*instanceImpl)
+	thisDateTimeFormat := func(interpreter types.Interpreter, this any, name string) *dateTimeFormatImpl {
+		format, ok := this.(*dateTimeFormatImpl)
+		if !ok {
+			ThrowTypeError(interpreter, "Method Intl.DateTimeFormat.prototype.%s called on incompatible receiver %s", name, describe(this))
+		}
+		return format
+	}
+	prototype.define("formatToParts", newNative(realm, "formatToParts", func(interpreter types.Interpreter, this any, params []any) any {
+		format := thisDateTimeFormat(interpreter, this, "formatToParts")
+		return partsToArray(interpreter, format.parts(timeValue(interpreter, GetArgument(params, 0))))
+	}), false)
+	prototype.define("resolvedOptions", newNative(realm, "resolvedOptions", func(interpreter types.Interpreter, this any, params []any) any {
+		format := thisDateTimeFormat(interpreter, this, "resolvedOptions")
+		options := []any{
+			"locale", format.locale,
+			"calendar", "gregory",
+			"numberingSystem", "latn",
+			"timeZone", timeZoneName(format.location),
+		}
+		hourCycle := ""
+		mapPattern(format.pattern, func(field rune, width int) string {
+			switch field {
+			case 'K':
+				hourCycle = "h11"
+			case 'h':
+				hourCycle = "h12"
+			case 'H':
+				hourCycle = "h23"
+			case 'k':
+				hourCycle = "h24"
+			}
+			return ""
+		})
+		if hourCycle != "" {
+			options = append(options, "hourCycle", hourCycle, "hour12", hourCycle == "h11" || hourCycle == "h12")
+		}
+		if format.dateStyle != "" || format.timeStyle != "" {
+			if format.dateStyle != "" {
+				options = append(options, "dateStyle", format.dateStyle)
+			}
+			if format.timeStyle != "" {
+				options = append(options, "timeStyle", format.timeStyle)
+			}
+		} else {
+			components := patternComponents(format.pattern)
+			for _, component := range dateTimeComponents {
+				if value, ok := components[component.name]; ok {
+					options = append(options, component.name, value)
+				}
+			}
+		}
+		return newResolvedOptions(interpreter, options...)
+	}), false)
+	return prototype
+}
@@ -0,0 +1,290 @@
+package call
+
+import (
+	"encoding/base64"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/nusr/gojs/types"
+)
+
+// utf8Labels are the labels the Encoding Standard gives UTF-8.
+var utf8Labels = []string{"unicode-1-1-utf-8", "unicode11utf8", "unicode20utf8", "utf-8", "utf8", "x-unicode20utf8"}
+
+// textEncoderImpl is a TextEncoder, which always encodes UTF-8.
+type textEncoderImpl struct {
+	*instanceImpl
+}
+
+func (encoder *textEncoderImpl) Get(key any) any {
+	if key == "encoding" {
+		return "utf-8"
+	}
+	return encoder.instanceImpl.Get(key)
+}
+
+func newUint8Array(interpreter types.Interpreter, data []byte) *typedArrayImpl {
+	return newTypedArray(interpreter, typedArrayKinds[1], newArrayBuffer(interpreter, data, -1, false), 0, int64(len(data)))
+}
+
+func newTextEncoderPrototype(realm *realm) *instanceImpl {
+	prototype := NewObject(realm.objectPrototype).(*instanceImpl)
+	thisTextEncoder := func(interpreter types.Interpreter, this any, name string) {
+		if _, ok := this.(*textEncoderImpl); !ok {
+			ThrowTypeError(interpreter, "Method TextEncoder.prototype.%s called on incompatible receiver %s", name, describe(this))
+		}
+	}
+	prototype.define("encode", newNative(realm, "encode", func(interpreter types.Interpreter, this any, params []any) any {
+		thisTextEncoder(interpreter, this, "encode")
+		text := ""
+		if value := GetArgument(params, 0); value != nil {
+			text = ToString(interpreter, value)
+		}
+		return newUint8Array(interpreter, []byte(toWellFormed(text)))
+	}), false)
+	prototype.define("encodeInto", newNative(realm, "encodeInto", func(interpreter types.Interpreter, this any, params []any) any {
+		thisTextEncoder(interpreter, this, "encodeInto")
+		text := toWellFormed(ToString(interpreter, GetArgument(params, 0)))
+		destination, ok := GetArgument(params, 1).(*typedArrayImpl)
+		if !ok || destination.kind != typedArrayKinds[1] {
+			ThrowTypeError(interpreter, "The \"dest\" argument must be an instance of Uint8Array. Received %s", received(GetArgument(params, 1)))
+		}
+		data, _ := Bytes(destination)
+		read, written := 0, 0
+		// only whole characters are written
+		for _, r := range text {
+			size := utf8.RuneLen(r)
+			if written+size > len(data) {
+				break
+			}
+			utf8.EncodeRune(data[written:], r)
+			written += size
+			read++
+			if r > 0xFFFF {
+				read++
+			}
+		}
+		result := NewInstance(interpreter)
+		result.Set("read", int64(read))
+		result.Set("written", int64(written))
+		return result
+	}), false)
+	prototype.define(SymbolToStringTag, "TextEncoder", false)
+	return prototype
+}
+
+func newTextEncoderConstructor(realm *realm) types.Object {
+	constructor := newConstructor(realm, "TextEncoder", func(interpreter types.Interpreter, this any, params []any) any {
+		ThrowTypeError(interpreter, "Class constructor TextEncoder cannot be invoked without 'new'")
+		return nil
+	}, func(interpreter types.Interpreter, params []any) any {
+		return &textEncoderImpl{instanceImpl: NewObject(realm.textEncoderPrototype).(*instanceImpl)}
+	}).(*nativeImpl)
+	constructor.define("prototype", realm.textEncoderPrototype, false)
+	realm.textEncoderPrototype.define("constructor", constructor, false)
+	return constructor
+}
+
+// textDecoderImpl is a TextDecoder of UTF-8. Between streaming calls it
+// keeps the state of a character whose bytes have not all arrived.
+type textDecoderImpl struct {
+	*instanceImpl
+	fatal     bool
+	ignoreBOM bool
+	// bomSeen reports whether the stream is past the place of a BOM.
+	bomSeen   bool
+	codePoint rune
+	needed    int
+	seen      int
+	lower     byte
+	upper     byte
+}
+
+func (decoder *textDecoderImpl) Get(key any) any {
+	switch key {
+	case "encoding":
+		return "utf-8"
+	case "fatal":
+		return decoder.fatal
+	case "ignoreBOM":
+		return decoder.ignoreBOM
+	}
+	return decoder.instanceImpl.Get(key)
+}
+
+func (decoder *textDecoderImpl) reset() {
+	decoder.bomSeen = false
+	decoder.codePoint, decoder.needed, decoder.seen = 0, 0, 0
+	decoder.lower, decoder.upper = 0x80, 0xBF
+}
+
+// decode runs the UTF-8 decoder of the Encoding Standard over data, which
+// replaces each maximal invalid sequence with one U+FFFD.
+func (decoder *textDecoderImpl) decode(interpreter types.Interpreter, data []byte, stream bool) string {
+	var builder strings.Builder
+	emit := func(r rune) {
+		if !decoder.bomSeen {
+			decoder.bomSeen = true
+			if r == 0xFEFF && !decoder.ignoreBOM {
+				return
+			}
+		}
+		builder.WriteRune(r)
+	}
+	invalid := func() {
+		if decoder.fatal {
+			decoder.reset()
+			ThrowTypeError(interpreter, "The encoded data was not valid for encoding utf-8")
+		}
+		emit(utf8.RuneError)
+	}
+	for i := 0; i < len(data); i++ {
+		b := data[i]
+		if decoder.needed == 0 {
+			switch {
+			case b <= 0x7F:
+				emit(rune(b))
+			case b >= 0xC2 && b <= 0xDF:
+				decoder.needed, decoder.codePoint = 1, rune(b&0x1F)
+			case b >= 0xE0 && b <= 0xEF:
+				if b == 0xE0 {
+					decoder.lower = 0xA0
+				} else if b == 0xED {
+					decoder.upper = 0x9F
+				}
+				decoder.needed, decoder.codePoint = 2, rune(b&0x0F)
+			case b >= 0xF0 && b <= 0xF4:
+				if b == 0xF0 {
+					decoder.lower = 0x90
+				} else if b == 0xF4 {
+					decoder.upper = 0x8F
+				}
+				decoder.needed, decoder.codePoint = 3, rune(b&0x07)
+			default:
+				invalid()
+			}
+			continue
+		}
+		if b < decoder.lower || b > decoder.upper {
+			decoder.codePoint, decoder.needed, decoder.seen = 0, 0, 0
+			decoder.lower, decoder.upper = 0x80, 0xBF
+			invalid()
+			// the byte may start the next character
+			i--
+			continue
+		}
+		decoder.lower, decoder.upper = 0x80, 0xBF
+		decoder.codePoint = decoder.codePoint<<6 | rune(b&0x3F)
+		decoder.seen++
+		if decoder.seen == decoder.needed {
+			emit(decoder.codePoint)
+			decoder.codePoint, decoder.needed, decoder.seen = 0, 0, 0
+		}
+	}
+	if !stream {
+		if decoder.needed != 0 {
+			decoder.needed = 0
+			invalid()
+		}
+		decoder.reset()
+	}
+	return builder.String()
+}
+
+func newTextDecoderPrototype(realm *realm) *instanceImpl {
+	prototype := NewObject(realm.objectPrototype).(*instanceImpl)
+	prototype.define("decode", newNative(realm, "decode", func(interpreter types.Interpreter, this any, params []any) any {
+		decoder, ok := this.(*textDecoderImpl)
+		if !ok {
+			ThrowTypeError(interpreter, "Method TextDecoder.prototype.decode called on incompatible receiver %s", describe(this))
+		}
+		var data []byte
+		if input := GetArgument(params, 0); input != nil {
+			if data, ok = Bytes(input); !ok {
+				ThrowTypeError(interpreter, "The \"list\" argument must be an instance of SharedArrayBuffer, ArrayBuffer or ArrayBufferView.")
+			}
+		}
+		stream := ToBoolean(GetProperty(interpreter, GetArgument(params, 1), "stream"))
+		return decoder.decode(interpreter, data, stream)
+	}), false)
+	prototype.define(SymbolToStringTag, "TextDecoder", false)
+	return prototype
+}
+
+func newTextDecoderConstructor(realm *realm) types.Object {
+	constructor := newConstructor(realm, "TextDecoder", func(interpreter types.Interpreter, this any, params []any) any {
+		ThrowTypeError(interpreter, "Class constructor TextDecoder cannot be invoked without 'new'")
+		return nil
+	}, func(interpreter types.Interpreter, params []any) any {
+		if value := GetArgument(params, 0); value != nil {
+			text := ToString(interpreter, value)
+			label := strings.ToLower(strings.Trim(text, asciiWhitespace))
+			if !slices.Contains(utf8Labels, label) {
+				ThrowRangeError(interpreter, "The \"%s\" encoding is not supported", text)
+			}
+		}
+		options := GetArgument(params, 1)
+		decoder := &textDecoderImpl{
+			instanceImpl: NewObject(realm.textDecoderPrototype).(*instanceImpl),
+			fatal:        ToBoolean(GetProperty(interpreter, options, "fatal")),
+			ignoreBOM:    ToBoolean(GetProperty(interpreter, options, "ignoreBOM")),
+		}
+		decoder.reset()
+		return decoder
+	}).(*nativeImpl)
+	constructor.define("prototype", realm.textDecoderPrototype, false)
+	realm.textDecoderPrototype.define("constructor", constructor, false)
+	return constructor
+}
+
+// asciiWhitespace is what atob ignores in its input.
+const asciiWhitespace = "\t\n\f\r "
+
+func newBase64Functions(realm *realm) []types.Function {
+	btoa := newNative(realm, "btoa", func(interpreter types.Interpreter, this any, params []any) any {
+		if len(params) == 0 {
+			ThrowTypeError(interpreter, "The \"input\" argument must be specified")
+		}
+		text := ToString(interpreter, params[0])
+		data := make([]byte, 0, len(text))
+		for _, r := range text {
+			if r > 0xFF {
+				throwDOMException(interpreter, "InvalidCharacterError", "Invalid character")
+			}
+			data = append(data, byte(r))
+		}
+		return base64.StdEncoding.EncodeToString(data)
+	})
+	atob := newNative(realm, "atob", func(interpreter types.Interpreter, this any, params []any) any {
+		if len(params) == 0 {
+			ThrowTypeError(interpreter, "The \"input\" argument must be specified")
+		}
+		text := strings.Map(func(r rune) rune {
+			if strings.ContainsRune(asciiWhitespace, r) {
+				return -1
+			}
+			return r
+		}, ToString(interpreter, params[0]))
+		if len(text)%4 == 0 {
+			text = strings.TrimSuffix(text, "=")
+			text = strings.TrimSuffix(text, "=")
+		}
+		for _, r := range text {
+			if !(r >= 'A' && r <= 'Z' || r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '+' || r == '/') {
+				throwDOMException(interpreter, "InvalidCharacterError", "Invalid character")
+			}
+		}
+		if len(text)%4 == 1 {
+			throwDOMException(interpreter, "InvalidCharacterError", "The string to be decoded is not correctly encoded.")
+		}
+		data, _ := base64.RawStdEncoding.DecodeString(text)
+		// each byte becomes the character of the same code
+		runes := make([]rune, len(data))
+		for i, b := range data {
+			runes[i] = rune(b)
+		}
+		return string(runes)
+	})
+	return []types.Function{btoa, atob}
+}
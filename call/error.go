@@ -0,0 +1,258 @@
+package call
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/nusr/gojs/types"
+)
+
+// errorImpl is an Error object or an object of one of its subclasses.
+type errorImpl struct {
+	*instanceImpl
+}
+
+// errorNames lists the Error constructors; every other one inherits from
+// the first.
+var errorNames = []string{
+	"Error",
+	"TypeError",
+	"RangeError",
+	"SyntaxError",
+	"ReferenceError",
+	"EvalError",
+	"URIError",
+	"AggregateError",
+}
+
+// newErrorPrototypes creates the prototypes of the Error constructors of a
+// realm, by name.
+func newErrorPrototypes(realm *realm) map[string]*instanceImpl {
+	errorPrototypes := map[string]*instanceImpl{}
+	for _, name := range errorNames {
+		var prototype *instanceImpl
+		if name == "Error" {
+			prototype = NewObject(realm.objectPrototype).(*instanceImpl)
+			prototype.define("toString", newNative(realm, "toString", func(interpreter types.Interpreter, this any, params []any) any {
+				if _, ok := this.(types.Property); !ok {
+					ThrowTypeError(interpreter, "Error.prototype.toString requires that 'this' be an Object")
+				}
+				return errorToString(interpreter, this)
+			}), false)
+		} else {
+			prototype = NewObject(errorPrototypes["Error"]).(*instanceImpl)
+		}
+		prototype.define("name", name, false)
+		prototype.define("message", "", false)
+		errorPrototypes[name] = prototype
+	}
+	return errorPrototypes
+}
+
+// String is what an uncaught error prints: its stack trace.
+func (object *errorImpl) String() string {
+	if stack, ok := object.Get("stack").(string); ok {
+		return stack
+	}
+	return errorToString(nil, object)
+}
+
+func errorToString(interpreter types.Interpreter, object any) string {
+	name := "Error"
+	if value := GetProperty(interpreter, object, "name"); value != nil {
+		name = ToString(interpreter, value)
+	}
+	message := ""
+	if value := GetProperty(interpreter, object, "message"); value != nil {
+		message = ToString(interpreter, value)
+	}
+	if name == "" {
+		return message
+	}
+	if message == "" {
+		return name
+	}
+	return name + ": " + message
+}
+
+// NewError creates an error of one of the built-in Error constructors, with
+// the stack of the interpreter.
+func NewError(interpreter types.Interpreter, name string, message any, options any) types.Object {
+	object := &errorImpl{
+		instanceImpl: NewObject(realmOf(interpreter).errorPrototypes[name]).(*instanceImpl),
+	}
+	if message != nil {
+		object.define("message", ToString(interpreter, message), false)
+	}
+	if _, ok := options.(types.Property); ok && HasProperty(options, "cause") {
+		object.define("cause", GetProperty(interpreter, options, "cause"), false)
+	}
+	object.define("stack", errorStack(interpreter, object), false)
+	return object
+}
+
+// errorStack formats a stack trace as V8 does, one "at" line per frame.
+func errorStack(interpreter types.Interpreter, object types.Object) string {
+	return formatStack(interpreter, errorToString(interpreter, object))
+}
+
+// formatStack appends the frames of the running calls to a header, the
+// way V8 prints a stack.
+func formatStack(interpreter types.Interpreter, header string) string {
+	var builder strings.Builder
+	builder.WriteString(header)
+	if interpreter == nil {
+		return header
+	}
+	for _, frame := range interpreter.StackTrace() {
+		// top-level code that is no longer running, as in a timer callback
+		if frame.Line == 0 {
+			continue
+		}
+		location := frame.File + ":" + strconv.Itoa(frame.Line) + ":" + strconv.Itoa(frame.Column)
+		builder.WriteString("\n    at ")
+		if frame.Name == "" {
+			builder.WriteString(location)
+		} else {
+			builder.WriteString(frame.Name + " (" + location + ")")
+		}
+	}
+	return builder.String()
+}
+
+// newAggregateError creates an AggregateError holding a list of errors.
+func newAggregateError(interpreter types.Interpreter, errors []any, message any, options any) types.Object {
+	object := NewError(interpreter, "AggregateError", message, options).(*errorImpl)
+	object.define("errors", NewArrayFrom(interpreter, errors), false)
+	return object
+}
+
+func newErrorConstructor(realm *realm, name string) types.Object {
+	create := func(interpreter types.Interpreter, params []any) any {
+		if name != "AggregateError" {
+			return NewError(interpreter, name, GetArgument(params, 0), GetArgument(params, 1))
+		}
+		var errors []any
+		iterator := GetIterator(interpreter, GetArgument(params, 0))
+		for {
+			value, ok := iterator.Step(interpreter)
+			if !ok {
+				break
+			}
+			errors = append(errors, value)
+		}
+		return newAggregateError(interpreter, errors, GetArgument(params, 1), GetArgument(params, 2))
+	}
+	// calling an Error constructor without new also creates an error
+	constructor := newConstructor(realm, name, func(interpreter types.Interpreter, this any, params []any) any {
+		return create(interpreter, params)
+	}, create).(*nativeImpl)
+	prototype := realm.errorPrototypes[name]
+	constructor.define("prototype", prototype, false)
+	prototype.define("constructor", constructor, false)
+	return constructor
+}
+
+// domExceptionCodes are the legacy codes of the DOMException names that
+// have one, in the order of their constants.
+var domExceptionCodes = []struct {
+	name     string
+	constant string
+}{
+	{"IndexSizeError", "INDEX_SIZE_ERR"},
+	{"", "DOMSTRING_SIZE_ERR"},
+	{"HierarchyRequestError", "HIERARCHY_REQUEST_ERR"},
+	{"WrongDocumentError", "WRONG_DOCUMENT_ERR"},
+	{"InvalidCharacterError", "INVALID_CHARACTER_ERR"},
+	{"", "NO_DATA_ALLOWED_ERR"},
+	{"NoModificationAllowedError", "NO_MODIFICATION_ALLOWED_ERR"},
+	{"NotFoundError", "NOT_FOUND_ERR"},
+	{"NotSupportedError", "NOT_SUPPORTED_ERR"},
+	{"InUseAttributeError", "INUSE_ATTRIBUTE_ERR"},
+	{"InvalidStateError", "INVALID_STATE_ERR"},
+	{"SyntaxError", "SYNTAX_ERR"},
+	{"InvalidModificationError", "INVALID_MODIFICATION_ERR"},
+	{"NamespaceError", "NAMESPACE_ERR"},
+	{"InvalidAccessError", "INVALID_ACCESS_ERR"},
+	{"", "VALIDATION_ERR"},
+	{"TypeMismatchError", "TYPE_MISMATCH_ERR"},
+	{"SecurityError", "SECURITY_ERR"},
+	{"NetworkError", "NETWORK_ERR"},
+	{"AbortError", "ABORT_ERR"},
+	{"URLMismatchError", "URL_MISMATCH_ERR"},
+	{"QuotaExceededError", "QUOTA_EXCEEDED_ERR"},
+	{"TimeoutError", "TIMEOUT_ERR"},
+	{"InvalidNodeTypeError", "INVALID_NODE_TYPE_ERR"},
+	{"DataCloneError", "DATA_CLONE_ERR"},
+}
+
+// domExceptionImpl is a DOMException, the error of web APIs. Its name,
+// message and code are read from it rather than being own properties.
+type domExceptionImpl struct {
+	*errorImpl
+	name    string
+	message string
+}
+
+func newDOMExceptionPrototype(realm *realm) *instanceImpl {
+	prototype := NewObject(realm.errorPrototypes["Error"]).(*instanceImpl)
+	defineDOMExceptionCodes(prototype)
+	prototype.define(SymbolToStringTag, "DOMException", false)
+	return prototype
+}
+
+func defineDOMExceptionCodes(object *instanceImpl) {
+	for i, code := range domExceptionCodes {
+		object.define(code.constant, int64(i+1), false)
+	}
+}
+
+func (exception *domExceptionImpl) Get(key any) any {
+	switch key {
+	case "name":
+		return exception.name
+	case "message":
+		return exception.message
+	case "code":
+		for i, code := range domExceptionCodes {
+			if code.name != "" && code.name == exception.name {
+				return int64(i + 1)
+			}
+		}
+		return int64(0)
+	}
+	return exception.errorImpl.Get(key)
+}
+
+// NewDOMException creates a DOMException with the stack of the interpreter.
+func NewDOMException(interpreter types.Interpreter, message string, name string) types.Object {
+	exception := &domExceptionImpl{
+		errorImpl: &errorImpl{
+			instanceImpl: NewObject(realmOf(interpreter).domExceptionPrototype).(*instanceImpl),
+		},
+		name:    name,
+		message: message,
+	}
+	exception.define("stack", errorStack(interpreter, exception), false)
+	return exception
+}
+
+func newDOMExceptionConstructor(realm *realm) types.Object {
+	constructor := newConstructor(realm, "DOMException", func(interpreter types.Interpreter, this any, params []any) any {
+		ThrowTypeError(interpreter, "Class constructor DOMException cannot be invoked without 'new'")
+		return nil
+	}, func(interpreter types.Interpreter, params []any) any {
+		message, name := "", "Error"
+		if value := GetArgument(params, 0); value != nil {
+			message = ToString(interpreter, value)
+		}
+		if value := GetArgument(params, 1); value != nil {
+			name = ToString(interpreter, value)
+		}
+		return NewDOMException(interpreter, message, name)
+	}).(*nativeImpl)
+	defineDOMExceptionCodes(constructor.instanceImpl)
+	constructor.define("prototype", realm.domExceptionPrototype, false)
+	realm.domExceptionPrototype.define("constructor", constructor, false)
+	return constructor
+}
@@ -0,0 +1,114 @@
+package call
+
+import (
+	"strings"
+
+	"github.com/nusr/gojs/environment"
+	"github.com/nusr/gojs/flow"
+	"github.com/nusr/gojs/parser"
+	"github.com/nusr/gojs/scanner"
+	"github.com/nusr/gojs/statement"
+	"github.com/nusr/gojs/types"
+)
+
+// evalNative is the global eval. Called by the name eval it is a direct
+// eval, which the interpreter runs in the scope of the call.
+func evalNative(interpreter types.Interpreter, this any, params []any) any {
+	return Eval(interpreter, GetArgument(params, 0), interpreter.GetGlobal())
+}
+
+// newEval creates the eval function of a realm.
+func newEval(realm *realm) types.Method {
+	eval := newNative(realm, "eval", evalNative).(*nativeImpl)
+	eval.define("length", int64(1), false)
+	return eval
+}
+
+// IsEval reports whether value is the eval function of the realm of the
+// interpreter.
+func IsEval(interpreter types.Interpreter, value any) bool {
+	return value == realmOf(interpreter).evalFunction
+}
+
+// checkCodeGeneration throws unless the embedding allows compiling strings.
+func checkCodeGeneration(interpreter types.Interpreter) {
+	if !interpreter.CodeGeneration() {
+		throwError(interpreter, "EvalError", "Code generation from strings disallowed for this context")
+	}
+}
+
+// parseScript parses code compiled at run time, turning the panics of the
+// scanner and parser into a SyntaxError.
+func parseScript(interpreter types.Interpreter, source string) []statement.Statement {
+	defer func() {
+		if err := recover(); err != nil {
+			switch data := err.(type) {
+			case string:
+				ThrowSyntaxError(interpreter, "%s", data)
+			case error:
+				ThrowSyntaxError(interpreter, "%s", data.Error())
+			default:
+				panic(err)
+			}
+		}
+	}()
+	return parser.NewWithSource(scanner.New(source).Scan(), source).Parse()
+}
+
+// Eval runs source in a block scope of env and returns the value of its
+// last statement that is not a declaration: let, const and class bindings
+// stay in the block, var and function bindings go to env. A value other
+// than a string is returned as is.
+func Eval(interpreter types.Interpreter, source any, env types.Environment) any {
+	text, ok := source.(string)
+	if !ok {
+		return source
+	}
+	checkCodeGeneration(interpreter)
+	scope := environment.NewBlock(env)
+	var result any
+	for _, item := range parseScript(interpreter, text) {
+		value := interpreter.ExecuteBlock(statement.BlockStatement{Statements: []statement.Statement{item}}, scope)
+		if val, ok := value.(flow.Return); ok {
+			return val.Value
+		}
+		switch item.(type) {
+		case statement.VariableStatement, statement.FunctionStatement, statement.ClassStatement:
+		default:
+			result = value
+		}
+	}
+	return result
+}
+
+func newFunctionConstructor(realm *realm) types.Object {
+	create := func(interpreter types.Interpreter, params []any) any {
+		checkCodeGeneration(interpreter)
+		var names []string
+		var body string
+		for i, param := range params {
+			if i == len(params)-1 {
+				body = ToString(interpreter, param)
+			} else {
+				names = append(names, ToString(interpreter, param))
+			}
+		}
+		source := "function anonymous(" + strings.Join(names, ",") + "\n) {\n" + body + "\n}"
+		statements := parseScript(interpreter, source)
+		// the parameters and body must not close the function early
+		function, ok := statements[0].(statement.FunctionStatement)
+		if len(statements) != 1 || !ok {
+			ThrowSyntaxError(interpreter, "Single function literal required")
+		}
+		method := NewMethod(interpreter, function, interpreter.GetGlobal()).(*functionImpl)
+		method.sloppy = true
+		return method
+	}
+	constructor := newConstructor(realm, "Function", func(interpreter types.Interpreter, this any, params []any) any {
+		return create(interpreter, params)
+	}, create).(*nativeImpl)
+	constructor.define("length", int64(1), false)
+	constructor.define("prototype", realm.functionPrototype, false)
+	realm.functionPrototype.define("constructor", constructor, false)
+	return constructor
+}
@@ -2,27 +2,220 @@ package call
 
 import (
 	"github.com/nusr/gojs/environment"
+	"github.com/nusr/gojs/flow"
 	"github.com/nusr/gojs/statement"
 	"github.com/nusr/gojs/token"
 	"github.com/nusr/gojs/types"
 )
 
+// newFunctionPrototype creates Function.prototype, itself a function that
+// returns undefined.
+func newFunctionPrototype(realm *realm) *nativeImpl {
+	return &nativeImpl{
+		instanceImpl: NewObject(realm.objectPrototype).(*instanceImpl),
+		fn: func(interpreter types.Interpreter, this any, params []any) any {
+			return nil
+		},
+	}
+}
+
+// defineFunctionPrototype adds the properties of Function.prototype once
+// built-in functions can be created with it.
+func defineFunctionPrototype(realm *realm) {
+	realm.functionPrototype.define("length", int64(0), false)
+	realm.functionPrototype.define("name", "", false)
+	realm.functionPrototype.define("toString", newNative(realm, "toString", func(interpreter types.Interpreter, this any, params []any) any {
+		function, ok := this.(types.Function)
+		if !ok {
+			ThrowTypeError(interpreter, "Function.prototype.toString requires that 'this' be a Function")
+		}
+		return function.String()
+	}), false)
+}
+
 type functionImpl struct {
-	env    types.Environment
-	body   statement.BlockStatement
-	params []token.Token
+	*instanceImpl
+	name      string
+	env       types.Environment
+	anchors   []types.Environment // scopes env refers to weakly
+	body      statement.BlockStatement
+	params    []token.Token
+	source    string // the text of the definition
+	generator bool
+	async     bool
+	arrow     bool // this is taken from the enclosing scope
+	sloppy    bool // an undefined this is the global object
+}
+
+// newFunctionImpl creates a script function with its length and an empty
+// name, which SetFunctionName may fill in.
+func newFunctionImpl(interpreter types.Interpreter, body statement.BlockStatement, params []token.Token, env types.Environment) *functionImpl {
+	function := &functionImpl{
+		instanceImpl: NewObject(realmOf(interpreter).functionPrototype).(*instanceImpl),
+		body:         body,
+		params:       params,
+		env:          env,
+		anchors:      environment.Anchors(env),
+	}
+	function.defineOwnProperty("length", dataDescriptor(int64(len(params)), false, false, true))
+	function.defineOwnProperty("name", dataDescriptor("", false, false, true))
+	return function
+}
+
+func NewFunction(interpreter types.Interpreter, body statement.BlockStatement, params []token.Token, env types.Environment) types.Method {
+	function := newFunctionImpl(interpreter, body, params, env)
+	prototype := NewObject(realmOf(interpreter).objectPrototype).(*instanceImpl)
+	prototype.define("constructor", function, false)
+	function.define("prototype", prototype, false)
+	return function
+}
+
+func NewGeneratorFunction(interpreter types.Interpreter, body statement.BlockStatement, params []token.Token, env types.Environment) types.Method {
+	function := newFunctionImpl(interpreter, body, params, env)
+	function.generator = true
+	function.define("prototype", NewObject(realmOf(interpreter).generatorPrototype), false)
+	return function
+}
+
+// NewAsyncGeneratorFunction creates a function returning an async generator.
+func NewAsyncGeneratorFunction(interpreter types.Interpreter, body statement.BlockStatement, params []token.Token, env types.Environment) types.Method {
+	function := newFunctionImpl(interpreter, body, params, env)
+	function.generator = true
+	function.async = true
+	function.define("prototype", NewObject(realmOf(interpreter).asyncGeneratorPrototype), false)
+	return function
+}
+
+// NewAsyncFunction creates a function that returns a promise of its result.
+func NewAsyncFunction(interpreter types.Interpreter, body statement.BlockStatement, params []token.Token, env types.Environment) types.Method {
+	function := newFunctionImpl(interpreter, body, params, env)
+	function.async = true
+	return function
+}
+
+// NewArrowFunction creates an arrow function, which has no this of its own.
+func NewArrowFunction(interpreter types.Interpreter, body statement.BlockStatement, params []token.Token, env types.Environment, async bool) types.Method {
+	function := newFunctionImpl(interpreter, body, params, env)
+	function.async = async
+	function.arrow = true
+	return function
+}
+
+// NewMethod creates the function for a method definition.
+func NewMethod(interpreter types.Interpreter, method statement.FunctionStatement, env types.Environment) types.Method {
+	var function types.Method
+	switch {
+	case method.Generator && method.Async:
+		function = NewAsyncGeneratorFunction(interpreter, method.Body, method.Params, env)
+	case method.Generator:
+		function = NewGeneratorFunction(interpreter, method.Body, method.Params, env)
+	case method.Async:
+		function = NewAsyncFunction(interpreter, method.Body, method.Params, env)
+	default:
+		function = NewFunction(interpreter, method.Body, method.Params, env)
+	}
+	if method.Key == nil {
+		SetFunctionName(function, method.Name.Lexeme)
+	}
+	function.(*functionImpl).source = method.Source
+	return function
+}
+
+// SetFunctionName names an anonymous function or class, as assigning it to
+// a variable does.
+func SetFunctionName(value any, name string) {
+	switch data := value.(type) {
+	case *functionImpl:
+		if data.name == "" {
+			data.name = name
+			data.define("name", name, false)
+		}
+	case *classImpl:
+		if data.name == "" {
+			data.name = name
+			data.define("name", name, false)
+		}
+	}
 }
 
-func NewFunction(body statement.BlockStatement, params []token.Token, env types.Environment) types.Function {
-	return &functionImpl{
-		body:   body,
-		params: params,
-		env:    env,
+// NewFunctionExpression creates the function for a function or arrow
+// function expression.
+func NewFunctionExpression(interpreter types.Interpreter, expression statement.FunctionExpression, env types.Environment) types.Method {
+	if expression.Arrow {
+		function := NewArrowFunction(interpreter, expression.Body, expression.Params, env, expression.Async)
+		function.(*functionImpl).source = expression.Source
+		return function
+	}
+	method := statement.FunctionStatement{
+		Body:      expression.Body,
+		Params:    expression.Params,
+		Generator: expression.Generator,
+		Async:     expression.Async,
+		Source:    expression.Source,
+	}
+	if expression.Name != nil {
+		method.Name = *expression.Name
+	}
+	return NewMethod(interpreter, method, env)
+}
+
+func (function *functionImpl) Construct(interpreter types.Interpreter, params []any) any {
+	switch {
+	case function.generator:
+		ThrowTypeError(interpreter, "generator function is not a constructor")
+	case function.arrow:
+		ThrowTypeError(interpreter, "arrow function is not a constructor")
+	case function.async:
+		ThrowTypeError(interpreter, "async function is not a constructor")
+	}
+	proto, _ := function.Get("prototype").(types.Property)
+	object := NewObject(proto)
+	if result, ok := function.CallWith(interpreter, object, params).(types.Property); ok {
+		return result
 	}
+	return object
 }
 
 func (function *functionImpl) Call(interpreter types.Interpreter, params []any) any {
+	return function.CallWith(interpreter, nil, params)
+}
+
+func (function *functionImpl) CallWith(interpreter types.Interpreter, this any, params []any) any {
+	if types.IsNullish(this) && function.sloppy {
+		if global, ok := interpreter.GetGlobal().(types.GlobalEnvironment); ok {
+			this = global.GlobalObject()
+		}
+	}
+	env := function.bind(this, params)
+	if function.generator && function.async {
+		body := function.generatorBody(env)
+		return newAsyncGenerator(interpreter, function, func(interpreter types.Interpreter) any {
+			// the operand of return is awaited
+			return Await(interpreter, body(interpreter))
+		})
+	}
+	if function.generator {
+		return newGenerator(interpreter, function, function.generatorBody(env))
+	}
+	if function.async {
+		return runAsync(interpreter, func(interpreter types.Interpreter) any {
+			return function.run(interpreter, env)
+		})
+	}
+	return function.run(interpreter, env)
+}
+
+// bind creates the scope of a call with the parameters defined. The scope
+// of a generator is detached from the closure, which the generator object
+// keeps alive instead of its goroutine.
+func (function *functionImpl) bind(this any, params []any) types.Environment {
 	env := environment.New(function.env)
+	if function.generator {
+		env = environment.NewDetached(function.env)
+	}
+	if this != nil && !function.arrow {
+		env.Define("this", this)
+	}
 	paramsLen := len(params)
 	for i, item := range function.params {
 		if i <= (paramsLen - 1) {
@@ -31,9 +224,38 @@ func (function *functionImpl) Call(interpreter types.Interpreter, params []any)
 			env.Define(item.Lexeme, nil)
 		}
 	}
-	return interpreter.ExecuteBlock(function.body, env)
+	return env
+}
+
+// run executes the body in a stack frame of its own.
+func (function *functionImpl) run(interpreter types.Interpreter, env types.Environment) any {
+	interpreter.PushFrame(function.name)
+	defer interpreter.PopFrame()
+	return function.execute(interpreter, env)
+}
+
+// generatorBody runs the body of a generator in env. It refers to the
+// statements of the function but not to the function, whose closure may
+// hold the generator.
+func (function *functionImpl) generatorBody(env types.Environment) func(interpreter types.Interpreter) any {
+	name, body := function.name, function.body
+	return func(interpreter types.Interpreter) any {
+		interpreter.PushFrame(name)
+		defer interpreter.PopFrame()
+		if val, ok := interpreter.ExecuteBlock(body, env).(flow.Return); ok {
+			return val.Value
+		}
+		return nil
+	}
+}
+
+func (function *functionImpl) execute(interpreter types.Interpreter, env types.Environment) any {
+	if val, ok := interpreter.ExecuteBlock(function.body, env).(flow.Return); ok {
+		return val.Value
+	}
+	return nil
 }
 
 func (function *functionImpl) String() string {
-	return ""
+	return function.source
 }
@@ -0,0 +1,151 @@
+//go:build ignore
+
+// gen_unicode generates unicode_tables.go, the normalization data of
+// String.prototype.normalize, from the Unicode Character Database.
+//
+//	go run gen_unicode.go [-ucd dir]
+//
+// Without -ucd the files are downloaded from unicode.org.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const version = "14.0.0"
+
+var ucd = flag.String("ucd", "", "directory holding UnicodeData.txt and CompositionExclusions.txt")
+
+func open(name string) io.ReadCloser {
+	if *ucd != "" {
+		file, err := os.Open(filepath.Join(*ucd, name))
+		if err != nil {
+			log.Fatal(err)
+		}
+		return file
+	}
+	response, err := http.Get("https://www.unicode.org/Public/" + version + "/ucd/" + name)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return response.Body
+}
+
+func parseRune(text string) rune {
+	value, err := strconv.ParseUint(strings.TrimSpace(text), 16, 32)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return rune(value)
+}
+
+func main() {
+	flag.Parse()
+	classes := make(map[rune]int)
+	type decomposition struct {
+		compat  bool
+		mapping []rune
+	}
+	decompositions := make(map[rune]decomposition)
+	data := open("UnicodeData.txt")
+	scanner := bufio.NewScanner(data)
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), ";")
+		if len(fields) < 6 {
+			continue
+		}
+		r := parseRune(fields[0])
+		if class, _ := strconv.Atoi(fields[3]); class != 0 {
+			classes[r] = class
+		}
+		if fields[5] == "" {
+			continue
+		}
+		var value decomposition
+		for _, item := range strings.Fields(fields[5]) {
+			if strings.HasPrefix(item, "<") {
+				value.compat = true
+				continue
+			}
+			value.mapping = append(value.mapping, parseRune(item))
+		}
+		decompositions[r] = value
+	}
+	data.Close()
+
+	var exclusions []rune
+	data = open("CompositionExclusions.txt")
+	scanner = bufio.NewScanner(data)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if index := strings.IndexByte(line, '#'); index >= 0 {
+			line = line[:index]
+		}
+		if strings.TrimSpace(line) != "" {
+			exclusions = append(exclusions, parseRune(line))
+		}
+	}
+	data.Close()
+
+	var buffer bytes.Buffer
+	fmt.Fprintf(&buffer, "// Code generated by gen_unicode.go from Unicode %s; DO NOT EDIT.\n\npackage call\n\n", version)
+	fmt.Fprintf(&buffer, "// combiningClasses holds the nonzero canonical combining classes.\n")
+	fmt.Fprintf(&buffer, "var combiningClasses = map[rune]uint8{\n")
+	for i, r := range sortedKeys(classes) {
+		fmt.Fprintf(&buffer, "0x%04X: %d,", r, classes[r])
+		if i%8 == 7 {
+			buffer.WriteByte('\n')
+		}
+	}
+	fmt.Fprintf(&buffer, "\n}\n\n")
+	fmt.Fprintf(&buffer, "// decompositions holds the decomposition mappings, one level deep.\n")
+	fmt.Fprintf(&buffer, "var decompositions = map[rune]decomposition{\n")
+	for _, r := range sortedKeys(decompositions) {
+		value := decompositions[r]
+		var mapping []string
+		for _, item := range value.mapping {
+			mapping = append(mapping, fmt.Sprintf("0x%04X", item))
+		}
+		fmt.Fprintf(&buffer, "0x%04X: {%t, []rune{%s}},\n", r, value.compat, strings.Join(mapping, ", "))
+	}
+	fmt.Fprintf(&buffer, "}\n\n")
+	fmt.Fprintf(&buffer, "// compositionExclusions lists the characters that are not recomposed.\n")
+	fmt.Fprintf(&buffer, "var compositionExclusions = map[rune]bool{\n")
+	for i, r := range exclusions {
+		fmt.Fprintf(&buffer, "0x%04X: true,", r)
+		if i%8 == 7 {
+			buffer.WriteByte('\n')
+		}
+	}
+	fmt.Fprintf(&buffer, "\n}\n")
+	source, err := format.Source(buffer.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile("unicode_tables.go", source, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func sortedKeys[V any](values map[rune]V) []rune {
+	keys := make([]rune, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(a, b int) bool {
+		return keys[a] < keys[b]
+	})
+	return keys
+}
@@ -0,0 +1,117 @@
+package call
+
+import (
+	"runtime"
+
+	"github.com/nusr/gojs/types"
+)
+
+type generatorImpl struct {
+	*instanceImpl
+	coroutine *coroutine
+	function  types.Object // keeps the scopes the coroutine refers to weakly alive
+}
+
+// newGenerator creates a suspended generator of function. Nothing the
+// coroutine reaches refers back to the generator object, so a generator
+// that is dropped before it finishes is collected and its goroutine
+// stopped.
+func newGenerator(interpreter types.Interpreter, function types.Object, body func(interpreter types.Interpreter) any) types.Object {
+	generator := &generatorImpl{
+		instanceImpl: NewObject(realmOf(interpreter).generatorPrototype).(*instanceImpl),
+		coroutine:    newCoroutine(interpreter, body),
+		function:     function,
+	}
+	runtime.AddCleanup(generator, (*coroutine).Close, generator.coroutine)
+	return generator
+}
+
+func thisGenerator(interpreter types.Interpreter, this any, name string) *generatorImpl {
+	generator, ok := this.(*generatorImpl)
+	if !ok {
+		ThrowTypeError(interpreter, "%s called on incompatible receiver", name)
+	}
+	return generator
+}
+
+func newGeneratorPrototype(realm *realm) types.Object {
+	prototype := NewObject(realm.objectPrototype).(*instanceImpl)
+	resume := func(name string, kind resumeKind) types.Method {
+		return newNative(realm, name, func(interpreter types.Interpreter, this any, params []any) any {
+			generator := thisGenerator(interpreter, this, "Generator.prototype."+name)
+			value, done := generator.coroutine.Resume(interpreter, kind, GetArgument(params, 0))
+			// the body refers to the scopes of the function weakly
+			runtime.KeepAlive(generator)
+			return NewIteratorResult(interpreter, value, done)
+		})
+	}
+	prototype.define("next", resume("next", resumeNext), false)
+	prototype.define("return", resume("return", resumeReturn), false)
+	prototype.define("throw", resume("throw", resumeThrow), false)
+	prototype.define(SymbolIterator, newNative(realm, "[Symbol.iterator]", func(interpreter types.Interpreter, this any, params []any) any {
+		return this
+	}), false)
+	prototype.define(SymbolToStringTag, "Generator", false)
+	return prototype
+}
+
+func currentCoroutine(interpreter types.Interpreter) *coroutine {
+	co, ok := interpreter.GetCoroutine().(*coroutine)
+	if !ok {
+		panic("yield is only valid in generator functions")
+	}
+	return co
+}
+
+// Yield suspends the running generator with value.
+func Yield(interpreter types.Interpreter, value any) any {
+	co := currentCoroutine(interpreter)
+	if co.async {
+		return co.asyncYield(value)
+	}
+	return co.Yield(value)
+}
+
+// YieldDelegate implements yield*, forwarding next, throw and return to the
+// inner iterator until it is done.
+func YieldDelegate(interpreter types.Interpreter, iterable any) any {
+	co := currentCoroutine(interpreter)
+	if co.async {
+		return co.asyncYieldDelegate(interpreter, iterable)
+	}
+	iterator := GetIterator(interpreter, iterable)
+	message := resumption{kind: resumeNext}
+	for {
+		var result any
+		switch message.kind {
+		case resumeNext:
+			result = Invoke(interpreter, iterator.next, iterator.object, []any{message.value})
+		case resumeThrow:
+			method := GetProperty(interpreter, iterator.object, "throw")
+			if method == nil {
+				iterator.Close(interpreter)
+				ThrowTypeError(interpreter, "The iterator does not provide a 'throw' method")
+			}
+			result = Invoke(interpreter, method, iterator.object, []any{message.value})
+		case resumeReturn:
+			method := GetProperty(interpreter, iterator.object, "return")
+			if method == nil {
+				return co.receive(message)
+			}
+			result = Invoke(interpreter, method, iterator.object, []any{message.value})
+		default:
+			return co.receive(message)
+		}
+		object, ok := result.(types.Property)
+		if !ok {
+			ThrowTypeError(interpreter, "Iterator result is not an object")
+		}
+		if ToBoolean(object.Get("done")) {
+			if message.kind == resumeReturn {
+				return co.receive(resumption{kind: resumeReturn, value: object.Get("value")})
+			}
+			return object.Get("value")
+		}
+		message = co.suspendWith(object.Get("value"))
+	}
+}
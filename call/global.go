@@ -1,35 +1,97 @@
 package call
 
 import (
-	"fmt"
+	"math"
+	"os"
+
+	"github.com/nusr/gojs/environment"
 
 	"github.com/nusr/gojs/types"
 )
 
-type globalImpl struct {
-	name string
-}
-
-func NewGlobal(name string) types.Function {
-	return &globalImpl{
-		name: name,
+// NewGlobalEnvironment creates a root environment backed by a new global
+// object, with the built-ins of a realm of its own registered.
+func NewGlobalEnvironment() types.GlobalEnvironment {
+	realm := newRealm()
+	object := NewObject(realm.objectPrototype)
+	env := &globalEnvironment{
+		GlobalEnvironment: environment.NewGlobal(object, func(name string, value any) {
+			if _, ok := getOwnProperty(object, name); ok {
+				object.Set(name, value)
+				return
+			}
+			defineOwnProperty(object, name, dataDescriptor(value, true, true, false))
+		}),
+		realm: realm,
 	}
+	registerGlobal(env)
+	return env
 }
 
-func (g *globalImpl) Call(interpreter types.Interpreter, params []any) any {
-	if g.name == "console.log" {
-		fmt.Println(params...)
+// registerGlobal defines the built-ins as non-enumerable properties of the
+// global object, which is globalThis.
+func registerGlobal(env *globalEnvironment) {
+	realm := env.realm
+	object := env.GlobalObject()
+	define := func(name string, value any) {
+		defineOwnProperty(object, name, dataDescriptor(value, true, false, true))
 	}
-	return nil
-}
-
-func (g *globalImpl) String() string {
-	return ""
-}
+	constant := func(name string, value any) {
+		defineOwnProperty(object, name, dataDescriptor(value, false, false, false))
+	}
+	define("globalThis", object)
+	constant("NaN", math.NaN())
+	constant("Infinity", math.Inf(1))
+	constant("undefined", nil)
+	define("console", newConsole(realm, NewConsoleOutput(os.Stdout, os.Stderr)))
 
-func RegisterGlobal(env types.Environment) {
-	instance := NewInstance()
-	instance.Set("log", NewGlobal("console.log"))
-	instance.Set("warn", NewGlobal("console.warn"))
-	env.Define("console", instance)
+	define("Symbol", newSymbolConstructor(realm))
+	define("Object", newObjectConstructor(realm))
+	define("Function", newFunctionConstructor(realm))
+	define("Array", newArrayConstructor(realm))
+	define("String", newStringConstructor(realm))
+	define("Number", newNumberConstructor(realm))
+	define("RegExp", newRegExpConstructor(realm))
+	for _, name := range errorNames {
+		define(name, newErrorConstructor(realm, name))
+	}
+	define("DOMException", newDOMExceptionConstructor(realm))
+	define("Map", newMapConstructor(realm))
+	define("Set", newSetConstructor(realm))
+	define("WeakMap", newWeakMapConstructor(realm, false))
+	define("WeakSet", newWeakMapConstructor(realm, true))
+	define("WeakRef", newWeakRefConstructor(realm))
+	define("Date", newDateConstructor(realm))
+	define("Math", newMath(realm))
+	define("JSON", newJSON(realm))
+	define("Reflect", newReflect(realm))
+	define("Intl", newIntl(realm))
+	define("Proxy", newProxyConstructor(realm))
+	define("ArrayBuffer", newArrayBufferConstructor(realm, false))
+	define("SharedArrayBuffer", newArrayBufferConstructor(realm, true))
+	define("DataView", newDataViewConstructor(realm))
+	for _, constructor := range newTypedArrayConstructors(realm) {
+		define(constructor.name, constructor)
+	}
+	define("Promise", newPromiseConstructor(realm))
+	define("setTimeout", newTimerFunction(realm, "setTimeout", false))
+	define("setInterval", newTimerFunction(realm, "setInterval", true))
+	define("clearTimeout", newClearTimerFunction(realm, "clearTimeout"))
+	define("clearInterval", newClearTimerFunction(realm, "clearInterval"))
+	define("queueMicrotask", newQueueMicrotask(realm))
+	define("parseInt", realm.parseIntFunction)
+	define("parseFloat", realm.parseFloatFunction)
+	define("isNaN", realm.isNaNFunction)
+	define("isFinite", realm.isFiniteFunction)
+	define("eval", realm.evalFunction)
+	for _, function := range newURIFunctions(realm) {
+		define(functionName(function), function)
+	}
+	define("structuredClone", newStructuredClone(realm))
+	define("TextEncoder", newTextEncoderConstructor(realm))
+	define("TextDecoder", newTextDecoderConstructor(realm))
+	for _, function := range newBase64Functions(realm) {
+		define(functionName(function), function)
+	}
+	define("crypto", newCrypto(realm))
 }
@@ -0,0 +1,986 @@
+package call
+
+import (
+	"encoding/hex"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/nusr/gojs/types"
+)
+
+// inspectOptions are the options of Node's util.inspect. A depth of
+// +Inf has no limit, and compact is false when it is 0.
+type inspectOptions struct {
+	depth          float64
+	showHidden     bool
+	breakLength    float64
+	maxArrayLength float64
+	compact        int
+	compactAll     bool // compact: true
+}
+
+var defaultInspectOptions = inspectOptions{
+	depth:          2,
+	breakLength:    80,
+	maxArrayLength: 100,
+	compact:        3,
+}
+
+// readInspectOptions overrides the defaults with the properties of an
+// options object; a depth of null has no limit.
+func readInspectOptions(interpreter types.Interpreter, value any) inspectOptions {
+	options := defaultInspectOptions
+	object, ok := value.(types.Object)
+	if !ok {
+		return options
+	}
+	if object.Has("depth") || object.Get("depth") != nil {
+		if depth := object.Get("depth"); types.IsNullish(depth) {
+			options.depth = math.Inf(1)
+		} else {
+			options.depth = ToNumber(interpreter, depth)
+		}
+	}
+	if value, ok := object.Get("showHidden").(bool); ok {
+		options.showHidden = value
+	}
+	if value := object.Get("breakLength"); value != nil {
+		options.breakLength = ToNumber(interpreter, value)
+	}
+	if value := object.Get("maxArrayLength"); value != nil {
+		options.maxArrayLength = ToNumber(interpreter, value)
+	}
+	switch value := object.Get("compact").(type) {
+	case bool:
+		options.compact = 0
+		options.compactAll = value
+	case nil:
+	default:
+		options.compact = int(ToNumber(interpreter, value))
+	}
+	return options
+}
+
+// Inspect formats a value for display the way Node's util.inspect does.
+func Inspect(interpreter types.Interpreter, value any) string {
+	return inspect(interpreter, value, defaultInspectOptions)
+}
+
+func inspect(interpreter types.Interpreter, value any, options inspectOptions) string {
+	inspector := &inspector{inspectOptions: options, realm: realmOf(interpreter)}
+	return inspector.value(value, 0)
+}
+
+// inspector holds the state of one inspect call: the objects being
+// formatted, to detect cycles, and the current indentation.
+type inspector struct {
+	inspectOptions
+	realm        *realm
+	indentation  int
+	seen         []types.Object
+	circular     map[types.Object]int
+	currentDepth int
+}
+
+// extrasType tells how formatProperty shows a key.
+type extrasType int
+
+const (
+	extrasObject   extrasType = iota
+	extrasArray               // an element, shown without its index
+	extrasArrayKey            // a named property of an array
+)
+
+func (inspector *inspector) value(value any, recurseTimes int) string {
+	// a proxy shows its target without running any trap
+	if proxy, ok := asProxy(value); ok {
+		if proxy.handler == nil {
+			return "<Revoked Proxy>"
+		}
+		value = proxy.target
+	}
+	object, ok := value.(types.Object)
+	if !ok {
+		return inspector.primitive(value)
+	}
+	for _, item := range inspector.seen {
+		if item == object {
+			if inspector.circular == nil {
+				inspector.circular = make(map[types.Object]int)
+			}
+			index, ok := inspector.circular[object]
+			if !ok {
+				index = len(inspector.circular) + 1
+				inspector.circular[object] = index
+			}
+			return "[Circular *" + strconv.Itoa(index) + "]"
+		}
+	}
+	return inspector.raw(object, recurseTimes)
+}
+
+func (inspector *inspector) primitive(value any) string {
+	switch data := value.(type) {
+	case nil:
+		return "undefined"
+	case string:
+		length := textLength(data)
+		if !inspector.compactAll && length > 16 && float64(length) > inspector.breakLength-float64(inspector.indentation)-4 {
+			var lines []string
+			for rest := data; rest != ""; {
+				end := strings.IndexByte(rest, '\n') + 1
+				if end == 0 {
+					end = len(rest)
+				}
+				lines = append(lines, quoteString(rest[:end]))
+				rest = rest[end:]
+			}
+			return strings.Join(lines, " +\n"+strings.Repeat(" ", inspector.indentation+2))
+		}
+		return quoteString(data)
+	case float64:
+		if data == 0 && math.Signbit(data) {
+			return "-0"
+		}
+		return NumberToString(data)
+	case int64:
+		return strconv.FormatInt(data, 10)
+	case types.NaN:
+		return "NaN"
+	case bool:
+		return strconv.FormatBool(data)
+	case *types.Symbol:
+		return data.String()
+	}
+	return ToString(nil, value)
+}
+
+// textLength is the length of a string in UTF-16 code units.
+func textLength(text string) int {
+	return len(toUTF16(text))
+}
+
+// quoteString quotes a string with single quotes, or with double quotes or
+// backticks when that avoids escaping a quote inside it.
+func quoteString(text string) string {
+	quote := byte('\'')
+	if strings.Contains(text, "'") {
+		if !strings.Contains(text, "\"") {
+			quote = '"'
+		} else if !strings.Contains(text, "`") && !strings.Contains(text, "${") {
+			quote = '`'
+		}
+	}
+	var builder strings.Builder
+	builder.WriteByte(quote)
+	for _, r := range codePoints(text) {
+		switch {
+		case r == rune(quote):
+			builder.WriteString("\\" + string(r))
+		case r == '\\':
+			builder.WriteString("\\\\")
+		case r == '\b':
+			builder.WriteString("\\b")
+		case r == '\t':
+			builder.WriteString("\\t")
+		case r == '\n':
+			builder.WriteString("\\n")
+		case r == '\f':
+			builder.WriteString("\\f")
+		case r == '\r':
+			builder.WriteString("\\r")
+		case r < 0x20 || r >= 0x7f && r < 0xa0:
+			builder.WriteString("\\x" + strings.ToUpper(strconv.FormatInt(int64(r)+0x100, 16)[1:]))
+		case utf16.IsSurrogate(r):
+			builder.WriteString("\\u" + strconv.FormatInt(int64(r), 16))
+		default:
+			builder.WriteRune(r)
+		}
+	}
+	builder.WriteByte(quote)
+	return builder.String()
+}
+
+// inspectConstructor finds the name of the nearest constructor on the
+// prototype chain, reporting false for an object without a prototype.
+// Functions inheriting from Function.prototype are named by their kind.
+func inspectConstructor(realm *realm, object types.Object) (string, bool) {
+	if function, ok := object.(types.Function); ok && object.GetPrototype() == realm.functionPrototype {
+		if _, ok := function.(*classImpl); ok {
+			return "Function", true
+		}
+		return functionType(function), true
+	}
+	for current := object; ; {
+		if current.Has("constructor") {
+			if constructor, ok := current.Get("constructor").(types.Function); ok {
+				if name := functionName(constructor); name != "" && inheritsFrom(object, GetProperty(nil, constructor, "prototype")) {
+					return name, true
+				}
+			}
+		}
+		next, ok := current.GetPrototype().(types.Object)
+		if !ok {
+			break
+		}
+		current = next
+	}
+	if object.GetPrototype() == nil {
+		return "", false
+	}
+	return "Object", true
+}
+
+// inheritsFrom reports whether prototype is on the prototype chain of
+// object, as instanceof checks.
+func inheritsFrom(object types.Object, prototype any) bool {
+	for current := object.GetPrototype(); current != nil; {
+		if current == prototype {
+			return true
+		}
+		next, ok := current.(types.Object)
+		if !ok {
+			return false
+		}
+		current = next.GetPrototype()
+	}
+	return false
+}
+
+func functionName(function any) string {
+	property, ok := function.(types.Property)
+	if !ok {
+		return ""
+	}
+	name, _ := property.Get("name").(string)
+	return name
+}
+
+func functionType(function types.Function) string {
+	kind := "Function"
+	if data, ok := function.(*functionImpl); ok {
+		if data.generator {
+			kind = "Generator" + kind
+		}
+		if data.async {
+			kind = "Async" + kind
+		}
+	}
+	return kind
+}
+
+// inspectPrefix names the kind of an object before its braces, like
+// `Foo(2) [Map] `.
+func inspectPrefix(constructor string, named bool, tag string, fallback string, size string) string {
+	if !named {
+		if tag != "" && tag != fallback {
+			return "[" + fallback + size + ": null prototype] [" + tag + "] "
+		}
+		return "[" + fallback + size + ": null prototype] "
+	}
+	if tag != "" && tag != constructor {
+		return constructor + size + " [" + tag + "] "
+	}
+	return constructor + size + " "
+}
+
+// ownKeys lists the keys inspect shows: the enumerable ones, or all of
+// them with showHidden.
+func (inspector *inspector) ownKeys(object types.Object, skip func(key any) bool) []any {
+	var keys []any
+	for _, key := range object.OwnKeys() {
+		if skip != nil && skip(key) {
+			continue
+		}
+		if inspector.showHidden || object.IsEnumerable(key) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+func isIndexKey(key any) bool {
+	_, ok := ArrayIndex(key)
+	return ok
+}
+
+type inspectFormatter func(recurseTimes int) []string
+
+func (inspector *inspector) raw(object types.Object, recurseTimes int) string {
+	constructor, named := inspectConstructor(inspector.realm, object)
+	tag, _ := object.Get(SymbolToStringTag).(string)
+	if tag != "" {
+		// an own tag is shown as a property instead
+		if inspector.showHidden && object.Has(SymbolToStringTag) || !inspector.showHidden && object.IsEnumerable(SymbolToStringTag) {
+			tag = ""
+		}
+	}
+	var keys []any
+	base := ""
+	braces := [2]string{"{", "}"}
+	extras := extrasObject
+	var formatter inspectFormatter
+	switch data := object.(type) {
+	case *arrayImpl:
+		prefix := ""
+		size := "(" + strconv.FormatInt(data.length, 10) + ")"
+		if constructor != "Array" || tag != "" {
+			prefix = inspectPrefix(constructor, named, tag, "Array", size)
+		}
+		keys = inspector.ownKeys(object, isIndexKey)
+		braces[0] = prefix + "["
+		braces[1] = "]"
+		if data.length == 0 && len(keys) == 0 {
+			return braces[0] + "]"
+		}
+		extras = extrasArrayKey
+		formatter = func(recurseTimes int) []string {
+			return inspector.array(data, recurseTimes)
+		}
+	case *typedArrayImpl:
+		length := data.length()
+		prefix := inspectPrefix(constructor, named, tag, "TypedArray", "("+strconv.FormatInt(length, 10)+")")
+		keys = inspector.ownKeys(object, isIndexKey)
+		braces[0] = prefix + "["
+		braces[1] = "]"
+		if length == 0 && len(keys) == 0 {
+			return braces[0] + "]"
+		}
+		extras = extrasArrayKey
+		formatter = func(recurseTimes int) []string {
+			return inspector.typedArray(data, recurseTimes)
+		}
+	case *mapImpl:
+		kind := "Map"
+		if data.isSet {
+			kind = "Set"
+		}
+		prefix := inspectPrefix(constructor, named, tag, kind, "("+strconv.Itoa(data.data.size)+")")
+		keys = inspector.ownKeys(object, nil)
+		if data.data.size == 0 && len(keys) == 0 {
+			return prefix + "{}"
+		}
+		braces[0] = prefix + "{"
+		formatter = func(recurseTimes int) []string {
+			return inspector.collection(data, recurseTimes)
+		}
+	case *mapIteratorImpl:
+		kind := "Map"
+		if data.isSet {
+			kind = "Set"
+		}
+		keys = inspector.ownKeys(object, nil)
+		if tag != kind+" Iterator" {
+			if tag != "" {
+				tag += "] ["
+			}
+			tag += kind + " Iterator"
+		}
+		braces[0] = "[" + tag + "] {"
+		formatter = func(recurseTimes int) []string {
+			return inspector.iterator(data, &braces, recurseTimes)
+		}
+	default:
+		keys = inspector.ownKeys(object, nil)
+		_, isFunction := object.(types.Function)
+		if constructor == "Object" && named && !isFunction {
+			if tag != "" {
+				braces[0] = inspectPrefix(constructor, named, tag, "Object", "") + "{"
+			}
+			if len(keys) == 0 {
+				return braces[0] + "}"
+			}
+			break
+		}
+		switch data := object.(type) {
+		case types.Function:
+			base = functionBase(data, constructor, named, tag)
+			if len(keys) == 0 {
+				return base
+			}
+		case *regexpImpl:
+			base = "/" + ToString(nil, data.Get("source")) + "/" + ToString(nil, data.Get("flags"))
+			if prefix := inspectPrefix(constructor, named, tag, "RegExp", ""); prefix != "RegExp " {
+				base = prefix + base
+			}
+			if len(keys) == 0 || float64(recurseTimes) > inspector.depth {
+				return base
+			}
+		case *dateImpl:
+			base = "Invalid Date"
+			if !math.IsNaN(data.time) {
+				base = toISOString(data.time)
+			}
+			if prefix := inspectPrefix(constructor, named, tag, "Date", ""); prefix != "Date " {
+				base = prefix + base
+			}
+			if len(keys) == 0 {
+				return base
+			}
+		case *errorImpl:
+			base, keys = inspector.error(data, keys)
+			if len(keys) == 0 {
+				return base
+			}
+		case *domExceptionImpl:
+			base, keys = inspector.error(data.errorImpl, keys)
+			// the stack starts with the name, which V8 puts after the class
+			if text := strings.TrimPrefix(base, data.name); text != base {
+				base = "DOMException [" + data.name + "]" + text
+			}
+			if len(keys) == 0 {
+				return base
+			}
+		case *promiseImpl:
+			braces[0] = inspectPrefix(constructor, named, tag, "Promise", "") + "{"
+			formatter = func(recurseTimes int) []string {
+				return inspector.promise(data, recurseTimes)
+			}
+		case *weakMapImpl:
+			kind := "WeakMap"
+			if data.isSet {
+				kind = "WeakSet"
+			}
+			braces[0] = inspectPrefix(constructor, named, tag, kind, "") + "{"
+			formatter = func(recurseTimes int) []string {
+				return []string{"<items unknown>"}
+			}
+		case *arrayBufferImpl:
+			kind := "ArrayBuffer"
+			if data.shared {
+				kind = "SharedArrayBuffer"
+			}
+			braces[0] = inspectPrefix(constructor, named, tag, kind, "") + "{"
+			formatter = func(recurseTimes int) []string {
+				return []string{inspector.arrayBuffer(data), inspector.entry("byteLength", int64(len(data.data)), recurseTimes)}
+			}
+		case *dataViewImpl:
+			braces[0] = inspectPrefix(constructor, named, tag, "DataView", "") + "{"
+			formatter = func(recurseTimes int) []string {
+				return []string{
+					inspector.entry("byteLength", data.Get("byteLength"), recurseTimes),
+					inspector.entry("byteOffset", data.Get("byteOffset"), recurseTimes),
+					inspector.entry("buffer", data.buffer, recurseTimes),
+				}
+			}
+		case *numberImpl:
+			base = boxedBase("Number", inspector.primitive(data.value), constructor, named, tag)
+			if len(keys) == 0 {
+				return base
+			}
+		case *stringImpl:
+			keys = inspector.ownKeys(object, func(key any) bool {
+				return isIndexKey(key) || key == "length"
+			})
+			base = boxedBase("String", quoteString(data.value), constructor, named, tag)
+			if len(keys) == 0 {
+				return base
+			}
+		default:
+			braces[0] = inspectPrefix(constructor, named, tag, "Object", "") + "{"
+			if len(keys) == 0 {
+				return braces[0] + "}"
+			}
+		}
+	}
+	if float64(recurseTimes) > inspector.depth {
+		name := inspectPrefix(constructor, named, tag, "Object", "")
+		name = name[:len(name)-1]
+		if named {
+			name = "[" + name + "]"
+		}
+		return name
+	}
+	recurseTimes++
+	inspector.seen = append(inspector.seen, object)
+	inspector.currentDepth = recurseTimes
+	var output []string
+	if formatter != nil {
+		output = formatter(recurseTimes)
+	}
+	for _, key := range keys {
+		output = append(output, inspector.property(object, recurseTimes, key, extras))
+	}
+	if index, ok := inspector.circular[object]; ok {
+		reference := "<ref *" + strconv.Itoa(index) + ">"
+		if inspector.compactAll {
+			braces[0] = reference + " " + braces[0]
+		} else if base == "" {
+			base = reference
+		} else {
+			base = reference + " " + base
+		}
+	}
+	inspector.seen = inspector.seen[:len(inspector.seen)-1]
+	return inspector.reduceToSingleString(output, base, braces, extras, recurseTimes, object)
+}
+
+func functionBase(function types.Function, constructor string, named bool, tag string) string {
+	if _, ok := function.(*classImpl); ok {
+		name := functionName(function)
+		if name == "" {
+			name = "(anonymous)"
+		}
+		base := "class " + name
+		if constructor != "Function" && named {
+			base += " [" + constructor + "]"
+		}
+		if tag != "" && constructor != tag {
+			base += " [" + tag + "]"
+		}
+		return "[" + base + "]"
+	}
+	kind := functionType(function)
+	base := "[" + kind
+	if !named {
+		base += " (null prototype)"
+	}
+	if name := functionName(function); name == "" {
+		base += " (anonymous)"
+	} else {
+		base += ": " + name
+	}
+	base += "]"
+	if constructor != kind && named {
+		base += " " + constructor
+	}
+	if tag != "" && constructor != tag {
+		base += " [" + tag + "]"
+	}
+	return base
+}
+
+func boxedBase(kind string, value string, constructor string, named bool, tag string) string {
+	base := "[" + kind
+	if kind != constructor {
+		if !named {
+			base += " (null prototype)"
+		} else {
+			base += " (" + constructor + ")"
+		}
+	}
+	base += ": " + value + "]"
+	if tag != "" && tag != constructor {
+		base += " [" + tag + "]"
+	}
+	return base
+}
+
+// error shows the stack of an error, adding its cause and errors to the
+// keys and dropping the keys the stack already shows.
+func (inspector *inspector) error(object *errorImpl, keys []any) (string, []any) {
+	stack, ok := object.Get("stack").(string)
+	if !ok || stack == "" {
+		stack = errorToString(nil, object)
+	}
+	if !inspector.showHidden {
+		kept := keys[:0]
+		for _, key := range keys {
+			if name, ok := key.(string); ok && (name == "name" || name == "message" || name == "stack") {
+				if value, ok := object.Get(name).(string); ok && strings.Contains(stack, value) {
+					continue
+				}
+			}
+			kept = append(kept, key)
+		}
+		keys = kept
+	}
+	hasKey := func(name string) bool {
+		for _, key := range keys {
+			if key == name {
+				return true
+			}
+		}
+		return false
+	}
+	if HasProperty(object, "cause") && !hasKey("cause") {
+		keys = append(keys, "cause")
+	}
+	if IsArray(object.Get("errors")) && !hasKey("errors") {
+		keys = append(keys, "errors")
+	}
+	position := -1
+	if message, ok := object.Get("message").(string); ok && message != "" {
+		if position = strings.Index(stack, message); position != -1 {
+			position += len(message)
+		}
+	}
+	if !strings.Contains(stack[max(position, 0):], "\n    at") {
+		stack = "[" + stack + "]"
+	}
+	if inspector.indentation != 0 {
+		stack = strings.ReplaceAll(stack, "\n", "\n"+strings.Repeat(" ", inspector.indentation))
+	}
+	return stack, keys
+}
+
+func remainingText(remaining int64) string {
+	text := "... " + strconv.FormatInt(remaining, 10) + " more item"
+	if remaining > 1 {
+		text += "s"
+	}
+	return text
+}
+
+func emptyItems(count int64) string {
+	text := "<" + strconv.FormatInt(count, 10) + " empty item"
+	if count > 1 {
+		text += "s"
+	}
+	return text + ">"
+}
+
+func (inspector *inspector) maxLength(length int64) int64 {
+	return int64(math.Min(math.Max(0, inspector.maxArrayLength), float64(length)))
+}
+
+// array formats the elements of an array, folding runs of holes.
+func (inspector *inspector) array(array *arrayImpl, recurseTimes int) []string {
+	maxLength := inspector.maxLength(array.length)
+	var output []string
+	element := func(value any) {
+		inspector.indentation += 2
+		output = append(output, inspector.value(value, recurseTimes))
+		inspector.indentation -= 2
+	}
+	index := int64(0)
+	for ; index < maxLength; index++ {
+		value, ok := array.element(index)
+		if !ok {
+			break
+		}
+		element(value)
+	}
+	if index == maxLength {
+		if remaining := array.length - maxLength; remaining > 0 {
+			output = append(output, remainingText(remaining))
+		}
+		return output
+	}
+	// holes are skipped by walking the present indices
+	for _, key := range array.OwnKeys() {
+		i, ok := ArrayIndex(key)
+		if !ok || int64(len(output)) >= maxLength {
+			break
+		}
+		if i < index {
+			continue
+		}
+		if i != index {
+			output = append(output, emptyItems(i-index))
+			index = i
+			if int64(len(output)) == maxLength {
+				break
+			}
+		}
+		value, _ := array.element(i)
+		element(value)
+		index++
+	}
+	remaining := array.length - index
+	if int64(len(output)) != maxLength {
+		if remaining > 0 {
+			output = append(output, emptyItems(remaining))
+		}
+	} else if remaining > 0 {
+		output = append(output, remainingText(remaining))
+	}
+	return output
+}
+
+// typedArray formats the elements of a typed array.
+func (inspector *inspector) typedArray(array *typedArrayImpl, recurseTimes int) []string {
+	length := array.length()
+	maxLength := inspector.maxLength(length)
+	var output []string
+	for i := int64(0); i < maxLength; i++ {
+		output = append(output, inspector.primitive(array.get(i)))
+	}
+	if remaining := length - maxLength; remaining > 0 {
+		output = append(output, remainingText(remaining))
+	}
+	return output
+}
+
+// arrayBuffer shows the bytes of a buffer in hex.
+func (inspector *inspector) arrayBuffer(buffer *arrayBufferImpl) string {
+	if buffer.detached {
+		return "(detached)"
+	}
+	length := int64(len(buffer.data))
+	maxLength := inspector.maxLength(length)
+	text := make([]string, maxLength)
+	for i := range text {
+		text[i] = hex.EncodeToString(buffer.data[i : i+1])
+	}
+	contents := strings.Join(text, " ")
+	if remaining := length - maxLength; remaining > 0 {
+		contents += " ... " + strconv.FormatInt(remaining, 10) + " more byte"
+		if remaining > 1 {
+			contents += "s"
+		}
+	}
+	return "[Uint8Contents]: <" + contents + ">"
+}
+
+// entry formats a value shown like a property that the object does not
+// own, such as the byteLength of an ArrayBuffer.
+func (inspector *inspector) entry(name string, value any, recurseTimes int) string {
+	inspector.indentation += 2
+	text := inspector.value(value, recurseTimes)
+	inspector.indentation -= 2
+	return name + ": " + text
+}
+
+func (inspector *inspector) collection(object *mapImpl, recurseTimes int) []string {
+	maxLength := inspector.maxLength(int64(object.data.size))
+	var output []string
+	inspector.indentation += 2
+	cursor := object.data.cursor()
+	for i := int64(0); i < maxLength; i++ {
+		entry, ok := cursor.next()
+		if !ok {
+			break
+		}
+		if object.isSet {
+			output = append(output, inspector.value(entry.key, recurseTimes))
+		} else {
+			output = append(output, inspector.value(entry.key, recurseTimes)+" => "+inspector.value(entry.value, recurseTimes))
+		}
+	}
+	if remaining := int64(object.data.size) - maxLength; remaining > 0 {
+		output = append(output, remainingText(remaining))
+	}
+	inspector.indentation -= 2
+	return output
+}
+
+// iteratorEntries previews the entries left to a Map or Set iterator
+// without advancing it.
+func iteratorEntries(iterator *mapIteratorImpl) [][2]any {
+	if iterator.cursor == nil {
+		return nil
+	}
+	cursor := *iterator.cursor
+	var entries [][2]any
+	for {
+		entry, ok := cursor.next()
+		if !ok {
+			return entries
+		}
+		value := entry.value
+		if iterator.isSet {
+			value = entry.key
+		}
+		entries = append(entries, [2]any{entry.key, value})
+	}
+}
+
+func (inspector *inspector) iterator(iterator *mapIteratorImpl, braces *[2]string, recurseTimes int) []string {
+	entries := iteratorEntries(iterator)
+	maxLength := inspector.maxLength(int64(len(entries)))
+	var output []string
+	if iterator.kind == arrayIteratorEntries {
+		braces[0] = strings.Replace(braces[0], " Iterator] {", " Entries] {", 1)
+	}
+	inspector.indentation += 2
+	for _, entry := range entries[:maxLength] {
+		switch iterator.kind {
+		case arrayIteratorKeys:
+			output = append(output, inspector.value(entry[0], recurseTimes))
+		case arrayIteratorValues:
+			output = append(output, inspector.value(entry[1], recurseTimes))
+		default:
+			pair := []string{inspector.value(entry[0], recurseTimes), inspector.value(entry[1], recurseTimes)}
+			output = append(output, inspector.reduceToSingleString(pair, "", [2]string{"[", "]"}, extrasArray, recurseTimes, nil))
+		}
+	}
+	inspector.indentation -= 2
+	if remaining := int64(len(entries)) - maxLength; remaining > 0 {
+		output = append(output, remainingText(remaining))
+	}
+	return output
+}
+
+func (inspector *inspector) promise(promise *promiseImpl, recurseTimes int) []string {
+	if promise.state == promisePending {
+		return []string{"<pending>"}
+	}
+	inspector.indentation += 2
+	text := inspector.value(promise.result, recurseTimes)
+	inspector.indentation -= 2
+	if promise.state == promiseRejected {
+		text = "<rejected> " + text
+	}
+	return []string{text}
+}
+
+var identifierKey = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z_0-9]*$`)
+
+func (inspector *inspector) property(object types.Object, recurseTimes int, key any, extras extrasType) string {
+	inspector.indentation += 2
+	text := inspector.value(object.Get(key), recurseTimes)
+	inspector.indentation -= 2
+	if extras == extrasArray {
+		return text
+	}
+	var name string
+	switch data := key.(type) {
+	case *types.Symbol:
+		name = "[" + data.String() + "]"
+	case string:
+		if data == "__proto__" {
+			name = "['__proto__']"
+		} else if !object.IsEnumerable(key) {
+			name = "[" + data + "]"
+		} else if identifierKey.MatchString(data) {
+			name = data
+		} else {
+			name = quoteString(data)
+		}
+	}
+	return name + ": " + text
+}
+
+func (inspector *inspector) isBelowBreakLength(output []string, start int, base string) bool {
+	total := len(output) + start
+	if float64(total+len(output)) > inspector.breakLength {
+		return false
+	}
+	for _, item := range output {
+		total += textLength(item)
+		if float64(total) > inspector.breakLength {
+			return false
+		}
+	}
+	return base == "" || !strings.Contains(base, "\n")
+}
+
+// reduceToSingleString puts the entries on one line when they fit, and
+// otherwise one per line or grouped in columns.
+func (inspector *inspector) reduceToSingleString(output []string, base string, braces [2]string, extras extrasType, recurseTimes int, value types.Object) string {
+	prefix := ""
+	if base != "" {
+		prefix = base + " "
+	}
+	indentation := "\n" + strings.Repeat(" ", inspector.indentation)
+	if inspector.compactAll {
+		start := len(output) + inspector.indentation + textLength(braces[0]) + textLength(base) + 10
+		if inspector.isBelowBreakLength(output, start, base) {
+			joined := strings.Join(output, ", ")
+			if !strings.Contains(joined, "\n") {
+				return prefix + braces[0] + " " + joined + " " + braces[1]
+			}
+		}
+		separator := " " + base + indentation + "  "
+		if base == "" {
+			separator = indentation + "  "
+			if textLength(braces[0]) == 1 {
+				separator = " "
+			}
+		}
+		return braces[0] + separator + strings.Join(output, ","+indentation+"  ") + " " + braces[1]
+	}
+	if inspector.compact >= 1 {
+		entries := len(output)
+		if extras == extrasArrayKey && entries > 6 {
+			output = inspector.groupArrayElements(output, value)
+		}
+		if inspector.currentDepth-recurseTimes < inspector.compact && entries == len(output) {
+			start := len(output) + inspector.indentation + textLength(braces[0]) + textLength(base) + 10
+			if inspector.isBelowBreakLength(output, start, base) {
+				joined := strings.Join(output, ", ")
+				if !strings.Contains(joined, "\n") {
+					return prefix + braces[0] + " " + joined + " " + braces[1]
+				}
+			}
+		}
+	}
+	return prefix + braces[0] + indentation + "  " + strings.Join(output, ","+indentation+"  ") + indentation + braces[1]
+}
+
+// groupArrayElements lays out many short array entries in aligned
+// columns.
+func (inspector *inspector) groupArrayElements(output []string, value types.Object) []string {
+	totalLength := 0
+	maxLength := 0
+	outputLength := len(output)
+	if inspector.maxArrayLength < float64(len(output)) {
+		// the "... more items" entry
+		outputLength--
+	}
+	const separatorSpace = 2
+	dataLength := make([]int, outputLength)
+	for i := 0; i < outputLength; i++ {
+		length := textLength(output[i])
+		dataLength[i] = length
+		totalLength += length + separatorSpace
+		maxLength = max(maxLength, length)
+	}
+	actualMax := maxLength + separatorSpace
+	if float64(actualMax*3+inspector.indentation) >= inspector.breakLength ||
+		float64(totalLength)/float64(actualMax) <= 5 && maxLength > 6 {
+		return output
+	}
+	averageBias := math.Sqrt(float64(actualMax) - float64(totalLength)/float64(len(output)))
+	biasedMax := math.Max(float64(actualMax)-3-averageBias, 1)
+	columns := min(
+		int(math.Round(math.Sqrt(2.5*biasedMax*float64(outputLength))/biasedMax)),
+		int(math.Floor((inspector.breakLength-float64(inspector.indentation))/float64(actualMax))),
+		inspector.compact*4,
+		15,
+	)
+	if columns <= 1 {
+		return output
+	}
+	var maxLineLength []int
+	for i := 0; i < columns; i++ {
+		lineLength := 0
+		for j := i; j < outputLength; j += columns {
+			lineLength = max(lineLength, dataLength[j])
+		}
+		maxLineLength = append(maxLineLength, lineLength+separatorSpace)
+	}
+	padStart := true
+	if value != nil {
+		for i := range output {
+			switch value.Get(strconv.Itoa(i)).(type) {
+			case int64, float64, types.NaN:
+			default:
+				padStart = false
+			}
+			if !padStart {
+				break
+			}
+		}
+	}
+	pad := func(text string, width int) string {
+		padding := strings.Repeat(" ", max(width-textLength(text), 0))
+		if padStart {
+			return padding + text
+		}
+		return text + padding
+	}
+	var grouped []string
+	for i := 0; i < outputLength; i += columns {
+		end := min(i+columns, outputLength)
+		var builder strings.Builder
+		j := i
+		for ; j < end-1; j++ {
+			builder.WriteString(pad(output[j]+", ", maxLineLength[j-i]))
+		}
+		if padStart {
+			builder.WriteString(pad(output[j], maxLineLength[j-i]-separatorSpace))
+		} else {
+			builder.WriteString(output[j])
+		}
+		grouped = append(grouped, builder.String())
+	}
+	if outputLength < len(output) {
+		grouped = append(grouped, output[outputLength])
+	}
+	return grouped
+}
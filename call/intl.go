@@ -0,0 +1,319 @@
+package call
+
+import (
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/nusr/gojs/types"
+)
+
+// getOption reads a string option of an Intl constructor, which must be
+// one of values.
+func getOption(interpreter types.Interpreter, options any, constructor string, name string, values []string, fallback string) string {
+	value := GetProperty(interpreter, options, name)
+	if value == nil {
+		return fallback
+	}
+	text := ToString(interpreter, value)
+	if !slices.Contains(values, text) {
+		ThrowRangeError(interpreter, "Value %s out of range for Intl.%s options property %s", text, constructor, name)
+	}
+	return text
+}
+
+// getBooleanOption reads a boolean option, reporting whether it was set.
+func getBooleanOption(interpreter types.Interpreter, options any, name string) (bool, bool) {
+	value := GetProperty(interpreter, options, name)
+	if value == nil {
+		return false, false
+	}
+	return ToBoolean(value), true
+}
+
+// getNumberOption reads an integer option between minimum and maximum,
+// reporting whether it was set.
+func getNumberOption(interpreter types.Interpreter, options any, name string, minimum int, maximum int) (int, bool) {
+	value := GetProperty(interpreter, options, name)
+	if value == nil {
+		return 0, false
+	}
+	x := ToNumber(interpreter, value)
+	if math.IsNaN(x) || x < float64(minimum) || x > float64(maximum) {
+		ThrowRangeError(interpreter, "%s value is out of range.", name)
+	}
+	return int(math.Floor(x)), true
+}
+
+// newIntlConstructor creates an Intl constructor with supportedLocalesOf.
+// Only PluralRules requires new.
+func newIntlConstructor(realm *realm, name string, prototype *instanceImpl, create func(interpreter types.Interpreter, locales any, options any) types.Object) types.Object {
+	construct := func(interpreter types.Interpreter, params []any) any {
+		return create(interpreter, GetArgument(params, 0), GetArgument(params, 1))
+	}
+	constructor := newConstructor(realm, name, func(interpreter types.Interpreter, this any, params []any) any {
+		if name == "PluralRules" {
+			ThrowTypeError(interpreter, "Constructor Intl.PluralRules requires 'new'")
+		}
+		return construct(interpreter, params)
+	}, construct).(*nativeImpl)
+	constructor.define("supportedLocalesOf", newNative(realm, "supportedLocalesOf", func(interpreter types.Interpreter, this any, params []any) any {
+		return NewArrayFrom(interpreter, supportedLocales(interpreter, GetArgument(params, 0)))
+	}), false)
+	constructor.define("prototype", prototype, false)
+	prototype.define("constructor", constructor, false)
+	prototype.define(SymbolToStringTag, "Intl."+name, false)
+	return constructor
+}
+
+// newResolvedOptions creates the object resolvedOptions returns from the
+// options in order, leaving out the nil ones.
+func newResolvedOptions(interpreter types.Interpreter, options ...any) types.Object {
+	result := NewInstance(interpreter)
+	for i := 0; i < len(options); i += 2 {
+		if options[i+1] != nil {
+			result.Set(options[i], options[i+1])
+		}
+	}
+	return result
+}
+
+func newIntl(realm *realm) types.Object {
+	object := NewObject(realm.objectPrototype).(*instanceImpl)
+	object.define("getCanonicalLocales", newNative(realm, "getCanonicalLocales", func(interpreter types.Interpreter, this any, params []any) any {
+		var result []any
+		for _, tag := range canonicalizeLocales(interpreter, GetArgument(params, 0)) {
+			result = append(result, tag)
+		}
+		return NewArrayFrom(interpreter, result)
+	}), false)
+	object.define("Collator", newIntlConstructor(realm, "Collator", realm.collatorPrototype, func(interpreter types.Interpreter, locales any, options any) types.Object {
+		return newCollator(interpreter, locales, options)
+	}), false)
+	object.define("DateTimeFormat", newIntlConstructor(realm, "DateTimeFormat", realm.dateTimeFormatPrototype, func(interpreter types.Interpreter, locales any, options any) types.Object {
+		return newDateTimeFormat(interpreter, locales, options, "any", "date")
+	}), false)
+	object.define("NumberFormat", newIntlConstructor(realm, "NumberFormat", realm.numberFormatPrototype, func(interpreter types.Interpreter, locales any, options any) types.Object {
+		return newNumberFormat(interpreter, locales, options)
+	}), false)
+	object.define("PluralRules", newIntlConstructor(realm, "PluralRules", realm.pluralRulesPrototype, func(interpreter types.Interpreter, locales any, options any) types.Object {
+		return newPluralRules(interpreter, locales, options)
+	}), false)
+	object.define(SymbolToStringTag, "Intl", false)
+	return object
+}
+
+// collatorImpl is an Intl.Collator.
+type collatorImpl struct {
+	*instanceImpl
+	locale            string
+	usage             string
+	sensitivity       string
+	ignorePunctuation bool
+	numeric           bool
+	caseFirst         string
+	realm             *realm
+	bound             types.Function
+}
+
+func newCollator(interpreter types.Interpreter, locales any, options any) *collatorImpl {
+	collator := &collatorImpl{
+		instanceImpl: NewObject(realmOf(interpreter).collatorPrototype).(*instanceImpl),
+	}
+	collator.realm = realmOf(interpreter)
+	collator.locale, _ = resolveLocale(interpreter, locales)
+	collator.usage = getOption(interpreter, options, "Collator", "usage", []string{"sort", "search"}, "sort")
+	collator.numeric, _ = getBooleanOption(interpreter, options, "numeric")
+	collator.caseFirst = getOption(interpreter, options, "Collator", "caseFirst", []string{"upper", "lower", "false"}, "false")
+	collator.sensitivity = getOption(interpreter, options, "Collator", "sensitivity", []string{"base", "accent", "case", "variant"}, "variant")
+	collator.ignorePunctuation, _ = getBooleanOption(interpreter, options, "ignorePunctuation")
+	return collator
+}
+
+func (collator *collatorImpl) Get(key any) any {
+	if key == "compare" {
+		if collator.bound == nil {
+			collator.bound = newNative(collator.realm, "", func(interpreter types.Interpreter, this any, params []any) any {
+				a := ToString(interpreter, GetArgument(params, 0))
+				b := ToString(interpreter, GetArgument(params, 1))
+				return int64(collator.compare(a, b))
+			})
+		}
+		return collator.bound
+	}
+	return collator.instanceImpl.Get(key)
+}
+
+// compareDigits orders letters the way compareRunes does, except that runs
+// of digits compare by their numeric value.
+func compareDigits(a []rune, b []rune) int {
+	isDigit := func(r rune) bool {
+		return r >= '0' && r <= '9'
+	}
+	number := func(text []rune, i int) ([]rune, int) {
+		start := i
+		for i < len(text) && isDigit(text[i]) {
+			i++
+		}
+		run := text[start:i]
+		for len(run) > 1 && run[0] == '0' {
+			run = run[1:]
+		}
+		return run, i
+	}
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		if isDigit(a[i]) && isDigit(b[j]) {
+			var x, y []rune
+			x, i = number(a, i)
+			y, j = number(b, j)
+			if len(x) != len(y) {
+				if len(x) < len(y) {
+					return -1
+				}
+				return 1
+			}
+			if result := compareRunes(x, y); result != 0 {
+				return result
+			}
+			continue
+		}
+		if a[i] != b[j] {
+			if a[i] < b[j] {
+				return -1
+			}
+			return 1
+		}
+		i++
+		j++
+	}
+	return compareRunes(a[i:], b[j:])
+}
+
+// compare orders two strings by letters, then accents, then case, up to
+// the sensitivity of the collator.
+func (collator *collatorImpl) compare(a string, b string) int {
+	if collator.ignorePunctuation {
+		strip := func(r rune) rune {
+			if unicode.IsPunct(r) || unicode.IsSpace(r) {
+				return -1
+			}
+			return r
+		}
+		a = strings.Map(strip, a)
+		b = strings.Map(strip, b)
+	}
+	p1, s1, t1 := collationKey(a)
+	p2, s2, t2 := collationKey(b)
+	primary := compareRunes
+	if collator.numeric {
+		primary = compareDigits
+	}
+	if result := primary(p1, p2); result != 0 {
+		return result
+	}
+	if collator.sensitivity == "accent" || collator.sensitivity == "variant" {
+		if result := compareRunes(s1, s2); result != 0 {
+			return result
+		}
+	}
+	if collator.sensitivity == "case" || collator.sensitivity == "variant" {
+		result := compareRunes(t1, t2)
+		if collator.caseFirst == "upper" {
+			result = -result
+		}
+		return result
+	}
+	return 0
+}
+
+func newCollatorPrototype(realm *realm) *instanceImpl {
+	prototype := NewObject(realm.objectPrototype).(*instanceImpl)
+	prototype.define("resolvedOptions", newNative(realm, "resolvedOptions", func(interpreter types.Interpreter, this any, params []any) any {
+		collator, ok := this.(*collatorImpl)
+		if !ok {
+			ThrowTypeError(interpreter, "Method Intl.Collator.prototype.resolvedOptions called on incompatible receiver %s", describe(this))
+		}
+		return newResolvedOptions(interpreter,
+			"locale", collator.locale,
+			"usage", collator.usage,
+			"sensitivity", collator.sensitivity,
+			"ignorePunctuation", collator.ignorePunctuation,
+			"collation", "default",
+			"numeric", collator.numeric,
+			"caseFirst", collator.caseFirst,
+		)
+	}), false)
+	return prototype
+}
+
+// pluralRulesImpl is an Intl.PluralRules.
+type pluralRulesImpl struct {
+	*instanceImpl
+	locale  string
+	data    *localeData
+	ordinal bool
+	digits  numberDigits
+}
+
+func newPluralRules(interpreter types.Interpreter, locales any, options any) *pluralRulesImpl {
+	rules := &pluralRulesImpl{
+		instanceImpl: NewObject(realmOf(interpreter).pluralRulesPrototype).(*instanceImpl),
+	}
+	rules.locale, rules.data = resolveLocale(interpreter, locales)
+	rules.ordinal = getOption(interpreter, options, "PluralRules", "type", []string{"cardinal", "ordinal"}, "cardinal") == "ordinal"
+	rules.digits = getDigitOptions(interpreter, options, 0, 3)
+	return rules
+}
+
+// pluralCategory returns the plural category of a number from its
+// formatted integer and fraction digits.
+func pluralCategory(data *localeData, ordinal bool, integer string, fraction string) string {
+	operands := pluralOperands{v: len(fraction)}
+	operands.i, _ = strconv.ParseFloat(integer, 64)
+	operands.n, _ = strconv.ParseFloat(integer+"."+fraction+"0", 64)
+	if ordinal {
+		return data.ordinal(operands)
+	}
+	return data.cardinal(operands)
+}
+
+func newPluralRulesPrototype(realm *realm) *instanceImpl {
+	prototype := NewObject(realm.objectPrototype).(*instanceImpl)
+	thisPluralRules := func(interpreter types.Interpreter, this any, name string) *pluralRulesImpl {
+		rules, ok := this.(*pluralRulesImpl)
+		if !ok {
+			ThrowTypeError(interpreter, "Method Intl.PluralRules.prototype.%s called on incompatible receiver %s", name, describe(this))
+		}
+		return rules
+	}
+	prototype.define("select", newNative(realm, "select", func(interpreter types.Interpreter, this any, params []any) any {
+		rules := thisPluralRules(interpreter, this, "select")
+		x := ToNumber(interpreter, GetArgument(params, 0))
+		if !isFinite(x) {
+			return "other"
+		}
+		integer, fraction, _ := rules.digits.format(math.Abs(x), 0)
+		return pluralCategory(rules.data, rules.ordinal, integer, fraction)
+	}), false)
+	prototype.define("resolvedOptions", newNative(realm, "resolvedOptions", func(interpreter types.Interpreter, this any, params []any) any {
+		rules := thisPluralRules(interpreter, this, "resolvedOptions")
+		kind := "cardinal"
+		categories := rules.data.cardinalCategories
+		if rules.ordinal {
+			kind = "ordinal"
+			categories = rules.data.ordinalCategories
+		}
+		list := make([]any, len(categories))
+		for i, category := range categories {
+			list[i] = category
+		}
+		options := []any{"locale", rules.locale, "type", kind}
+		options = append(options, rules.digits.resolvedOptions()...)
+		options = append(options, "pluralCategories", NewArrayFrom(interpreter, list))
+		return newResolvedOptions(interpreter, options...)
+	}), false)
+	return prototype
+}
@@ -0,0 +1,139 @@
+package call
+
+import (
+	"unicode"
+	"unicode/utf16"
+
+	"github.com/nusr/gojs/token"
+	"github.com/nusr/gojs/types"
+)
+
+// Iterator drives a JavaScript iterator object through the iteration protocol.
+type Iterator struct {
+	object any
+	next   any
+	done   bool
+	async  bool // results are awaited, as in for await
+}
+
+func GetIterator(interpreter types.Interpreter, value any) *Iterator {
+	method := GetProperty(interpreter, value, SymbolIterator)
+	if method == nil {
+		ThrowTypeError(interpreter, "%s is not iterable", describe(value))
+	}
+	object := Invoke(interpreter, method, value, nil)
+	if _, ok := object.(types.Property); !ok {
+		ThrowTypeError(interpreter, "Result of the Symbol.iterator method is not an object")
+	}
+	return &Iterator{
+		object: object,
+		next:   GetProperty(interpreter, object, "next"),
+	}
+}
+
+// GetAsyncIterator gets the iterator of for await, wrapping a sync iterator
+// when value has no Symbol.asyncIterator method.
+func GetAsyncIterator(interpreter types.Interpreter, value any) *Iterator {
+	method := GetProperty(interpreter, value, SymbolAsyncIterator)
+	if method == nil {
+		iterator := newAsyncFromSyncIterator(interpreter, GetIterator(interpreter, value))
+		return &Iterator{
+			object: iterator,
+			next:   GetProperty(interpreter, iterator, "next"),
+			async:  true,
+		}
+	}
+	object := Invoke(interpreter, method, value, nil)
+	if _, ok := object.(types.Property); !ok {
+		ThrowTypeError(interpreter, "Result of the Symbol.asyncIterator method is not an object")
+	}
+	return &Iterator{
+		object: object,
+		next:   GetProperty(interpreter, object, "next"),
+		async:  true,
+	}
+}
+
+// invoke calls a method of the iterator, awaiting the result of an async one.
+func (iterator *Iterator) invoke(interpreter types.Interpreter, method any, params []any) any {
+	result := Invoke(interpreter, method, iterator.object, params)
+	if iterator.async {
+		return Await(interpreter, result)
+	}
+	return result
+}
+
+// Step advances the iterator, reporting false once it is exhausted.
+func (iterator *Iterator) Step(interpreter types.Interpreter) (any, bool) {
+	if iterator.done {
+		return nil, false
+	}
+	// an iterator whose next() throws must not be closed
+	iterator.done = true
+	result, ok := iterator.invoke(interpreter, iterator.next, nil).(types.Property)
+	if !ok {
+		ThrowTypeError(interpreter, "Iterator result is not an object")
+	}
+	if ToBoolean(result.Get("done")) {
+		return nil, false
+	}
+	iterator.done = false
+	return result.Get("value"), true
+}
+
+// Close calls return() on an iterator that was left before it was exhausted.
+func (iterator *Iterator) Close(interpreter types.Interpreter) {
+	if iterator.done {
+		return
+	}
+	iterator.done = true
+	method := GetProperty(interpreter, iterator.object, "return")
+	if method == nil {
+		return
+	}
+	if _, ok := iterator.invoke(interpreter, method, nil).(types.Property); !ok {
+		ThrowTypeError(interpreter, "Iterator result is not an object")
+	}
+}
+
+func NewIteratorResult(interpreter types.Interpreter, value any, done bool) types.Object {
+	result := NewInstance(interpreter)
+	result.Set("value", value)
+	result.Set("done", done)
+	return result
+}
+
+// NewIterator wraps a Go step function into an iterator object.
+func NewIterator(interpreter types.Interpreter, next func() (any, bool)) types.Object {
+	iterator := NewInstance(interpreter).(*instanceImpl)
+	iterator.define("next", NewNative(interpreter, "next", func(interpreter types.Interpreter, this any, params []any) any {
+		if value, ok := next(); ok {
+			return NewIteratorResult(interpreter, value, false)
+		}
+		return NewIteratorResult(interpreter, nil, true)
+	}), false)
+	iterator.define(SymbolIterator, NewNative(interpreter, "[Symbol.iterator]", func(interpreter types.Interpreter, this any, params []any) any {
+		return this
+	}), false)
+	return iterator
+}
+
+func newStringIterator(interpreter types.Interpreter, text string) types.Object {
+	units := toUTF16(text)
+	index := 0
+	return NewIterator(interpreter, func() (any, bool) {
+		if index >= len(units) {
+			return nil, false
+		}
+		start := index
+		index++
+		if utf16.IsSurrogate(rune(units[start])) && index < len(units) && utf16.DecodeRune(rune(units[start]), rune(units[index])) != unicode.ReplacementChar {
+			index++
+		}
+		return fromUTF16(units[start:index]), true
+	})
+}
+
+func stringIteratorNative(interpreter types.Interpreter, this any, params []any) any {
+	return newStringIterator(interpreter, token.ConvertAnyToString(this))
+}
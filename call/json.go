@@ -0,0 +1,612 @@
+package call
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/nusr/gojs/types"
+)
+
+func newJSON(realm *realm) types.Object {
+	object := NewObject(realm.objectPrototype).(*instanceImpl)
+	object.define("parse", newNative(realm, "parse", func(interpreter types.Interpreter, this any, params []any) any {
+		parser := &jsonParser{
+			interpreter: interpreter,
+			source:      toUTF16(ToString(interpreter, GetArgument(params, 0))),
+		}
+		result := parser.parse()
+		reviver := GetArgument(params, 1)
+		if _, ok := reviver.(types.Function); !ok {
+			return result
+		}
+		root := NewInstance(interpreter)
+		root.Set("", result)
+		return internalizeJSONProperty(interpreter, root, "", reviver)
+	}), false)
+	object.define("stringify", newNative(realm, "stringify", func(interpreter types.Interpreter, this any, params []any) any {
+		stringifier := newJSONStringifier(interpreter, GetArgument(params, 1), GetArgument(params, 2))
+		root := NewInstance(interpreter)
+		root.Set("", GetArgument(params, 0))
+		if text, ok := stringifier.property(root, "", false); ok {
+			return text
+		}
+		return nil
+	}), false)
+	object.define(SymbolToStringTag, "JSON", false)
+	return object
+}
+
+// internalizeJSONProperty walks a parsed value bottom-up through a reviver.
+// A reviver returning undefined deletes the property.
+func internalizeJSONProperty(interpreter types.Interpreter, holder types.Property, name string, reviver any) any {
+	value := holder.Get(name)
+	if object, ok := value.(types.Object); ok {
+		revive := func(key string) {
+			result := internalizeJSONProperty(interpreter, object, key, reviver)
+			if result == nil {
+				object.Delete(key)
+			} else {
+				object.Set(key, result)
+			}
+		}
+		if IsArray(object) {
+			length := lengthOf(interpreter, object)
+			for i := int64(0); i < length; i++ {
+				revive(strconv.FormatInt(i, 10))
+			}
+		} else {
+			for _, key := range enumerableKeys(object) {
+				revive(key)
+			}
+		}
+	}
+	return Invoke(interpreter, reviver, holder, []any{name, value})
+}
+
+// enumerableKeys lists the enumerable own string keys of an object.
+func enumerableKeys(object types.Object) []string {
+	var result []string
+	for _, key := range object.OwnKeys() {
+		if text, ok := key.(string); ok && object.IsEnumerable(key) {
+			result = append(result, text)
+		}
+	}
+	return result
+}
+
+// jsonParser reads JSON text, reporting errors with the messages and
+// UTF-16 positions V8 uses.
+type jsonParser struct {
+	interpreter types.Interpreter
+	source      []uint16
+	pos         int
+}
+
+func (parser *jsonParser) parse() any {
+	result := parser.value()
+	parser.skipSpace()
+	if parser.pos < len(parser.source) {
+		ThrowSyntaxError(parser.interpreter, "Unexpected non-whitespace character after JSON at position %d", parser.pos)
+	}
+	return result
+}
+
+// peek returns the current code unit, or -1 at the end of the input.
+func (parser *jsonParser) peek() int {
+	if parser.pos < len(parser.source) {
+		return int(parser.source[parser.pos])
+	}
+	return -1
+}
+
+func (parser *jsonParser) skipSpace() {
+	for {
+		switch parser.peek() {
+		case ' ', '\t', '\n', '\r':
+			parser.pos++
+		default:
+			return
+		}
+	}
+}
+
+func (parser *jsonParser) fail(message string) {
+	ThrowSyntaxError(parser.interpreter, "%s in JSON at position %d", message, parser.pos)
+}
+
+// unexpected reports the token at the current position.
+func (parser *jsonParser) unexpected() {
+	c := parser.peek()
+	switch {
+	case c < 0:
+		ThrowSyntaxError(parser.interpreter, "Unexpected end of JSON input")
+	case c == '"':
+		ThrowSyntaxError(parser.interpreter, "Unexpected string in JSON at position %d", parser.pos)
+	case c == '-' || isDigit(c):
+		ThrowSyntaxError(parser.interpreter, "Unexpected number in JSON at position %d", parser.pos)
+	}
+	text := fromUTF16(parser.source)
+	switch text {
+	case "undefined", "NaN", "Infinity", "[object Object]":
+		ThrowSyntaxError(parser.interpreter, "\"%s\" is not valid JSON", text)
+	}
+	// long inputs are quoted as ten code units either side of the token
+	const context = 10
+	token := fromUTF16(parser.source[parser.pos : parser.pos+1])
+	length := len(parser.source)
+	switch {
+	case length < context*2+1:
+		ThrowSyntaxError(parser.interpreter, "Unexpected token '%s', \"%s\" is not valid JSON", token, text)
+	case parser.pos < context:
+		ThrowSyntaxError(parser.interpreter, "Unexpected token '%s', \"%s\"... is not valid JSON", token, fromUTF16(parser.source[:parser.pos+context]))
+	case parser.pos < length-context:
+		ThrowSyntaxError(parser.interpreter, "Unexpected token '%s', ...\"%s\"... is not valid JSON", token, fromUTF16(parser.source[parser.pos-context:parser.pos+context]))
+	default:
+		ThrowSyntaxError(parser.interpreter, "Unexpected token '%s', ...\"%s\" is not valid JSON", token, fromUTF16(parser.source[parser.pos-context:]))
+	}
+}
+
+func isDigit(c int) bool {
+	return c >= '0' && c <= '9'
+}
+
+func (parser *jsonParser) value() any {
+	parser.skipSpace()
+	switch c := parser.peek(); {
+	case c == '"':
+		return parser.string()
+	case c == '{':
+		return parser.object()
+	case c == '[':
+		return parser.array()
+	case c == 't':
+		parser.literal("true")
+		return true
+	case c == 'f':
+		parser.literal("false")
+		return false
+	case c == 'n':
+		parser.literal("null")
+		return types.Null{}
+	case c == '-' || isDigit(c):
+		return parser.number()
+	}
+	parser.unexpected()
+	return nil
+}
+
+func (parser *jsonParser) literal(word string) {
+	parser.pos++
+	for i := 1; i < len(word); i++ {
+		if parser.peek() != int(word[i]) {
+			parser.unexpected()
+		}
+		parser.pos++
+	}
+}
+
+func (parser *jsonParser) object() any {
+	parser.pos++
+	object := NewInstance(parser.interpreter)
+	parser.skipSpace()
+	if parser.peek() == '}' {
+		parser.pos++
+		return object
+	}
+	if parser.peek() != '"' {
+		parser.fail("Expected property name or '}'")
+	}
+	for {
+		key := parser.string()
+		parser.skipSpace()
+		if parser.peek() != ':' {
+			parser.fail("Expected ':' after property name")
+		}
+		parser.pos++
+		object.Set(key, parser.value())
+		parser.skipSpace()
+		switch parser.peek() {
+		case ',':
+			parser.pos++
+			parser.skipSpace()
+			if parser.peek() != '"' {
+				parser.fail("Expected double-quoted property name")
+			}
+		case '}':
+			parser.pos++
+			return object
+		default:
+			parser.fail("Expected ',' or '}' after property value")
+		}
+	}
+}
+
+func (parser *jsonParser) array() any {
+	parser.pos++
+	var list []any
+	parser.skipSpace()
+	if parser.peek() == ']' {
+		parser.pos++
+		return NewArray(parser.interpreter)
+	}
+	for {
+		list = append(list, parser.value())
+		parser.skipSpace()
+		switch parser.peek() {
+		case ',':
+			parser.pos++
+		case ']':
+			parser.pos++
+			return NewArrayFrom(parser.interpreter, list)
+		default:
+			parser.fail("Expected ',' or ']' after array element")
+		}
+	}
+}
+
+func (parser *jsonParser) string() string {
+	parser.pos++
+	var units []uint16
+	for {
+		c := parser.peek()
+		switch {
+		case c < 0:
+			parser.fail("Unterminated string")
+		case c == '"':
+			parser.pos++
+			return fromUTF16(units)
+		case c < 0x20:
+			parser.fail("Bad control character in string literal")
+		case c == '\\':
+			parser.pos++
+			units = append(units, parser.escape())
+		default:
+			units = append(units, uint16(c))
+		}
+		parser.pos++
+	}
+}
+
+// escape reads the character after a backslash, leaving pos on its last
+// code unit.
+func (parser *jsonParser) escape() uint16 {
+	switch parser.peek() {
+	case -1:
+		ThrowSyntaxError(parser.interpreter, "Unexpected end of JSON input")
+	case '"':
+		return '"'
+	case '\\':
+		return '\\'
+	case '/':
+		return '/'
+	case 'b':
+		return '\b'
+	case 'f':
+		return '\f'
+	case 'n':
+		return '\n'
+	case 'r':
+		return '\r'
+	case 't':
+		return '\t'
+	case 'u':
+		var code uint16
+		for i := 0; i < 4; i++ {
+			parser.pos++
+			digit := hexDigit(parser.peek())
+			if digit < 0 {
+				parser.fail("Bad Unicode escape")
+			}
+			code = code<<4 | uint16(digit)
+		}
+		return code
+	}
+	parser.fail("Bad escaped character")
+	return 0
+}
+
+func hexDigit(c int) int {
+	switch {
+	case c >= '0' && c <= '9':
+		return c - '0'
+	case c >= 'a' && c <= 'f':
+		return c - 'a' + 10
+	case c >= 'A' && c <= 'F':
+		return c - 'A' + 10
+	}
+	return -1
+}
+
+func (parser *jsonParser) number() any {
+	start := parser.pos
+	if parser.peek() == '-' {
+		parser.pos++
+	}
+	if parser.peek() == '0' {
+		parser.pos++
+		if isDigit(parser.peek()) {
+			parser.unexpected()
+		}
+	} else if isDigit(parser.peek()) {
+		parser.digits()
+	} else {
+		parser.fail("No number after minus sign")
+	}
+	if parser.peek() == '.' {
+		parser.pos++
+		if !isDigit(parser.peek()) {
+			parser.fail("Unterminated fractional number")
+		}
+		parser.digits()
+	}
+	if c := parser.peek(); c == 'e' || c == 'E' {
+		parser.pos++
+		if c := parser.peek(); c == '+' || c == '-' {
+			parser.pos++
+		}
+		if !isDigit(parser.peek()) {
+			parser.fail("Exponent part is missing a number")
+		}
+		parser.digits()
+	}
+	// out of range numbers parse to an infinity alongside the error
+	value, _ := strconv.ParseFloat(fromUTF16(parser.source[start:parser.pos]), 64)
+	return value
+}
+
+func (parser *jsonParser) digits() {
+	for isDigit(parser.peek()) {
+		parser.pos++
+	}
+}
+
+type jsonStackEntry struct {
+	key    string
+	object types.Object
+}
+
+type jsonStringifier struct {
+	interpreter types.Interpreter
+	replacer    any
+	keys        []string // the replacer array, when there is one
+	gap         string
+	indent      string
+	stack       []jsonStackEntry
+}
+
+func newJSONStringifier(interpreter types.Interpreter, replacer any, space any) *jsonStringifier {
+	stringifier := &jsonStringifier{
+		interpreter: interpreter,
+	}
+	if _, ok := replacer.(types.Function); ok {
+		stringifier.replacer = replacer
+	} else if IsArray(replacer) {
+		stringifier.keys = []string{}
+		seen := make(map[string]bool)
+		length := lengthOf(interpreter, replacer.(types.Object))
+		for i := int64(0); i < length; i++ {
+			var key string
+			switch item := replacer.(types.Object).Get(i).(type) {
+			case string:
+				key = item
+			case int64, float64, types.NaN, *stringImpl, *numberImpl:
+				key = ToString(interpreter, item)
+			default:
+				continue
+			}
+			if !seen[key] {
+				seen[key] = true
+				stringifier.keys = append(stringifier.keys, key)
+			}
+		}
+	}
+	switch object := space.(type) {
+	case *stringImpl:
+		space = object.value
+	case *numberImpl:
+		space = object.value
+	}
+	switch data := space.(type) {
+	case string:
+		units := toUTF16(data)
+		if len(units) > 10 {
+			units = units[:10]
+		}
+		stringifier.gap = fromUTF16(units)
+	case int64, float64:
+		count := math.Min(10, toIntegerOrInfinity(interpreter, data))
+		if count >= 1 {
+			stringifier.gap = strings.Repeat(" ", int(count))
+		}
+	}
+	return stringifier
+}
+
+// property serializes holder[key], reporting false when the value is left
+// out.
+func (stringifier *jsonStringifier) property(holder types.Property, key string, index bool) (string, bool) {
+	value := holder.Get(key)
+	if object, ok := value.(types.Property); ok {
+		if toJSON, ok := object.Get("toJSON").(types.Function); ok {
+			value = Invoke(stringifier.interpreter, toJSON, value, []any{key})
+		}
+	}
+	if stringifier.replacer != nil {
+		value = Invoke(stringifier.interpreter, stringifier.replacer, holder, []any{key, value})
+	}
+	switch object := value.(type) {
+	case *stringImpl:
+		value = object.value
+	case *numberImpl:
+		value = object.value
+	}
+	switch data := value.(type) {
+	case types.Null:
+		return "null", true
+	case bool:
+		if data {
+			return "true", true
+		}
+		return "false", true
+	case string:
+		return quoteJSONString(data), true
+	case int64:
+		return strconv.FormatInt(data, 10), true
+	case float64:
+		if math.IsNaN(data) || math.IsInf(data, 0) {
+			return "null", true
+		}
+		return NumberToString(data), true
+	case types.NaN:
+		return "null", true
+	case types.Function:
+		return "", false
+	case types.Object:
+		label := "property '" + key + "'"
+		if index {
+			label = "index " + key
+		}
+		stringifier.enter(label, data)
+		defer stringifier.leave()
+		if IsArray(data) {
+			return stringifier.array(data), true
+		}
+		return stringifier.object(data), true
+	}
+	return "", false
+}
+
+// enter pushes an object being serialized, throwing on a cycle the way V8
+// describes it.
+func (stringifier *jsonStringifier) enter(key string, object types.Object) {
+	for i, entry := range stringifier.stack {
+		if entry.object != object {
+			continue
+		}
+		var message strings.Builder
+		message.WriteString("Converting circular structure to JSON")
+		message.WriteString("\n    --> starting at object with constructor '" + constructorName(object) + "'")
+		rest := stringifier.stack[i+1:]
+		for j, entry := range rest {
+			if len(rest) > 3 && j >= 2 && j < len(rest)-1 {
+				if j == 2 {
+					message.WriteString("\n    |     ...")
+				}
+				continue
+			}
+			message.WriteString("\n    |     " + entry.key + " -> object with constructor '" + constructorName(entry.object) + "'")
+		}
+		message.WriteString("\n    --- " + key + " closes the circle")
+		ThrowTypeError(stringifier.interpreter, "%s", message.String())
+	}
+	stringifier.stack = append(stringifier.stack, jsonStackEntry{key: key, object: object})
+}
+
+func (stringifier *jsonStringifier) leave() {
+	stringifier.stack = stringifier.stack[:len(stringifier.stack)-1]
+}
+
+// constructorName names the constructor of an object for error messages.
+func constructorName(object types.Object) string {
+	switch constructor := object.Get("constructor").(type) {
+	case *nativeImpl:
+		return constructor.name
+	case types.Property:
+		if name, ok := constructor.Get("name").(string); ok && name != "" {
+			return name
+		}
+	}
+	return "Object"
+}
+
+func (stringifier *jsonStringifier) object(object types.Object) string {
+	stepback := stringifier.indent
+	stringifier.indent += stringifier.gap
+	defer func() {
+		stringifier.indent = stepback
+	}()
+	keys := stringifier.keys
+	if keys == nil {
+		keys = enumerableKeys(object)
+	}
+	var partial []string
+	for _, key := range keys {
+		if stringifier.keys != nil && !HasProperty(object, key) {
+			continue
+		}
+		text, ok := stringifier.property(object, key, false)
+		if !ok {
+			continue
+		}
+		member := quoteJSONString(key) + ":"
+		if stringifier.gap != "" {
+			member += " "
+		}
+		partial = append(partial, member+text)
+	}
+	return stringifier.join("{", partial, "}", stepback)
+}
+
+func (stringifier *jsonStringifier) array(array types.Object) string {
+	stepback := stringifier.indent
+	stringifier.indent += stringifier.gap
+	defer func() {
+		stringifier.indent = stepback
+	}()
+	length := lengthOf(stringifier.interpreter, array)
+	partial := make([]string, 0, length)
+	for i := int64(0); i < length; i++ {
+		text, ok := stringifier.property(array, strconv.FormatInt(i, 10), true)
+		if !ok {
+			text = "null"
+		}
+		partial = append(partial, text)
+	}
+	return stringifier.join("[", partial, "]", stepback)
+}
+
+func (stringifier *jsonStringifier) join(open string, partial []string, close string, stepback string) string {
+	if len(partial) == 0 {
+		return open + close
+	}
+	if stringifier.gap == "" {
+		return open + strings.Join(partial, ",") + close
+	}
+	separator := ",\n" + stringifier.indent
+	return open + "\n" + stringifier.indent + strings.Join(partial, separator) + "\n" + stepback + close
+}
+
+// quoteJSONString wraps a string in double quotes, escaping it for JSON.
+func quoteJSONString(text string) string {
+	var result strings.Builder
+	result.WriteByte('"')
+	for _, c := range codePoints(text) {
+		switch c {
+		case '"':
+			result.WriteString("\\\"")
+		case '\\':
+			result.WriteString("\\\\")
+		case '\b':
+			result.WriteString("\\b")
+		case '\f':
+			result.WriteString("\\f")
+		case '\n':
+			result.WriteString("\\n")
+		case '\r':
+			result.WriteString("\\r")
+		case '\t':
+			result.WriteString("\\t")
+		default:
+			if c < 0x20 || utf16.IsSurrogate(c) {
+				fmt.Fprintf(&result, "\\u%04x", c)
+			} else {
+				result.WriteRune(c)
+			}
+		}
+	}
+	result.WriteByte('"')
+	return result.String()
+}
@@ -0,0 +1,539 @@
+package call
+
+import (
+	"math"
+	"regexp"
+	"strings"
+
+	"github.com/nusr/gojs/types"
+)
+
+// currencyNames are the ways a locale writes a currency.
+type currencyNames struct {
+	symbol string
+	narrow string
+	one    string // the name after a number of the plural category one
+	other  string
+}
+
+// localeData is the CLDR data Intl formats with for one language.
+type localeData struct {
+	decimal string
+	group   string
+	// percent follows the number
+	percent string
+	// currencyAfter puts the currency symbol after the number, separated
+	// by a no-break space.
+	currencyAfter bool
+	// accounting wraps negative amounts in parentheses for the accounting
+	// currency sign.
+	accounting bool
+	// nameSeparator comes between a number and the name of a currency.
+	nameSeparator string
+	currencies    map[string]currencyNames
+
+	cardinal           func(operands pluralOperands) string
+	ordinal            func(operands pluralOperands) string
+	cardinalCategories []string
+	ordinalCategories  []string
+
+	months      []string
+	shortMonths []string
+	// standaloneShortMonths name a month on its own, when they differ.
+	standaloneShortMonths []string
+	narrowMonths          []string
+	weekdays              []string
+	shortWeekdays         []string
+	standaloneWeekdays    []string
+	narrowWeekdays        []string
+	dayPeriods            [2]string
+	// hour12 reports whether the locale uses a 12-hour clock by default.
+	hour12 bool
+	// skeletons map the fields of a date or time, such as yMMMd, to the
+	// pattern that formats them. Numeric and text months are M and MMM,
+	// weekdays E, and hours h for a 12-hour clock or H for a 24-hour one.
+	// Hmm and Hmms are hours with 2-digit minutes, when they differ from
+	// Hm and Hms.
+	skeletons map[string]string
+	// The patterns of the full, long, medium and short styles.
+	dateStyles [4]string
+	timeStyles [4]string
+	// dateTimeStyles join a date and a time as {1} and {0}.
+	dateTimeStyles [4]string
+	// zoneFirst puts the time zone name before the time.
+	zoneFirst bool
+	// gmt prefixes the offset of a time zone without a name.
+	gmt string
+	// gmtMinus signs a negative offset, when it is not a hyphen.
+	gmtMinus string
+	// utc is the long name of UTC.
+	utc string
+}
+
+// pluralOperands are the operands of the CLDR plural rules: the absolute
+// value n, its integer digits i, and its visible fraction digits v.
+type pluralOperands struct {
+	n float64
+	i float64
+	v int
+}
+
+func pluralOther(operands pluralOperands) string {
+	return "other"
+}
+
+// pluralOne is the cardinal rule of English and German.
+func pluralOne(operands pluralOperands) string {
+	if operands.i == 1 && operands.v == 0 {
+		return "one"
+	}
+	return "other"
+}
+
+// commonSkeletons are the minute and second formats all locales share.
+var commonSkeletons = map[string]string{
+	"m":  "m",
+	"ms": "mm:ss",
+	"s":  "s",
+}
+
+var bundledLocales = map[string]*localeData{
+	"en": {
+		decimal:       ".",
+		group:         ",",
+		percent:       "%",
+		accounting:    true,
+		nameSeparator: " ",
+		currencies: map[string]currencyNames{
+			"USD": {"$", "$", "US dollar", "US dollars"},
+			"EUR": {"€", "€", "euro", "euros"},
+			"JPY": {"¥", "¥", "Japanese yen", "Japanese yen"},
+			"CNY": {"CN¥", "¥", "Chinese yuan", "Chinese yuan"},
+			"GBP": {"£", "£", "British pound", "British pounds"},
+		},
+		cardinal: pluralOne,
+		ordinal: func(operands pluralOperands) string {
+			switch n10, n100 := math.Mod(operands.n, 10), math.Mod(operands.n, 100); {
+			case n10 == 1 && n100 != 11:
+				return "one"
+			case n10 == 2 && n100 != 12:
+				return "two"
+			case n10 == 3 && n100 != 13:
+				return "few"
+			}
+			return "other"
+		},
+		cardinalCategories: []string{"one", "other"},
+		ordinalCategories:  []string{"few", "one", "two", "other"},
+		months:             []string{"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"},
+		shortMonths:        monthNames,
+		narrowMonths:       []string{"J", "F", "M", "A", "M", "J", "J", "A", "S", "O", "N", "D"},
+		weekdays:           []string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"},
+		shortWeekdays:      dayNames,
+		narrowWeekdays:     []string{"S", "M", "T", "W", "T", "F", "S"},
+		dayPeriods:         [2]string{"AM", "PM"},
+		hour12:             true,
+		skeletons: map[string]string{
+			"y":      "y",
+			"yM":     "M/y",
+			"yMd":    "M/d/y",
+			"yMEd":   "EEE, M/d/y",
+			"yMMM":   "MMM y",
+			"yMMMd":  "MMM d, y",
+			"yMMMEd": "EEE, MMM d, y",
+			"M":      "L",
+			"Md":     "M/d",
+			"MEd":    "EEE, M/d",
+			"MMM":    "LLL",
+			"MMMd":   "MMM d",
+			"MMMEd":  "EEE, MMM d",
+			"d":      "d",
+			"Ed":     "d EEE",
+			"E":      "ccc",
+			"h":      "h a",
+			"hm":     "h:mm a",
+			"hms":    "h:mm:ss a",
+			"H":      "HH",
+			"Hm":     "HH:mm",
+			"Hms":    "HH:mm:ss",
+		},
+		dateStyles:     [4]string{"EEEE, MMMM d, y", "MMMM d, y", "MMM d, y", "M/d/yy"},
+		timeStyles:     [4]string{"h:mm:ss a zzzz", "h:mm:ss a z", "h:mm:ss a", "h:mm a"},
+		dateTimeStyles: [4]string{"{1} 'at' {0}", "{1} 'at' {0}", "{1}, {0}", "{1}, {0}"},
+		gmt:            "GMT",
+		utc:            "Coordinated Universal Time",
+	},
+	"de": {
+		decimal:       ",",
+		group:         ".",
+		percent:       "\u00a0%",
+		currencyAfter: true,
+		nameSeparator: " ",
+		currencies: map[string]currencyNames{
+			"USD": {"$", "$", "US-Dollar", "US-Dollar"},
+			"EUR": {"€", "€", "Euro", "Euro"},
+			"JPY": {"¥", "¥", "Japanischer Yen", "Japanische Yen"},
+			"CNY": {"CN¥", "¥", "Chinesischer Yuan", "Renminbi Yuan"},
+			"GBP": {"£", "£", "Britisches Pfund", "Britische Pfund"},
+		},
+		cardinal:              pluralOne,
+		ordinal:               pluralOther,
+		cardinalCategories:    []string{"one", "other"},
+		ordinalCategories:     []string{"other"},
+		months:                []string{"Januar", "Februar", "März", "April", "Mai", "Juni", "Juli", "August", "September", "Oktober", "November", "Dezember"},
+		shortMonths:           []string{"Jan.", "Feb.", "März", "Apr.", "Mai", "Juni", "Juli", "Aug.", "Sept.", "Okt.", "Nov.", "Dez."},
+		standaloneShortMonths: []string{"Jan", "Feb", "Mär", "Apr", "Mai", "Jun", "Jul", "Aug", "Sep", "Okt", "Nov", "Dez"},
+		narrowMonths:          []string{"J", "F", "M", "A", "M", "J", "J", "A", "S", "O", "N", "D"},
+		weekdays:              []string{"Sonntag", "Montag", "Dienstag", "Mittwoch", "Donnerstag", "Freitag", "Samstag"},
+		shortWeekdays:         []string{"So.", "Mo.", "Di.", "Mi.", "Do.", "Fr.", "Sa."},
+		standaloneWeekdays:    []string{"So", "Mo", "Di", "Mi", "Do", "Fr", "Sa"},
+		narrowWeekdays:        []string{"S", "M", "D", "M", "D", "F", "S"},
+		dayPeriods:            [2]string{"AM", "PM"},
+		skeletons: map[string]string{
+			"y":      "y",
+			"yM":     "M/y",
+			"yMd":    "d.M.y",
+			"yMEd":   "EEE, d.M.y",
+			"yMMM":   "MMM y",
+			"yMMMd":  "d. MMM y",
+			"yMMMEd": "EEE, d. MMM y",
+			"M":      "L",
+			"Md":     "d.M.",
+			"MEd":    "EEE, d.M.",
+			"MMM":    "LLL",
+			"MMMd":   "d. MMM",
+			"MMMEd":  "EEE, d. MMM",
+			"d":      "d",
+			"Ed":     "EEE, d.",
+			"E":      "ccc",
+			"h":      "K 'Uhr' a",
+			"hm":     "K:mm a",
+			"hms":    "K:mm:ss a",
+			"H":      "HH 'Uhr'",
+			"Hm":     "HH:mm",
+			"Hmm":    "H:mm",
+			"Hms":    "HH:mm:ss",
+			"Hmms":   "H:mm:ss",
+		},
+		dateStyles:     [4]string{"EEEE, d. MMMM y", "d. MMMM y", "dd.MM.y", "dd.MM.yy"},
+		timeStyles:     [4]string{"HH:mm:ss zzzz", "HH:mm:ss z", "HH:mm:ss", "HH:mm"},
+		dateTimeStyles: [4]string{"{1} 'um' {0}", "{1} 'um' {0}", "{1}, {0}", "{1}, {0}"},
+		gmt:            "GMT",
+		utc:            "Koordinierte Weltzeit",
+	},
+	"fr": {
+		decimal:       ",",
+		group:         "\u202f",
+		percent:       "\u00a0%",
+		currencyAfter: true,
+		accounting:    true,
+		nameSeparator: " ",
+		currencies: map[string]currencyNames{
+			"USD": {"$US", "$", "dollar des États-Unis", "dollars des États-Unis"},
+			"EUR": {"€", "€", "euro", "euros"},
+			"JPY": {"JPY", "¥", "yen japonais", "yens japonais"},
+			"CNY": {"CNY", "¥", "yuan renminbi chinois", "yuans renminbi chinois"},
+			"GBP": {"£GB", "£", "livre sterling", "livres sterling"},
+		},
+		cardinal: func(operands pluralOperands) string {
+			switch {
+			case operands.i == 0 || operands.i == 1:
+				return "one"
+			case operands.v == 0 && math.Mod(operands.i, 1000000) == 0:
+				return "many"
+			}
+			return "other"
+		},
+		ordinal: func(operands pluralOperands) string {
+			if operands.n == 1 {
+				return "one"
+			}
+			return "other"
+		},
+		cardinalCategories: []string{"many", "one", "other"},
+		ordinalCategories:  []string{"one", "other"},
+		months:             []string{"janvier", "février", "mars", "avril", "mai", "juin", "juillet", "août", "septembre", "octobre", "novembre", "décembre"},
+		shortMonths:        []string{"janv.", "févr.", "mars", "avr.", "mai", "juin", "juil.", "août", "sept.", "oct.", "nov.", "déc."},
+		narrowMonths:       []string{"J", "F", "M", "A", "M", "J", "J", "A", "S", "O", "N", "D"},
+		weekdays:           []string{"dimanche", "lundi", "mardi", "mercredi", "jeudi", "vendredi", "samedi"},
+		shortWeekdays:      []string{"dim.", "lun.", "mar.", "mer.", "jeu.", "ven.", "sam."},
+		narrowWeekdays:     []string{"D", "L", "M", "M", "J", "V", "S"},
+		dayPeriods:         [2]string{"AM", "PM"},
+		skeletons: map[string]string{
+			"y":      "y",
+			"yM":     "MM/y",
+			"yMd":    "dd/MM/y",
+			"yMEd":   "EEE dd/MM/y",
+			"yMMM":   "MMM y",
+			"yMMMd":  "d MMM y",
+			"yMMMEd": "EEE d MMM y",
+			"M":      "L",
+			"Md":     "dd/MM",
+			"MEd":    "EEE dd/MM",
+			"MMM":    "LLL",
+			"MMMd":   "d MMM",
+			"MMMEd":  "EEE d MMM",
+			"d":      "d",
+			"Ed":     "EEE d",
+			"E":      "ccc",
+			"h":      "K a",
+			"hm":     "K:mm a",
+			"hms":    "K:mm:ss a",
+			"H":      "HH 'h'",
+			"Hm":     "HH:mm",
+			"Hmm":    "H:mm",
+			"Hms":    "HH:mm:ss",
+			"Hmms":   "H:mm:ss",
+		},
+		dateStyles:     [4]string{"EEEE d MMMM y", "d MMMM y", "d MMM y", "dd/MM/y"},
+		timeStyles:     [4]string{"HH:mm:ss zzzz", "HH:mm:ss z", "HH:mm:ss", "HH:mm"},
+		dateTimeStyles: [4]string{"{1} 'à' {0}", "{1} 'à' {0}", "{1}, {0}", "{1} {0}"},
+		gmt:            "UTC",
+		gmtMinus:       "\u2212",
+		utc:            "temps universel coordonné",
+	},
+	"ja": {
+		decimal:    ".",
+		group:      ",",
+		percent:    "%",
+		accounting: true,
+		currencies: map[string]currencyNames{
+			"USD": {"$", "$", "米ドル", "米ドル"},
+			"EUR": {"€", "€", "ユーロ", "ユーロ"},
+			"JPY": {"￥", "￥", "円", "円"},
+			"CNY": {"元", "￥", "中国人民元", "中国人民元"},
+			"GBP": {"£", "£", "英国ポンド", "英国ポンド"},
+		},
+		cardinal:           pluralOther,
+		ordinal:            pluralOther,
+		cardinalCategories: []string{"other"},
+		ordinalCategories:  []string{"other"},
+		months:             []string{"1月", "2月", "3月", "4月", "5月", "6月", "7月", "8月", "9月", "10月", "11月", "12月"},
+		shortMonths:        []string{"1月", "2月", "3月", "4月", "5月", "6月", "7月", "8月", "9月", "10月", "11月", "12月"},
+		narrowMonths:       []string{"1", "2", "3", "4", "5", "6", "7", "8", "9", "10", "11", "12"},
+		weekdays:           []string{"日曜日", "月曜日", "火曜日", "水曜日", "木曜日", "金曜日", "土曜日"},
+		shortWeekdays:      []string{"日", "月", "火", "水", "木", "金", "土"},
+		narrowWeekdays:     []string{"日", "月", "火", "水", "木", "金", "土"},
+		dayPeriods:         [2]string{"午前", "午後"},
+		skeletons: map[string]string{
+			"y":         "y年",
+			"yM":        "y/M",
+			"yMd":       "y/M/d",
+			"yMEd":      "y/M/d(EEE)",
+			"yMMM":      "y年M月",
+			"yMMMd":     "y年M月d日",
+			"yMMMEd":    "y年M月d日(EEE)",
+			"yMMMEEEEd": "y年M月d日EEEE",
+			"M":         "M月",
+			"Md":        "M/d",
+			"MEd":       "M/d(EEE)",
+			"MMM":       "M月",
+			"MMMd":      "M月d日",
+			"MMMEd":     "M月d日(EEE)",
+			"MMMEEEEd":  "M月d日EEEE",
+			"d":         "d日",
+			"Ed":        "d日(EEE)",
+			"E":         "ccc",
+			"h":         "aK時",
+			"hm":        "aK:mm",
+			"hms":       "aK:mm:ss",
+			"H":         "H時",
+			"Hm":        "H:mm",
+			"Hms":       "H:mm:ss",
+		},
+		dateStyles:     [4]string{"y年M月d日EEEE", "y年M月d日", "y/MM/dd", "y/MM/dd"},
+		timeStyles:     [4]string{"H時mm分ss秒 zzzz", "H:mm:ss z", "H:mm:ss", "H:mm"},
+		dateTimeStyles: [4]string{"{1} {0}", "{1} {0}", "{1} {0}", "{1} {0}"},
+		gmt:            "GMT",
+		utc:            "協定世界時",
+	},
+	"zh": {
+		decimal:    ".",
+		group:      ",",
+		percent:    "%",
+		accounting: true,
+		currencies: map[string]currencyNames{
+			"USD": {"US$", "$", "美元", "美元"},
+			"EUR": {"€", "€", "欧元", "欧元"},
+			"JPY": {"JP¥", "¥", "日元", "日元"},
+			"CNY": {"¥", "¥", "人民币", "人民币"},
+			"GBP": {"£", "£", "英镑", "英镑"},
+		},
+		cardinal:           pluralOther,
+		ordinal:            pluralOther,
+		cardinalCategories: []string{"other"},
+		ordinalCategories:  []string{"other"},
+		months:             []string{"一月", "二月", "三月", "四月", "五月", "六月", "七月", "八月", "九月", "十月", "十一月", "十二月"},
+		shortMonths:        []string{"1月", "2月", "3月", "4月", "5月", "6月", "7月", "8月", "9月", "10月", "11月", "12月"},
+		narrowMonths:       []string{"1", "2", "3", "4", "5", "6", "7", "8", "9", "10", "11", "12"},
+		weekdays:           []string{"星期日", "星期一", "星期二", "星期三", "星期四", "星期五", "星期六"},
+		shortWeekdays:      []string{"周日", "周一", "周二", "周三", "周四", "周五", "周六"},
+		narrowWeekdays:     []string{"日", "一", "二", "三", "四", "五", "六"},
+		dayPeriods:         [2]string{"上午", "下午"},
+		skeletons: map[string]string{
+			"y":         "y年",
+			"yM":        "y/M",
+			"yMd":       "y/M/d",
+			"yMEd":      "y/M/dEEE",
+			"yMMM":      "y年M月",
+			"yMMMd":     "y年M月d日",
+			"yMMMEd":    "y年M月d日EEE",
+			"yMMMEEEEd": "y年M月d日EEEE",
+			"M":         "M月",
+			"Md":        "M/d",
+			"MEd":       "M/dEEE",
+			"MMM":       "LLL",
+			"MMMd":      "M月d日",
+			"MMMEd":     "M月d日EEE",
+			"MMMEEEEd":  "M月d日EEEE",
+			"d":         "d日",
+			"Ed":        "d日EEE",
+			"E":         "ccc",
+			"h":         "aK时",
+			"hm":        "aK:mm",
+			"hms":       "aK:mm:ss",
+			"H":         "H时",
+			"Hm":        "HH:mm",
+			"Hmm":       "H:mm",
+			"Hms":       "HH:mm:ss",
+			"Hmms":      "H:mm:ss",
+		},
+		dateStyles:     [4]string{"y年M月d日EEEE", "y年M月d日", "y年M月d日", "y/M/d"},
+		timeStyles:     [4]string{"zzzz HH:mm:ss", "z HH:mm:ss", "HH:mm:ss", "HH:mm"},
+		dateTimeStyles: [4]string{"{1} {0}", "{1} {0}", "{1} {0}", "{1} {0}"},
+		zoneFirst:      true,
+		gmt:            "GMT",
+		utc:            "协调世界时",
+	},
+}
+
+// BundledLocales lists the locales with embedded data, en-US first as the
+// default.
+func BundledLocales() []string {
+	return []string{"en-US", "de", "fr", "ja", "zh"}
+}
+
+// languageTag matches a BCP 47 language tag: a language, script, region
+// and variants, then extensions and a private use part.
+var languageTag = regexp.MustCompile(`^[a-z]{2,3}(-[a-z]{4})?(-([a-z]{2}|[0-9]{3}))?(-([a-z0-9]{5,8}|[0-9][a-z0-9]{3}))*(-[0-9a-wyz](-[a-z0-9]{2,8})+)*(-x(-[a-z0-9]{1,8})+)?$`)
+
+// canonicalizeLocale validates a language tag and fixes the case of its
+// subtags, as in zh-Hans-CN.
+func canonicalizeLocale(tag string) (string, bool) {
+	tag = strings.ToLower(tag)
+	if !languageTag.MatchString(tag) {
+		return "", false
+	}
+	subtags := strings.Split(tag, "-")
+	for i := 1; i < len(subtags); i++ {
+		subtag := subtags[i]
+		if len(subtag) == 1 {
+			break
+		}
+		switch {
+		case i == 1 && len(subtag) == 4:
+			subtags[i] = strings.ToUpper(subtag[:1]) + subtag[1:]
+		case len(subtag) == 2:
+			subtags[i] = strings.ToUpper(subtag)
+		}
+	}
+	return strings.Join(subtags, "-"), true
+}
+
+// canonicalizeLocales reads the locales argument of Intl, a tag or a list
+// of them.
+func canonicalizeLocales(interpreter types.Interpreter, locales any) []string {
+	var list []any
+	switch data := locales.(type) {
+	case nil:
+		return nil
+	case string:
+		list = []any{data}
+	case types.Object:
+		length := lengthOf(interpreter, data)
+		for k := int64(0); k < length; k++ {
+			if data.Has(k) {
+				list = append(list, data.Get(k))
+			}
+		}
+	default:
+		return nil
+	}
+	var result []string
+	seen := make(map[string]bool)
+	for _, item := range list {
+		switch item.(type) {
+		case string, types.Object:
+		default:
+			ThrowTypeError(interpreter, "Language ID should be string or object.")
+		}
+		tag, ok := canonicalizeLocale(ToString(interpreter, item))
+		if !ok {
+			ThrowRangeError(interpreter, "Incorrect locale information provided")
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			result = append(result, tag)
+		}
+	}
+	return result
+}
+
+// language returns the language subtag of a canonical tag.
+func language(tag string) string {
+	language, _, _ := strings.Cut(tag, "-")
+	return language
+}
+
+// availableLocale finds the data for a tag among the locales the
+// interpreter supports. Extensions are dropped from the tag.
+func availableLocale(interpreter types.Interpreter, tag string) (string, *localeData) {
+	if index := strings.Index(tag, "-x-"); index >= 0 {
+		tag = tag[:index]
+	}
+	subtags := strings.Split(tag, "-")
+	for i, subtag := range subtags {
+		if len(subtag) == 1 {
+			subtags = subtags[:i]
+			break
+		}
+	}
+	tag = strings.Join(subtags, "-")
+	for _, locale := range interpreter.Locales() {
+		if canonical, ok := canonicalizeLocale(locale); ok && language(canonical) == subtags[0] {
+			if data, ok := bundledLocales[subtags[0]]; ok {
+				return tag, data
+			}
+		}
+	}
+	return "", nil
+}
+
+// resolveLocale picks the first requested locale that is supported, or
+// the default one.
+func resolveLocale(interpreter types.Interpreter, locales any) (string, *localeData) {
+	for _, tag := range canonicalizeLocales(interpreter, locales) {
+		if locale, data := availableLocale(interpreter, tag); data != nil {
+			return locale, data
+		}
+	}
+	for _, locale := range interpreter.Locales() {
+		if canonical, ok := canonicalizeLocale(locale); ok {
+			if locale, data := availableLocale(interpreter, canonical); data != nil {
+				return locale, data
+			}
+		}
+	}
+	return "en-US", bundledLocales["en"]
+}
+
+// supportedLocales filters the requested locales to the supported ones.
+func supportedLocales(interpreter types.Interpreter, locales any) []any {
+	result := []any{}
+	for _, tag := range canonicalizeLocales(interpreter, locales) {
+		if _, data := availableLocale(interpreter, tag); data != nil {
+			result = append(result, tag)
+		}
+	}
+	return result
+}
@@ -0,0 +1,548 @@
+package call
+
+import (
+	"math"
+
+	"github.com/nusr/gojs/flow"
+	"github.com/nusr/gojs/types"
+)
+
+// mapEntry is a node of the insertion-ordered list behind a Map or Set. A
+// removed entry keeps its next link so a cursor standing on it can move on.
+type mapEntry struct {
+	key     any
+	value   any
+	prev    *mapEntry
+	next    *mapEntry
+	removed bool
+}
+
+// orderedMap stores entries by SameValueZero key in insertion order. Only
+// the tail may be a removed entry left in the list, so that entries added
+// later are still reached from it.
+type orderedMap struct {
+	index map[any]*mapEntry
+	head  *mapEntry
+	tail  *mapEntry
+	size  int
+}
+
+func newOrderedMap() *orderedMap {
+	head := &mapEntry{}
+	return &orderedMap{
+		index: make(map[any]*mapEntry),
+		head:  head,
+		tail:  head,
+	}
+}
+
+// mapKey folds keys that are SameValueZero into one Go map key.
+func mapKey(key any) any {
+	switch value := key.(type) {
+	case int64:
+		return float64(value)
+	case float64:
+		if math.IsNaN(value) {
+			return types.NaN{}
+		}
+		if value == 0 {
+			return 0.0
+		}
+	}
+	return key
+}
+
+// canonicalKey turns -0 into +0 before a key is stored.
+func canonicalKey(key any) any {
+	if value, ok := key.(float64); ok && value == 0 {
+		return 0.0
+	}
+	return key
+}
+
+func (data *orderedMap) get(key any) (any, bool) {
+	if entry, ok := data.index[mapKey(key)]; ok {
+		return entry.value, true
+	}
+	return nil, false
+}
+
+func (data *orderedMap) has(key any) bool {
+	_, ok := data.index[mapKey(key)]
+	return ok
+}
+
+func (data *orderedMap) set(key any, value any) {
+	if entry, ok := data.index[mapKey(key)]; ok {
+		entry.value = value
+		return
+	}
+	entry := &mapEntry{key: canonicalKey(key), value: value, prev: data.tail}
+	last := data.tail
+	last.next = entry
+	data.tail = entry
+	if last.removed {
+		data.unlink(last)
+	}
+	data.index[mapKey(key)] = entry
+	data.size++
+}
+
+func (data *orderedMap) delete(key any) bool {
+	entry, ok := data.index[mapKey(key)]
+	if !ok {
+		return false
+	}
+	delete(data.index, mapKey(key))
+	data.size--
+	entry.removed = true
+	entry.key, entry.value = nil, nil
+	if entry != data.tail {
+		data.unlink(entry)
+	}
+	return true
+}
+
+func (data *orderedMap) unlink(entry *mapEntry) {
+	entry.prev.next = entry.next
+	entry.next.prev = entry.prev
+}
+
+func (data *orderedMap) clear() {
+	if data.tail == data.head {
+		return
+	}
+	for entry := data.head.next; entry != nil; entry = entry.next {
+		entry.removed = true
+		entry.key, entry.value = nil, nil
+	}
+	data.head.next = data.tail
+	data.tail.prev = data.head
+	data.index = make(map[any]*mapEntry)
+	data.size = 0
+}
+
+// cursor walks the entries, including those added while it is in use.
+func (data *orderedMap) cursor() *mapCursor {
+	return &mapCursor{entry: data.head}
+}
+
+type mapCursor struct {
+	entry *mapEntry
+}
+
+// next reports false once the cursor is exhausted, and keeps doing so.
+func (cursor *mapCursor) next() (*mapEntry, bool) {
+	if cursor.entry == nil {
+		return nil, false
+	}
+	for entry := cursor.entry.next; entry != nil; entry = entry.next {
+		if !entry.removed {
+			cursor.entry = entry
+			return entry, true
+		}
+	}
+	cursor.entry = nil
+	return nil, false
+}
+
+// mapImpl is a Map or a Set; a Set stores each value as its own key.
+type mapImpl struct {
+	*instanceImpl
+	data  *orderedMap
+	isSet bool
+}
+
+func newMap(interpreter types.Interpreter) *mapImpl {
+	return &mapImpl{
+		instanceImpl: NewObject(realmOf(interpreter).mapPrototype).(*instanceImpl),
+		data:         newOrderedMap(),
+	}
+}
+
+func newSet(interpreter types.Interpreter) *mapImpl {
+	return &mapImpl{
+		instanceImpl: NewObject(realmOf(interpreter).setPrototype).(*instanceImpl),
+		data:         newOrderedMap(),
+		isSet:        true,
+	}
+}
+
+func (object *mapImpl) Get(key any) any {
+	if key == "size" {
+		return int64(object.data.size)
+	}
+	return object.instanceImpl.Get(key)
+}
+
+func thisMap(interpreter types.Interpreter, this any, name string, isSet bool) *mapImpl {
+	object, ok := this.(*mapImpl)
+	if !ok || object.isSet != isSet {
+		kind := "Map"
+		if isSet {
+			kind = "Set"
+		}
+		ThrowTypeError(interpreter, "Method %s.prototype.%s called on incompatible receiver %s", kind, name, describe(this))
+	}
+	return object
+}
+
+// mapIteratorImpl is the iterator of Map and Set keys, values and entries.
+type mapIteratorImpl struct {
+	*instanceImpl
+	cursor *mapCursor
+	kind   arrayIteratorKind
+	isSet  bool
+}
+
+func newMapIterator(interpreter types.Interpreter, object *mapImpl, kind arrayIteratorKind) types.Object {
+	prototype := realmOf(interpreter).mapIteratorPrototype
+	if object.isSet {
+		prototype = realmOf(interpreter).setIteratorPrototype
+	}
+	return &mapIteratorImpl{
+		instanceImpl: NewObject(prototype).(*instanceImpl),
+		cursor:       object.data.cursor(),
+		kind:         kind,
+		isSet:        object.isSet,
+	}
+}
+
+func newMapIteratorPrototype(realm *realm, tag string) types.Object {
+	prototype := NewObject(realm.objectPrototype).(*instanceImpl)
+	prototype.define("next", newNative(realm, "next", func(interpreter types.Interpreter, this any, params []any) any {
+		iterator, ok := this.(*mapIteratorImpl)
+		if !ok {
+			ThrowTypeError(interpreter, "next method called on incompatible receiver %s", describe(this))
+		}
+		entry, ok := iterator.cursor.next()
+		if !ok {
+			return NewIteratorResult(interpreter, nil, true)
+		}
+		switch iterator.kind {
+		case arrayIteratorKeys:
+			return NewIteratorResult(interpreter, entry.key, false)
+		case arrayIteratorEntries:
+			return NewIteratorResult(interpreter, NewArrayFrom(interpreter, []any{entry.key, entry.value}), false)
+		}
+		return NewIteratorResult(interpreter, entry.value, false)
+	}), false)
+	prototype.define(SymbolIterator, newNative(realm, "[Symbol.iterator]", func(interpreter types.Interpreter, this any, params []any) any {
+		return this
+	}), false)
+	prototype.define(SymbolToStringTag, tag, false)
+	return prototype
+}
+
+// addFromIterable passes the values of an iterable, or the key and value of
+// each entry, to the adder method of a new collection.
+func addFromIterable(interpreter types.Interpreter, collection types.Object, iterable any, name string, entries bool) {
+	if types.IsNullish(iterable) {
+		return
+	}
+	adder := collection.Get(name)
+	checkCallable(interpreter, adder)
+	iterator := GetIterator(interpreter, iterable)
+	for {
+		value, ok := iterator.Step(interpreter)
+		if !ok {
+			return
+		}
+		if reason, threw := recoverThrow(func() {
+			if !entries {
+				Invoke(interpreter, adder, collection, []any{value})
+				return
+			}
+			if _, ok := value.(types.Property); !ok {
+				ThrowTypeError(interpreter, "Iterator value %s is not an entry object", ToString(interpreter, value))
+			}
+			Invoke(interpreter, adder, collection, []any{GetProperty(interpreter, value, "0"), GetProperty(interpreter, value, "1")})
+		}); threw {
+			iterator.Close(interpreter)
+			panic(flow.NewThrow(reason))
+		}
+	}
+}
+
+// defineCollectionMethods adds the methods Map and Set share.
+func defineCollectionMethods(realm *realm, prototype *instanceImpl, isSet bool) {
+	method := func(name string, fn func(interpreter types.Interpreter, object *mapImpl, params []any) any) types.Method {
+		native := newNative(realm, name, func(interpreter types.Interpreter, this any, params []any) any {
+			return fn(interpreter, thisMap(interpreter, this, name, isSet), params)
+		})
+		prototype.define(name, native, false)
+		return native
+	}
+	method("has", func(interpreter types.Interpreter, object *mapImpl, params []any) any {
+		return object.data.has(GetArgument(params, 0))
+	})
+	method("delete", func(interpreter types.Interpreter, object *mapImpl, params []any) any {
+		return object.data.delete(GetArgument(params, 0))
+	})
+	method("clear", func(interpreter types.Interpreter, object *mapImpl, params []any) any {
+		object.data.clear()
+		return nil
+	})
+	method("forEach", func(interpreter types.Interpreter, object *mapImpl, params []any) any {
+		callback := GetArgument(params, 0)
+		checkCallable(interpreter, callback)
+		cursor := object.data.cursor()
+		for entry, ok := cursor.next(); ok; entry, ok = cursor.next() {
+			Invoke(interpreter, callback, GetArgument(params, 1), []any{entry.value, entry.key, object})
+		}
+		return nil
+	})
+	method("entries", func(interpreter types.Interpreter, object *mapImpl, params []any) any {
+		return newMapIterator(interpreter, object, arrayIteratorEntries)
+	})
+	values := method("values", func(interpreter types.Interpreter, object *mapImpl, params []any) any {
+		return newMapIterator(interpreter, object, arrayIteratorValues)
+	})
+	if isSet {
+		prototype.define("keys", values, false)
+		prototype.define(SymbolIterator, values, false)
+		prototype.define(SymbolToStringTag, "Set", false)
+		return
+	}
+	method("keys", func(interpreter types.Interpreter, object *mapImpl, params []any) any {
+		return newMapIterator(interpreter, object, arrayIteratorKeys)
+	})
+	prototype.define(SymbolIterator, prototype.Get("entries"), false)
+	prototype.define(SymbolToStringTag, "Map", false)
+}
+
+func newMapPrototype(realm *realm) *instanceImpl {
+	prototype := NewObject(realm.objectPrototype).(*instanceImpl)
+	prototype.define("get", newNative(realm, "get", func(interpreter types.Interpreter, this any, params []any) any {
+		value, _ := thisMap(interpreter, this, "get", false).data.get(GetArgument(params, 0))
+		return value
+	}), false)
+	prototype.define("set", newNative(realm, "set", func(interpreter types.Interpreter, this any, params []any) any {
+		thisMap(interpreter, this, "set", false).data.set(GetArgument(params, 0), GetArgument(params, 1))
+		return this
+	}), false)
+	defineCollectionMethods(realm, prototype, false)
+	return prototype
+}
+
+// setRecord is the size, has and keys of the argument of a Set method, which
+// only needs to be set-like.
+type setRecord struct {
+	object any
+	size   float64
+	has    any
+	keys   any
+}
+
+func getSetRecord(interpreter types.Interpreter, value any, name string) setRecord {
+	if _, ok := value.(types.Property); !ok {
+		ThrowTypeError(interpreter, "Set.prototype.%s argument must be an object", name)
+	}
+	size := ToNumber(interpreter, GetProperty(interpreter, value, "size"))
+	if math.IsNaN(size) {
+		ThrowTypeError(interpreter, "The .size property is NaN")
+	}
+	record := setRecord{object: value, size: toIntegerOrInfinity(interpreter, size)}
+	if record.size < 0 {
+		ThrowRangeError(interpreter, "'%s' is an invalid size", ToString(interpreter, size))
+	}
+	record.has = GetProperty(interpreter, value, "has")
+	checkCallable(interpreter, record.has)
+	record.keys = GetProperty(interpreter, value, "keys")
+	checkCallable(interpreter, record.keys)
+	return record
+}
+
+func (record setRecord) contains(interpreter types.Interpreter, value any) bool {
+	return ToBoolean(Invoke(interpreter, record.has, record.object, []any{value}))
+}
+
+// iterate calls fn with every key of the set-like object until it returns
+// false, closing the iterator in that case.
+func (record setRecord) iterate(interpreter types.Interpreter, fn func(value any) bool) {
+	object := Invoke(interpreter, record.keys, record.object, nil)
+	if _, ok := object.(types.Property); !ok {
+		ThrowTypeError(interpreter, "%s is not an object", describe(object))
+	}
+	iterator := &Iterator{object: object, next: GetProperty(interpreter, object, "next")}
+	for {
+		value, ok := iterator.Step(interpreter)
+		if !ok {
+			return
+		}
+		if !fn(canonicalKey(value)) {
+			iterator.Close(interpreter)
+			return
+		}
+	}
+}
+
+// copySet creates a Set with the values of another one.
+func copySet(interpreter types.Interpreter, object *mapImpl) *mapImpl {
+	result := newSet(interpreter)
+	cursor := object.data.cursor()
+	for entry, ok := cursor.next(); ok; entry, ok = cursor.next() {
+		result.data.set(entry.key, entry.value)
+	}
+	return result
+}
+
+func newSetPrototype(realm *realm) *instanceImpl {
+	prototype := NewObject(realm.objectPrototype).(*instanceImpl)
+	prototype.define("add", newNative(realm, "add", func(interpreter types.Interpreter, this any, params []any) any {
+		value := canonicalKey(GetArgument(params, 0))
+		thisMap(interpreter, this, "add", true).data.set(value, value)
+		return this
+	}), false)
+	defineCollectionMethods(realm, prototype, true)
+	method := func(name string, fn func(interpreter types.Interpreter, object *mapImpl, other setRecord) any) {
+		prototype.define(name, newNative(realm, name, func(interpreter types.Interpreter, this any, params []any) any {
+			object := thisMap(interpreter, this, name, true)
+			return fn(interpreter, object, getSetRecord(interpreter, GetArgument(params, 0), name))
+		}), false)
+	}
+	// each calls fn with the values of a Set until it returns false.
+	each := func(object *mapImpl, fn func(value any) bool) {
+		cursor := object.data.cursor()
+		for entry, ok := cursor.next(); ok; entry, ok = cursor.next() {
+			if !fn(entry.key) {
+				return
+			}
+		}
+	}
+	method("union", func(interpreter types.Interpreter, object *mapImpl, other setRecord) any {
+		result := copySet(interpreter, object)
+		other.iterate(interpreter, func(value any) bool {
+			result.data.set(value, value)
+			return true
+		})
+		return result
+	})
+	method("intersection", func(interpreter types.Interpreter, object *mapImpl, other setRecord) any {
+		result := newSet(interpreter)
+		if float64(object.data.size) <= other.size {
+			each(object, func(value any) bool {
+				if other.contains(interpreter, value) {
+					result.data.set(value, value)
+				}
+				return true
+			})
+		} else {
+			other.iterate(interpreter, func(value any) bool {
+				if object.data.has(value) {
+					result.data.set(value, value)
+				}
+				return true
+			})
+		}
+		return result
+	})
+	method("difference", func(interpreter types.Interpreter, object *mapImpl, other setRecord) any {
+		result := copySet(interpreter, object)
+		if float64(object.data.size) <= other.size {
+			each(object, func(value any) bool {
+				if other.contains(interpreter, value) {
+					result.data.delete(value)
+				}
+				return true
+			})
+		} else {
+			other.iterate(interpreter, func(value any) bool {
+				result.data.delete(value)
+				return true
+			})
+		}
+		return result
+	})
+	method("symmetricDifference", func(interpreter types.Interpreter, object *mapImpl, other setRecord) any {
+		result := copySet(interpreter, object)
+		other.iterate(interpreter, func(value any) bool {
+			if object.data.has(value) {
+				result.data.delete(value)
+			} else {
+				result.data.set(value, value)
+			}
+			return true
+		})
+		return result
+	})
+	method("isSubsetOf", func(interpreter types.Interpreter, object *mapImpl, other setRecord) any {
+		if float64(object.data.size) > other.size {
+			return false
+		}
+		result := true
+		each(object, func(value any) bool {
+			result = other.contains(interpreter, value)
+			return result
+		})
+		return result
+	})
+	method("isSupersetOf", func(interpreter types.Interpreter, object *mapImpl, other setRecord) any {
+		if float64(object.data.size) < other.size {
+			return false
+		}
+		result := true
+		other.iterate(interpreter, func(value any) bool {
+			result = object.data.has(value)
+			return result
+		})
+		return result
+	})
+	method("isDisjointFrom", func(interpreter types.Interpreter, object *mapImpl, other setRecord) any {
+		result := true
+		if float64(object.data.size) <= other.size {
+			each(object, func(value any) bool {
+				result = !other.contains(interpreter, value)
+				return result
+			})
+		} else {
+			other.iterate(interpreter, func(value any) bool {
+				result = !object.data.has(value)
+				return result
+			})
+		}
+		return result
+	})
+	return prototype
+}
+
+func newMapConstructor(realm *realm) types.Object {
+	constructor := newConstructor(realm, "Map", func(interpreter types.Interpreter, this any, params []any) any {
+		ThrowTypeError(interpreter, "Constructor Map requires 'new'")
+		return nil
+	}, func(interpreter types.Interpreter, params []any) any {
+		object := newMap(interpreter)
+		addFromIterable(interpreter, object, GetArgument(params, 0), "set", true)
+		return object
+	}).(*nativeImpl)
+	constructor.define("groupBy", newNative(realm, "groupBy", func(interpreter types.Interpreter, this any, params []any) any {
+		result := newMap(interpreter)
+		groupBy(interpreter, params, func(key any, value any) {
+			group, ok := result.data.get(key)
+			if !ok {
+				group = NewArray(interpreter)
+				result.data.set(key, group)
+			}
+			array := group.(types.Object)
+			array.Set(lengthOf(interpreter, array), value)
+		})
+		return result
+	}), false)
+	constructor.define("prototype", realm.mapPrototype, false)
+	realm.mapPrototype.define("constructor", constructor, false)
+	return constructor
+}
+
+func newSetConstructor(realm *realm) types.Object {
+	constructor := newConstructor(realm, "Set", func(interpreter types.Interpreter, this any, params []any) any {
+		ThrowTypeError(interpreter, "Constructor Set requires 'new'")
+		return nil
+	}, func(interpreter types.Interpreter, params []any) any {
+		object := newSet(interpreter)
+		addFromIterable(interpreter, object, GetArgument(params, 0), "add", false)
+		return object
+	}).(*nativeImpl)
+	constructor.define("prototype", realm.setPrototype, false)
+	realm.setPrototype.define("constructor", constructor, false)
+	return constructor
+}
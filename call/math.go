@@ -0,0 +1,190 @@
+package call
+
+import (
+	"math"
+	"math/bits"
+
+	"github.com/nusr/gojs/types"
+)
+
+func newMath(realm *realm) types.Object {
+	object := NewObject(realm.objectPrototype).(*instanceImpl)
+	constants := []struct {
+		name  string
+		value float64
+	}{
+		{"E", math.E},
+		{"LN10", math.Ln10},
+		{"LN2", math.Ln2},
+		{"LOG10E", math.Log10E},
+		{"LOG2E", math.Log2E},
+		{"PI", math.Pi},
+		{"SQRT1_2", 1 / math.Sqrt2},
+		{"SQRT2", math.Sqrt2},
+	}
+	for _, item := range constants {
+		object.define(item.name, item.value, false)
+	}
+	unary := []struct {
+		name string
+		fn   func(float64) float64
+	}{
+		{"abs", math.Abs},
+		{"acos", math.Acos},
+		{"acosh", math.Acosh},
+		{"asin", math.Asin},
+		{"asinh", math.Asinh},
+		{"atan", math.Atan},
+		{"atanh", math.Atanh},
+		{"cbrt", math.Cbrt},
+		{"ceil", math.Ceil},
+		{"cos", math.Cos},
+		{"cosh", math.Cosh},
+		{"exp", math.Exp},
+		{"expm1", math.Expm1},
+		{"floor", math.Floor},
+		{"fround", func(x float64) float64 { return float64(float32(x)) }},
+		{"log", math.Log},
+		{"log1p", math.Log1p},
+		{"log10", math.Log10},
+		{"log2", math.Log2},
+		{"round", mathRound},
+		{"sign", mathSign},
+		{"sin", math.Sin},
+		{"sinh", math.Sinh},
+		{"sqrt", math.Sqrt},
+		{"tan", math.Tan},
+		{"tanh", math.Tanh},
+		{"trunc", math.Trunc},
+	}
+	for _, item := range unary {
+		fn := item.fn
+		object.define(item.name, newNative(realm, item.name, func(interpreter types.Interpreter, this any, params []any) any {
+			return fn(ToNumber(interpreter, GetArgument(params, 0)))
+		}), false)
+	}
+	object.define("atan2", newNative(realm, "atan2", func(interpreter types.Interpreter, this any, params []any) any {
+		y := ToNumber(interpreter, GetArgument(params, 0))
+		x := ToNumber(interpreter, GetArgument(params, 1))
+		return math.Atan2(y, x)
+	}), false)
+	object.define("pow", newNative(realm, "pow", func(interpreter types.Interpreter, this any, params []any) any {
+		x := ToNumber(interpreter, GetArgument(params, 0))
+		y := ToNumber(interpreter, GetArgument(params, 1))
+		return mathPow(x, y)
+	}), false)
+	object.define("clz32", newNative(realm, "clz32", func(interpreter types.Interpreter, this any, params []any) any {
+		return float64(bits.LeadingZeros32(ToUint32(interpreter, GetArgument(params, 0))))
+	}), false)
+	object.define("imul", newNative(realm, "imul", func(interpreter types.Interpreter, this any, params []any) any {
+		a := int32(ToUint32(interpreter, GetArgument(params, 0)))
+		b := int32(ToUint32(interpreter, GetArgument(params, 1)))
+		return float64(a * b)
+	}), false)
+	object.define("hypot", newNative(realm, "hypot", func(interpreter types.Interpreter, this any, params []any) any {
+		return mathHypot(toNumbers(interpreter, params))
+	}), false)
+	object.define("max", newNative(realm, "max", func(interpreter types.Interpreter, this any, params []any) any {
+		result := math.Inf(-1)
+		for _, value := range toNumbers(interpreter, params) {
+			if math.IsNaN(value) || math.IsNaN(result) {
+				result = math.NaN()
+			} else if value > result || (value == 0 && result == 0 && !math.Signbit(value)) {
+				result = value
+			}
+		}
+		return result
+	}), false)
+	object.define("min", newNative(realm, "min", func(interpreter types.Interpreter, this any, params []any) any {
+		result := math.Inf(1)
+		for _, value := range toNumbers(interpreter, params) {
+			if math.IsNaN(value) || math.IsNaN(result) {
+				result = math.NaN()
+			} else if value < result || (value == 0 && result == 0 && math.Signbit(value)) {
+				result = value
+			}
+		}
+		return result
+	}), false)
+	object.define("random", newNative(realm, "random", func(interpreter types.Interpreter, this any, params []any) any {
+		return interpreter.Random()
+	}), false)
+	object.define(SymbolToStringTag, "Math", false)
+	return object
+}
+
+// toNumbers converts every argument before any of them is used, so all of
+// their valueOf methods run.
+func toNumbers(interpreter types.Interpreter, params []any) []float64 {
+	list := make([]float64, len(params))
+	for i, item := range params {
+		list[i] = ToNumber(interpreter, item)
+	}
+	return list
+}
+
+// mathRound rounds half up, keeping -0 for numbers in [-0.5, 0).
+func mathRound(x float64) float64 {
+	if math.IsNaN(x) || math.IsInf(x, 0) || x == math.Trunc(x) {
+		return x
+	}
+	if x < 0 && x >= -0.5 {
+		return math.Copysign(0, -1)
+	}
+	result := math.Floor(x)
+	if x-result >= 0.5 {
+		result++
+	}
+	return result
+}
+
+func mathSign(x float64) float64 {
+	if math.IsNaN(x) || x == 0 {
+		return x
+	}
+	if x > 0 {
+		return 1
+	}
+	return -1
+}
+
+// mathPow differs from math.Pow where Go treats 1 as a fixed point.
+func mathPow(x float64, y float64) float64 {
+	if math.IsNaN(y) || (math.Abs(x) == 1 && math.IsInf(y, 0)) {
+		return math.NaN()
+	}
+	return math.Pow(x, y)
+}
+
+// mathHypot scales by the largest value and sums with Kahan compensation,
+// as V8 does.
+func mathHypot(list []float64) float64 {
+	largest := 0.0
+	hasNaN := false
+	for _, value := range list {
+		if math.IsInf(value, 0) {
+			return math.Inf(1)
+		}
+		if math.IsNaN(value) {
+			hasNaN = true
+		} else if math.Abs(value) > largest {
+			largest = math.Abs(value)
+		}
+	}
+	if hasNaN {
+		return math.NaN()
+	}
+	if largest == 0 {
+		return 0
+	}
+	sum := 0.0
+	compensation := 0.0
+	for _, value := range list {
+		n := math.Abs(value) / largest
+		summand := n*n - compensation
+		preliminary := sum + summand
+		compensation = (preliminary - sum) - summand
+		sum = preliminary
+	}
+	return math.Sqrt(sum) * largest
+}
@@ -0,0 +1,145 @@
+package call
+
+import (
+	"fmt"
+
+	"github.com/nusr/gojs/flow"
+	"github.com/nusr/gojs/token"
+	"github.com/nusr/gojs/types"
+)
+
+type NativeFunction func(interpreter types.Interpreter, this any, params []any) any
+
+// NativeConstructor creates the object for `new` on a built-in constructor.
+type NativeConstructor func(interpreter types.Interpreter, params []any) any
+
+type nativeImpl struct {
+	*instanceImpl
+	name      string
+	fn        NativeFunction
+	construct NativeConstructor
+}
+
+// NewNative creates a built-in function of the realm of interpreter.
+func NewNative(interpreter types.Interpreter, name string, fn NativeFunction) types.Method {
+	return newNative(realmOf(interpreter), name, fn)
+}
+
+func newNative(realm *realm, name string, fn NativeFunction) types.Method {
+	return newConstructor(realm, name, fn, nil)
+}
+
+// newConstructor creates a built-in function; a nil construct makes `new`
+// throw a TypeError.
+func newConstructor(realm *realm, name string, fn NativeFunction, construct NativeConstructor) types.Method {
+	native := &nativeImpl{
+		instanceImpl: NewObject(realm.functionPrototype).(*instanceImpl),
+		name:         name,
+		fn:           fn,
+		construct:    construct,
+	}
+	native.define("name", name, false)
+	return native
+}
+
+func (native *nativeImpl) Construct(interpreter types.Interpreter, params []any) any {
+	if native.construct == nil {
+		ThrowTypeError(interpreter, "%s is not a constructor", native.name)
+	}
+	return native.construct(interpreter, params)
+}
+
+func (native *nativeImpl) Call(interpreter types.Interpreter, params []any) any {
+	return native.fn(interpreter, nil, params)
+}
+
+func (native *nativeImpl) CallWith(interpreter types.Interpreter, this any, params []any) any {
+	return native.fn(interpreter, this, params)
+}
+
+func (native *nativeImpl) String() string {
+	return "function " + native.name + "() { [native code] }"
+}
+
+// Invoke calls a function value with the given receiver.
+func Invoke(interpreter types.Interpreter, callable any, this any, params []any) any {
+	var result any
+	if val, ok := callable.(types.Method); ok {
+		result = val.CallWith(interpreter, this, params)
+	} else if val, ok := callable.(types.Function); ok {
+		result = val.Call(interpreter, params)
+	} else {
+		ThrowTypeError(interpreter, "%s is not a function", describe(callable))
+	}
+	if val, ok := result.(flow.Return); ok {
+		return val.Value
+	}
+	return result
+}
+
+// Construct invokes a constructor value the way `new` does.
+func Construct(interpreter types.Interpreter, callee any, params []any) any {
+	if val, ok := callee.(types.Constructor); ok {
+		return val.Construct(interpreter, params)
+	}
+	ThrowTypeError(interpreter, "%s is not a constructor", describe(callee))
+	return nil
+}
+
+// GetArgument returns the parameter at index, or nil when it is missing.
+func GetArgument(params []any, index int) any {
+	if index < len(params) {
+		return params[index]
+	}
+	return nil
+}
+
+// nullable returns null in place of a missing object.
+func nullable(value types.Property) any {
+	if value == nil {
+		return types.Null{}
+	}
+	return value
+}
+
+// throwError throws a new error of one of the built-in Error constructors.
+func throwError(interpreter types.Interpreter, name string, format string, a ...any) {
+	panic(flow.NewThrow(NewError(interpreter, name, fmt.Sprintf(format, a...), nil)))
+}
+
+func ThrowTypeError(interpreter types.Interpreter, format string, a ...any) {
+	throwError(interpreter, "TypeError", format, a...)
+}
+
+func ThrowRangeError(interpreter types.Interpreter, format string, a ...any) {
+	throwError(interpreter, "RangeError", format, a...)
+}
+
+func ThrowURIError(interpreter types.Interpreter, format string, a ...any) {
+	throwError(interpreter, "URIError", format, a...)
+}
+
+func ThrowSyntaxError(interpreter types.Interpreter, format string, a ...any) {
+	throwError(interpreter, "SyntaxError", format, a...)
+}
+
+// throwDOMException throws the DOMException of a web API, such as a
+// DataCloneError, by its name.
+func throwDOMException(interpreter types.Interpreter, name string, format string, a ...any) {
+	panic(flow.NewThrow(NewDOMException(interpreter, fmt.Sprintf(format, a...), name)))
+}
+
+// describe names a value for error messages.
+func describe(value any) string {
+	switch value.(type) {
+	case nil:
+		return "undefined"
+	case types.Null:
+		return "null"
+	case types.Function:
+		return "function"
+	case types.Property:
+		return "object"
+	}
+	return token.ConvertAnyToString(value)
+}
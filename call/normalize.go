@@ -0,0 +1,126 @@
+package call
+
+//go:generate go run gen_unicode.go
+
+type decomposition struct {
+	compat  bool
+	mapping []rune
+}
+
+// compositions maps a pair of characters to their primary composite.
+var compositions = make(map[[2]rune]rune)
+
+func init() {
+	for r, value := range decompositions {
+		if value.compat || len(value.mapping) != 2 || compositionExclusions[r] {
+			continue
+		}
+		if combiningClasses[r] != 0 || combiningClasses[value.mapping[0]] != 0 {
+			continue
+		}
+		compositions[[2]rune{value.mapping[0], value.mapping[1]}] = r
+	}
+}
+
+// Hangul syllables are composed and decomposed algorithmically.
+const (
+	hangulBase   = 0xAC00
+	hangulL      = 0x1100
+	hangulV      = 0x1161
+	hangulT      = 0x11A7
+	hangulLCount = 19
+	hangulVCount = 21
+	hangulTCount = 28
+	hangulNCount = hangulVCount * hangulTCount
+	hangulCount  = hangulLCount * hangulNCount
+)
+
+func decompose(result []rune, r rune, compat bool) []rune {
+	if index := r - hangulBase; index >= 0 && index < hangulCount {
+		result = append(result, hangulL+index/hangulNCount, hangulV+index%hangulNCount/hangulTCount)
+		if t := index % hangulTCount; t != 0 {
+			result = append(result, hangulT+t)
+		}
+		return result
+	}
+	if value, ok := decompositions[r]; ok && (compat || !value.compat) {
+		for _, item := range value.mapping {
+			result = decompose(result, item, compat)
+		}
+		return result
+	}
+	return append(result, r)
+}
+
+// decomposeString fully decomposes text and puts combining marks in
+// canonical order.
+func decomposeString(text string, compat bool) []rune {
+	var result []rune
+	for _, r := range text {
+		result = decompose(result, r, compat)
+	}
+	for i := 1; i < len(result); i++ {
+		for j := i; j > 0; j-- {
+			class := combiningClasses[result[j]]
+			previous := combiningClasses[result[j-1]]
+			if class == 0 || previous <= class {
+				break
+			}
+			result[j], result[j-1] = result[j-1], result[j]
+		}
+	}
+	return result
+}
+
+func compose(first rune, second rune) (rune, bool) {
+	if l := first - hangulL; l >= 0 && l < hangulLCount {
+		if v := second - hangulV; v >= 0 && v < hangulVCount {
+			return hangulBase + (l*hangulVCount+v)*hangulTCount, true
+		}
+	}
+	if index := first - hangulBase; index >= 0 && index < hangulCount && index%hangulTCount == 0 {
+		if t := second - hangulT; t > 0 && t < hangulTCount {
+			return first + t, true
+		}
+	}
+	r, ok := compositions[[2]rune{first, second}]
+	return r, ok
+}
+
+// composeRunes applies canonical composition to decomposed text.
+func composeRunes(list []rune) []rune {
+	if len(list) == 0 {
+		return list
+	}
+	result := []rune{list[0]}
+	starter := -1
+	if combiningClasses[list[0]] == 0 {
+		starter = 0
+	}
+	var last uint8
+	for _, r := range list[1:] {
+		class := combiningClasses[r]
+		if starter >= 0 && (last < class || (last == 0 && len(result)-1 == starter)) {
+			if composite, ok := compose(result[starter], r); ok {
+				result[starter] = composite
+				continue
+			}
+		}
+		if class == 0 {
+			starter = len(result)
+		}
+		last = class
+		result = append(result, r)
+	}
+	return result
+}
+
+// Normalize converts text to one of the forms NFC, NFD, NFKC and NFKD.
+func Normalize(text string, form string) string {
+	compat := form == "NFKC" || form == "NFKD"
+	list := decomposeString(text, compat)
+	if form == "NFC" || form == "NFKC" {
+		list = composeRunes(list)
+	}
+	return string(list)
+}
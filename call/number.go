@@ -0,0 +1,484 @@
+package call
+
+import (
+	"math"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/nusr/gojs/types"
+)
+
+// numberImpl is a Number object, the boxed form of a number primitive.
+type numberImpl struct {
+	*instanceImpl
+	value float64
+}
+
+func newNumberObject(interpreter types.Interpreter, value float64) *numberImpl {
+	return &numberImpl{
+		instanceImpl: NewObject(realmOf(interpreter).numberPrototype).(*instanceImpl),
+		value:        value,
+	}
+}
+
+// getNumberProperty reads a property of a number primitive.
+func getNumberProperty(interpreter types.Interpreter, key any) any {
+	return realmOf(interpreter).numberPrototype.Get(key)
+}
+
+func thisNumber(interpreter types.Interpreter, this any, name string) float64 {
+	switch data := this.(type) {
+	case *numberImpl:
+		return data.value
+	case int64, float64, types.NaN:
+		number, _ := toFloat(data)
+		return number
+	}
+	ThrowTypeError(interpreter, "Number.prototype.%s requires that 'this' be a Number", name)
+	return 0
+}
+
+func newNumberPrototype(realm *realm) *instanceImpl {
+	prototype := NewObject(realm.objectPrototype).(*instanceImpl)
+	method := func(name string, fn func(interpreter types.Interpreter, x float64, params []any) any) {
+		prototype.define(name, newNative(realm, name, func(interpreter types.Interpreter, this any, params []any) any {
+			return fn(interpreter, thisNumber(interpreter, this, name), params)
+		}), false)
+	}
+	method("toString", func(interpreter types.Interpreter, x float64, params []any) any {
+		radix := 10.0
+		if value := GetArgument(params, 0); value != nil {
+			radix = toIntegerOrInfinity(interpreter, value)
+		}
+		if radix < 2 || radix > 36 {
+			ThrowRangeError(interpreter, "toString() radix argument must be between 2 and 36")
+		}
+		if radix == 10 {
+			return NumberToString(x)
+		}
+		return numberToRadixString(x, int(radix))
+	})
+	method("valueOf", func(interpreter types.Interpreter, x float64, params []any) any {
+		return x
+	})
+	method("toFixed", func(interpreter types.Interpreter, x float64, params []any) any {
+		digits := toIntegerOrInfinity(interpreter, GetArgument(params, 0))
+		if digits < 0 || digits > 100 {
+			ThrowRangeError(interpreter, "toFixed() digits argument must be between 0 and 100")
+		}
+		return numberToFixed(x, int(digits))
+	})
+	method("toExponential", func(interpreter types.Interpreter, x float64, params []any) any {
+		value := GetArgument(params, 0)
+		digits := toIntegerOrInfinity(interpreter, value)
+		if math.IsNaN(x) || math.IsInf(x, 0) {
+			return NumberToString(x)
+		}
+		if digits < 0 || digits > 100 {
+			ThrowRangeError(interpreter, "toExponential() argument must be between 0 and 100")
+		}
+		if value == nil {
+			return numberToExponential(x, -1)
+		}
+		return numberToExponential(x, int(digits))
+	})
+	method("toLocaleString", func(interpreter types.Interpreter, x float64, params []any) any {
+		return newNumberFormat(interpreter, GetArgument(params, 0), GetArgument(params, 1)).format(x)
+	})
+	method("toPrecision", func(interpreter types.Interpreter, x float64, params []any) any {
+		value := GetArgument(params, 0)
+		if value == nil {
+			return NumberToString(x)
+		}
+		precision := toIntegerOrInfinity(interpreter, value)
+		if math.IsNaN(x) || math.IsInf(x, 0) {
+			return NumberToString(x)
+		}
+		if precision < 1 || precision > 100 {
+			ThrowRangeError(interpreter, "toPrecision() argument must be between 1 and 100")
+		}
+		return numberToPrecision(x, int(precision))
+	})
+	return prototype
+}
+
+// numberToFixed formats x with digits decimals. Exact ties round away
+// from zero, so 1.005 stays 1.00 (it is stored below the tie) while 2.5
+// becomes 3.
+func numberToFixed(x float64, digits int) string {
+	if math.IsNaN(x) || math.IsInf(x, 0) || math.Abs(x) >= 1e21 {
+		return NumberToString(x)
+	}
+	sign := ""
+	if x < 0 {
+		sign = "-"
+		x = -x
+	}
+	text := roundScaled(x, digits).String()
+	if digits == 0 {
+		return sign + text
+	}
+	if len(text) <= digits {
+		text = strings.Repeat("0", digits+1-len(text)) + text
+	}
+	return sign + text[:len(text)-digits] + "." + text[len(text)-digits:]
+}
+
+// roundScaled returns x × 10^digits rounded to the nearest integer, taking
+// the larger one on a tie. x must not be negative.
+func roundScaled(x float64, digits int) *big.Int {
+	value := new(big.Rat).SetFloat64(x)
+	scale := new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs(digits))), nil))
+	if digits >= 0 {
+		value.Mul(value, scale)
+	} else {
+		value.Quo(value, scale)
+	}
+	value.Add(value, big.NewRat(1, 2))
+	return new(big.Int).Quo(value.Num(), value.Denom())
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// significantDigits rounds x > 0 to count significant digits, returning
+// them with the decimal exponent of the first one.
+func significantDigits(x float64, count int) (string, int) {
+	exponent := int(math.Floor(math.Log10(x)))
+	// the estimate can be off by one near powers of ten
+	ten := new(big.Rat).SetFloat64(x)
+	for ten.Cmp(powerOfTen(exponent)) < 0 {
+		exponent--
+	}
+	for ten.Cmp(powerOfTen(exponent+1)) >= 0 {
+		exponent++
+	}
+	digits := roundScaled(x, count-1-exponent).String()
+	if len(digits) > count {
+		// rounding carried into a new digit, like 9.99 to 10.0
+		exponent++
+		digits = digits[:count]
+	}
+	return digits, exponent
+}
+
+func powerOfTen(exponent int) *big.Rat {
+	power := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs(exponent))), nil)
+	if exponent < 0 {
+		return new(big.Rat).SetFrac(big.NewInt(1), power)
+	}
+	return new(big.Rat).SetInt(power)
+}
+
+// numberToExponential formats finite x in exponential notation with
+// fraction digits, or as few as identify x when fraction is -1.
+func numberToExponential(x float64, fraction int) string {
+	sign := ""
+	if x < 0 {
+		sign = "-"
+		x = -x
+	}
+	var digits string
+	var exponent int
+	switch {
+	case x == 0:
+		digits = strings.Repeat("0", fraction+1)
+		if fraction < 0 {
+			digits = "0"
+		}
+	case fraction < 0:
+		text := strconv.FormatFloat(x, 'e', -1, 64)
+		index := strings.IndexByte(text, 'e')
+		digits = strings.Replace(text[:index], ".", "", 1)
+		exponent, _ = strconv.Atoi(text[index+1:])
+	default:
+		digits, exponent = significantDigits(x, fraction+1)
+	}
+	if len(digits) > 1 {
+		digits = digits[:1] + "." + digits[1:]
+	}
+	return sign + digits + exponentSuffix(exponent)
+}
+
+func exponentSuffix(exponent int) string {
+	if exponent < 0 {
+		return "e-" + strconv.Itoa(-exponent)
+	}
+	return "e+" + strconv.Itoa(exponent)
+}
+
+// numberToPrecision formats finite x with precision significant digits.
+func numberToPrecision(x float64, precision int) string {
+	sign := ""
+	if x < 0 {
+		sign = "-"
+		x = -x
+	}
+	digits := strings.Repeat("0", precision)
+	exponent := 0
+	if x != 0 {
+		digits, exponent = significantDigits(x, precision)
+	}
+	if exponent < -6 || exponent >= precision {
+		if precision > 1 {
+			digits = digits[:1] + "." + digits[1:]
+		}
+		return sign + digits + exponentSuffix(exponent)
+	}
+	if exponent == precision-1 {
+		return sign + digits
+	}
+	if exponent >= 0 {
+		return sign + digits[:exponent+1] + "." + digits[exponent+1:]
+	}
+	return sign + "0." + strings.Repeat("0", -exponent-1) + digits
+}
+
+// numberToRadixString formats x in a radix other than 10 the way V8 does,
+// writing fraction digits only up to the precision of x.
+func numberToRadixString(x float64, radix int) string {
+	if math.IsNaN(x) || math.IsInf(x, 0) {
+		return NumberToString(x)
+	}
+	const chars = "0123456789abcdefghijklmnopqrstuvwxyz"
+	negative := x < 0
+	if negative {
+		x = -x
+	}
+	integer := math.Floor(x)
+	fraction := x - integer
+	delta := math.Max(0.5*(math.Nextafter(x, math.Inf(1))-x), math.SmallestNonzeroFloat64)
+	var fractionDigits []byte
+	if fraction >= delta {
+		for {
+			fraction *= float64(radix)
+			delta *= float64(radix)
+			digit := int(fraction)
+			fractionDigits = append(fractionDigits, chars[digit])
+			fraction -= float64(digit)
+			if fraction > 0.5 || (fraction == 0.5 && digit&1 == 1) {
+				if fraction+delta > 1 {
+					// round up, carrying into earlier digits
+					for {
+						if len(fractionDigits) == 0 {
+							integer++
+							break
+						}
+						last := strings.IndexByte(chars, fractionDigits[len(fractionDigits)-1])
+						fractionDigits = fractionDigits[:len(fractionDigits)-1]
+						if last+1 < radix {
+							fractionDigits = append(fractionDigits, chars[last+1])
+							break
+						}
+					}
+					break
+				}
+			}
+			if fraction < delta {
+				break
+			}
+		}
+	}
+	var integerDigits []byte
+	// digits below the precision of a large integer are written as zeros
+	for doubleExponent(integer/float64(radix)) > 0 {
+		integer /= float64(radix)
+		integerDigits = append(integerDigits, '0')
+	}
+	for {
+		remainder := math.Mod(integer, float64(radix))
+		integerDigits = append(integerDigits, chars[int(remainder)])
+		integer = (integer - remainder) / float64(radix)
+		if integer <= 0 {
+			break
+		}
+	}
+	var result strings.Builder
+	if negative {
+		result.WriteByte('-')
+	}
+	for i := len(integerDigits) - 1; i >= 0; i-- {
+		result.WriteByte(integerDigits[i])
+	}
+	if len(fractionDigits) > 0 {
+		result.WriteByte('.')
+		result.Write(fractionDigits)
+	}
+	return result.String()
+}
+
+// doubleExponent is the binary exponent of the last bit of the significand
+// of x, positive once x is at least 2^53.
+func doubleExponent(x float64) int {
+	biased := int(math.Float64bits(x) >> 52 & 0x7FF)
+	if biased == 0 {
+		return -1074
+	}
+	return biased - 1075
+}
+
+func newNumberConstructor(realm *realm) types.Object {
+	toNumber := func(interpreter types.Interpreter, params []any) float64 {
+		if len(params) == 0 {
+			return 0
+		}
+		return ToNumber(interpreter, params[0])
+	}
+	constructor := newConstructor(realm, "Number", func(interpreter types.Interpreter, this any, params []any) any {
+		return toNumber(interpreter, params)
+	}, func(interpreter types.Interpreter, params []any) any {
+		return newNumberObject(interpreter, toNumber(interpreter, params))
+	}).(*nativeImpl)
+	constants := []struct {
+		name  string
+		value float64
+	}{
+		{"EPSILON", math.Nextafter(1, 2) - 1},
+		{"MAX_SAFE_INTEGER", maxSafeInteger},
+		{"MIN_SAFE_INTEGER", -maxSafeInteger},
+		{"MAX_VALUE", math.MaxFloat64},
+		{"MIN_VALUE", math.SmallestNonzeroFloat64},
+		{"NaN", math.NaN()},
+		{"POSITIVE_INFINITY", math.Inf(1)},
+		{"NEGATIVE_INFINITY", math.Inf(-1)},
+	}
+	for _, item := range constants {
+		constructor.define(item.name, item.value, false)
+	}
+	predicate := func(name string, fn func(x float64) bool) {
+		constructor.define(name, newNative(realm, name, func(interpreter types.Interpreter, this any, params []any) any {
+			x, ok := toFloat(GetArgument(params, 0))
+			return ok && fn(x)
+		}), false)
+	}
+	predicate("isFinite", func(x float64) bool {
+		return !math.IsNaN(x) && !math.IsInf(x, 0)
+	})
+	predicate("isInteger", isIntegralNumber)
+	predicate("isNaN", math.IsNaN)
+	predicate("isSafeInteger", func(x float64) bool {
+		return isIntegralNumber(x) && math.Abs(x) <= maxSafeInteger
+	})
+	constructor.define("parseFloat", realm.parseFloatFunction, false)
+	constructor.define("parseInt", realm.parseIntFunction, false)
+	constructor.define("prototype", realm.numberPrototype, false)
+	realm.numberPrototype.define("constructor", constructor, false)
+	return constructor
+}
+
+func isIntegralNumber(x float64) bool {
+	return !math.IsInf(x, 0) && x == math.Trunc(x)
+}
+
+var floatPrefix = regexp.MustCompile(`^[+-]?(Infinity|(\d+\.?\d*|\.\d+)([eE][+-]?\d+)?)`)
+
+// parseFloatNative is parseFloat, shared by the global and Number.
+func parseFloatNative(interpreter types.Interpreter, this any, params []any) any {
+	text := strings.TrimLeftFunc(ToString(interpreter, GetArgument(params, 0)), isJSWhiteSpace)
+	prefix := floatPrefix.FindString(text)
+	switch strings.TrimLeft(prefix, "+-") {
+	case "":
+		return math.NaN()
+	case "Infinity":
+		if prefix[0] == '-' {
+			return math.Inf(-1)
+		}
+		return math.Inf(1)
+	}
+	value, _ := strconv.ParseFloat(prefix, 64)
+	return value
+}
+
+// parseIntNative is parseInt, shared by the global and Number.
+func parseIntNative(interpreter types.Interpreter, this any, params []any) any {
+	text := strings.TrimLeftFunc(ToString(interpreter, GetArgument(params, 0)), isJSWhiteSpace)
+	radix := int32(ToUint32(interpreter, GetArgument(params, 1)))
+	return parseInt(text, int(radix))
+}
+
+func parseInt(text string, radix int) float64 {
+	negative := false
+	if text != "" && (text[0] == '-' || text[0] == '+') {
+		negative = text[0] == '-'
+		text = text[1:]
+	}
+	stripPrefix := true
+	if radix != 0 {
+		if radix < 2 || radix > 36 {
+			return math.NaN()
+		}
+		stripPrefix = radix == 16
+	} else {
+		radix = 10
+	}
+	if stripPrefix && len(text) >= 2 && text[0] == '0' && (text[1] == 'x' || text[1] == 'X') {
+		text = text[2:]
+		radix = 16
+	}
+	end := 0
+	for end < len(text) && digitValue(text[end]) < radix {
+		end++
+	}
+	if end == 0 {
+		return math.NaN()
+	}
+	var value float64
+	switch {
+	case radix == 10:
+		value, _ = strconv.ParseFloat(text[:end], 64)
+	case radix&(radix-1) == 0:
+		integer, _ := new(big.Int).SetString(text[:end], radix)
+		value, _ = new(big.Float).SetInt(integer).Float64()
+	default:
+		value = parseIntApproximate(text[:end], radix)
+	}
+	if negative {
+		return -value
+	}
+	return value
+}
+
+// parseIntApproximate accumulates digits in 32-bit chunks like V8, which
+// the spec allows to lose precision for radixes other than 10 and powers
+// of two.
+func parseIntApproximate(digits string, radix int) float64 {
+	const maxMultiplier = math.MaxUint32 / 36
+	result := 0.0
+	for digits != "" {
+		part, multiplier := uint32(0), uint32(1)
+		for digits != "" && multiplier*uint32(radix) <= maxMultiplier {
+			part = part*uint32(radix) + uint32(digitValue(digits[0]))
+			multiplier *= uint32(radix)
+			digits = digits[1:]
+		}
+		result = result*float64(multiplier) + float64(part)
+	}
+	return result
+}
+
+func digitValue(c byte) int {
+	switch {
+	case c >= '0' && c <= '9':
+		return int(c - '0')
+	case c >= 'a' && c <= 'z':
+		return int(c-'a') + 10
+	case c >= 'A' && c <= 'Z':
+		return int(c-'A') + 10
+	}
+	return 36
+}
+
+func isNaNNative(interpreter types.Interpreter, this any, params []any) any {
+	return math.IsNaN(ToNumber(interpreter, GetArgument(params, 0)))
+}
+
+func isFiniteNative(interpreter types.Interpreter, this any, params []any) any {
+	x := ToNumber(interpreter, GetArgument(params, 0))
+	return !math.IsNaN(x) && !math.IsInf(x, 0)
+}
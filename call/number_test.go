@@ -0,0 +1,71 @@
+package call
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNumberFormat(t *testing.T) {
+	tests := []struct {
+		name   string
+		format func(float64, int) string
+		value  float64
+		digits int
+		want   string
+	}{
+		{"toFixed", numberToFixed, 1.005, 2, "1.00"},
+		{"toFixed", numberToFixed, 2.5, 0, "3"},
+		{"toFixed", numberToFixed, -2.5, 0, "-3"},
+		{"toFixed", numberToFixed, 1.45, 1, "1.4"},
+		{"toFixed", numberToFixed, 8.345, 2, "8.35"},
+		{"toFixed", numberToFixed, 0.000001, 2, "0.00"},
+		{"toFixed", numberToFixed, 0.1, 20, "0.10000000000000000555"},
+		{"toFixed", numberToFixed, 1e21, 2, "1e+21"},
+		{"toFixed", numberToFixed, math.Copysign(0, -1), 1, "0.0"},
+		{"toExponential", numberToExponential, 123.456, 2, "1.23e+2"},
+		{"toExponential", numberToExponential, 0.00015, 1, "1.5e-4"},
+		{"toExponential", numberToExponential, 9.995, 2, "9.99e+0"},
+		{"toExponential", numberToExponential, 99.95, 2, "1.00e+2"},
+		{"toExponential", numberToExponential, 123.456, -1, "1.23456e+2"},
+		{"toExponential", numberToExponential, 0, 2, "0.00e+0"},
+		{"toPrecision", numberToPrecision, 123.456, 4, "123.5"},
+		{"toPrecision", numberToPrecision, 123.456, 2, "1.2e+2"},
+		{"toPrecision", numberToPrecision, 0.000001234, 2, "0.0000012"},
+		{"toPrecision", numberToPrecision, 1e-7, 1, "1e-7"},
+		{"toPrecision", numberToPrecision, 0, 3, "0.00"},
+		{"toString", numberToRadixString, 255, 16, "ff"},
+		{"toString", numberToRadixString, -0.5, 2, "-0.1"},
+		{"toString", numberToRadixString, 0.1, 3, "0.0022002200220022002200220022002201"},
+		{"toString", numberToRadixString, 1e21, 36, "5v1j4f4ds7c000"},
+	}
+	for _, tt := range tests {
+		if got := tt.format(tt.value, tt.digits); got != tt.want {
+			t.Errorf("%s(%v, %d) actual = %v, expect= %v", tt.name, tt.value, tt.digits, got, tt.want)
+		}
+	}
+}
+
+func TestParseInt(t *testing.T) {
+	tests := []struct {
+		text  string
+		radix int
+		want  float64
+	}{
+		{"42px", 0, 42},
+		{"-0x1F", 0, -31},
+		{"0x1F", 10, 0},
+		{"z", 36, 35},
+		{"12", 2, 1},
+		{"1e3", 0, 1},
+		{"123456789012345678901234567890", 10, 1.2345678901234568e+29},
+		{"ffffffffffffffffffff", 36, 5.72892623093303e+30},
+	}
+	for _, tt := range tests {
+		if got := parseInt(tt.text, tt.radix); got != tt.want {
+			t.Errorf("parseInt(%q, %d) actual = %v, expect= %v", tt.text, tt.radix, got, tt.want)
+		}
+	}
+	if got := parseInt("", 10); !math.IsNaN(got) {
+		t.Errorf("parseInt(\"\") actual = %v, expect NaN", got)
+	}
+}
@@ -0,0 +1,431 @@
+package call
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/nusr/gojs/types"
+)
+
+// formatPart is a piece of a formatted number or date, as formatToParts
+// returns it.
+type formatPart struct {
+	kind  string
+	value string
+}
+
+func joinParts(parts []formatPart) string {
+	var builder strings.Builder
+	for _, part := range parts {
+		builder.WriteString(part.value)
+	}
+	return builder.String()
+}
+
+func partsToArray(interpreter types.Interpreter, parts []formatPart) types.Object {
+	list := make([]any, len(parts))
+	for i, part := range parts {
+		object := NewInstance(interpreter)
+		object.Set("type", part.kind)
+		object.Set("value", part.value)
+		list[i] = object
+	}
+	return NewArrayFrom(interpreter, list)
+}
+
+// numberDigits are the digit options of NumberFormat and PluralRules. The
+// significant digits are used instead of the fraction digits when set.
+type numberDigits struct {
+	minimumIntegerDigits     int
+	minimumFractionDigits    int
+	maximumFractionDigits    int
+	minimumSignificantDigits int
+	maximumSignificantDigits int
+}
+
+// getDigitOptions reads the digit options, with the default fraction
+// digits of the style.
+func getDigitOptions(interpreter types.Interpreter, options any, minimumFraction int, maximumFraction int) numberDigits {
+	digits := numberDigits{minimumIntegerDigits: 1}
+	if value, ok := getNumberOption(interpreter, options, "minimumIntegerDigits", 1, 21); ok {
+		digits.minimumIntegerDigits = value
+	}
+	minimum, hasMinimum := getNumberOption(interpreter, options, "minimumFractionDigits", 0, 100)
+	maximum, hasMaximum := getNumberOption(interpreter, options, "maximumFractionDigits", 0, 100)
+	minimumSignificant, hasMinimumSignificant := getNumberOption(interpreter, options, "minimumSignificantDigits", 1, 21)
+	maximumSignificant, hasMaximumSignificant := getNumberOption(interpreter, options, "maximumSignificantDigits", 1, 21)
+	if hasMinimumSignificant || hasMaximumSignificant {
+		if !hasMinimumSignificant {
+			minimumSignificant = 1
+		}
+		if !hasMaximumSignificant {
+			maximumSignificant = 21
+		} else if maximumSignificant < minimumSignificant {
+			ThrowRangeError(interpreter, "maximumSignificantDigits value is out of range.")
+		}
+		digits.minimumSignificantDigits = minimumSignificant
+		digits.maximumSignificantDigits = maximumSignificant
+		return digits
+	}
+	switch {
+	case !hasMinimum && !hasMaximum:
+		minimum, maximum = minimumFraction, maximumFraction
+	case !hasMinimum:
+		minimum = min(minimumFraction, maximum)
+	case !hasMaximum:
+		maximum = max(maximumFraction, minimum)
+	case minimum > maximum:
+		ThrowRangeError(interpreter, "maximumFractionDigits value is out of range.")
+	}
+	digits.minimumFractionDigits = minimum
+	digits.maximumFractionDigits = maximum
+	return digits
+}
+
+func (digits numberDigits) resolvedOptions() []any {
+	result := []any{"minimumIntegerDigits", int64(digits.minimumIntegerDigits)}
+	if digits.maximumSignificantDigits > 0 {
+		return append(result,
+			"minimumSignificantDigits", int64(digits.minimumSignificantDigits),
+			"maximumSignificantDigits", int64(digits.maximumSignificantDigits),
+		)
+	}
+	return append(result,
+		"minimumFractionDigits", int64(digits.minimumFractionDigits),
+		"maximumFractionDigits", int64(digits.maximumFractionDigits),
+	)
+}
+
+// roundDigits rounds the decimal digits of a number to the first keep of
+// them, with ties away from zero. point is the position of the decimal
+// point in digits, which the carry can move.
+func roundDigits(digits string, point int, keep int) (string, int) {
+	if keep >= len(digits) {
+		return digits, point
+	}
+	if keep < 0 {
+		return "", point
+	}
+	result := []byte(digits[:keep])
+	if digits[keep] >= '5' {
+		i := len(result) - 1
+		for ; i >= 0 && result[i] == '9'; i-- {
+			result[i] = '0'
+		}
+		if i < 0 {
+			result = append([]byte{'1'}, result...)
+			point++
+		} else {
+			result[i]++
+		}
+	}
+	return string(result), point
+}
+
+// format rounds x, which is finite and not negative, and returns its
+// integer and fraction digits. shift moves the decimal point to the right,
+// so a percentage is exact. zero reports whether x rounds to 0.
+func (digits numberDigits) format(x float64, shift int) (integer string, fraction string, zero bool) {
+	// the shortest digits that read back as x, as ICU uses
+	mantissa, exponent, _ := strings.Cut(strconv.FormatFloat(x, 'e', -1, 64), "e")
+	text := strings.Replace(mantissa, ".", "", 1)
+	point, _ := strconv.Atoi(exponent)
+	point += 1 + shift
+	significant := digits.maximumSignificantDigits > 0
+	keep := point + digits.maximumFractionDigits
+	if significant {
+		keep = digits.maximumSignificantDigits
+	}
+	text, point = roundDigits(text, point, keep)
+	text = strings.TrimRight(text, "0")
+	zero = text == ""
+	if zero {
+		point = 1
+	}
+	if significant && len(text) < digits.minimumSignificantDigits {
+		text += strings.Repeat("0", digits.minimumSignificantDigits-len(text))
+	}
+	switch {
+	case point <= 0:
+		fraction = strings.Repeat("0", -point) + text
+	case point >= len(text):
+		integer = text + strings.Repeat("0", point-len(text))
+	default:
+		integer, fraction = text[:point], text[point:]
+	}
+	if !significant && len(fraction) < digits.minimumFractionDigits {
+		fraction += strings.Repeat("0", digits.minimumFractionDigits-len(fraction))
+	}
+	if len(integer) < digits.minimumIntegerDigits {
+		integer = strings.Repeat("0", digits.minimumIntegerDigits-len(integer)) + integer
+	}
+	return integer, fraction, zero
+}
+
+// numberFormatImpl is an Intl.NumberFormat.
+type numberFormatImpl struct {
+	*instanceImpl
+	locale          string
+	data            *localeData
+	style           string
+	currency        string
+	currencyDisplay string
+	currencySign    string
+	digits          numberDigits
+	useGrouping     any // "auto", "always", "min2" or false
+	signDisplay     string
+	realm           *realm
+	bound           types.Function
+}
+
+var currencyCode = regexp.MustCompile(`^[A-Za-z]{3}$`)
+
+// currencyDigits returns the number of minor units of a currency.
+func currencyDigits(currency string) int {
+	switch currency {
+	case "BIF", "CLP", "DJF", "GNF", "ISK", "JPY", "KMF", "KRW", "PYG", "RWF", "UGX", "UYI", "VND", "VUV", "XAF", "XOF", "XPF":
+		return 0
+	case "BHD", "IQD", "JOD", "KWD", "LYD", "OMR", "TND":
+		return 3
+	}
+	return 2
+}
+
+func newNumberFormat(interpreter types.Interpreter, locales any, options any) *numberFormatImpl {
+	format := &numberFormatImpl{
+		instanceImpl: NewObject(realmOf(interpreter).numberFormatPrototype).(*instanceImpl),
+	}
+	format.realm = realmOf(interpreter)
+	format.locale, format.data = resolveLocale(interpreter, locales)
+	format.style = getOption(interpreter, options, "NumberFormat", "style", []string{"decimal", "percent", "currency"}, "decimal")
+	if value := GetProperty(interpreter, options, "currency"); value != nil {
+		currency := ToString(interpreter, value)
+		if !currencyCode.MatchString(currency) {
+			ThrowRangeError(interpreter, "Invalid currency code : %s", currency)
+		}
+		format.currency = strings.ToUpper(currency)
+	}
+	if format.style == "currency" && format.currency == "" {
+		ThrowTypeError(interpreter, "Currency code is required with currency style.")
+	}
+	format.currencyDisplay = getOption(interpreter, options, "NumberFormat", "currencyDisplay", []string{"code", "symbol", "narrowSymbol", "name"}, "symbol")
+	format.currencySign = getOption(interpreter, options, "NumberFormat", "currencySign", []string{"standard", "accounting"}, "standard")
+	switch format.style {
+	case "currency":
+		digits := currencyDigits(format.currency)
+		format.digits = getDigitOptions(interpreter, options, digits, digits)
+	case "percent":
+		format.digits = getDigitOptions(interpreter, options, 0, 0)
+	default:
+		format.digits = getDigitOptions(interpreter, options, 0, 3)
+	}
+	getOption(interpreter, options, "NumberFormat", "notation", []string{"standard"}, "standard")
+	format.useGrouping = "auto"
+	switch value := GetProperty(interpreter, options, "useGrouping").(type) {
+	case nil:
+	case bool:
+		if value {
+			format.useGrouping = "always"
+		} else {
+			format.useGrouping = false
+		}
+	default:
+		if grouping := getOption(interpreter, options, "NumberFormat", "useGrouping", []string{"min2", "auto", "always", "true", "false"}, "auto"); grouping != "true" && grouping != "false" {
+			format.useGrouping = grouping
+		}
+	}
+	format.signDisplay = getOption(interpreter, options, "NumberFormat", "signDisplay", []string{"auto", "never", "always", "exceptZero", "negative"}, "auto")
+	return format
+}
+
+func (format *numberFormatImpl) Get(key any) any {
+	if key == "format" {
+		if format.bound == nil {
+			format.bound = newNative(format.realm, "", func(interpreter types.Interpreter, this any, params []any) any {
+				return format.format(ToNumber(interpreter, GetArgument(params, 0)))
+			})
+		}
+		return format.bound
+	}
+	return format.instanceImpl.Get(key)
+}
+
+// integerParts splits the integer digits into groups of three.
+func (format *numberFormatImpl) integerParts(integer string) []formatPart {
+	minimum := 4
+	switch format.useGrouping {
+	case false:
+		minimum = len(integer) + 1
+	case "min2":
+		minimum = 5
+	}
+	if len(integer) < minimum {
+		return []formatPart{{"integer", integer}}
+	}
+	var parts []formatPart
+	for first := (len(integer)-1)%3 + 1; integer != ""; first = 3 {
+		if parts != nil {
+			parts = append(parts, formatPart{"group", format.data.group})
+		}
+		parts = append(parts, formatPart{"integer", integer[:first]})
+		integer = integer[first:]
+	}
+	return parts
+}
+
+// currencyName returns how the currency is displayed, the name depending
+// on the plural category of the amount.
+func (format *numberFormatImpl) currencyName(category string) string {
+	names, ok := format.data.currencies[format.currency]
+	switch {
+	case format.currencyDisplay == "code" || !ok:
+		return format.currency
+	case format.currencyDisplay == "narrowSymbol":
+		return names.narrow
+	case format.currencyDisplay != "name":
+		return names.symbol
+	case category == "one":
+		return names.one
+	}
+	return names.other
+}
+
+func (format *numberFormatImpl) parts(x float64) []formatPart {
+	data := format.data
+	var number []formatPart
+	negative := math.Signbit(x)
+	zero := false
+	category := "other"
+	switch {
+	case math.IsNaN(x):
+		number = []formatPart{{"nan", "NaN"}}
+		negative = false
+	case math.IsInf(x, 0):
+		number = []formatPart{{"infinity", "∞"}}
+	default:
+		shift := 0
+		if format.style == "percent" {
+			shift = 2
+		}
+		var integer, fraction string
+		integer, fraction, zero = format.digits.format(math.Abs(x), shift)
+		number = format.integerParts(integer)
+		if fraction != "" {
+			number = append(number, formatPart{"decimal", data.decimal}, formatPart{"fraction", fraction})
+		}
+		category = pluralCategory(data, false, integer, fraction)
+	}
+	sign := ""
+	switch format.signDisplay {
+	case "auto":
+		if negative {
+			sign = "-"
+		}
+	case "always":
+		sign = "+"
+		if negative {
+			sign = "-"
+		}
+	case "exceptZero":
+		if !zero && !math.IsNaN(x) {
+			sign = "+"
+			if negative {
+				sign = "-"
+			}
+		}
+	case "negative":
+		if negative && !zero {
+			sign = "-"
+		}
+	}
+	accounting := format.style == "currency" && format.currencySign == "accounting" && sign == "-" && data.accounting
+	var parts []formatPart
+	switch {
+	case accounting:
+		parts = append(parts, formatPart{"literal", "("})
+	case sign == "-":
+		parts = append(parts, formatPart{"minusSign", sign})
+	case sign == "+":
+		parts = append(parts, formatPart{"plusSign", sign})
+	}
+	switch format.style {
+	case "percent":
+		parts = append(parts, number...)
+		if space := strings.TrimSuffix(data.percent, "%"); space != "" {
+			parts = append(parts, formatPart{"literal", space})
+		}
+		parts = append(parts, formatPart{"percentSign", "%"})
+	case "currency":
+		currency := formatPart{"currency", format.currencyName(category)}
+		switch {
+		case format.currencyDisplay == "name":
+			parts = append(parts, number...)
+			if data.nameSeparator != "" {
+				parts = append(parts, formatPart{"literal", data.nameSeparator})
+			}
+			parts = append(parts, currency)
+		case data.currencyAfter:
+			parts = append(parts, number...)
+			parts = append(parts, formatPart{"literal", "\u00a0"}, currency)
+		default:
+			parts = append(parts, currency)
+			// a symbol ending in a letter is kept apart from the digits
+			if last, _ := utf8.DecodeLastRuneInString(currency.value); unicode.IsLetter(last) {
+				parts = append(parts, formatPart{"literal", "\u00a0"})
+			}
+			parts = append(parts, number...)
+		}
+	default:
+		parts = append(parts, number...)
+	}
+	if accounting {
+		parts = append(parts, formatPart{"literal", ")"})
+	}
+	return parts
+}
+
+func (format *numberFormatImpl) format(x float64) string {
+	return joinParts(format.parts(x))
+}
+
+func newNumberFormatPrototype(realm *realm) *instanceImpl {
+	prototype := NewObject(realm.objectPrototype).(*instanceImpl)
+	thisNumberFormat := func(interpreter types.Interpreter, this any, name string) *numberFormatImpl {
+		format, ok := this.(*numberFormatImpl)
+		if !ok {
+			ThrowTypeError(interpreter, "Method Intl.NumberFormat.prototype.%s called on incompatible receiver %s", name, describe(this))
+		}
+		return format
+	}
+	prototype.define("formatToParts", newNative(realm, "formatToParts", func(interpreter types.Interpreter, this any, params []any) any {
+		format := thisNumberFormat(interpreter, this, "formatToParts")
+		return partsToArray(interpreter, format.parts(ToNumber(interpreter, GetArgument(params, 0))))
+	}), false)
+	prototype.define("resolvedOptions", newNative(realm, "resolvedOptions", func(interpreter types.Interpreter, this any, params []any) any {
+		format := thisNumberFormat(interpreter, this, "resolvedOptions")
+		options := []any{"locale", format.locale, "numberingSystem", "latn", "style", format.style}
+		if format.style == "currency" {
+			options = append(options,
+				"currency", format.currency,
+				"currencyDisplay", format.currencyDisplay,
+				"currencySign", format.currencySign,
+			)
+		}
+		options = append(options, format.digits.resolvedOptions()...)
+		options = append(options,
+			"useGrouping", format.useGrouping,
+			"notation", "standard",
+			"signDisplay", format.signDisplay,
+			"roundingMode", "halfExpand",
+			"roundingIncrement", int64(1),
+			"trailingZeroDisplay", "auto",
+			"roundingPriority", "auto",
+		)
+		return newResolvedOptions(interpreter, options...)
+	}), false)
+	return prototype
+}
@@ -0,0 +1,242 @@
+package call
+
+import (
+	"math"
+
+	"github.com/nusr/gojs/flow"
+	"github.com/nusr/gojs/types"
+)
+
+// defineObjectPrototype adds the methods of Object.prototype, which is
+// created before them so that Function.prototype can inherit from it.
+func defineObjectPrototype(realm *realm, prototype *instanceImpl) {
+	prototype.define("hasOwnProperty", newNative(realm, "hasOwnProperty", func(interpreter types.Interpreter, this any, params []any) any {
+		key := ToPropertyKey(GetArgument(params, 0))
+		return ToObject(interpreter, this).Has(key)
+	}), false)
+	prototype.define("isPrototypeOf", newNative(realm, "isPrototypeOf", func(interpreter types.Interpreter, this any, params []any) any {
+		value, ok := GetArgument(params, 0).(types.Object)
+		if !ok {
+			return false
+		}
+		object := ToObject(interpreter, this)
+		for proto := value.GetPrototype(); proto != nil; {
+			if proto == object {
+				return true
+			}
+			next, ok := proto.(types.Object)
+			if !ok {
+				break
+			}
+			proto = next.GetPrototype()
+		}
+		return false
+	}), false)
+	prototype.define("propertyIsEnumerable", newNative(realm, "propertyIsEnumerable", func(interpreter types.Interpreter, this any, params []any) any {
+		key := ToPropertyKey(GetArgument(params, 0))
+		return ToObject(interpreter, this).IsEnumerable(key)
+	}), false)
+	prototype.define("toString", newNative(realm, "toString", func(interpreter types.Interpreter, this any, params []any) any {
+		return objectToString(interpreter, this)
+	}), false)
+	prototype.define("toLocaleString", newNative(realm, "toLocaleString", func(interpreter types.Interpreter, this any, params []any) any {
+		return Invoke(interpreter, GetProperty(interpreter, ToObject(interpreter, this), "toString"), this, nil)
+	}), false)
+	prototype.define("valueOf", newNative(realm, "valueOf", func(interpreter types.Interpreter, this any, params []any) any {
+		return ToObject(interpreter, this)
+	}), false)
+}
+
+// objectToString implements Object.prototype.toString, naming the kind of
+// object or its Symbol.toStringTag.
+func objectToString(interpreter types.Interpreter, value any) string {
+	if value == nil {
+		return "[object Undefined]"
+	}
+	if types.IsNull(value) {
+		return "[object Null]"
+	}
+	tag := "Object"
+	switch value.(type) {
+	case bool:
+		tag = "Boolean"
+	case int64, float64, types.NaN, *numberImpl:
+		tag = "Number"
+	case string, *stringImpl:
+		tag = "String"
+	case *regexpImpl:
+		tag = "RegExp"
+	case *errorImpl:
+		tag = "Error"
+	case *dateImpl:
+		tag = "Date"
+	case types.Function:
+		tag = "Function"
+	}
+	if IsArray(value) {
+		tag = "Array"
+	}
+	if val, ok := GetProperty(interpreter, value, SymbolToStringTag).(string); ok {
+		tag = val
+	}
+	return "[object " + tag + "]"
+}
+
+// SameValue compares like === except that NaN equals NaN and +0 differs
+// from -0.
+func SameValue(left any, right any) bool {
+	if a, b, ok := toFloat2(left, right); ok {
+		if math.IsNaN(a) || math.IsNaN(b) {
+			return math.IsNaN(a) && math.IsNaN(b)
+		}
+		return a == b && math.Signbit(a) == math.Signbit(b)
+	}
+	return left == right
+}
+
+// ownEnumerableKeys lists the enumerable own string keys of a value
+// converted to an object.
+func ownEnumerableKeys(interpreter types.Interpreter, value any) (types.Object, []string) {
+	object := ToObject(interpreter, value)
+	return object, enumerableKeys(object)
+}
+
+// groupBy calls the callback of Object.groupBy or Map.groupBy for every
+// value of an iterable and passes its key to add; the iterator is closed if
+// either throws.
+func groupBy(interpreter types.Interpreter, params []any, add func(key any, value any)) {
+	items := GetArgument(params, 0)
+	callback := GetArgument(params, 1)
+	if types.IsNullish(items) {
+		ThrowTypeError(interpreter, "%s is not iterable", describe(items))
+	}
+	checkCallable(interpreter, callback)
+	iterator := GetIterator(interpreter, items)
+	for k := int64(0); ; k++ {
+		value, ok := iterator.Step(interpreter)
+		if !ok {
+			return
+		}
+		if reason, threw := recoverThrow(func() {
+			add(Invoke(interpreter, callback, nil, []any{value, k}), value)
+		}); threw {
+			iterator.Close(interpreter)
+			panic(flow.NewThrow(reason))
+		}
+	}
+}
+
+func newObjectConstructor(realm *realm) types.Object {
+	object := func(interpreter types.Interpreter, params []any) any {
+		value := GetArgument(params, 0)
+		if types.IsNullish(value) {
+			return NewInstance(interpreter)
+		}
+		return ToObject(interpreter, value)
+	}
+	constructor := newConstructor(realm, "Object", func(interpreter types.Interpreter, this any, params []any) any {
+		return object(interpreter, params)
+	}, object).(*nativeImpl)
+	constructor.define("keys", newNative(realm, "keys", func(interpreter types.Interpreter, this any, params []any) any {
+		_, keys := ownEnumerableKeys(interpreter, GetArgument(params, 0))
+		result := make([]any, len(keys))
+		for i, key := range keys {
+			result[i] = key
+		}
+		return NewArrayFrom(interpreter, result)
+	}), false)
+	constructor.define("values", newNative(realm, "values", func(interpreter types.Interpreter, this any, params []any) any {
+		object, keys := ownEnumerableKeys(interpreter, GetArgument(params, 0))
+		result := make([]any, 0, len(keys))
+		for _, key := range keys {
+			// a getter can remove a later key, which is then skipped
+			if object.IsEnumerable(key) {
+				result = append(result, object.Get(key))
+			}
+		}
+		return NewArrayFrom(interpreter, result)
+	}), false)
+	constructor.define("entries", newNative(realm, "entries", func(interpreter types.Interpreter, this any, params []any) any {
+		object, keys := ownEnumerableKeys(interpreter, GetArgument(params, 0))
+		result := make([]any, 0, len(keys))
+		for _, key := range keys {
+			if object.IsEnumerable(key) {
+				result = append(result, NewArrayFrom(interpreter, []any{key, object.Get(key)}))
+			}
+		}
+		return NewArrayFrom(interpreter, result)
+	}), false)
+	constructor.define("fromEntries", newNative(realm, "fromEntries", func(interpreter types.Interpreter, this any, params []any) any {
+		iterable := GetArgument(params, 0)
+		if types.IsNullish(iterable) {
+			ThrowTypeError(interpreter, "%s is not iterable", describe(iterable))
+		}
+		result := NewInstance(interpreter)
+		iterator := GetIterator(interpreter, iterable)
+		for {
+			entry, ok := iterator.Step(interpreter)
+			if !ok {
+				return result
+			}
+			if reason, threw := recoverThrow(func() {
+				if _, ok := entry.(types.Property); !ok {
+					ThrowTypeError(interpreter, "Iterator value %s is not an entry object", ToString(interpreter, entry))
+				}
+				key := ToPropertyKey(ToPrimitive(interpreter, GetProperty(interpreter, entry, "0"), "string"))
+				result.Set(key, GetProperty(interpreter, entry, "1"))
+			}); threw {
+				iterator.Close(interpreter)
+				panic(flow.NewThrow(reason))
+			}
+		}
+	}), false)
+	constructor.define("assign", newNative(realm, "assign", func(interpreter types.Interpreter, this any, params []any) any {
+		target := ToObject(interpreter, GetArgument(params, 0))
+		for i, source := range params {
+			if i == 0 || types.IsNullish(source) {
+				continue
+			}
+			from := ToObject(interpreter, source)
+			for _, key := range from.OwnKeys() {
+				if from.IsEnumerable(key) {
+					target.Set(key, from.Get(key))
+				}
+			}
+		}
+		return target
+	}), false)
+	constructor.define("is", newNative(realm, "is", func(interpreter types.Interpreter, this any, params []any) any {
+		return SameValue(GetArgument(params, 0), GetArgument(params, 1))
+	}), false)
+	constructor.define("hasOwn", newNative(realm, "hasOwn", func(interpreter types.Interpreter, this any, params []any) any {
+		object := ToObject(interpreter, GetArgument(params, 0))
+		return object.Has(ToPropertyKey(GetArgument(params, 1)))
+	}), false)
+	constructor.define("groupBy", newNative(realm, "groupBy", func(interpreter types.Interpreter, this any, params []any) any {
+		result := NewObject(nil)
+		groupBy(interpreter, params, func(key any, value any) {
+			key = ToPropertyKey(ToPrimitive(interpreter, key, "string"))
+			group, ok := result.Get(key).(types.Object)
+			if !ok {
+				group = NewArray(interpreter)
+				result.Set(key, group)
+			}
+			group.Set(lengthOf(interpreter, group), value)
+		})
+		return result
+	}), false)
+	constructor.define("getOwnPropertySymbols", newNative(realm, "getOwnPropertySymbols", func(interpreter types.Interpreter, this any, params []any) any {
+		var result []any
+		if object, ok := GetArgument(params, 0).(types.Object); ok {
+			for _, key := range object.OwnKeys() {
+				if types.IsSymbol(key) {
+					result = append(result, key)
+				}
+			}
+		}
+		return NewArrayFrom(interpreter, result)
+	}), false)
+	constructor.define("prototype", realm.objectPrototype, false)
+	realm.objectPrototype.define("constructor", constructor, false)
+	return constructor
+}
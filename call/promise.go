@@ -0,0 +1,416 @@
+package call
+
+import (
+	"github.com/nusr/gojs/flow"
+	"github.com/nusr/gojs/types"
+)
+
+type promiseState int
+
+const (
+	promisePending promiseState = iota
+	promiseFulfilled
+	promiseRejected
+)
+
+// promiseReaction is a handler registered with then, settling capability
+// with its result.
+type promiseReaction struct {
+	capability *promiseImpl
+	rejected   bool
+	handler    any
+}
+
+type promiseImpl struct {
+	*instanceImpl
+	state     promiseState
+	result    any
+	reactions []promiseReaction
+	handled   bool
+	resolved  bool
+}
+
+// NewPromise creates a pending promise.
+func NewPromise(interpreter types.Interpreter) types.Object {
+	return newPromise(interpreter)
+}
+
+func newPromise(interpreter types.Interpreter) *promiseImpl {
+	return &promiseImpl{
+		instanceImpl: NewObject(realmOf(interpreter).promisePrototype).(*instanceImpl),
+	}
+}
+
+func thisPromise(interpreter types.Interpreter, this any, name string) *promiseImpl {
+	promise, ok := this.(*promiseImpl)
+	if !ok {
+		ThrowTypeError(interpreter, "Method Promise.prototype.%s called on incompatible receiver %s", name, describe(this))
+	}
+	return promise
+}
+
+// recoverThrow runs fn and returns the value it threw, if any. Panics that
+// are not script exceptions keep unwinding.
+func recoverThrow(fn func()) (reason any, threw bool) {
+	defer func() {
+		if err := recover(); err != nil {
+			if !flow.Catchable(err) {
+				panic(err)
+			}
+			reason = flow.Recover(err)
+			threw = true
+		}
+	}()
+	fn()
+	return nil, false
+}
+
+func (promise *promiseImpl) settle(interpreter types.Interpreter, state promiseState, value any) {
+	if promise.state != promisePending {
+		return
+	}
+	promise.state = state
+	promise.result = value
+	reactions := promise.reactions
+	promise.reactions = nil
+	if state == promiseRejected && !promise.handled {
+		interpreter.GetEventLoop().TrackRejection(promise, value)
+	}
+	for _, reaction := range reactions {
+		if reaction.rejected == (state == promiseRejected) {
+			promise.enqueueReaction(interpreter, reaction)
+		}
+	}
+}
+
+// Resolve settles the promise with value, adopting the state of a
+// thenable. Only the first call of Resolve or Reject has an effect.
+func (promise *promiseImpl) Resolve(interpreter types.Interpreter, value any) {
+	if promise.resolved {
+		return
+	}
+	promise.resolved = true
+	promise.resolve(interpreter, value)
+}
+
+// Reject settles the promise with reason.
+func (promise *promiseImpl) Reject(interpreter types.Interpreter, reason any) {
+	if promise.resolved {
+		return
+	}
+	promise.resolved = true
+	promise.settle(interpreter, promiseRejected, reason)
+}
+
+func (promise *promiseImpl) resolve(interpreter types.Interpreter, value any) {
+	if value == any(promise) {
+		promise.settle(interpreter, promiseRejected, NewError(interpreter, "TypeError", "Chaining cycle detected for promise #<Promise>", nil))
+		return
+	}
+	if _, ok := value.(types.Property); !ok {
+		promise.settle(interpreter, promiseFulfilled, value)
+		return
+	}
+	var then any
+	if reason, threw := recoverThrow(func() {
+		then = GetProperty(interpreter, value, "then")
+	}); threw {
+		promise.settle(interpreter, promiseRejected, reason)
+		return
+	}
+	if _, ok := then.(types.Function); !ok {
+		promise.settle(interpreter, promiseFulfilled, value)
+		return
+	}
+	interpreter.GetEventLoop().EnqueueMicrotask(func() {
+		resolve, reject := promise.resolvingFunctions(interpreter)
+		if reason, threw := recoverThrow(func() {
+			Invoke(interpreter, then, value, []any{resolve, reject})
+		}); threw {
+			Invoke(interpreter, reject, nil, []any{reason})
+		}
+	})
+}
+
+// resolvingFunctions returns a resolve and reject pair of which only the
+// first call has an effect.
+func (promise *promiseImpl) resolvingFunctions(interpreter types.Interpreter) (types.Method, types.Method) {
+	done := false
+	resolve := NewNative(interpreter, "", func(interpreter types.Interpreter, this any, params []any) any {
+		if !done {
+			done = true
+			promise.resolve(interpreter, GetArgument(params, 0))
+		}
+		return nil
+	})
+	reject := NewNative(interpreter, "", func(interpreter types.Interpreter, this any, params []any) any {
+		if !done {
+			done = true
+			promise.settle(interpreter, promiseRejected, GetArgument(params, 0))
+		}
+		return nil
+	})
+	return resolve, reject
+}
+
+func (promise *promiseImpl) enqueueReaction(interpreter types.Interpreter, reaction promiseReaction) {
+	argument := promise.result
+	interpreter.GetEventLoop().EnqueueMicrotask(func() {
+		capability := reaction.capability
+		if reaction.handler == nil {
+			if capability == nil {
+				return
+			}
+			if reaction.rejected {
+				capability.Reject(interpreter, argument)
+			} else {
+				capability.Resolve(interpreter, argument)
+			}
+			return
+		}
+		var result any
+		reason, threw := recoverThrow(func() {
+			result = Invoke(interpreter, reaction.handler, nil, []any{argument})
+		})
+		if capability == nil {
+			return
+		}
+		if threw {
+			capability.Reject(interpreter, reason)
+		} else {
+			capability.Resolve(interpreter, result)
+		}
+	})
+}
+
+// Then registers handlers and returns the derived promise; handlers that
+// are not functions pass the result through.
+func (promise *promiseImpl) Then(interpreter types.Interpreter, onFulfilled any, onRejected any) types.Object {
+	if _, ok := onFulfilled.(types.Function); !ok {
+		onFulfilled = nil
+	}
+	if _, ok := onRejected.(types.Function); !ok {
+		onRejected = nil
+	}
+	capability := newPromise(interpreter)
+	fulfill := promiseReaction{capability: capability, handler: onFulfilled}
+	reject := promiseReaction{capability: capability, rejected: true, handler: onRejected}
+	switch promise.state {
+	case promisePending:
+		promise.reactions = append(promise.reactions, fulfill, reject)
+	case promiseFulfilled:
+		promise.enqueueReaction(interpreter, fulfill)
+	case promiseRejected:
+		if !promise.handled {
+			interpreter.GetEventLoop().HandleRejection(promise)
+		}
+		promise.enqueueReaction(interpreter, reject)
+	}
+	promise.handled = true
+	return capability
+}
+
+// PromiseResolve returns value if it is a promise, or a promise fulfilled
+// with value.
+func PromiseResolve(interpreter types.Interpreter, value any) types.Object {
+	if promise, ok := value.(*promiseImpl); ok {
+		return promise
+	}
+	promise := newPromise(interpreter)
+	promise.Resolve(interpreter, value)
+	return promise
+}
+
+func newPromisePrototype(realm *realm) types.Object {
+	prototype := NewObject(realm.objectPrototype).(*instanceImpl)
+	prototype.define("then", newNative(realm, "then", func(interpreter types.Interpreter, this any, params []any) any {
+		return thisPromise(interpreter, this, "then").Then(interpreter, GetArgument(params, 0), GetArgument(params, 1))
+	}), false)
+	prototype.define("catch", newNative(realm, "catch", func(interpreter types.Interpreter, this any, params []any) any {
+		return Invoke(interpreter, GetProperty(interpreter, this, "then"), this, []any{nil, GetArgument(params, 0)})
+	}), false)
+	prototype.define("finally", newNative(realm, "finally", func(interpreter types.Interpreter, this any, params []any) any {
+		onFinally := GetArgument(params, 0)
+		if _, ok := onFinally.(types.Function); !ok {
+			return Invoke(interpreter, GetProperty(interpreter, this, "then"), this, []any{onFinally, onFinally})
+		}
+		thenFinally := newNative(realm, "", func(interpreter types.Interpreter, _ any, params []any) any {
+			value := GetArgument(params, 0)
+			promise := PromiseResolve(interpreter, Invoke(interpreter, onFinally, nil, nil))
+			return Invoke(interpreter, GetProperty(interpreter, promise, "then"), promise, []any{newNative(realm, "", func(interpreter types.Interpreter, _ any, _ []any) any {
+				return value
+			})})
+		})
+		catchFinally := newNative(realm, "", func(interpreter types.Interpreter, _ any, params []any) any {
+			reason := GetArgument(params, 0)
+			promise := PromiseResolve(interpreter, Invoke(interpreter, onFinally, nil, nil))
+			return Invoke(interpreter, GetProperty(interpreter, promise, "then"), promise, []any{newNative(realm, "", func(interpreter types.Interpreter, _ any, _ []any) any {
+				panic(flow.NewThrow(reason))
+			})})
+		})
+		return Invoke(interpreter, GetProperty(interpreter, this, "then"), this, []any{thenFinally, catchFinally})
+	}), false)
+	prototype.define(SymbolToStringTag, "Promise", false)
+	return prototype
+}
+
+// promiseCombinator runs the shared part of Promise.all, allSettled, race
+// and any: each value of the iterable is resolved to a promise and step
+// attaches its handlers. finish is called once the iterable is exhausted
+// with the number of values seen.
+func promiseCombinator(interpreter types.Interpreter, iterable any, step func(index int, next any, result *promiseImpl), finish func(count int, result *promiseImpl)) types.Object {
+	result := newPromise(interpreter)
+	if reason, threw := recoverThrow(func() {
+		iterator := GetIterator(interpreter, iterable)
+		index := 0
+		for {
+			value, ok := iterator.Step(interpreter)
+			if !ok {
+				break
+			}
+			next := PromiseResolve(interpreter, value)
+			step(index, next, result)
+			index++
+		}
+		finish(index, result)
+	}); threw {
+		result.Reject(interpreter, reason)
+	}
+	return result
+}
+
+func invokeThen(interpreter types.Interpreter, promise any, onFulfilled any, onRejected any) {
+	Invoke(interpreter, GetProperty(interpreter, promise, "then"), promise, []any{onFulfilled, onRejected})
+}
+
+func newPromiseConstructor(realm *realm) types.Object {
+	constructor := newConstructor(realm, "Promise", func(interpreter types.Interpreter, this any, params []any) any {
+		ThrowTypeError(interpreter, "Promise constructor cannot be invoked without 'new'")
+		return nil
+	}, func(interpreter types.Interpreter, params []any) any {
+		executor := GetArgument(params, 0)
+		if _, ok := executor.(types.Function); !ok {
+			ThrowTypeError(interpreter, "Promise resolver %s is not a function", describe(executor))
+		}
+		promise := newPromise(interpreter)
+		resolve, reject := promise.resolvingFunctions(interpreter)
+		if reason, threw := recoverThrow(func() {
+			Invoke(interpreter, executor, nil, []any{resolve, reject})
+		}); threw {
+			Invoke(interpreter, reject, nil, []any{reason})
+		}
+		return promise
+	}).(*nativeImpl)
+	constructor.define("prototype", realm.promisePrototype, false)
+	realm.promisePrototype.(*instanceImpl).define("constructor", constructor, false)
+	constructor.define("resolve", newNative(realm, "resolve", func(interpreter types.Interpreter, this any, params []any) any {
+		return PromiseResolve(interpreter, GetArgument(params, 0))
+	}), false)
+	constructor.define("reject", newNative(realm, "reject", func(interpreter types.Interpreter, this any, params []any) any {
+		promise := newPromise(interpreter)
+		promise.Reject(interpreter, GetArgument(params, 0))
+		return promise
+	}), false)
+	constructor.define("all", newNative(realm, "all", func(interpreter types.Interpreter, this any, params []any) any {
+		var values []any
+		remaining := 1
+		return promiseCombinator(interpreter, GetArgument(params, 0), func(index int, next any, result *promiseImpl) {
+			values = append(values, nil)
+			remaining++
+			called := false
+			invokeThen(interpreter, next, newNative(realm, "", func(interpreter types.Interpreter, this any, params []any) any {
+				if called {
+					return nil
+				}
+				called = true
+				values[index] = GetArgument(params, 0)
+				remaining--
+				if remaining == 0 {
+					result.Resolve(interpreter, NewArrayFrom(interpreter, values))
+				}
+				return nil
+			}), newNative(realm, "", func(interpreter types.Interpreter, this any, params []any) any {
+				result.Reject(interpreter, GetArgument(params, 0))
+				return nil
+			}))
+		}, func(count int, result *promiseImpl) {
+			remaining--
+			if remaining == 0 {
+				result.Resolve(interpreter, NewArrayFrom(interpreter, values))
+			}
+		})
+	}), false)
+	constructor.define("allSettled", newNative(realm, "allSettled", func(interpreter types.Interpreter, this any, params []any) any {
+		var values []any
+		remaining := 1
+		return promiseCombinator(interpreter, GetArgument(params, 0), func(index int, next any, result *promiseImpl) {
+			values = append(values, nil)
+			remaining++
+			called := false
+			settle := func(status string, key string) types.Method {
+				return newNative(realm, "", func(interpreter types.Interpreter, this any, params []any) any {
+					if called {
+						return nil
+					}
+					called = true
+					entry := NewInstance(interpreter)
+					entry.Set("status", status)
+					entry.Set(key, GetArgument(params, 0))
+					values[index] = entry
+					remaining--
+					if remaining == 0 {
+						result.Resolve(interpreter, NewArrayFrom(interpreter, values))
+					}
+					return nil
+				})
+			}
+			invokeThen(interpreter, next, settle("fulfilled", "value"), settle("rejected", "reason"))
+		}, func(count int, result *promiseImpl) {
+			remaining--
+			if remaining == 0 {
+				result.Resolve(interpreter, NewArrayFrom(interpreter, values))
+			}
+		})
+	}), false)
+	constructor.define("race", newNative(realm, "race", func(interpreter types.Interpreter, this any, params []any) any {
+		return promiseCombinator(interpreter, GetArgument(params, 0), func(index int, next any, result *promiseImpl) {
+			invokeThen(interpreter, next, newNative(realm, "", func(interpreter types.Interpreter, this any, params []any) any {
+				result.Resolve(interpreter, GetArgument(params, 0))
+				return nil
+			}), newNative(realm, "", func(interpreter types.Interpreter, this any, params []any) any {
+				result.Reject(interpreter, GetArgument(params, 0))
+				return nil
+			}))
+		}, func(count int, result *promiseImpl) {})
+	}), false)
+	constructor.define("any", newNative(realm, "any", func(interpreter types.Interpreter, this any, params []any) any {
+		var errors []any
+		remaining := 1
+		return promiseCombinator(interpreter, GetArgument(params, 0), func(index int, next any, result *promiseImpl) {
+			errors = append(errors, nil)
+			remaining++
+			called := false
+			invokeThen(interpreter, next, newNative(realm, "", func(interpreter types.Interpreter, this any, params []any) any {
+				result.Resolve(interpreter, GetArgument(params, 0))
+				return nil
+			}), newNative(realm, "", func(interpreter types.Interpreter, this any, params []any) any {
+				if called {
+					return nil
+				}
+				called = true
+				errors[index] = GetArgument(params, 0)
+				remaining--
+				if remaining == 0 {
+					result.Reject(interpreter, newAggregateError(interpreter, errors, "All promises were rejected", nil))
+				}
+				return nil
+			}))
+		}, func(count int, result *promiseImpl) {
+			remaining--
+			if remaining == 0 {
+				result.Reject(interpreter, newAggregateError(interpreter, errors, "All promises were rejected", nil))
+			}
+		})
+	}), false)
+	return constructor
+}
@@ -0,0 +1,91 @@
+package call
+
+import (
+	"strconv"
+
+	"github.com/nusr/gojs/types"
+)
+
+// GetProperty reads a property from any value, including primitives.
+// Primitives read the prototypes of the realm of interpreter.
+func GetProperty(interpreter types.Interpreter, value any, key any) any {
+	switch data := value.(type) {
+	case types.Property:
+		return data.Get(key)
+	case string:
+		return getStringProperty(interpreter, data, key)
+	case *types.Symbol:
+		return getSymbolProperty(interpreter, data, key)
+	case int64, float64, types.NaN:
+		return getNumberProperty(interpreter, key)
+	}
+	return nil
+}
+
+// DefineField defines a writable, configurable property the way a class
+// member does, replacing a read-only one such as the name of the class.
+func DefineField(object types.Object, key any, value any, enumerable bool) {
+	defineOwnProperty(object, key, dataDescriptor(value, true, enumerable, true))
+}
+
+// HasProperty reports whether key is an own or inherited property of value.
+func HasProperty(value any, key any) bool {
+	for value != nil {
+		if proxy, ok := asProxy(value); ok {
+			return proxy.has(ToPropertyKey(key))
+		}
+		object, ok := value.(types.Object)
+		if !ok {
+			property, ok := value.(types.Property)
+			return ok && property.Get(key) != nil
+		}
+		if object.Has(key) {
+			return true
+		}
+		value = object.GetPrototype()
+	}
+	return false
+}
+
+// ForInKeys lists the enumerable string keys of value and its prototype
+// chain in the order a for...in loop visits them.
+func ForInKeys(value any) []any {
+	var result []any
+	if text, ok := value.(string); ok {
+		for i := range toUTF16(text) {
+			result = append(result, strconv.Itoa(i))
+		}
+		return result
+	}
+	visited := make(map[any]bool)
+	for value != nil {
+		object, ok := value.(types.Object)
+		if !ok {
+			break
+		}
+		for _, key := range object.OwnKeys() {
+			if types.IsSymbol(key) || visited[key] {
+				continue
+			}
+			visited[key] = true
+			if object.IsEnumerable(key) {
+				result = append(result, key)
+			}
+		}
+		value = object.GetPrototype()
+	}
+	return result
+}
+
+// ForInPresent reports whether a key listed by ForInKeys is still present
+// when the loop reaches it: a property deleted by an earlier iteration is
+// not visited. As in V8, the keys of a proxy are not checked again.
+func ForInPresent(value any, key any) bool {
+	if _, ok := asProxy(value); ok {
+		return true
+	}
+	if _, ok := value.(types.Object); !ok {
+		return true
+	}
+	return HasProperty(value, key)
+}
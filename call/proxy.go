@@ -0,0 +1,383 @@
+package call
+
+import (
+	"github.com/nusr/gojs/types"
+)
+
+// proxyImpl forwards every operation on it to a trap of its handler, or to
+// its target when the handler has none, and checks that the traps respect
+// the invariants of the target. The Property methods have no interpreter
+// of their own, so traps run on the one that created the proxy.
+type proxyImpl struct {
+	target      types.Object
+	handler     types.Object // nil once revoked
+	interpreter types.Interpreter
+	self        types.Object // the value scripts see, callable if target is
+}
+
+// callableProxyImpl is a proxy whose target is a function, so the proxy can
+// be called and constructed too.
+type callableProxyImpl struct {
+	*proxyImpl
+}
+
+func newProxy(interpreter types.Interpreter, target any, handler any) types.Object {
+	targetObject, ok := target.(types.Object)
+	handlerObject, valid := handler.(types.Object)
+	if !ok || !valid {
+		ThrowTypeError(interpreter, "Cannot create proxy with a non-object as target or handler")
+	}
+	proxy := &proxyImpl{
+		target:      targetObject,
+		handler:     handlerObject,
+		interpreter: interpreter,
+	}
+	proxy.self = proxy
+	if _, ok := target.(types.Function); ok {
+		proxy.self = &callableProxyImpl{proxy}
+	}
+	return proxy.self
+}
+
+// asProxy returns the proxy behind a value, callable or not.
+func asProxy(value any) (*proxyImpl, bool) {
+	switch data := value.(type) {
+	case *proxyImpl:
+		return data, true
+	case *callableProxyImpl:
+		return data.proxyImpl, true
+	}
+	return nil, false
+}
+
+// trap looks up a handler method, returning nil when the handler does not
+// define it.
+func (proxy *proxyImpl) trap(name string) any {
+	if proxy.handler == nil {
+		ThrowTypeError(proxy.interpreter, "Cannot perform '%s' on a proxy that has been revoked", name)
+	}
+	trap := proxy.handler.Get(name)
+	if trap == nil {
+		return nil
+	}
+	if _, ok := trap.(types.Function); !ok {
+		ThrowTypeError(proxy.interpreter, "'%s' returned for property '%s' of object '#<Object>' is not a function", proxy.text(trap), name)
+	}
+	return trap
+}
+
+func (proxy *proxyImpl) invoke(trap any, params ...any) any {
+	return Invoke(proxy.interpreter, trap, proxy.handler, params)
+}
+
+// text formats a key or value for the message of a broken invariant.
+func (proxy *proxyImpl) text(value any) string {
+	if symbol, ok := value.(*types.Symbol); ok {
+		return symbol.String()
+	}
+	return ToString(proxy.interpreter, value)
+}
+
+func (proxy *proxyImpl) Get(key any) any {
+	return proxy.get(ToPropertyKey(key), proxy.self)
+}
+
+func (proxy *proxyImpl) Set(key any, value any) {
+	proxy.set(ToPropertyKey(key), value, proxy.self)
+}
+
+func (proxy *proxyImpl) Has(key any) bool {
+	_, ok := proxy.getOwnProperty(ToPropertyKey(key))
+	return ok
+}
+
+func (proxy *proxyImpl) Delete(key any) bool {
+	key = ToPropertyKey(key)
+	trap := proxy.trap("deleteProperty")
+	if trap == nil {
+		return proxy.target.Delete(key)
+	}
+	if !ToBoolean(proxy.invoke(trap, proxy.target, key)) {
+		return false
+	}
+	if current, ok := getOwnProperty(proxy.target, key); ok {
+		if !current.configurable {
+			ThrowTypeError(proxy.interpreter, "'deleteProperty' on proxy: trap returned truish for property '%s' which is non-configurable in the proxy target", proxy.text(key))
+		}
+		if !isExtensible(proxy.target) {
+			ThrowTypeError(proxy.interpreter, "'deleteProperty' on proxy: trap returned truish for property '%s' but the proxy target is non-extensible", proxy.text(key))
+		}
+	}
+	return true
+}
+
+func (proxy *proxyImpl) OwnKeys() []any {
+	trap := proxy.trap("ownKeys")
+	if trap == nil {
+		return proxy.target.OwnKeys()
+	}
+	result := proxy.invoke(trap, proxy.target)
+	keys := createListFromArrayLike(proxy.interpreter, result)
+	seen := make(map[any]bool)
+	for _, key := range keys {
+		if _, ok := key.(string); !ok && !types.IsSymbol(key) {
+			ThrowTypeError(proxy.interpreter, "%s is not a valid property name", proxy.text(key))
+		}
+		if seen[key] {
+			ThrowTypeError(proxy.interpreter, "'ownKeys' on proxy: trap returned duplicate entries")
+		}
+		seen[key] = true
+	}
+	extensible := isExtensible(proxy.target)
+	for _, key := range proxy.target.OwnKeys() {
+		if seen[key] {
+			delete(seen, key)
+			continue
+		}
+		if current, _ := getOwnProperty(proxy.target, key); !current.configurable || !extensible {
+			ThrowTypeError(proxy.interpreter, "'ownKeys' on proxy: trap result did not include '%s'", proxy.text(key))
+		}
+	}
+	if !extensible && len(seen) > 0 {
+		ThrowTypeError(proxy.interpreter, "'ownKeys' on proxy: trap returned extra keys but proxy target is non-extensible")
+	}
+	return keys
+}
+
+func (proxy *proxyImpl) IsEnumerable(key any) bool {
+	desc, ok := proxy.getOwnProperty(ToPropertyKey(key))
+	return ok && desc.enumerable
+}
+
+func (proxy *proxyImpl) GetPrototype() types.Property {
+	trap := proxy.trap("getPrototypeOf")
+	if trap == nil {
+		return proxy.target.GetPrototype()
+	}
+	result := proxy.invoke(trap, proxy.target)
+	proto, ok := result.(types.Object)
+	if !ok && !types.IsNull(result) {
+		ThrowTypeError(proxy.interpreter, "'getPrototypeOf' on proxy: trap returned neither object nor null")
+	}
+	if !isExtensible(proxy.target) && types.Property(proto) != proxy.target.GetPrototype() {
+		ThrowTypeError(proxy.interpreter, "'getPrototypeOf' on proxy: proxy target is non-extensible but the trap did not return its actual prototype")
+	}
+	if !ok {
+		return nil
+	}
+	return proto
+}
+
+func (proxy *proxyImpl) get(key any, receiver any) any {
+	trap := proxy.trap("get")
+	if trap == nil {
+		return getWithReceiver(proxy.target, key, receiver)
+	}
+	value := proxy.invoke(trap, proxy.target, key, receiver)
+	if current, ok := getOwnProperty(proxy.target, key); ok && !current.configurable {
+		if current.isData() && !current.writable && !SameValue(value, current.value) {
+			ThrowTypeError(proxy.interpreter, "'get' on proxy: property '%s' is a read-only and non-configurable data property on the proxy target but the proxy did not return its actual value (expected '%s' but got '%s')", proxy.text(key), proxy.text(current.value), proxy.text(value))
+		}
+		if current.isAccessor() && current.get == nil && value != nil {
+			ThrowTypeError(proxy.interpreter, "'get' on proxy: property '%s' is a non-configurable accessor property on the proxy target and does not have a getter function, but the trap did not return 'undefined' (got '%s')", proxy.text(key), proxy.text(value))
+		}
+	}
+	return value
+}
+
+func (proxy *proxyImpl) set(key any, value any, receiver any) bool {
+	trap := proxy.trap("set")
+	if trap == nil {
+		return setWithReceiver(proxy.target, key, value, receiver)
+	}
+	if !ToBoolean(proxy.invoke(trap, proxy.target, key, value, receiver)) {
+		return false
+	}
+	if current, ok := getOwnProperty(proxy.target, key); ok && !current.configurable {
+		if current.isData() && !current.writable && !SameValue(value, current.value) {
+			ThrowTypeError(proxy.interpreter, "'set' on proxy: trap returned truish for property '%s' which exists in the proxy target as a non-configurable and non-writable data property with a different value", proxy.text(key))
+		}
+		if current.isAccessor() && current.set == nil {
+			ThrowTypeError(proxy.interpreter, "'set' on proxy: trap returned truish for property '%s' which exists in the proxy target as a non-configurable and non-writable accessor property without a setter", proxy.text(key))
+		}
+	}
+	return true
+}
+
+func (proxy *proxyImpl) has(key any) bool {
+	trap := proxy.trap("has")
+	if trap == nil {
+		return HasProperty(proxy.target, key)
+	}
+	if ToBoolean(proxy.invoke(trap, proxy.target, key)) {
+		return true
+	}
+	if current, ok := getOwnProperty(proxy.target, key); ok {
+		if !current.configurable {
+			ThrowTypeError(proxy.interpreter, "'has' on proxy: trap returned falsish for property '%s' which exists in the proxy target as non-configurable", proxy.text(key))
+		}
+		if !isExtensible(proxy.target) {
+			ThrowTypeError(proxy.interpreter, "'has' on proxy: trap returned falsish for property '%s' but the proxy target is not extensible", proxy.text(key))
+		}
+	}
+	return false
+}
+
+func (proxy *proxyImpl) getOwnProperty(key any) (propertyDescriptor, bool) {
+	trap := proxy.trap("getOwnPropertyDescriptor")
+	if trap == nil {
+		return getOwnProperty(proxy.target, key)
+	}
+	result := proxy.invoke(trap, proxy.target, key)
+	if _, ok := result.(types.Object); !ok && result != nil {
+		ThrowTypeError(proxy.interpreter, "'getOwnPropertyDescriptor' on proxy: trap returned neither object nor undefined for property '%s'", proxy.text(key))
+	}
+	current, exists := getOwnProperty(proxy.target, key)
+	if result == nil {
+		if !exists {
+			return propertyDescriptor{}, false
+		}
+		if !current.configurable {
+			ThrowTypeError(proxy.interpreter, "'getOwnPropertyDescriptor' on proxy: trap returned undefined for property '%s' which is non-configurable in the proxy target", proxy.text(key))
+		}
+		if !isExtensible(proxy.target) {
+			ThrowTypeError(proxy.interpreter, "'getOwnPropertyDescriptor' on proxy: trap returned undefined for property '%s' which exists in the non-extensible proxy target", proxy.text(key))
+		}
+		return propertyDescriptor{}, false
+	}
+	desc := toPropertyDescriptor(proxy.interpreter, result).complete()
+	if !compatibleDescriptor(isExtensible(proxy.target), desc, current, exists) {
+		ThrowTypeError(proxy.interpreter, "'getOwnPropertyDescriptor' on proxy: trap returned descriptor for property '%s' that is incompatible with the existing property in the proxy target", proxy.text(key))
+	}
+	if !desc.configurable {
+		if !exists || current.configurable {
+			ThrowTypeError(proxy.interpreter, "'getOwnPropertyDescriptor' on proxy: trap reported non-configurability for property '%s' which is either non-existent or configurable in the proxy target", proxy.text(key))
+		}
+		if desc.hasWritable && !desc.writable && current.writable {
+			ThrowTypeError(proxy.interpreter, "'getOwnPropertyDescriptor' on proxy: trap reported non-configurable and writable for property '%s' which is non-configurable, non-writable in the proxy target", proxy.text(key))
+		}
+	}
+	return desc, true
+}
+
+func (proxy *proxyImpl) defineOwnProperty(key any, desc propertyDescriptor) bool {
+	trap := proxy.trap("defineProperty")
+	if trap == nil {
+		return defineOwnProperty(proxy.target, key, desc)
+	}
+	if !ToBoolean(proxy.invoke(trap, proxy.target, key, fromPropertyDescriptor(proxy.interpreter, desc))) {
+		return false
+	}
+	current, exists := getOwnProperty(proxy.target, key)
+	extensible := isExtensible(proxy.target)
+	settingConfigFalse := desc.hasConfigurable && !desc.configurable
+	if !exists {
+		if !extensible {
+			ThrowTypeError(proxy.interpreter, "'defineProperty' on proxy: trap returned truish for adding property '%s'  to the non-extensible proxy target", proxy.text(key))
+		}
+		if settingConfigFalse {
+			ThrowTypeError(proxy.interpreter, "'defineProperty' on proxy: trap returned truish for defining non-configurable property '%s' which is either non-existent or configurable in the proxy target", proxy.text(key))
+		}
+		return true
+	}
+	if !compatibleDescriptor(extensible, desc, current, exists) {
+		ThrowTypeError(proxy.interpreter, "'defineProperty' on proxy: trap returned truish for adding property '%s'  that is incompatible with the existing property in the proxy target", proxy.text(key))
+	}
+	if settingConfigFalse && current.configurable {
+		ThrowTypeError(proxy.interpreter, "'defineProperty' on proxy: trap returned truish for defining non-configurable property '%s' which is either non-existent or configurable in the proxy target", proxy.text(key))
+	}
+	if current.isData() && !current.configurable && current.writable && desc.hasWritable && !desc.writable {
+		ThrowTypeError(proxy.interpreter, "'defineProperty' on proxy: trap returned truish for defining non-configurable property '%s' which cannot be non-writable, unless there exists a corresponding non-configurable, non-writable own property of the target object.", proxy.text(key))
+	}
+	return true
+}
+
+func (proxy *proxyImpl) isExtensible() bool {
+	trap := proxy.trap("isExtensible")
+	if trap == nil {
+		return isExtensible(proxy.target)
+	}
+	result := ToBoolean(proxy.invoke(trap, proxy.target))
+	if expected := isExtensible(proxy.target); result != expected {
+		ThrowTypeError(proxy.interpreter, "'isExtensible' on proxy: trap result does not reflect extensibility of proxy target (which is '%t')", expected)
+	}
+	return result
+}
+
+func (proxy *proxyImpl) preventExtensions() bool {
+	trap := proxy.trap("preventExtensions")
+	if trap == nil {
+		return preventExtensions(proxy.target)
+	}
+	result := ToBoolean(proxy.invoke(trap, proxy.target))
+	if result && isExtensible(proxy.target) {
+		ThrowTypeError(proxy.interpreter, "'preventExtensions' on proxy: trap returned truish but the proxy target is extensible")
+	}
+	return result
+}
+
+func (proxy *proxyImpl) setPrototype(proto types.Property) bool {
+	trap := proxy.trap("setPrototypeOf")
+	if trap == nil {
+		return setPrototypeOf(proxy.target, proto)
+	}
+	if !ToBoolean(proxy.invoke(trap, proxy.target, nullable(proto))) {
+		return false
+	}
+	if !isExtensible(proxy.target) && proxy.target.GetPrototype() != proto {
+		ThrowTypeError(proxy.interpreter, "'setPrototypeOf' on proxy: trap returned truish for setting a new prototype on the non-extensible proxy target")
+	}
+	return true
+}
+
+func (proxy *callableProxyImpl) Call(interpreter types.Interpreter, params []any) any {
+	return proxy.CallWith(interpreter, nil, params)
+}
+
+func (proxy *callableProxyImpl) CallWith(interpreter types.Interpreter, this any, params []any) any {
+	trap := proxy.trap("apply")
+	if trap == nil {
+		return Invoke(interpreter, proxy.target, this, params)
+	}
+	return Invoke(interpreter, trap, proxy.handler, []any{proxy.target, this, NewArrayFrom(interpreter, append([]any{}, params...))})
+}
+
+func (proxy *callableProxyImpl) Construct(interpreter types.Interpreter, params []any) any {
+	trap := proxy.trap("construct")
+	if _, ok := proxy.target.(types.Constructor); !ok {
+		ThrowTypeError(interpreter, "%s is not a constructor", describe(proxy))
+	}
+	if trap == nil {
+		return Construct(interpreter, proxy.target, params)
+	}
+	result := Invoke(interpreter, trap, proxy.handler, []any{proxy.target, NewArrayFrom(interpreter, append([]any{}, params...)), proxy})
+	if _, ok := result.(types.Object); !ok {
+		ThrowTypeError(interpreter, "'construct' on proxy: trap returned non-object ('%s')", proxy.text(result))
+	}
+	return result
+}
+
+func (proxy *callableProxyImpl) String() string {
+	return "function () { [native code] }"
+}
+
+func newProxyConstructor(realm *realm) types.Object {
+	constructor := newConstructor(realm, "Proxy", func(interpreter types.Interpreter, this any, params []any) any {
+		ThrowTypeError(interpreter, "Constructor Proxy requires 'new'")
+		return nil
+	}, func(interpreter types.Interpreter, params []any) any {
+		return newProxy(interpreter, GetArgument(params, 0), GetArgument(params, 1))
+	}).(*nativeImpl)
+	constructor.define("revocable", newNative(realm, "revocable", func(interpreter types.Interpreter, this any, params []any) any {
+		proxy, _ := asProxy(newProxy(interpreter, GetArgument(params, 0), GetArgument(params, 1)))
+		result := NewInstance(interpreter)
+		result.Set("proxy", proxy.self)
+		result.Set("revoke", newNative(realm, "", func(interpreter types.Interpreter, this any, params []any) any {
+			proxy.target = nil
+			proxy.handler = nil
+			return nil
+		}))
+		return result
+	}), false)
+	return constructor
+}
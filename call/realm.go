@@ -0,0 +1,138 @@
+package call
+
+import (
+	"sync"
+
+	"github.com/nusr/gojs/types"
+)
+
+// realm holds the intrinsic objects of a global environment: the
+// prototypes of the built-in objects and the functions compared by
+// identity. Every global environment has its own, so a script changing a
+// built-in does not affect other interpreters.
+type realm struct {
+	objectPrototype   *instanceImpl
+	functionPrototype *nativeImpl
+	symbolPrototype   types.Object
+	errorPrototypes   map[string]*instanceImpl
+	// symbolRegistry holds the symbols created by Symbol.for
+	symbolRegistry map[string]*types.Symbol
+
+	domExceptionPrototype  *instanceImpl
+	arrayPrototype         *instanceImpl
+	arrayIteratorPrototype types.Object
+	stringPrototype        *instanceImpl
+	numberPrototype        *instanceImpl
+
+	regexpPrototype               *instanceImpl
+	regexpStringIteratorPrototype types.Object
+	mapPrototype                  *instanceImpl
+	setPrototype                  *instanceImpl
+	mapIteratorPrototype          types.Object
+	setIteratorPrototype          types.Object
+	datePrototype                 *instanceImpl
+	promisePrototype              types.Object
+	generatorPrototype            types.Object
+	asyncGeneratorPrototype       types.Object
+	timeoutPrototype              types.Object
+
+	arrayBufferPrototype       *instanceImpl
+	sharedArrayBufferPrototype *instanceImpl
+	dataViewPrototype          *instanceImpl
+	typedArrayPrototype        *instanceImpl
+	typedArrayPrototypes       map[*typedArrayKind]*instanceImpl
+
+	collatorPrototype       *instanceImpl
+	numberFormatPrototype   *instanceImpl
+	pluralRulesPrototype    *instanceImpl
+	dateTimeFormatPrototype *instanceImpl
+	textEncoderPrototype    *instanceImpl
+	textDecoderPrototype    *instanceImpl
+
+	evalFunction              types.Method
+	parseIntFunction          types.Method
+	parseFloatFunction        types.Method
+	isNaNFunction             types.Method
+	isFiniteFunction          types.Method
+	stringIteratorFunction    types.Method
+	regexpBuiltinExecFunction types.Method
+}
+
+// newRealm creates the intrinsics, each after the ones it is built on.
+func newRealm() *realm {
+	realm := &realm{}
+	realm.objectPrototype = NewObject(nil).(*instanceImpl)
+	realm.functionPrototype = newFunctionPrototype(realm)
+	defineObjectPrototype(realm, realm.objectPrototype)
+	defineFunctionPrototype(realm)
+	realm.symbolPrototype = newSymbolPrototype(realm)
+	realm.symbolRegistry = make(map[string]*types.Symbol)
+	realm.errorPrototypes = newErrorPrototypes(realm)
+	realm.domExceptionPrototype = newDOMExceptionPrototype(realm)
+
+	realm.evalFunction = newEval(realm)
+	realm.parseIntFunction = newNative(realm, "parseInt", parseIntNative)
+	realm.parseFloatFunction = newNative(realm, "parseFloat", parseFloatNative)
+	realm.isNaNFunction = newNative(realm, "isNaN", isNaNNative)
+	realm.isFiniteFunction = newNative(realm, "isFinite", isFiniteNative)
+	realm.stringIteratorFunction = newNative(realm, "[Symbol.iterator]", stringIteratorNative)
+	realm.regexpBuiltinExecFunction = newNative(realm, "exec", regexpBuiltinExecNative)
+
+	realm.arrayIteratorPrototype = newArrayIteratorPrototype(realm)
+	realm.arrayPrototype = newArrayPrototype(realm)
+	realm.stringPrototype = newStringPrototype(realm)
+	realm.numberPrototype = newNumberPrototype(realm)
+	realm.regexpStringIteratorPrototype = newRegExpStringIteratorPrototype(realm)
+	realm.regexpPrototype = newRegExpPrototype(realm)
+	realm.mapIteratorPrototype = newMapIteratorPrototype(realm, "Map Iterator")
+	realm.setIteratorPrototype = newMapIteratorPrototype(realm, "Set Iterator")
+	realm.mapPrototype = newMapPrototype(realm)
+	realm.setPrototype = newSetPrototype(realm)
+	realm.datePrototype = newDatePrototype(realm)
+	realm.promisePrototype = newPromisePrototype(realm)
+	realm.generatorPrototype = newGeneratorPrototype(realm)
+	realm.asyncGeneratorPrototype = newAsyncGeneratorPrototype(realm)
+	realm.timeoutPrototype = newTimeoutPrototype(realm)
+
+	realm.arrayBufferPrototype = newArrayBufferPrototype(realm, false)
+	realm.sharedArrayBufferPrototype = newArrayBufferPrototype(realm, true)
+	realm.dataViewPrototype = newDataViewPrototype(realm)
+	// %TypedArray%.prototype borrows the generic methods of Array.prototype
+	realm.typedArrayPrototype = newTypedArrayPrototype(realm)
+	realm.typedArrayPrototypes = newTypedArrayPrototypes(realm)
+
+	realm.collatorPrototype = newCollatorPrototype(realm)
+	realm.numberFormatPrototype = newNumberFormatPrototype(realm)
+	realm.pluralRulesPrototype = newPluralRulesPrototype(realm)
+	realm.dateTimeFormatPrototype = newDateTimeFormatPrototype(realm)
+	realm.textEncoderPrototype = newTextEncoderPrototype(realm)
+	realm.textDecoderPrototype = newTextDecoderPrototype(realm)
+	return realm
+}
+
+// globalEnvironment is a global environment with the realm of its
+// built-ins.
+type globalEnvironment struct {
+	types.GlobalEnvironment
+	realm *realm
+}
+
+// detachedRealm serves code running without an interpreter or on an
+// environment not made by NewGlobalEnvironment.
+var detachedRealm struct {
+	once  sync.Once
+	realm *realm
+}
+
+// realmOf returns the realm of the global environment of interpreter.
+func realmOf(interpreter types.Interpreter) *realm {
+	if interpreter != nil {
+		if global, ok := interpreter.GetGlobal().(*globalEnvironment); ok {
+			return global.realm
+		}
+	}
+	detachedRealm.once.Do(func() {
+		detachedRealm.realm = newRealm()
+	})
+	return detachedRealm.realm
+}
@@ -0,0 +1,381 @@
+package call
+
+import (
+	"github.com/nusr/gojs/types"
+)
+
+// propertyDescriptor describes an own property. A descriptor read from a
+// script may be partial; the has fields record which attributes it names.
+type propertyDescriptor struct {
+	value           any
+	get             any
+	set             any
+	writable        bool
+	enumerable      bool
+	configurable    bool
+	hasValue        bool
+	hasGet          bool
+	hasSet          bool
+	hasWritable     bool
+	hasEnumerable   bool
+	hasConfigurable bool
+}
+
+func dataDescriptor(value any, writable bool, enumerable bool, configurable bool) propertyDescriptor {
+	return propertyDescriptor{
+		value:           value,
+		writable:        writable,
+		enumerable:      enumerable,
+		configurable:    configurable,
+		hasValue:        true,
+		hasWritable:     true,
+		hasEnumerable:   true,
+		hasConfigurable: true,
+	}
+}
+
+func (desc propertyDescriptor) isAccessor() bool {
+	return desc.hasGet || desc.hasSet
+}
+
+func (desc propertyDescriptor) isData() bool {
+	return desc.hasValue || desc.hasWritable
+}
+
+// update copies the attributes a partial descriptor names.
+func (desc *propertyDescriptor) update(from propertyDescriptor) {
+	if from.hasValue {
+		desc.value = from.value
+	}
+	if from.hasWritable {
+		desc.writable = from.writable
+	}
+	if from.hasEnumerable {
+		desc.enumerable = from.enumerable
+	}
+	if from.hasConfigurable {
+		desc.configurable = from.configurable
+	}
+}
+
+// complete fills in the attributes a partial descriptor leaves out.
+func (desc propertyDescriptor) complete() propertyDescriptor {
+	if !desc.isAccessor() {
+		desc.hasValue = true
+		desc.hasWritable = true
+	} else {
+		desc.hasGet = true
+		desc.hasSet = true
+	}
+	desc.hasEnumerable = true
+	desc.hasConfigurable = true
+	return desc
+}
+
+// toPropertyDescriptor reads a descriptor object like the one passed to
+// Reflect.defineProperty.
+func toPropertyDescriptor(interpreter types.Interpreter, value any) propertyDescriptor {
+	object, ok := value.(types.Object)
+	if !ok {
+		ThrowTypeError(interpreter, "Property description must be an object: %s", describe(value))
+	}
+	var desc propertyDescriptor
+	if HasProperty(object, "enumerable") {
+		desc.hasEnumerable = true
+		desc.enumerable = ToBoolean(object.Get("enumerable"))
+	}
+	if HasProperty(object, "configurable") {
+		desc.hasConfigurable = true
+		desc.configurable = ToBoolean(object.Get("configurable"))
+	}
+	if HasProperty(object, "value") {
+		desc.hasValue = true
+		desc.value = object.Get("value")
+	}
+	if HasProperty(object, "writable") {
+		desc.hasWritable = true
+		desc.writable = ToBoolean(object.Get("writable"))
+	}
+	if HasProperty(object, "get") {
+		desc.hasGet = true
+		desc.get = object.Get("get")
+		if _, ok := desc.get.(types.Function); !ok && desc.get != nil {
+			ThrowTypeError(interpreter, "Getter must be a function: %s", ToString(interpreter, desc.get))
+		}
+	}
+	if HasProperty(object, "set") {
+		desc.hasSet = true
+		desc.set = object.Get("set")
+		if _, ok := desc.set.(types.Function); !ok && desc.set != nil {
+			ThrowTypeError(interpreter, "Setter must be a function: %s", ToString(interpreter, desc.set))
+		}
+	}
+	if desc.isAccessor() && desc.isData() {
+		ThrowTypeError(interpreter, "Invalid property descriptor. Cannot both specify accessors and a value or writable attribute")
+	}
+	return desc
+}
+
+// fromPropertyDescriptor creates the object Reflect.getOwnPropertyDescriptor
+// returns.
+func fromPropertyDescriptor(interpreter types.Interpreter, desc propertyDescriptor) types.Object {
+	result := NewInstance(interpreter)
+	if desc.hasValue {
+		result.Set("value", desc.value)
+	}
+	if desc.hasWritable {
+		result.Set("writable", desc.writable)
+	}
+	if desc.hasGet {
+		result.Set("get", desc.get)
+	}
+	if desc.hasSet {
+		result.Set("set", desc.set)
+	}
+	if desc.hasEnumerable {
+		result.Set("enumerable", desc.enumerable)
+	}
+	if desc.hasConfigurable {
+		result.Set("configurable", desc.configurable)
+	}
+	return result
+}
+
+// compatibleDescriptor reports whether desc may be applied to a property in
+// its current state, or added when it does not exist.
+func compatibleDescriptor(extensible bool, desc propertyDescriptor, current propertyDescriptor, exists bool) bool {
+	if !exists {
+		return extensible
+	}
+	if current.configurable {
+		return true
+	}
+	if desc.hasConfigurable && desc.configurable {
+		return false
+	}
+	if desc.hasEnumerable && desc.enumerable != current.enumerable {
+		return false
+	}
+	if (desc.isAccessor() || desc.isData()) && desc.isAccessor() != current.isAccessor() {
+		return false
+	}
+	if current.isAccessor() {
+		return (!desc.hasGet || SameValue(desc.get, current.get)) && (!desc.hasSet || SameValue(desc.set, current.set))
+	}
+	if !current.writable {
+		return (!desc.hasWritable || !desc.writable) && (!desc.hasValue || SameValue(desc.value, current.value))
+	}
+	return true
+}
+
+// ordinaryObject is implemented by objects that keep the attributes of
+// their own properties; every object made by NewObject does.
+type ordinaryObject interface {
+	getOwnProperty(key any) (propertyDescriptor, bool)
+	defineOwnProperty(key any, desc propertyDescriptor) bool
+	isExtensible() bool
+	preventExtensions() bool
+	setPrototype(proto types.Property) bool
+}
+
+func getOwnProperty(object types.Object, key any) (propertyDescriptor, bool) {
+	key = ToPropertyKey(key)
+	if val, ok := object.(ordinaryObject); ok {
+		return val.getOwnProperty(key)
+	}
+	if !object.Has(key) {
+		return propertyDescriptor{}, false
+	}
+	return dataDescriptor(object.Get(key), true, object.IsEnumerable(key), true), true
+}
+
+func defineOwnProperty(object types.Object, key any, desc propertyDescriptor) bool {
+	key = ToPropertyKey(key)
+	if val, ok := object.(ordinaryObject); ok {
+		return val.defineOwnProperty(key, desc)
+	}
+	if desc.isAccessor() {
+		return false
+	}
+	object.Set(key, desc.value)
+	return true
+}
+
+func isExtensible(object types.Object) bool {
+	if val, ok := object.(ordinaryObject); ok {
+		return val.isExtensible()
+	}
+	return true
+}
+
+func preventExtensions(object types.Object) bool {
+	if val, ok := object.(ordinaryObject); ok {
+		return val.preventExtensions()
+	}
+	return false
+}
+
+// setPrototypeOf changes the prototype of an object unless it is not
+// extensible or the change would make the chain circular.
+func setPrototypeOf(object types.Object, proto types.Property) bool {
+	if proxy, ok := asProxy(object); ok {
+		return proxy.setPrototype(proto)
+	}
+	if object.GetPrototype() == proto {
+		return true
+	}
+	if !isExtensible(object) {
+		return false
+	}
+	for item := proto; item != nil; {
+		if item == types.Property(object) {
+			return false
+		}
+		next, ok := item.(types.Object)
+		if _, proxy := asProxy(item); !ok || proxy {
+			break
+		}
+		item = next.GetPrototype()
+	}
+	val, ok := object.(ordinaryObject)
+	return ok && val.setPrototype(proto)
+}
+
+// getWithReceiver reads a property the way Reflect.get does; the receiver
+// is only observable by the get trap of a proxy.
+func getWithReceiver(object types.Object, key any, receiver any) any {
+	if proxy, ok := asProxy(object); ok {
+		return proxy.get(key, receiver)
+	}
+	return object.Get(key)
+}
+
+// setWithReceiver assigns a property the way Reflect.set does: a writable
+// property found on object or its prototypes is defined on the receiver.
+func setWithReceiver(object types.Object, key any, value any, receiver any) bool {
+	if proxy, ok := asProxy(object); ok {
+		return proxy.set(key, value, receiver)
+	}
+	current, ok := getOwnProperty(object, key)
+	if !ok {
+		if parent, ok := object.GetPrototype().(types.Object); ok {
+			return setWithReceiver(parent, key, value, receiver)
+		}
+		current = dataDescriptor(nil, true, true, true)
+	}
+	if current.isAccessor() || !current.writable {
+		return false
+	}
+	target, ok := receiver.(types.Object)
+	if !ok {
+		return false
+	}
+	if existing, ok := getOwnProperty(target, key); ok {
+		if existing.isAccessor() || !existing.writable {
+			return false
+		}
+		return defineOwnProperty(target, key, propertyDescriptor{value: value, hasValue: true})
+	}
+	return defineOwnProperty(target, key, dataDescriptor(value, true, true, true))
+}
+
+// reflectTarget returns the object a Reflect method operates on.
+func reflectTarget(interpreter types.Interpreter, name string, params []any) types.Object {
+	object, ok := GetArgument(params, 0).(types.Object)
+	if !ok {
+		ThrowTypeError(interpreter, "Reflect.%s called on non-object", name)
+	}
+	return object
+}
+
+// createListFromArrayLike copies the elements of an array-like object.
+func createListFromArrayLike(interpreter types.Interpreter, value any) []any {
+	object, ok := value.(types.Object)
+	if !ok {
+		ThrowTypeError(interpreter, "CreateListFromArrayLike called on non-object")
+	}
+	length := lengthOf(interpreter, object)
+	result := make([]any, length)
+	for i := range result {
+		result[i] = object.Get(int64(i))
+	}
+	return result
+}
+
+func newReflect(realm *realm) types.Object {
+	object := NewObject(realm.objectPrototype).(*instanceImpl)
+	object.define("apply", newNative(realm, "apply", func(interpreter types.Interpreter, this any, params []any) any {
+		target := GetArgument(params, 0)
+		checkCallable(interpreter, target)
+		return Invoke(interpreter, target, GetArgument(params, 1), createListFromArrayLike(interpreter, GetArgument(params, 2)))
+	}), false)
+	object.define("construct", newNative(realm, "construct", func(interpreter types.Interpreter, this any, params []any) any {
+		target := GetArgument(params, 0)
+		if len(params) > 2 {
+			if _, ok := params[2].(types.Constructor); !ok {
+				ThrowTypeError(interpreter, "%s is not a constructor", describe(params[2]))
+			}
+		}
+		if _, ok := target.(types.Constructor); !ok {
+			ThrowTypeError(interpreter, "%s is not a constructor", describe(target))
+		}
+		return Construct(interpreter, target, createListFromArrayLike(interpreter, GetArgument(params, 1)))
+	}), false)
+	object.define("defineProperty", newNative(realm, "defineProperty", func(interpreter types.Interpreter, this any, params []any) any {
+		target := reflectTarget(interpreter, "defineProperty", params)
+		key := ToPropertyKey(GetArgument(params, 1))
+		return defineOwnProperty(target, key, toPropertyDescriptor(interpreter, GetArgument(params, 2)))
+	}), false)
+	object.define("deleteProperty", newNative(realm, "deleteProperty", func(interpreter types.Interpreter, this any, params []any) any {
+		return reflectTarget(interpreter, "deleteProperty", params).Delete(ToPropertyKey(GetArgument(params, 1)))
+	}), false)
+	object.define("get", newNative(realm, "get", func(interpreter types.Interpreter, this any, params []any) any {
+		target := reflectTarget(interpreter, "get", params)
+		receiver := any(target)
+		if len(params) > 2 {
+			receiver = params[2]
+		}
+		return getWithReceiver(target, ToPropertyKey(GetArgument(params, 1)), receiver)
+	}), false)
+	object.define("getOwnPropertyDescriptor", newNative(realm, "getOwnPropertyDescriptor", func(interpreter types.Interpreter, this any, params []any) any {
+		target := reflectTarget(interpreter, "getOwnPropertyDescriptor", params)
+		if desc, ok := getOwnProperty(target, GetArgument(params, 1)); ok {
+			return fromPropertyDescriptor(interpreter, desc)
+		}
+		return nil
+	}), false)
+	object.define("getPrototypeOf", newNative(realm, "getPrototypeOf", func(interpreter types.Interpreter, this any, params []any) any {
+		return nullable(reflectTarget(interpreter, "getPrototypeOf", params).GetPrototype())
+	}), false)
+	object.define("has", newNative(realm, "has", func(interpreter types.Interpreter, this any, params []any) any {
+		return HasProperty(reflectTarget(interpreter, "has", params), ToPropertyKey(GetArgument(params, 1)))
+	}), false)
+	object.define("isExtensible", newNative(realm, "isExtensible", func(interpreter types.Interpreter, this any, params []any) any {
+		return isExtensible(reflectTarget(interpreter, "isExtensible", params))
+	}), false)
+	object.define("ownKeys", newNative(realm, "ownKeys", func(interpreter types.Interpreter, this any, params []any) any {
+		return NewArrayFrom(interpreter, reflectTarget(interpreter, "ownKeys", params).OwnKeys())
+	}), false)
+	object.define("preventExtensions", newNative(realm, "preventExtensions", func(interpreter types.Interpreter, this any, params []any) any {
+		return preventExtensions(reflectTarget(interpreter, "preventExtensions", params))
+	}), false)
+	object.define("set", newNative(realm, "set", func(interpreter types.Interpreter, this any, params []any) any {
+		target := reflectTarget(interpreter, "set", params)
+		receiver := any(target)
+		if len(params) > 3 {
+			receiver = params[3]
+		}
+		return setWithReceiver(target, ToPropertyKey(GetArgument(params, 1)), GetArgument(params, 2), receiver)
+	}), false)
+	object.define("setPrototypeOf", newNative(realm, "setPrototypeOf", func(interpreter types.Interpreter, this any, params []any) any {
+		target := reflectTarget(interpreter, "setPrototypeOf", params)
+		proto := GetArgument(params, 1)
+		if _, ok := proto.(types.Object); !ok && !types.IsNull(proto) {
+			ThrowTypeError(interpreter, "Object prototype may only be an Object or null: %s", describe(proto))
+		}
+		value, _ := proto.(types.Property)
+		return setPrototypeOf(target, value)
+	}), false)
+	object.define(SymbolToStringTag, "Reflect", false)
+	return object
+}
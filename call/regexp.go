@@ -0,0 +1,524 @@
+package call
+
+import (
+	"strings"
+
+	"github.com/nusr/gojs/regex"
+	"github.com/nusr/gojs/types"
+)
+
+// regexpImpl is a RegExp object. Its flags and source are read from the
+// compiled expression; lastIndex is an ordinary own property.
+type regexpImpl struct {
+	*instanceImpl
+	re *regex.Regexp
+}
+
+// NewRegExp compiles a regular expression object, throwing a SyntaxError
+// for an invalid pattern or flags.
+func NewRegExp(interpreter types.Interpreter, pattern string, flags string) types.Object {
+	re, err := regex.Compile(pattern, flags)
+	if err != nil {
+		ThrowSyntaxError(interpreter, "%s", err.Error())
+	}
+	object := &regexpImpl{
+		instanceImpl: NewObject(realmOf(interpreter).regexpPrototype).(*instanceImpl),
+		re:           re,
+	}
+	object.define("lastIndex", int64(0), false)
+	return object
+}
+
+func (object *regexpImpl) Get(key any) any {
+	switch key {
+	case "source":
+		return escapePattern(object.re.Source())
+	case "flags":
+		return object.re.Flags()
+	case "global":
+		return object.re.Global()
+	case "ignoreCase":
+		return object.re.IgnoreCase()
+	case "multiline":
+		return object.re.Multiline()
+	case "dotAll":
+		return object.re.DotAll()
+	case "unicode":
+		return object.re.Unicode()
+	case "sticky":
+		return object.re.Sticky()
+	case "hasIndices":
+		return object.re.HasIndices()
+	}
+	return object.instanceImpl.Get(key)
+}
+
+// Set keeps lastIndex non-enumerable.
+func (object *regexpImpl) Set(key any, value any) {
+	if key == "lastIndex" {
+		object.define(key, value, false)
+		return
+	}
+	object.instanceImpl.Set(key, value)
+}
+
+// escapePattern makes a pattern printable between slashes.
+func escapePattern(source string) string {
+	if source == "" {
+		return "(?:)"
+	}
+	var result strings.Builder
+	inClass := false
+	escaped := false
+	for _, c := range source {
+		switch {
+		case escaped:
+			escaped = false
+		case c == '\\':
+			escaped = true
+		case c == '[':
+			inClass = true
+		case c == ']':
+			inClass = false
+		case c == '/' && !inClass:
+			result.WriteByte('\\')
+		}
+		switch c {
+		case '\n':
+			result.WriteString(`\n`)
+		case '\r':
+			result.WriteString(`\r`)
+		case 0x2028:
+			result.WriteString(`\u2028`)
+		case 0x2029:
+			result.WriteString(`\u2029`)
+		default:
+			result.WriteRune(c)
+		}
+	}
+	return result.String()
+}
+
+// IsRegExp reports whether a value is treated as a regular expression,
+// which Symbol.match can override.
+func IsRegExp(interpreter types.Interpreter, value any) bool {
+	if _, ok := value.(types.Object); !ok {
+		return false
+	}
+	if matcher := GetProperty(interpreter, value, SymbolMatch); matcher != nil {
+		return ToBoolean(matcher)
+	}
+	_, ok := value.(*regexpImpl)
+	return ok
+}
+
+func thisRegExp(interpreter types.Interpreter, this any, name string) *regexpImpl {
+	object, ok := this.(*regexpImpl)
+	if !ok {
+		ThrowTypeError(interpreter, "Method RegExp.prototype.%s called on incompatible receiver %s", name, describe(this))
+	}
+	return object
+}
+
+func thisRegExpObject(interpreter types.Interpreter, this any, name string) types.Object {
+	object, ok := this.(types.Object)
+	if !ok {
+		ThrowTypeError(interpreter, "Method RegExp.prototype.%s called on incompatible receiver %s", name, describe(this))
+	}
+	return object
+}
+
+// regexpExec runs the exec method of a regular expression, which may be
+// user defined.
+func regexpExec(interpreter types.Interpreter, object types.Object, text string) types.Object {
+	if exec := object.Get("exec"); exec != realmOf(interpreter).regexpBuiltinExecFunction {
+		if _, ok := exec.(types.Function); ok {
+			result := Invoke(interpreter, exec, object, []any{text})
+			if types.IsNull(result) {
+				return nil
+			}
+			if result, ok := result.(types.Object); ok {
+				return result
+			}
+			ThrowTypeError(interpreter, "%s is not an object or null", describe(result))
+		}
+	}
+	return regexpBuiltinExec(interpreter, thisRegExp(interpreter, object, "exec"), text)
+}
+
+// regexpBuiltinExec matches at lastIndex and builds the match array.
+func regexpBuiltinExec(interpreter types.Interpreter, object *regexpImpl, text string) types.Object {
+	re := object.re
+	lastIndex := toLength(interpreter, object.Get("lastIndex"))
+	global := re.Global() || re.Sticky()
+	if !global {
+		lastIndex = 0
+	}
+	units := toUTF16(text)
+	if lastIndex > int64(len(units)) {
+		if global {
+			object.Set("lastIndex", int64(0))
+		}
+		return nil
+	}
+	captures, err := re.Exec(units, int(lastIndex))
+	if err != nil {
+		ThrowRangeError(interpreter, "%s", err.Error())
+	}
+	if captures == nil {
+		if global {
+			object.Set("lastIndex", int64(0))
+		}
+		return nil
+	}
+	if global {
+		object.Set("lastIndex", int64(captures[1]))
+	}
+	values := make([]any, re.Groups()+1)
+	indices := make([]any, re.Groups()+1)
+	for i := range values {
+		start, end := captures[2*i], captures[2*i+1]
+		if start >= 0 {
+			values[i] = fromUTF16(units[start:end])
+			indices[i] = NewArrayFrom(interpreter, []any{int64(start), int64(end)})
+		}
+	}
+	result := NewArrayFrom(interpreter, values)
+	result.Set("index", int64(captures[0]))
+	result.Set("input", text)
+	var groups, indexGroups any
+	if re.HasNamedGroups() {
+		names := NewObject(nil)
+		positions := NewObject(nil)
+		for i, name := range re.GroupNames() {
+			if name != "" {
+				names.Set(name, values[i+1])
+				positions.Set(name, indices[i+1])
+			}
+		}
+		groups, indexGroups = names, positions
+	}
+	result.Set("groups", groups)
+	if re.HasIndices() {
+		pairs := NewArrayFrom(interpreter, indices)
+		pairs.Set("groups", indexGroups)
+		result.Set("indices", pairs)
+	}
+	return result
+}
+
+func regexpBuiltinExecNative(interpreter types.Interpreter, this any, params []any) any {
+	return nullable(regexpBuiltinExec(interpreter, thisRegExp(interpreter, this, "exec"), ToString(interpreter, GetArgument(params, 0))))
+}
+
+// advanceStringIndex steps past an empty match, by a whole code point in
+// unicode mode.
+func advanceStringIndex(text string, index int64, unicode bool) int64 {
+	units := toUTF16(text)
+	if unicode && index+1 < int64(len(units)) && units[index] >= 0xD800 && units[index] <= 0xDBFF && units[index+1] >= 0xDC00 && units[index+1] <= 0xDFFF {
+		return index + 2
+	}
+	return index + 1
+}
+
+// stepPastEmptyMatch advances lastIndex after a match of the empty string,
+// so global matching can not loop forever.
+func stepPastEmptyMatch(interpreter types.Interpreter, object types.Object, match types.Object, text string, unicode bool) {
+	if ToString(interpreter, match.Get(int64(0))) == "" {
+		lastIndex := toLength(interpreter, object.Get("lastIndex"))
+		object.Set("lastIndex", advanceStringIndex(text, lastIndex, unicode))
+	}
+}
+
+func flagsOf(interpreter types.Interpreter, object types.Object) string {
+	return ToString(interpreter, object.Get("flags"))
+}
+
+func newRegExpPrototype(realm *realm) *instanceImpl {
+	prototype := NewObject(realm.objectPrototype).(*instanceImpl)
+	method := func(key any, name string, fn func(interpreter types.Interpreter, object types.Object, text string, params []any) any) {
+		prototype.define(key, newNative(realm, name, func(interpreter types.Interpreter, this any, params []any) any {
+			object := thisRegExpObject(interpreter, this, name)
+			return fn(interpreter, object, ToString(interpreter, GetArgument(params, 0)), params)
+		}), false)
+	}
+	prototype.define("exec", realm.regexpBuiltinExecFunction, false)
+	method("test", "test", func(interpreter types.Interpreter, object types.Object, text string, params []any) any {
+		return regexpExec(interpreter, object, text) != nil
+	})
+	prototype.define("toString", newNative(realm, "toString", func(interpreter types.Interpreter, this any, params []any) any {
+		object := thisRegExpObject(interpreter, this, "toString")
+		return "/" + ToString(interpreter, object.Get("source")) + "/" + flagsOf(interpreter, object)
+	}), false)
+	method(SymbolMatch, "[Symbol.match]", func(interpreter types.Interpreter, object types.Object, text string, params []any) any {
+		flags := flagsOf(interpreter, object)
+		if !strings.Contains(flags, "g") {
+			return nullable(regexpExec(interpreter, object, text))
+		}
+		object.Set("lastIndex", int64(0))
+		var matches []any
+		for {
+			result := regexpExec(interpreter, object, text)
+			if result == nil {
+				break
+			}
+			matches = append(matches, ToString(interpreter, result.Get(int64(0))))
+			stepPastEmptyMatch(interpreter, object, result, text, strings.Contains(flags, "u"))
+		}
+		if len(matches) == 0 {
+			return types.Null{}
+		}
+		return NewArrayFrom(interpreter, matches)
+	})
+	method(SymbolMatchAll, "[Symbol.matchAll]", func(interpreter types.Interpreter, object types.Object, text string, params []any) any {
+		flags := flagsOf(interpreter, object)
+		matcher := NewRegExp(interpreter, sourceOf(interpreter, object), flags)
+		matcher.Set("lastIndex", toLength(interpreter, object.Get("lastIndex")))
+		return &regexpStringIteratorImpl{
+			instanceImpl: NewObject(realm.regexpStringIteratorPrototype).(*instanceImpl),
+			matcher:      matcher,
+			text:         text,
+			global:       strings.Contains(flags, "g"),
+			unicode:      strings.Contains(flags, "u"),
+		}
+	})
+	method(SymbolReplace, "[Symbol.replace]", func(interpreter types.Interpreter, object types.Object, text string, params []any) any {
+		return replaceRegExp(interpreter, object, text, GetArgument(params, 1))
+	})
+	method(SymbolSearch, "[Symbol.search]", func(interpreter types.Interpreter, object types.Object, text string, params []any) any {
+		previous := object.Get("lastIndex")
+		if !StrictEquals(previous, int64(0)) {
+			object.Set("lastIndex", int64(0))
+		}
+		result := regexpExec(interpreter, object, text)
+		if !StrictEquals(object.Get("lastIndex"), previous) {
+			object.Set("lastIndex", previous)
+		}
+		if result == nil {
+			return int64(-1)
+		}
+		return result.Get("index")
+	})
+	method(SymbolSplit, "[Symbol.split]", func(interpreter types.Interpreter, object types.Object, text string, params []any) any {
+		return splitRegExp(interpreter, object, text, GetArgument(params, 1))
+	})
+	return prototype
+}
+
+func sourceOf(interpreter types.Interpreter, object types.Object) string {
+	if object, ok := object.(*regexpImpl); ok {
+		return object.re.Source()
+	}
+	return ToString(interpreter, object.Get("source"))
+}
+
+// replaceRegExp implements RegExp.prototype[Symbol.replace].
+func replaceRegExp(interpreter types.Interpreter, object types.Object, text string, replacement any) string {
+	_, functional := replacement.(types.Function)
+	if !functional {
+		replacement = ToString(interpreter, replacement)
+	}
+	flags := flagsOf(interpreter, object)
+	global := strings.Contains(flags, "g")
+	if global {
+		object.Set("lastIndex", int64(0))
+	}
+	var results []types.Object
+	for {
+		result := regexpExec(interpreter, object, text)
+		if result == nil {
+			break
+		}
+		results = append(results, result)
+		if !global {
+			break
+		}
+		stepPastEmptyMatch(interpreter, object, result, text, strings.Contains(flags, "u"))
+	}
+	units := toUTF16(text)
+	var accumulated strings.Builder
+	next := 0
+	for _, result := range results {
+		count := lengthOf(interpreter, result) - 1
+		if count < 0 {
+			count = 0
+		}
+		matched := ToString(interpreter, result.Get(int64(0)))
+		position := clampIndex(interpreter, result.Get("index"), len(units))
+		captures := make([]any, count)
+		for n := int64(1); n <= count; n++ {
+			if capture := result.Get(n); capture != nil {
+				captures[n-1] = ToString(interpreter, capture)
+			}
+		}
+		groups := result.Get("groups")
+		var value string
+		if functional {
+			arguments := append([]any{matched}, captures...)
+			arguments = append(arguments, int64(position), text)
+			if groups != nil {
+				arguments = append(arguments, groups)
+			}
+			value = ToString(interpreter, Invoke(interpreter, replacement, nil, arguments))
+		} else {
+			if groups != nil {
+				groups = ToObject(interpreter, groups)
+			}
+			value = GetSubstitution(interpreter, matched, text, position, captures, groups, replacement.(string))
+		}
+		if position >= next {
+			accumulated.WriteString(fromUTF16(units[next:position]))
+			accumulated.WriteString(value)
+			next = position + len(toUTF16(matched))
+		}
+	}
+	if next < len(units) {
+		accumulated.WriteString(fromUTF16(units[next:]))
+	}
+	return joinSurrogates(accumulated.String())
+}
+
+// splitRegExp implements RegExp.prototype[Symbol.split] with a sticky copy
+// of the expression, tried at every position.
+func splitRegExp(interpreter types.Interpreter, object types.Object, text string, limit any) types.Object {
+	flags := flagsOf(interpreter, object)
+	unicode := strings.Contains(flags, "u")
+	if !strings.Contains(flags, "y") {
+		flags += "y"
+	}
+	splitter := NewRegExp(interpreter, sourceOf(interpreter, object), flags)
+	max := int64(maxArrayLength)
+	if limit != nil {
+		max = int64(ToUint32(interpreter, limit))
+	}
+	var result []any
+	if max == 0 {
+		return NewArrayFrom(interpreter, result)
+	}
+	units := toUTF16(text)
+	size := int64(len(units))
+	if size == 0 {
+		if regexpExec(interpreter, splitter, text) != nil {
+			return NewArrayFrom(interpreter, result)
+		}
+		return NewArrayFrom(interpreter, []any{text})
+	}
+	p := int64(0)
+	for q := p; q < size; {
+		splitter.Set("lastIndex", q)
+		match := regexpExec(interpreter, splitter, text)
+		if match == nil {
+			q = advanceStringIndex(text, q, unicode)
+			continue
+		}
+		e := toLength(interpreter, splitter.Get("lastIndex"))
+		if e > size {
+			e = size
+		}
+		if e == p {
+			q = advanceStringIndex(text, q, unicode)
+			continue
+		}
+		result = append(result, fromUTF16(units[p:q]))
+		if int64(len(result)) == max {
+			return NewArrayFrom(interpreter, result)
+		}
+		p = e
+		for i := int64(1); i < lengthOf(interpreter, match); i++ {
+			result = append(result, match.Get(i))
+			if int64(len(result)) == max {
+				return NewArrayFrom(interpreter, result)
+			}
+		}
+		q = p
+	}
+	return NewArrayFrom(interpreter, append(result, fromUTF16(units[p:])))
+}
+
+// regexpStringIteratorImpl is the iterator matchAll returns.
+type regexpStringIteratorImpl struct {
+	*instanceImpl
+	matcher types.Object
+	text    string
+	global  bool
+	unicode bool
+	done    bool
+}
+
+func newRegExpStringIteratorPrototype(realm *realm) types.Object {
+	prototype := NewObject(realm.objectPrototype).(*instanceImpl)
+	prototype.define("next", newNative(realm, "next", func(interpreter types.Interpreter, this any, params []any) any {
+		iterator, ok := this.(*regexpStringIteratorImpl)
+		if !ok {
+			ThrowTypeError(interpreter, "next method called on incompatible receiver %s", describe(this))
+		}
+		if iterator.done {
+			return NewIteratorResult(interpreter, nil, true)
+		}
+		match := regexpExec(interpreter, iterator.matcher, iterator.text)
+		if match == nil {
+			iterator.done = true
+			return NewIteratorResult(interpreter, nil, true)
+		}
+		if iterator.global {
+			stepPastEmptyMatch(interpreter, iterator.matcher, match, iterator.text, iterator.unicode)
+		} else {
+			iterator.done = true
+		}
+		return NewIteratorResult(interpreter, match, false)
+	}), false)
+	prototype.define(SymbolIterator, newNative(realm, "[Symbol.iterator]", func(interpreter types.Interpreter, this any, params []any) any {
+		return this
+	}), false)
+	prototype.define(SymbolToStringTag, "RegExp String Iterator", false)
+	return prototype
+}
+
+// regexpCreate compiles the pattern String.prototype.match and its
+// relatives use for a value that is not a regular expression.
+func regexpCreate(interpreter types.Interpreter, pattern any, flags string) types.Object {
+	if pattern == nil {
+		return NewRegExp(interpreter, "", flags)
+	}
+	return NewRegExp(interpreter, ToString(interpreter, pattern), flags)
+}
+
+func newRegExpConstructor(realm *realm) types.Object {
+	create := func(interpreter types.Interpreter, params []any) types.Object {
+		pattern := GetArgument(params, 0)
+		flags := GetArgument(params, 1)
+		if object, ok := pattern.(*regexpImpl); ok {
+			pattern = object.re.Source()
+			if flags == nil {
+				flags = object.re.Flags()
+			}
+		} else if IsRegExp(interpreter, pattern) {
+			source := GetProperty(interpreter, pattern, "source")
+			if flags == nil {
+				flags = GetProperty(interpreter, pattern, "flags")
+			}
+			pattern = source
+		}
+		var p, f string
+		if pattern != nil {
+			p = ToString(interpreter, pattern)
+		}
+		if flags != nil {
+			f = ToString(interpreter, flags)
+		}
+		return NewRegExp(interpreter, p, f)
+	}
+	var constructor *nativeImpl
+	constructor = newConstructor(realm, "RegExp", func(interpreter types.Interpreter, this any, params []any) any {
+		pattern := GetArgument(params, 0)
+		if IsRegExp(interpreter, pattern) && GetArgument(params, 1) == nil && GetProperty(interpreter, pattern, "constructor") == constructor {
+			return pattern
+		}
+		return create(interpreter, params)
+	}, func(interpreter types.Interpreter, params []any) any {
+		return create(interpreter, params)
+	}).(*nativeImpl)
+	constructor.define("prototype", realm.regexpPrototype, false)
+	realm.regexpPrototype.define("constructor", constructor, false)
+	return constructor
+}
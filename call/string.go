@@ -0,0 +1,870 @@
+package call
+
+import (
+	"math"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/nusr/gojs/types"
+)
+
+// maxStringLength is the longest string V8 can create.
+const maxStringLength = 1<<29 - 24
+
+// stringImpl is a String object, the boxed form of a string primitive.
+type stringImpl struct {
+	*instanceImpl
+	value string
+}
+
+func newStringObject(interpreter types.Interpreter, value string) *stringImpl {
+	return &stringImpl{
+		instanceImpl: NewObject(realmOf(interpreter).stringPrototype).(*instanceImpl),
+		value:        value,
+	}
+}
+
+// Strings are stored in WTF-8: UTF-8 that also encodes a surrogate which
+// is not part of a pair, as three bytes like any code point of its size, so
+// that every sequence of UTF-16 code units survives the round trip.
+
+// decodeRune decodes the code point at the start of text like
+// utf8.DecodeRuneInString, returning a lone surrogate as itself.
+func decodeRune(text string) (rune, int) {
+	if len(text) >= 3 && text[0] == 0xED && text[1] >= 0xA0 && text[1] <= 0xBF && text[2] >= 0x80 && text[2] <= 0xBF {
+		return 0xD000 | rune(text[1]&0x3F)<<6 | rune(text[2]&0x3F), 3
+	}
+	return utf8.DecodeRuneInString(text)
+}
+
+// codePoints splits text into code points, keeping lone surrogates.
+func codePoints(text string) []rune {
+	units := toUTF16(text)
+	list := make([]rune, 0, len(units))
+	for i := 0; i < len(units); i++ {
+		r := rune(units[i])
+		if utf16.IsSurrogate(r) && i+1 < len(units) {
+			if pair := utf16.DecodeRune(r, rune(units[i+1])); pair != unicode.ReplacementChar {
+				r = pair
+				i++
+			}
+		}
+		list = append(list, r)
+	}
+	return list
+}
+
+// appendRune appends the WTF-8 encoding of r to buffer.
+func appendRune(buffer []byte, r rune) []byte {
+	if utf16.IsSurrogate(r) {
+		return append(buffer, 0xED, byte(0x80|r>>6&0x3F), byte(0x80|r&0x3F))
+	}
+	return utf8.AppendRune(buffer, r)
+}
+
+// toUTF16 splits a string into the UTF-16 code units JavaScript indexes.
+func toUTF16(text string) []uint16 {
+	units := make([]uint16, 0, len(text))
+	for len(text) > 0 {
+		r, size := decodeRune(text)
+		text = text[size:]
+		if r >= 0x10000 {
+			high, low := utf16.EncodeRune(r)
+			units = append(units, uint16(high), uint16(low))
+		} else {
+			units = append(units, uint16(r))
+		}
+	}
+	return units
+}
+
+func fromUTF16(units []uint16) string {
+	buffer := make([]byte, 0, len(units))
+	for i := 0; i < len(units); i++ {
+		r := rune(units[i])
+		if utf16.IsSurrogate(r) && i+1 < len(units) {
+			if pair := utf16.DecodeRune(r, rune(units[i+1])); pair != unicode.ReplacementChar {
+				r = pair
+				i++
+			}
+		}
+		buffer = appendRune(buffer, r)
+	}
+	return string(buffer)
+}
+
+// Concat joins two strings, encoding a high surrogate at the end of left
+// and a low one at the start of right as the pair they form, so that equal
+// strings are stored alike.
+func Concat(left string, right string) string {
+	n := len(left)
+	if n >= 3 && len(right) >= 3 {
+		high, size := decodeRune(left[n-3:])
+		low, _ := decodeRune(right)
+		if size == 3 && high >= 0xD800 && high < 0xDC00 && low >= 0xDC00 && low <= 0xDFFF {
+			return left[:n-3] + string(utf16.DecodeRune(high, low)) + right[3:]
+		}
+	}
+	return left + right
+}
+
+// joinSurrogates is Concat for a string assembled from several pieces: it
+// encodes every high surrogate followed by a low one as their pair.
+func joinSurrogates(text string) string {
+	if !strings.Contains(text, "\xed") {
+		return text
+	}
+	return fromUTF16(toUTF16(text))
+}
+
+// isWellFormed reports whether text has no lone surrogates.
+func isWellFormed(text string) bool {
+	return utf8.ValidString(text)
+}
+
+// toWellFormed replaces the lone surrogates of text with U+FFFD, for output
+// that must be valid UTF-8.
+func toWellFormed(text string) string {
+	if isWellFormed(text) {
+		return text
+	}
+	return string(utf16.Decode(toUTF16(text)))
+}
+
+// mapWellFormed applies fn to the parts of text between lone surrogates,
+// for functions of the strings package, which do not keep them.
+func mapWellFormed(text string, fn func(text string) string) string {
+	if isWellFormed(text) {
+		return fn(text)
+	}
+	var buffer []byte
+	start := 0
+	for i := 0; i < len(text); {
+		r, size := decodeRune(text[i:])
+		if utf16.IsSurrogate(r) {
+			buffer = append(buffer, fn(text[start:i])...)
+			buffer = append(buffer, text[i:i+size]...)
+			start = i + size
+		}
+		i += size
+	}
+	return string(append(buffer, fn(text[start:])...))
+}
+
+// getStringProperty reads a property of a string primitive.
+func getStringProperty(interpreter types.Interpreter, text string, key any) any {
+	if value, ok := stringOwnProperty(text, key); ok {
+		return value
+	}
+	return realmOf(interpreter).stringPrototype.Get(key)
+}
+
+// stringOwnProperty reads the length or a character of a string.
+func stringOwnProperty(text string, key any) (any, bool) {
+	if key == "length" {
+		return int64(len(toUTF16(text))), true
+	}
+	if i, ok := arrayIndex(key); ok {
+		if units := toUTF16(text); i < int64(len(units)) {
+			return fromUTF16(units[i : i+1]), true
+		}
+	}
+	return nil, false
+}
+
+func (object *stringImpl) isOwnIndex(key any) bool {
+	i, ok := arrayIndex(key)
+	return ok && i < int64(len(toUTF16(object.value)))
+}
+
+func (object *stringImpl) Get(key any) any {
+	if value, ok := stringOwnProperty(object.value, key); ok {
+		return value
+	}
+	return object.instanceImpl.Get(key)
+}
+
+// Set ignores writes to length and the characters, which are read-only.
+func (object *stringImpl) Set(key any, value any) {
+	if key == "length" || object.isOwnIndex(key) {
+		return
+	}
+	object.instanceImpl.Set(key, value)
+}
+
+func (object *stringImpl) Has(key any) bool {
+	return key == "length" || object.isOwnIndex(key) || object.instanceImpl.Has(key)
+}
+
+func (object *stringImpl) Delete(key any) bool {
+	if key == "length" || object.isOwnIndex(key) {
+		return false
+	}
+	return object.instanceImpl.Delete(key)
+}
+
+func (object *stringImpl) OwnKeys() []any {
+	var keys []any
+	for i := range toUTF16(object.value) {
+		keys = append(keys, ToPropertyKey(int64(i)))
+	}
+	keys = append(keys, "length")
+	return append(keys, object.instanceImpl.OwnKeys()...)
+}
+
+func (object *stringImpl) IsEnumerable(key any) bool {
+	return object.isOwnIndex(key) || (key != "length" && object.instanceImpl.IsEnumerable(key))
+}
+
+func (object *stringImpl) getOwnProperty(key any) (propertyDescriptor, bool) {
+	if value, ok := stringOwnProperty(object.value, key); ok {
+		return dataDescriptor(value, false, key != "length", false), true
+	}
+	return object.instanceImpl.getOwnProperty(key)
+}
+
+// defineOwnProperty can not change length or the characters, but accepts a
+// descriptor that matches them.
+func (object *stringImpl) defineOwnProperty(key any, desc propertyDescriptor) bool {
+	if key == "length" || object.isOwnIndex(key) {
+		current, _ := object.getOwnProperty(key)
+		return compatibleDescriptor(false, desc, current, true)
+	}
+	return object.instanceImpl.defineOwnProperty(key, desc)
+}
+
+func thisString(interpreter types.Interpreter, this any, name string) string {
+	switch data := this.(type) {
+	case nil, types.Null:
+		ThrowTypeError(interpreter, "String.prototype.%s called on null or undefined", name)
+	case string:
+		return data
+	case *stringImpl:
+		return data.value
+	}
+	return ToString(interpreter, this)
+}
+
+// isJSWhiteSpace reports whether r is white space or a line terminator to
+// String.prototype.trim.
+func isJSWhiteSpace(r rune) bool {
+	switch r {
+	case '\t', '\n', '\v', '\f', '\r', ' ', 0xA0, 0x1680, 0x2028, 0x2029, 0x202F, 0x205F, 0x3000, 0xFEFF:
+		return true
+	}
+	return r >= 0x2000 && r <= 0x200A
+}
+
+// indexOfUnits finds search in units at or after from, returning -1 when
+// it is missing.
+func indexOfUnits(units []uint16, search []uint16, from int) int {
+	for i := from; i+len(search) <= len(units); i++ {
+		if unitsEqual(units[i:i+len(search)], search) {
+			return i
+		}
+	}
+	return -1
+}
+
+func unitsEqual(a []uint16, b []uint16) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// clampIndex converts a position argument to an index in [0, length].
+func clampIndex(interpreter types.Interpreter, value any, length int) int {
+	return int(math.Min(math.Max(toIntegerOrInfinity(interpreter, value), 0), float64(length)))
+}
+
+// searchArgument converts the search string of includes, startsWith and
+// endsWith, which must not be a regular expression.
+func searchArgument(interpreter types.Interpreter, params []any, name string) []uint16 {
+	search := GetArgument(params, 0)
+	if IsRegExp(interpreter, search) {
+		ThrowTypeError(interpreter, "First argument to String.prototype.%s must not be a regular expression", name)
+	}
+	return toUTF16(ToString(interpreter, search))
+}
+
+// GetSubstitution expands the $ patterns of a replacement string. captures
+// holds the groups of a regular expression match, whose named groups are
+// in groups.
+func GetSubstitution(interpreter types.Interpreter, matched string, text string, position int, captures []any, groups any, replacement string) string {
+	units := toUTF16(text)
+	tail := position + len(toUTF16(matched))
+	if tail > len(units) {
+		tail = len(units)
+	}
+	var result strings.Builder
+	for i := 0; i < len(replacement); i++ {
+		c := replacement[i]
+		if c != '$' || i+1 == len(replacement) {
+			result.WriteByte(c)
+			continue
+		}
+		next := replacement[i+1]
+		switch {
+		case next == '$':
+			result.WriteByte('$')
+			i++
+		case next == '&':
+			result.WriteString(matched)
+			i++
+		case next == '`':
+			result.WriteString(fromUTF16(units[:position]))
+			i++
+		case next == '\'':
+			result.WriteString(fromUTF16(units[tail:]))
+			i++
+		case next >= '0' && next <= '9':
+			index := int(next - '0')
+			digits := 1
+			if i+2 < len(replacement) && replacement[i+2] >= '0' && replacement[i+2] <= '9' {
+				if two := index*10 + int(replacement[i+2]-'0'); two >= 1 && two <= len(captures) {
+					index = two
+					digits = 2
+				}
+			}
+			if index < 1 || index > len(captures) {
+				result.WriteByte('$')
+				continue
+			}
+			if capture := captures[index-1]; capture != nil {
+				result.WriteString(ToString(interpreter, capture))
+			}
+			i += digits
+		case next == '<':
+			end := strings.IndexByte(replacement[i+2:], '>')
+			if groups == nil || end < 0 {
+				result.WriteByte('$')
+				continue
+			}
+			name := replacement[i+2 : i+2+end]
+			if capture := GetProperty(interpreter, groups, name); capture != nil {
+				result.WriteString(ToString(interpreter, capture))
+			}
+			i += end + 2
+		default:
+			result.WriteByte('$')
+		}
+	}
+	return result.String()
+}
+
+// toUpperCase maps a string to upper case, including the characters that
+// expand to several letters.
+func toUpperCase(text string) string {
+	return mapWellFormed(text, func(text string) string {
+		return strings.ToUpper(strings.ReplaceAll(text, "ß", "SS"))
+	})
+}
+
+// toLowerCase maps a string to lower case, using the final form of sigma at
+// the end of a word.
+func toLowerCase(text string) string {
+	return mapWellFormed(text, lowerCase)
+}
+
+func lowerCase(text string) string {
+	list := []rune(text)
+	var result strings.Builder
+	for i, r := range list {
+		switch {
+		case r == 'İ':
+			result.WriteString("i\u0307")
+		case r == 'Σ' && i > 0 && unicode.IsLetter(list[i-1]) && (i+1 == len(list) || !unicode.IsLetter(list[i+1])):
+			result.WriteRune('ς')
+		default:
+			result.WriteRune(unicode.ToLower(r))
+		}
+	}
+	return result.String()
+}
+
+// collationKey splits a string into the letters, accents and case that
+// localeCompare compares in turn.
+func collationKey(text string) ([]rune, []rune, []rune) {
+	var primary, secondary, tertiary []rune
+	for _, r := range decomposeString(text, true) {
+		if combiningClasses[r] != 0 {
+			secondary = append(secondary, r)
+			continue
+		}
+		secondary = append(secondary, 0)
+		lower := unicode.ToLower(r)
+		primary = append(primary, lower)
+		if lower != r {
+			tertiary = append(tertiary, 1)
+		} else {
+			tertiary = append(tertiary, 0)
+		}
+	}
+	return primary, secondary, tertiary
+}
+
+func compareRunes(a []rune, b []rune) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			if a[i] < b[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	switch {
+	case len(a) < len(b):
+		return -1
+	case len(a) > len(b):
+		return 1
+	}
+	return 0
+}
+
+// LocaleCompare orders strings by letters, then accents, then case, with
+// lower case first.
+func LocaleCompare(a string, b string) int {
+	p1, s1, t1 := collationKey(a)
+	p2, s2, t2 := collationKey(b)
+	if result := compareRunes(p1, p2); result != 0 {
+		return result
+	}
+	if result := compareRunes(s1, s2); result != 0 {
+		return result
+	}
+	return compareRunes(t1, t2)
+}
+
+// padString pads text with fill to maxLength code units.
+func padString(interpreter types.Interpreter, text string, params []any, atStart bool) string {
+	units := toUTF16(text)
+	var maxLength int64
+	if value := GetArgument(params, 0); value != nil {
+		maxLength = int64(math.Max(toIntegerOrInfinity(interpreter, value), 0))
+	}
+	if maxLength <= int64(len(units)) {
+		return text
+	}
+	fill := []uint16{' '}
+	if value := GetArgument(params, 1); value != nil {
+		fill = toUTF16(ToString(interpreter, value))
+	}
+	if len(fill) == 0 {
+		return text
+	}
+	if maxLength > maxStringLength {
+		ThrowRangeError(interpreter, "Invalid string length")
+	}
+	padding := make([]uint16, 0, maxLength-int64(len(units)))
+	for int64(len(padding)) < maxLength-int64(len(units)) {
+		padding = append(padding, fill[int64(len(padding))%int64(len(fill))])
+	}
+	if atStart {
+		return Concat(fromUTF16(padding), text)
+	}
+	return Concat(text, fromUTF16(padding))
+}
+
+func newStringPrototype(realm *realm) *instanceImpl {
+	prototype := NewObject(realm.objectPrototype).(*instanceImpl)
+	method := func(name string, fn func(interpreter types.Interpreter, text string, params []any) any) {
+		prototype.define(name, newNative(realm, name, func(interpreter types.Interpreter, this any, params []any) any {
+			return fn(interpreter, thisString(interpreter, this, name), params)
+		}), false)
+	}
+	valueOf := func(name string) types.Method {
+		return newNative(realm, name, func(interpreter types.Interpreter, this any, params []any) any {
+			switch data := this.(type) {
+			case string:
+				return data
+			case *stringImpl:
+				return data.value
+			}
+			ThrowTypeError(interpreter, "String.prototype.%s requires that 'this' be a String", name)
+			return nil
+		})
+	}
+	prototype.define("toString", valueOf("toString"), false)
+	prototype.define("valueOf", valueOf("valueOf"), false)
+
+	method("at", func(interpreter types.Interpreter, text string, params []any) any {
+		units := toUTF16(text)
+		k := toIntegerOrInfinity(interpreter, GetArgument(params, 0))
+		if k < 0 {
+			k += float64(len(units))
+		}
+		if k < 0 || k >= float64(len(units)) {
+			return nil
+		}
+		return fromUTF16(units[int(k) : int(k)+1])
+	})
+	method("charAt", func(interpreter types.Interpreter, text string, params []any) any {
+		units := toUTF16(text)
+		k := toIntegerOrInfinity(interpreter, GetArgument(params, 0))
+		if k < 0 || k >= float64(len(units)) {
+			return ""
+		}
+		return fromUTF16(units[int(k) : int(k)+1])
+	})
+	method("charCodeAt", func(interpreter types.Interpreter, text string, params []any) any {
+		units := toUTF16(text)
+		k := toIntegerOrInfinity(interpreter, GetArgument(params, 0))
+		if k < 0 || k >= float64(len(units)) {
+			return math.NaN()
+		}
+		return int64(units[int(k)])
+	})
+	method("codePointAt", func(interpreter types.Interpreter, text string, params []any) any {
+		units := toUTF16(text)
+		k := toIntegerOrInfinity(interpreter, GetArgument(params, 0))
+		if k < 0 || k >= float64(len(units)) {
+			return nil
+		}
+		i := int(k)
+		if utf16.IsSurrogate(rune(units[i])) && i+1 < len(units) {
+			if r := utf16.DecodeRune(rune(units[i]), rune(units[i+1])); r != unicode.ReplacementChar {
+				return int64(r)
+			}
+		}
+		return int64(units[i])
+	})
+	method("concat", func(interpreter types.Interpreter, text string, params []any) any {
+		var result strings.Builder
+		result.WriteString(text)
+		for _, item := range params {
+			result.WriteString(ToString(interpreter, item))
+		}
+		return joinSurrogates(result.String())
+	})
+	method("endsWith", func(interpreter types.Interpreter, text string, params []any) any {
+		units := toUTF16(text)
+		search := searchArgument(interpreter, params, "endsWith")
+		end := len(units)
+		if value := GetArgument(params, 1); value != nil {
+			end = clampIndex(interpreter, value, len(units))
+		}
+		start := end - len(search)
+		return start >= 0 && unitsEqual(units[start:end], search)
+	})
+	method("includes", func(interpreter types.Interpreter, text string, params []any) any {
+		units := toUTF16(text)
+		search := searchArgument(interpreter, params, "includes")
+		return indexOfUnits(units, search, clampIndex(interpreter, GetArgument(params, 1), len(units))) >= 0
+	})
+	method("indexOf", func(interpreter types.Interpreter, text string, params []any) any {
+		units := toUTF16(text)
+		search := toUTF16(ToString(interpreter, GetArgument(params, 0)))
+		return int64(indexOfUnits(units, search, clampIndex(interpreter, GetArgument(params, 1), len(units))))
+	})
+	method("lastIndexOf", func(interpreter types.Interpreter, text string, params []any) any {
+		units := toUTF16(text)
+		search := toUTF16(ToString(interpreter, GetArgument(params, 0)))
+		start := len(units)
+		if position := ToNumber(interpreter, GetArgument(params, 1)); !math.IsNaN(position) {
+			start = clampIndex(interpreter, position, len(units))
+		}
+		if start > len(units)-len(search) {
+			start = len(units) - len(search)
+		}
+		for i := start; i >= 0; i-- {
+			if unitsEqual(units[i:i+len(search)], search) {
+				return int64(i)
+			}
+		}
+		return int64(-1)
+	})
+	method("localeCompare", func(interpreter types.Interpreter, text string, params []any) any {
+		that := ToString(interpreter, GetArgument(params, 0))
+		if locales, options := GetArgument(params, 1), GetArgument(params, 2); locales != nil || options != nil {
+			return int64(newCollator(interpreter, locales, options).compare(text, that))
+		}
+		return int64(LocaleCompare(text, that))
+	})
+	method("match", func(interpreter types.Interpreter, text string, params []any) any {
+		regexp := GetArgument(params, 0)
+		if regexp != nil {
+			if matcher := GetProperty(interpreter, regexp, SymbolMatch); matcher != nil {
+				return Invoke(interpreter, matcher, regexp, []any{text})
+			}
+		}
+		matcher := regexpCreate(interpreter, regexp, "")
+		return Invoke(interpreter, matcher.Get(SymbolMatch), matcher, []any{text})
+	})
+	method("matchAll", func(interpreter types.Interpreter, text string, params []any) any {
+		regexp := GetArgument(params, 0)
+		if regexp != nil {
+			if IsRegExp(interpreter, regexp) && !strings.Contains(ToString(interpreter, GetProperty(interpreter, regexp, "flags")), "g") {
+				ThrowTypeError(interpreter, "String.prototype.matchAll called with a non-global RegExp argument")
+			}
+			if matcher := GetProperty(interpreter, regexp, SymbolMatchAll); matcher != nil {
+				return Invoke(interpreter, matcher, regexp, []any{text})
+			}
+		}
+		matcher := regexpCreate(interpreter, regexp, "g")
+		return Invoke(interpreter, matcher.Get(SymbolMatchAll), matcher, []any{text})
+	})
+	method("normalize", func(interpreter types.Interpreter, text string, params []any) any {
+		form := "NFC"
+		if value := GetArgument(params, 0); value != nil {
+			form = ToString(interpreter, value)
+		}
+		switch form {
+		case "NFC", "NFD", "NFKC", "NFKD":
+			return mapWellFormed(text, func(text string) string {
+				return Normalize(text, form)
+			})
+		}
+		ThrowRangeError(interpreter, "The normalization form should be one of NFC, NFD, NFKC, NFKD.")
+		return nil
+	})
+	method("padEnd", func(interpreter types.Interpreter, text string, params []any) any {
+		return padString(interpreter, text, params, false)
+	})
+	method("padStart", func(interpreter types.Interpreter, text string, params []any) any {
+		return padString(interpreter, text, params, true)
+	})
+	method("repeat", func(interpreter types.Interpreter, text string, params []any) any {
+		count := toIntegerOrInfinity(interpreter, GetArgument(params, 0))
+		if count < 0 || math.IsInf(count, 1) {
+			ThrowRangeError(interpreter, "Invalid count value: %s", NumberToString(count))
+		}
+		if text == "" || count == 0 {
+			return ""
+		}
+		if float64(len(toUTF16(text)))*count > maxStringLength {
+			ThrowRangeError(interpreter, "Invalid string length")
+		}
+		return joinSurrogates(strings.Repeat(text, int(count)))
+	})
+	method("replace", func(interpreter types.Interpreter, text string, params []any) any {
+		return replaceString(interpreter, text, params, false)
+	})
+	method("replaceAll", func(interpreter types.Interpreter, text string, params []any) any {
+		if pattern := GetArgument(params, 0); IsRegExp(interpreter, pattern) && !strings.Contains(ToString(interpreter, GetProperty(interpreter, pattern, "flags")), "g") {
+			ThrowTypeError(interpreter, "String.prototype.replaceAll called with a non-global RegExp argument")
+		}
+		return replaceString(interpreter, text, params, true)
+	})
+	method("search", func(interpreter types.Interpreter, text string, params []any) any {
+		regexp := GetArgument(params, 0)
+		if regexp != nil {
+			if searcher := GetProperty(interpreter, regexp, SymbolSearch); searcher != nil {
+				return Invoke(interpreter, searcher, regexp, []any{text})
+			}
+		}
+		searcher := regexpCreate(interpreter, regexp, "")
+		return Invoke(interpreter, searcher.Get(SymbolSearch), searcher, []any{text})
+	})
+	method("slice", func(interpreter types.Interpreter, text string, params []any) any {
+		units := toUTF16(text)
+		start := relativeIndex(interpreter, GetArgument(params, 0), int64(len(units)))
+		end := endIndex(interpreter, GetArgument(params, 1), int64(len(units)))
+		if start >= end {
+			return ""
+		}
+		return fromUTF16(units[start:end])
+	})
+	method("split", func(interpreter types.Interpreter, text string, params []any) any {
+		return splitString(interpreter, text, params)
+	})
+	method("startsWith", func(interpreter types.Interpreter, text string, params []any) any {
+		units := toUTF16(text)
+		search := searchArgument(interpreter, params, "startsWith")
+		start := clampIndex(interpreter, GetArgument(params, 1), len(units))
+		return start+len(search) <= len(units) && unitsEqual(units[start:start+len(search)], search)
+	})
+	method("substr", func(interpreter types.Interpreter, text string, params []any) any {
+		units := toUTF16(text)
+		start := relativeIndex(interpreter, GetArgument(params, 0), int64(len(units)))
+		end := int64(len(units))
+		if value := GetArgument(params, 1); value != nil {
+			end = start + int64(math.Min(math.Max(toIntegerOrInfinity(interpreter, value), 0), float64(end-start)))
+		}
+		return fromUTF16(units[start:end])
+	})
+	method("substring", func(interpreter types.Interpreter, text string, params []any) any {
+		units := toUTF16(text)
+		start := clampIndex(interpreter, GetArgument(params, 0), len(units))
+		end := len(units)
+		if value := GetArgument(params, 1); value != nil {
+			end = clampIndex(interpreter, value, len(units))
+		}
+		if start > end {
+			start, end = end, start
+		}
+		return fromUTF16(units[start:end])
+	})
+	method("toLocaleLowerCase", func(interpreter types.Interpreter, text string, params []any) any {
+		return toLowerCase(text)
+	})
+	method("toLocaleUpperCase", func(interpreter types.Interpreter, text string, params []any) any {
+		return toUpperCase(text)
+	})
+	method("toLowerCase", func(interpreter types.Interpreter, text string, params []any) any {
+		return toLowerCase(text)
+	})
+	method("toUpperCase", func(interpreter types.Interpreter, text string, params []any) any {
+		return toUpperCase(text)
+	})
+	method("trim", func(interpreter types.Interpreter, text string, params []any) any {
+		return strings.TrimFunc(text, isJSWhiteSpace)
+	})
+	method("trimEnd", func(interpreter types.Interpreter, text string, params []any) any {
+		return strings.TrimRightFunc(text, isJSWhiteSpace)
+	})
+	method("trimStart", func(interpreter types.Interpreter, text string, params []any) any {
+		return strings.TrimLeftFunc(text, isJSWhiteSpace)
+	})
+	prototype.define(SymbolIterator, realm.stringIteratorFunction, false)
+	return prototype
+}
+
+// replaceString implements replace and replaceAll for a string pattern,
+// deferring to the Symbol.replace method of any other pattern.
+func replaceString(interpreter types.Interpreter, text string, params []any, all bool) string {
+	pattern := GetArgument(params, 0)
+	replacement := GetArgument(params, 1)
+	if pattern != nil {
+		if method := GetProperty(interpreter, pattern, SymbolReplace); method != nil {
+			return ToString(interpreter, Invoke(interpreter, method, pattern, []any{text, replacement}))
+		}
+	}
+	units := toUTF16(text)
+	search := ToString(interpreter, pattern)
+	searchUnits := toUTF16(search)
+	_, functional := replacement.(types.Function)
+	if !functional {
+		replacement = ToString(interpreter, replacement)
+	}
+	var positions []int
+	advance := len(searchUnits)
+	if advance == 0 {
+		advance = 1
+	}
+	for position := indexOfUnits(units, searchUnits, 0); position >= 0; position = indexOfUnits(units, searchUnits, position+advance) {
+		positions = append(positions, position)
+		if !all {
+			break
+		}
+	}
+	var result strings.Builder
+	end := 0
+	for _, position := range positions {
+		result.WriteString(fromUTF16(units[end:position]))
+		if functional {
+			result.WriteString(ToString(interpreter, Invoke(interpreter, replacement, nil, []any{search, int64(position), text})))
+		} else {
+			result.WriteString(GetSubstitution(interpreter, search, text, position, nil, nil, replacement.(string)))
+		}
+		end = position + len(searchUnits)
+	}
+	result.WriteString(fromUTF16(units[end:]))
+	return joinSurrogates(result.String())
+}
+
+// splitString implements split for a string separator, deferring to the
+// Symbol.split method of any other separator.
+func splitString(interpreter types.Interpreter, text string, params []any) types.Object {
+	separator := GetArgument(params, 0)
+	limit := GetArgument(params, 1)
+	if separator != nil {
+		if method := GetProperty(interpreter, separator, SymbolSplit); method != nil {
+			result, _ := Invoke(interpreter, method, separator, []any{text, limit}).(types.Object)
+			return result
+		}
+	}
+	max := int64(maxArrayLength)
+	if limit != nil {
+		max = int64(ToUint32(interpreter, limit))
+	}
+	var result []any
+	if max == 0 {
+		return NewArrayFrom(interpreter, result)
+	}
+	if separator == nil {
+		return NewArrayFrom(interpreter, []any{text})
+	}
+	units := toUTF16(text)
+	separatorUnits := toUTF16(ToString(interpreter, separator))
+	if len(separatorUnits) == 0 {
+		for i := 0; i < len(units) && int64(len(result)) < max; i++ {
+			result = append(result, fromUTF16(units[i:i+1]))
+		}
+		return NewArrayFrom(interpreter, result)
+	}
+	start := 0
+	for position := indexOfUnits(units, separatorUnits, 0); position >= 0; position = indexOfUnits(units, separatorUnits, start) {
+		result = append(result, fromUTF16(units[start:position]))
+		if int64(len(result)) == max {
+			return NewArrayFrom(interpreter, result)
+		}
+		start = position + len(separatorUnits)
+	}
+	return NewArrayFrom(interpreter, append(result, fromUTF16(units[start:])))
+}
+
+func newStringConstructor(realm *realm) types.Object {
+	toString := func(interpreter types.Interpreter, params []any) string {
+		if len(params) == 0 {
+			return ""
+		}
+		if symbol, ok := params[0].(*types.Symbol); ok {
+			return symbol.String()
+		}
+		return ToString(interpreter, params[0])
+	}
+	constructor := newConstructor(realm, "String", func(interpreter types.Interpreter, this any, params []any) any {
+		return toString(interpreter, params)
+	}, func(interpreter types.Interpreter, params []any) any {
+		if len(params) > 0 && types.IsSymbol(params[0]) {
+			ThrowTypeError(interpreter, "Cannot convert a Symbol value to a string")
+		}
+		return newStringObject(interpreter, toString(interpreter, params))
+	}).(*nativeImpl)
+	constructor.define("fromCharCode", newNative(realm, "fromCharCode", func(interpreter types.Interpreter, this any, params []any) any {
+		units := make([]uint16, len(params))
+		for i, item := range params {
+			units[i] = uint16(ToUint32(interpreter, item))
+		}
+		return fromUTF16(units)
+	}), false)
+	constructor.define("fromCodePoint", newNative(realm, "fromCodePoint", func(interpreter types.Interpreter, this any, params []any) any {
+		units := make([]uint16, 0, len(params))
+		for _, item := range params {
+			code := ToNumber(interpreter, item)
+			if code != math.Trunc(code) || code < 0 || code > unicode.MaxRune {
+				ThrowRangeError(interpreter, "Invalid code point %s", ToString(interpreter, item))
+			}
+			if code >= 0x10000 {
+				high, low := utf16.EncodeRune(rune(code))
+				units = append(units, uint16(high), uint16(low))
+			} else {
+				units = append(units, uint16(code))
+			}
+		}
+		return fromUTF16(units)
+	}), false)
+	constructor.define("raw", newNative(realm, "raw", func(interpreter types.Interpreter, this any, params []any) any {
+		raw := ToObject(interpreter, GetProperty(interpreter, ToObject(interpreter, GetArgument(params, 0)), "raw"))
+		length := lengthOf(interpreter, raw)
+		var result strings.Builder
+		for k := int64(0); k < length; k++ {
+			result.WriteString(ToString(interpreter, raw.Get(k)))
+			if k+1 < length && k+1 < int64(len(params)) {
+				result.WriteString(ToString(interpreter, params[k+1]))
+			}
+		}
+		return joinSurrogates(result.String())
+	}), false)
+	constructor.define("prototype", realm.stringPrototype, false)
+	realm.stringPrototype.define("constructor", constructor, false)
+	return constructor
+}
@@ -0,0 +1,81 @@
+package call
+
+import (
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		text string
+		form string
+		want string
+	}{
+		{"A\u030A", "NFC", "\u00C5"},
+		{"\u00C5", "NFD", "A\u030A"},
+		{"\u1E9B\u0323", "NFC", "\u1E9B\u0323"},
+		{"\u1E9B\u0323", "NFD", "\u017F\u0323\u0307"},
+		{"\u1E9B\u0323", "NFKC", "\u1E69"},
+		{"\u1E9B\u0323", "NFKD", "s\u0323\u0307"},
+		{"a\u0301\u0323", "NFC", "\u1EA1\u0301"},
+		{"\uFB01", "NFKD", "fi"},
+		{"\u1100\u1161\u11A8", "NFC", "\uAC01"},
+		{"\uAC01", "NFD", "\u1100\u1161\u11A8"},
+		{"\u0958", "NFC", "\u0915\u093C"},
+	}
+	for _, tt := range tests {
+		if got := Normalize(tt.text, tt.form); got != tt.want {
+			t.Errorf("Normalize(%+q, %s) actual = %+q, expect= %+q", tt.text, tt.form, got, tt.want)
+		}
+	}
+}
+
+func TestLocaleCompare(t *testing.T) {
+	tests := []struct {
+		a    string
+		b    string
+		want int
+	}{
+		{"a", "b", -1},
+		{"a", "B", -1},
+		{"a", "A", -1},
+		{"résumé", "resume", 1},
+		{"resume", "résumé", -1},
+		{"é", "é", 0},
+		{"abc", "ab", 1},
+	}
+	for _, tt := range tests {
+		if got := LocaleCompare(tt.a, tt.b); got != tt.want {
+			t.Errorf("LocaleCompare(%q, %q) actual = %v, expect= %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestUTF16(t *testing.T) {
+	tests := [][]uint16{
+		{0xD800},
+		{'a', 0xDFFF, 'b'},
+		{0xD83D, 0xDE00},
+		{0xDE00, 0xD83D},
+		{0xD7FF, 0xE000},
+	}
+	for _, units := range tests {
+		got := toUTF16(fromUTF16(units))
+		if len(got) != len(units) {
+			t.Errorf("round trip of %x actual = %x", units, got)
+			continue
+		}
+		for i := range units {
+			if got[i] != units[i] {
+				t.Errorf("round trip of %x actual = %x", units, got)
+				break
+			}
+		}
+	}
+	high, low := fromUTF16([]uint16{0xD83D}), fromUTF16([]uint16{0xDE00})
+	if Concat(high, low) != "😀" || joinSurrogates(high+"|"+high+low) != high+"|😀" {
+		t.Errorf("expect the surrogates to pair, actual: %+q", Concat(high, low))
+	}
+	if toWellFormed(high+"a"+low) != "�a�" {
+		t.Errorf("expect replacement characters, actual: %+q", toWellFormed(high+"a"+low))
+	}
+}
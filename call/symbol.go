@@ -0,0 +1,108 @@
+package call
+
+import (
+	"github.com/nusr/gojs/token"
+	"github.com/nusr/gojs/types"
+)
+
+var (
+	SymbolIterator      = types.NewSymbol("Symbol.iterator")
+	SymbolAsyncIterator = types.NewSymbol("Symbol.asyncIterator")
+	SymbolHasInstance   = types.NewSymbol("Symbol.hasInstance")
+	SymbolToPrimitive   = types.NewSymbol("Symbol.toPrimitive")
+	SymbolToStringTag   = types.NewSymbol("Symbol.toStringTag")
+
+	SymbolIsConcatSpreadable = types.NewSymbol("Symbol.isConcatSpreadable")
+	SymbolMatch              = types.NewSymbol("Symbol.match")
+	SymbolMatchAll           = types.NewSymbol("Symbol.matchAll")
+	SymbolReplace            = types.NewSymbol("Symbol.replace")
+	SymbolSearch             = types.NewSymbol("Symbol.search")
+	SymbolSplit              = types.NewSymbol("Symbol.split")
+)
+
+var wellKnownSymbols = map[string]*types.Symbol{
+	"iterator":      SymbolIterator,
+	"asyncIterator": SymbolAsyncIterator,
+	"hasInstance":   SymbolHasInstance,
+	"toPrimitive":   SymbolToPrimitive,
+	"toStringTag":   SymbolToStringTag,
+
+	"isConcatSpreadable": SymbolIsConcatSpreadable,
+	"match":              SymbolMatch,
+	"matchAll":           SymbolMatchAll,
+	"replace":            SymbolReplace,
+	"search":             SymbolSearch,
+	"split":              SymbolSplit,
+}
+
+func newSymbolPrototype(realm *realm) types.Object {
+	prototype := NewObject(realm.objectPrototype).(*instanceImpl)
+	prototype.define("toString", newNative(realm, "toString", func(interpreter types.Interpreter, this any, params []any) any {
+		return thisSymbol(interpreter, this, "Symbol.prototype.toString").String()
+	}), false)
+	prototype.define("valueOf", newNative(realm, "valueOf", func(interpreter types.Interpreter, this any, params []any) any {
+		return thisSymbol(interpreter, this, "Symbol.prototype.valueOf")
+	}), false)
+	prototype.define(SymbolToStringTag, "Symbol", false)
+	return prototype
+}
+
+func thisSymbol(interpreter types.Interpreter, this any, name string) *types.Symbol {
+	symbol, ok := this.(*types.Symbol)
+	if !ok {
+		ThrowTypeError(interpreter, "%s requires that 'this' be a Symbol", name)
+	}
+	return symbol
+}
+
+func getSymbolProperty(interpreter types.Interpreter, symbol *types.Symbol, key any) any {
+	if key == "description" {
+		if symbol.Anonymous {
+			return nil
+		}
+		return symbol.Description
+	}
+	return realmOf(interpreter).symbolPrototype.Get(key)
+}
+
+// symbolFor returns the symbol registered in realm for a key, creating it
+// once.
+func symbolFor(realm *realm, key string) *types.Symbol {
+	if symbol, ok := realm.symbolRegistry[key]; ok {
+		return symbol
+	}
+	symbol := types.NewSymbol(key)
+	symbol.Registered = true
+	realm.symbolRegistry[key] = symbol
+	return symbol
+}
+
+func newSymbolConstructor(realm *realm) types.Object {
+	constructor := newConstructor(realm, "Symbol", func(interpreter types.Interpreter, this any, params []any) any {
+		description := GetArgument(params, 0)
+		if description == nil {
+			symbol := types.NewSymbol("")
+			symbol.Anonymous = true
+			return symbol
+		}
+		return types.NewSymbol(ToString(interpreter, description))
+	}, nil).(*nativeImpl)
+	for name, symbol := range wellKnownSymbols {
+		constructor.define(name, symbol, false)
+	}
+	constructor.define("for", newNative(realm, "for", func(interpreter types.Interpreter, this any, params []any) any {
+		return symbolFor(realmOf(interpreter), ToString(interpreter, GetArgument(params, 0)))
+	}), false)
+	constructor.define("keyFor", newNative(realm, "keyFor", func(interpreter types.Interpreter, this any, params []any) any {
+		symbol, ok := GetArgument(params, 0).(*types.Symbol)
+		if !ok {
+			ThrowTypeError(interpreter, "%s is not a symbol", token.ConvertAnyToString(GetArgument(params, 0)))
+		}
+		if symbol.Registered {
+			return symbol.Description
+		}
+		return nil
+	}), false)
+	constructor.define("prototype", realm.symbolPrototype, false)
+	return constructor
+}
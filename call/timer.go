@@ -0,0 +1,130 @@
+package call
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/nusr/gojs/token"
+	"github.com/nusr/gojs/types"
+)
+
+// maxTimeout is the largest delay a timer accepts, as in Node.
+const maxTimeout = 1<<31 - 1
+
+// timeoutImpl is the object returned by setTimeout and setInterval.
+type timeoutImpl struct {
+	*instanceImpl
+	timer types.Timer
+}
+
+func thisTimeout(interpreter types.Interpreter, this any, name string) *timeoutImpl {
+	timeout, ok := this.(*timeoutImpl)
+	if !ok {
+		ThrowTypeError(interpreter, "Timeout.prototype.%s called on incompatible receiver", name)
+	}
+	return timeout
+}
+
+func newTimeoutPrototype(realm *realm) types.Object {
+	prototype := NewObject(realm.objectPrototype).(*instanceImpl)
+	prototype.define("ref", newNative(realm, "ref", func(interpreter types.Interpreter, this any, params []any) any {
+		thisTimeout(interpreter, this, "ref").timer.SetRef(true)
+		return this
+	}), false)
+	prototype.define("unref", newNative(realm, "unref", func(interpreter types.Interpreter, this any, params []any) any {
+		thisTimeout(interpreter, this, "unref").timer.SetRef(false)
+		return this
+	}), false)
+	prototype.define("hasRef", newNative(realm, "hasRef", func(interpreter types.Interpreter, this any, params []any) any {
+		return thisTimeout(interpreter, this, "hasRef").timer.HasRef()
+	}), false)
+	prototype.define("refresh", newNative(realm, "refresh", func(interpreter types.Interpreter, this any, params []any) any {
+		thisTimeout(interpreter, this, "refresh").timer.Refresh()
+		return this
+	}), false)
+	prototype.define("close", newNative(realm, "close", func(interpreter types.Interpreter, this any, params []any) any {
+		thisTimeout(interpreter, this, "close").timer.Cancel()
+		return this
+	}), false)
+	prototype.define(SymbolToPrimitive, newNative(realm, "[Symbol.toPrimitive]", func(interpreter types.Interpreter, this any, params []any) any {
+		return thisTimeout(interpreter, this, "[Symbol.toPrimitive]").timer.ID()
+	}), false)
+	prototype.define(SymbolToStringTag, "Timeout", false)
+	return prototype
+}
+
+// received describes an invalid argument the way Node's errors do.
+func received(value any) string {
+	switch value.(type) {
+	case nil:
+		return "undefined"
+	case types.Null:
+		return "null"
+	case types.Function:
+		return "function"
+	case types.Property:
+		return "an instance of Object"
+	}
+	return fmt.Sprintf("type %s (%s)", TypeOf(value), token.ConvertAnyToString(value))
+}
+
+func checkCallback(interpreter types.Interpreter, callback any) {
+	if _, ok := callback.(types.Function); !ok {
+		ThrowTypeError(interpreter, "The \"callback\" argument must be of type function. Received %s", received(callback))
+	}
+}
+
+// timerDelay converts the delay argument of a timer, which falls back to
+// 1ms when it is out of range.
+func timerDelay(interpreter types.Interpreter, value any) time.Duration {
+	delay := ToNumber(interpreter, value)
+	if math.IsNaN(delay) || delay < 1 || delay > maxTimeout {
+		delay = 1
+	}
+	return time.Duration(delay * float64(time.Millisecond))
+}
+
+func newTimerFunction(realm *realm, name string, repeat bool) types.Method {
+	return newNative(realm, name, func(interpreter types.Interpreter, this any, params []any) any {
+		callback := GetArgument(params, 0)
+		checkCallback(interpreter, callback)
+		var args []any
+		if len(params) > 2 {
+			args = params[2:]
+		}
+		timeout := &timeoutImpl{
+			instanceImpl: NewObject(realm.timeoutPrototype).(*instanceImpl),
+		}
+		timeout.timer = interpreter.GetEventLoop().AddTimer(timerDelay(interpreter, GetArgument(params, 1)), repeat, func() {
+			Invoke(interpreter, callback, timeout, args)
+		})
+		return timeout
+	})
+}
+
+func newClearTimerFunction(realm *realm, name string) types.Method {
+	return newNative(realm, name, func(interpreter types.Interpreter, this any, params []any) any {
+		switch data := GetArgument(params, 0).(type) {
+		case *timeoutImpl:
+			data.timer.Cancel()
+		case int64, float64, string:
+			id := ToNumber(interpreter, data)
+			if timer := interpreter.GetEventLoop().GetTimer(int64(id)); timer != nil && float64(int64(id)) == id {
+				timer.Cancel()
+			}
+		}
+		return nil
+	})
+}
+
+func newQueueMicrotask(realm *realm) types.Method {
+	return newNative(realm, "queueMicrotask", func(interpreter types.Interpreter, this any, params []any) any {
+		callback := GetArgument(params, 0)
+		checkCallback(interpreter, callback)
+		interpreter.GetEventLoop().EnqueueMicrotask(func() {
+			Invoke(interpreter, callback, nil, nil)
+		})
+		return nil
+	})
+}
@@ -0,0 +1,639 @@
+package call
+
+import (
+	"encoding/binary"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/nusr/gojs/types"
+)
+
+// typedArrayKind describes the element type of one typed array
+// constructor: integer elements read as int64 and float ones as float64.
+type typedArrayKind struct {
+	name string
+	size int64
+	get  func(data []byte, order binary.ByteOrder) any
+	set  func(data []byte, order binary.ByteOrder, value float64)
+}
+
+var typedArrayKinds = []*typedArrayKind{
+	{name: "Int8Array", size: 1, get: func(data []byte, order binary.ByteOrder) any {
+		return int64(int8(data[0]))
+	}, set: func(data []byte, order binary.ByteOrder, value float64) {
+		data[0] = byte(wrapUint32(value))
+	}},
+	{name: "Uint8Array", size: 1, get: func(data []byte, order binary.ByteOrder) any {
+		return int64(data[0])
+	}, set: func(data []byte, order binary.ByteOrder, value float64) {
+		data[0] = byte(wrapUint32(value))
+	}},
+	{name: "Uint8ClampedArray", size: 1, get: func(data []byte, order binary.ByteOrder) any {
+		return int64(data[0])
+	}, set: func(data []byte, order binary.ByteOrder, value float64) {
+		data[0] = clampByte(value)
+	}},
+	{name: "Int16Array", size: 2, get: func(data []byte, order binary.ByteOrder) any {
+		return int64(int16(order.Uint16(data)))
+	}, set: func(data []byte, order binary.ByteOrder, value float64) {
+		order.PutUint16(data, uint16(wrapUint32(value)))
+	}},
+	{name: "Uint16Array", size: 2, get: func(data []byte, order binary.ByteOrder) any {
+		return int64(order.Uint16(data))
+	}, set: func(data []byte, order binary.ByteOrder, value float64) {
+		order.PutUint16(data, uint16(wrapUint32(value)))
+	}},
+	{name: "Int32Array", size: 4, get: func(data []byte, order binary.ByteOrder) any {
+		return int64(int32(order.Uint32(data)))
+	}, set: func(data []byte, order binary.ByteOrder, value float64) {
+		order.PutUint32(data, wrapUint32(value))
+	}},
+	{name: "Uint32Array", size: 4, get: func(data []byte, order binary.ByteOrder) any {
+		return int64(order.Uint32(data))
+	}, set: func(data []byte, order binary.ByteOrder, value float64) {
+		order.PutUint32(data, wrapUint32(value))
+	}},
+	{name: "Float32Array", size: 4, get: func(data []byte, order binary.ByteOrder) any {
+		return float64(math.Float32frombits(order.Uint32(data)))
+	}, set: func(data []byte, order binary.ByteOrder, value float64) {
+		order.PutUint32(data, math.Float32bits(float32(value)))
+	}},
+	{name: "Float64Array", size: 8, get: func(data []byte, order binary.ByteOrder) any {
+		return math.Float64frombits(order.Uint64(data))
+	}, set: func(data []byte, order binary.ByteOrder, value float64) {
+		order.PutUint64(data, math.Float64bits(value))
+	}},
+}
+
+// newTypedArrayPrototypes creates the prototype of each kind, which
+// inherits from %TypedArray%.prototype.
+func newTypedArrayPrototypes(realm *realm) map[*typedArrayKind]*instanceImpl {
+	prototypes := make(map[*typedArrayKind]*instanceImpl)
+	for _, kind := range typedArrayKinds {
+		prototype := NewObject(realm.typedArrayPrototype).(*instanceImpl)
+		prototype.define("BYTES_PER_ELEMENT", kind.size, false)
+		prototypes[kind] = prototype
+	}
+	return prototypes
+}
+
+// typedArrayImpl is a view of a buffer as an array of numbers of one
+// kind, stored little-endian.
+type typedArrayImpl struct {
+	*instanceImpl
+	kind   *typedArrayKind
+	buffer *arrayBufferImpl
+	offset int64
+	count  int64 // -1 when the array tracks the length of a resizable buffer
+}
+
+func newTypedArray(interpreter types.Interpreter, kind *typedArrayKind, buffer *arrayBufferImpl, offset int64, count int64) *typedArrayImpl {
+	return &typedArrayImpl{
+		instanceImpl: NewObject(realmOf(interpreter).typedArrayPrototypes[kind]).(*instanceImpl),
+		kind:         kind,
+		buffer:       buffer,
+		offset:       offset,
+		count:        count,
+	}
+}
+
+// allocateTypedArray creates a zeroed typed array with its own buffer.
+func allocateTypedArray(interpreter types.Interpreter, kind *typedArrayKind, length int64) *typedArrayImpl {
+	if length > maxAllocation/kind.size {
+		ThrowRangeError(interpreter, "Invalid typed array length: %d", length)
+	}
+	return newTypedArray(interpreter, kind, allocateArrayBuffer(interpreter, length*kind.size, -1, false), 0, length)
+}
+
+// outOfBounds reports whether the buffer was detached or shrunk below the
+// view.
+func (array *typedArrayImpl) outOfBounds() bool {
+	length := int64(len(array.buffer.data))
+	if array.buffer.detached || array.offset > length {
+		return true
+	}
+	return array.count >= 0 && array.offset+array.count*array.kind.size > length
+}
+
+func (array *typedArrayImpl) length() int64 {
+	if array.outOfBounds() {
+		return 0
+	}
+	if array.count < 0 {
+		return (int64(len(array.buffer.data)) - array.offset) / array.kind.size
+	}
+	return array.count
+}
+
+// element returns the bytes of the element at index.
+func (array *typedArrayImpl) element(index int64) []byte {
+	start := array.offset + index*array.kind.size
+	return array.buffer.data[start : start+array.kind.size]
+}
+
+func (array *typedArrayImpl) get(index int64) any {
+	return array.kind.get(array.element(index), binary.LittleEndian)
+}
+
+func (array *typedArrayImpl) set(index int64, value float64) {
+	array.kind.set(array.element(index), binary.LittleEndian, value)
+}
+
+// numericKey reports whether key is a canonical numeric string or a number,
+// returning the index it names or -1 when it names no element.
+func (array *typedArrayImpl) numericKey(key any) (int64, bool) {
+	var number float64
+	switch data := key.(type) {
+	case int64:
+		number = float64(data)
+	case float64:
+		number = data
+	case types.NaN:
+		return -1, true
+	case string:
+		if data == "-0" {
+			return -1, true
+		}
+		number = StringToNumber(data)
+		if NumberToString(number) != data {
+			return 0, false
+		}
+	default:
+		return 0, false
+	}
+	if number != math.Trunc(number) || (number == 0 && math.Signbit(number)) || number < 0 || number >= float64(array.length()) {
+		return -1, true
+	}
+	return int64(number), true
+}
+
+func (array *typedArrayImpl) Get(key any) any {
+	if i, ok := array.numericKey(key); ok {
+		if i < 0 {
+			return nil
+		}
+		return array.get(i)
+	}
+	switch key {
+	case "length":
+		return array.length()
+	case "byteLength":
+		return array.length() * array.kind.size
+	case "byteOffset":
+		if array.outOfBounds() {
+			return int64(0)
+		}
+		return array.offset
+	case "buffer":
+		return array.buffer
+	case SymbolToStringTag:
+		return array.kind.name
+	}
+	return array.instanceImpl.Get(key)
+}
+
+// Set converts the value to a number; writes past the end are ignored.
+func (array *typedArrayImpl) Set(key any, value any) {
+	if i, ok := array.numericKey(key); ok {
+		number := ToNumber(nil, value)
+		if i, ok = array.numericKey(key); ok && i >= 0 {
+			array.set(i, number)
+		}
+		return
+	}
+	array.instanceImpl.Set(key, value)
+}
+
+func (array *typedArrayImpl) Has(key any) bool {
+	if i, ok := array.numericKey(key); ok {
+		return i >= 0
+	}
+	return array.instanceImpl.Has(key)
+}
+
+func (array *typedArrayImpl) Delete(key any) bool {
+	if i, ok := array.numericKey(key); ok {
+		return i < 0
+	}
+	return array.instanceImpl.Delete(key)
+}
+
+func (array *typedArrayImpl) OwnKeys() []any {
+	length := array.length()
+	keys := make([]any, 0, length)
+	for i := int64(0); i < length; i++ {
+		keys = append(keys, strconv.FormatInt(i, 10))
+	}
+	return append(keys, array.instanceImpl.OwnKeys()...)
+}
+
+func (array *typedArrayImpl) IsEnumerable(key any) bool {
+	if i, ok := array.numericKey(key); ok {
+		return i >= 0
+	}
+	return array.instanceImpl.IsEnumerable(key)
+}
+
+func (array *typedArrayImpl) getOwnProperty(key any) (propertyDescriptor, bool) {
+	if i, ok := array.numericKey(key); ok {
+		if i < 0 {
+			return propertyDescriptor{}, false
+		}
+		return dataDescriptor(array.get(i), true, true, true), true
+	}
+	return array.instanceImpl.getOwnProperty(key)
+}
+
+// defineOwnProperty fails for attributes elements can not have: they are
+// always writable, enumerable and configurable data properties.
+func (array *typedArrayImpl) defineOwnProperty(key any, desc propertyDescriptor) bool {
+	i, ok := array.numericKey(key)
+	if !ok {
+		return array.instanceImpl.defineOwnProperty(key, desc)
+	}
+	if i < 0 || desc.isAccessor() ||
+		(desc.hasConfigurable && !desc.configurable) ||
+		(desc.hasEnumerable && !desc.enumerable) ||
+		(desc.hasWritable && !desc.writable) {
+		return false
+	}
+	if desc.hasValue {
+		array.Set(i, desc.value)
+	}
+	return true
+}
+
+// preventExtensions fails while elements may still appear as a resizable
+// buffer grows.
+func (array *typedArrayImpl) preventExtensions() bool {
+	if array.count < 0 {
+		return false
+	}
+	return array.instanceImpl.preventExtensions()
+}
+
+func thisTypedArray(interpreter types.Interpreter, this any, name string) *typedArrayImpl {
+	array, ok := this.(*typedArrayImpl)
+	if !ok {
+		ThrowTypeError(interpreter, "this is not a typed array.")
+	}
+	if array.outOfBounds() {
+		ThrowTypeError(interpreter, "Cannot perform %%TypedArray%%.prototype.%s on a detached ArrayBuffer", name)
+	}
+	return array
+}
+
+// typedArrayFrom creates an array with a constructor's own kind, checking
+// that it made a typed array long enough.
+func typedArrayFrom(interpreter types.Interpreter, constructor any, length int64) *typedArrayImpl {
+	array, ok := Construct(interpreter, constructor, []any{length}).(*typedArrayImpl)
+	if !ok {
+		ThrowTypeError(interpreter, "this is not a typed array.")
+	}
+	if array.length() < length {
+		ThrowTypeError(interpreter, "Derived TypedArray constructor created an array which was too small")
+	}
+	return array
+}
+
+// sortNumbers orders numbers ascending, with -0 before +0 and NaN last.
+func sortNumbers(values []any) {
+	sort.SliceStable(values, func(a, b int) bool {
+		x, _ := toFloat(values[a])
+		y, _ := toFloat(values[b])
+		if math.IsNaN(y) {
+			return !math.IsNaN(x)
+		}
+		if x == 0 && y == 0 {
+			return math.Signbit(x) && !math.Signbit(y)
+		}
+		return x < y
+	})
+}
+
+// values copies the elements of the array out.
+func (array *typedArrayImpl) values() []any {
+	values := make([]any, array.length())
+	for i := range values {
+		values[i] = array.get(int64(i))
+	}
+	return values
+}
+
+func newTypedArrayPrototype(realm *realm) *instanceImpl {
+	prototype := NewObject(realm.objectPrototype).(*instanceImpl)
+	method := func(name string, fn func(interpreter types.Interpreter, array *typedArrayImpl, params []any) any) types.Method {
+		native := newNative(realm, name, func(interpreter types.Interpreter, this any, params []any) any {
+			return fn(interpreter, thisTypedArray(interpreter, this, name), params)
+		})
+		prototype.define(name, native, false)
+		return native
+	}
+	// these work on any array-like, so they are Array.prototype's own
+	// methods behind a receiver check
+	for _, name := range []string{
+		"at", "copyWithin", "entries", "every", "fill", "find", "findIndex", "findLast",
+		"findLastIndex", "forEach", "includes", "indexOf", "join", "keys", "lastIndexOf",
+		"reduce", "reduceRight", "reverse", "some", "toLocaleString",
+	} {
+		generic := realm.arrayPrototype.Get(name)
+		method(name, func(interpreter types.Interpreter, array *typedArrayImpl, params []any) any {
+			return Invoke(interpreter, generic, array, params)
+		})
+	}
+	values := method("values", func(interpreter types.Interpreter, array *typedArrayImpl, params []any) any {
+		return newArrayIterator(interpreter, array, arrayIteratorValues)
+	})
+	prototype.define(SymbolIterator, values, false)
+	prototype.define("toString", realm.arrayPrototype.Get("toString"), false)
+
+	method("filter", func(interpreter types.Interpreter, array *typedArrayImpl, params []any) any {
+		callback := GetArgument(params, 0)
+		checkCallable(interpreter, callback)
+		var kept []any
+		length := array.length()
+		for k := int64(0); k < length; k++ {
+			value := array.Get(k)
+			if ToBoolean(Invoke(interpreter, callback, GetArgument(params, 1), []any{value, k, array})) {
+				kept = append(kept, value)
+			}
+		}
+		result := allocateTypedArray(interpreter, array.kind, int64(len(kept)))
+		for i, value := range kept {
+			result.Set(int64(i), value)
+		}
+		return result
+	})
+	method("map", func(interpreter types.Interpreter, array *typedArrayImpl, params []any) any {
+		callback := GetArgument(params, 0)
+		checkCallable(interpreter, callback)
+		length := array.length()
+		result := allocateTypedArray(interpreter, array.kind, length)
+		for k := int64(0); k < length; k++ {
+			result.Set(k, Invoke(interpreter, callback, GetArgument(params, 1), []any{array.Get(k), k, array}))
+		}
+		return result
+	})
+	method("set", func(interpreter types.Interpreter, array *typedArrayImpl, params []any) any {
+		offset := toIntegerOrInfinity(interpreter, GetArgument(params, 1))
+		if offset < 0 {
+			ThrowRangeError(interpreter, "offset is out of bounds")
+		}
+		var values []any
+		if source, ok := GetArgument(params, 0).(*typedArrayImpl); ok {
+			values = source.values()
+		} else {
+			source := ToObject(interpreter, GetArgument(params, 0))
+			length := lengthOf(interpreter, source)
+			if offset+float64(length) > float64(array.length()) {
+				ThrowRangeError(interpreter, "offset is out of bounds")
+			}
+			values = make([]any, length)
+			for k := range values {
+				values[k] = source.Get(int64(k))
+			}
+		}
+		if offset+float64(len(values)) > float64(array.length()) {
+			ThrowRangeError(interpreter, "offset is out of bounds")
+		}
+		for k, value := range values {
+			array.Set(int64(offset)+int64(k), value)
+		}
+		return nil
+	})
+	method("slice", func(interpreter types.Interpreter, array *typedArrayImpl, params []any) any {
+		length := array.length()
+		start := relativeIndex(interpreter, GetArgument(params, 0), length)
+		end := endIndex(interpreter, GetArgument(params, 1), length)
+		result := allocateTypedArray(interpreter, array.kind, max(end-start, 0))
+		for k := start; k < end && k < array.length(); k++ {
+			result.set(k-start, ToNumber(nil, array.get(k)))
+		}
+		return result
+	})
+	method("sort", func(interpreter types.Interpreter, array *typedArrayImpl, params []any) any {
+		comparator := GetArgument(params, 0)
+		checkComparator(interpreter, comparator)
+		values := array.values()
+		if comparator == nil {
+			sortNumbers(values)
+		} else {
+			values = sortValues(interpreter, values, comparator)
+		}
+		for k, value := range values {
+			array.Set(int64(k), value)
+		}
+		return array
+	})
+	method("subarray", func(interpreter types.Interpreter, array *typedArrayImpl, params []any) any {
+		length := array.length()
+		start := relativeIndex(interpreter, GetArgument(params, 0), length)
+		offset := array.offset + start*array.kind.size
+		if array.count < 0 && GetArgument(params, 1) == nil {
+			return newTypedArray(interpreter, array.kind, array.buffer, offset, -1)
+		}
+		end := endIndex(interpreter, GetArgument(params, 1), length)
+		return newTypedArray(interpreter, array.kind, array.buffer, offset, max(end-start, 0))
+	})
+	method("toReversed", func(interpreter types.Interpreter, array *typedArrayImpl, params []any) any {
+		length := array.length()
+		result := allocateTypedArray(interpreter, array.kind, length)
+		for k := int64(0); k < length; k++ {
+			result.set(k, ToNumber(nil, array.get(length-1-k)))
+		}
+		return result
+	})
+	method("toSorted", func(interpreter types.Interpreter, array *typedArrayImpl, params []any) any {
+		comparator := GetArgument(params, 0)
+		checkComparator(interpreter, comparator)
+		values := array.values()
+		if comparator == nil {
+			sortNumbers(values)
+		} else {
+			values = sortValues(interpreter, values, comparator)
+		}
+		result := allocateTypedArray(interpreter, array.kind, int64(len(values)))
+		for k, value := range values {
+			result.Set(int64(k), value)
+		}
+		return result
+	})
+	method("with", func(interpreter types.Interpreter, array *typedArrayImpl, params []any) any {
+		length := array.length()
+		relative := toIntegerOrInfinity(interpreter, GetArgument(params, 0))
+		if relative < 0 {
+			relative += float64(length)
+		}
+		value := ToNumber(interpreter, GetArgument(params, 1))
+		if relative < 0 || relative >= float64(array.length()) {
+			ThrowRangeError(interpreter, "Invalid typed array index")
+		}
+		result := allocateTypedArray(interpreter, array.kind, length)
+		for k := int64(0); k < length; k++ {
+			if k == int64(relative) {
+				result.set(k, value)
+			} else {
+				result.set(k, ToNumber(nil, array.get(k)))
+			}
+		}
+		return result
+	})
+	return prototype
+}
+
+// newTypedArrayConstructors creates the constructor of every kind; they
+// inherit their statics from %TypedArray%, which is not a global.
+func newTypedArrayConstructors(realm *realm) []*nativeImpl {
+	abstract := func(interpreter types.Interpreter, params []any) any {
+		ThrowTypeError(interpreter, "Abstract class TypedArray not directly constructable")
+		return nil
+	}
+	typedArray := newConstructor(realm, "TypedArray", func(interpreter types.Interpreter, this any, params []any) any {
+		return abstract(interpreter, params)
+	}, abstract).(*nativeImpl)
+	typedArray.define("from", newNative(realm, "from", func(interpreter types.Interpreter, this any, params []any) any {
+		mapper := GetArgument(params, 1)
+		if mapper != nil {
+			checkCallable(interpreter, mapper)
+		}
+		var values []any
+		items := GetArgument(params, 0)
+		if GetProperty(interpreter, items, SymbolIterator) == nil {
+			object := ToObject(interpreter, items)
+			values = make([]any, lengthOf(interpreter, object))
+			for k := range values {
+				values[k] = object.Get(int64(k))
+			}
+		} else {
+			values = iterableToList(interpreter, items)
+		}
+		result := typedArrayFrom(interpreter, this, int64(len(values)))
+		for k, value := range values {
+			if mapper != nil {
+				value = Invoke(interpreter, mapper, GetArgument(params, 2), []any{value, int64(k)})
+			}
+			result.Set(int64(k), value)
+		}
+		return result
+	}), false)
+	typedArray.define("of", newNative(realm, "of", func(interpreter types.Interpreter, this any, params []any) any {
+		result := typedArrayFrom(interpreter, this, int64(len(params)))
+		for k, value := range params {
+			result.Set(int64(k), value)
+		}
+		return result
+	}), false)
+	typedArray.define("prototype", realm.typedArrayPrototype, false)
+	realm.typedArrayPrototype.define("constructor", typedArray, false)
+
+	var constructors []*nativeImpl
+	for _, kind := range typedArrayKinds {
+		constructor := newTypedArrayConstructor(realm, kind)
+		constructor.proto = typedArray
+		constructors = append(constructors, constructor)
+	}
+	return constructors
+}
+
+// iterableToList collects the values an iterable produces.
+func iterableToList(interpreter types.Interpreter, items any) []any {
+	var values []any
+	iterator := GetIterator(interpreter, items)
+	for {
+		value, ok := iterator.Step(interpreter)
+		if !ok {
+			return values
+		}
+		values = append(values, value)
+	}
+}
+
+func newTypedArrayConstructor(realm *realm, kind *typedArrayKind) *nativeImpl {
+	constructor := newConstructor(realm, kind.name, func(interpreter types.Interpreter, this any, params []any) any {
+		ThrowTypeError(interpreter, "Constructor %s requires 'new'", kind.name)
+		return nil
+	}, func(interpreter types.Interpreter, params []any) any {
+		switch source := GetArgument(params, 0).(type) {
+		case *arrayBufferImpl:
+			return typedArrayOnBuffer(interpreter, kind, source, GetArgument(params, 1), GetArgument(params, 2))
+		case *typedArrayImpl:
+			if source.outOfBounds() {
+				ThrowTypeError(interpreter, "Cannot perform Construct on a detached ArrayBuffer")
+			}
+			return typedArrayOf(interpreter, kind, source.values())
+		case types.Object:
+			if GetProperty(interpreter, source, SymbolIterator) != nil {
+				return typedArrayOf(interpreter, kind, iterableToList(interpreter, source))
+			}
+			length := lengthOf(interpreter, source)
+			values := make([]any, length)
+			for k := range values {
+				values[k] = source.Get(int64(k))
+			}
+			return typedArrayOf(interpreter, kind, values)
+		case nil:
+			return allocateTypedArray(interpreter, kind, 0)
+		default:
+			length, ok := toIndex(interpreter, source)
+			if !ok {
+				ThrowRangeError(interpreter, "Invalid typed array length: %s", NumberToString(ToNumber(interpreter, source)))
+			}
+			return allocateTypedArray(interpreter, kind, length)
+		}
+	}).(*nativeImpl)
+	constructor.define("BYTES_PER_ELEMENT", kind.size, false)
+	constructor.define("prototype", realm.typedArrayPrototypes[kind], false)
+	realm.typedArrayPrototypes[kind].define("constructor", constructor, false)
+	return constructor
+}
+
+// typedArrayOf creates a typed array holding values converted to numbers.
+func typedArrayOf(interpreter types.Interpreter, kind *typedArrayKind, values []any) *typedArrayImpl {
+	array := allocateTypedArray(interpreter, kind, int64(len(values)))
+	for k, value := range values {
+		array.Set(int64(k), value)
+	}
+	return array
+}
+
+// typedArrayOnBuffer creates a view of buffer from a byte offset, tracking
+// the length of a resizable buffer when no length is given.
+func typedArrayOnBuffer(interpreter types.Interpreter, kind *typedArrayKind, buffer *arrayBufferImpl, byteOffset any, length any) *typedArrayImpl {
+	offset, ok := toIndex(interpreter, byteOffset)
+	if !ok {
+		ThrowRangeError(interpreter, "Start offset %s is outside the bounds of the buffer", NumberToString(ToNumber(interpreter, byteOffset)))
+	}
+	if offset%kind.size != 0 {
+		ThrowRangeError(interpreter, "start offset of %s should be a multiple of %d", kind.name, kind.size)
+	}
+	count := int64(-1)
+	if length != nil {
+		if count, ok = toIndex(interpreter, length); !ok {
+			ThrowRangeError(interpreter, "Invalid typed array length: %s", NumberToString(ToNumber(interpreter, length)))
+		}
+	}
+	if buffer.detached {
+		ThrowTypeError(interpreter, "Cannot perform Construct on a detached ArrayBuffer")
+	}
+	byteLength := int64(len(buffer.data))
+	if count < 0 {
+		if buffer.maxByteLength >= 0 {
+			if offset > byteLength {
+				ThrowRangeError(interpreter, "Start offset %d is outside the bounds of the buffer", offset)
+			}
+			return newTypedArray(interpreter, kind, buffer, offset, -1)
+		}
+		if byteLength%kind.size != 0 {
+			ThrowRangeError(interpreter, "byte length of %s should be a multiple of %d", kind.name, kind.size)
+		}
+		if offset > byteLength {
+			ThrowRangeError(interpreter, "Start offset %d is outside the bounds of the buffer", offset)
+		}
+		return newTypedArray(interpreter, kind, buffer, offset, (byteLength-offset)/kind.size)
+	}
+	if offset+count*kind.size > byteLength {
+		ThrowRangeError(interpreter, "Invalid typed array length: %d", count)
+	}
+	return newTypedArray(interpreter, kind, buffer, offset, count)
+}
@@ -0,0 +1,133 @@
+package call
+
+import (
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/nusr/gojs/types"
+)
+
+const (
+	// uriUnescaped are the characters no URI function escapes.
+	uriUnescaped = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_.!~*'()"
+	// uriReserved delimit the parts of a URI, so encodeURI and decodeURI
+	// leave them alone.
+	uriReserved = ";/?:@&=+$,#"
+)
+
+// encodeURI escapes the UTF-8 bytes of every character not in unescaped.
+// A lone surrogate has no UTF-8 form.
+func encodeURI(interpreter types.Interpreter, text string, unescaped string) string {
+	const hex = "0123456789ABCDEF"
+	var builder strings.Builder
+	for _, r := range codePoints(text) {
+		if r < utf8.RuneSelf && strings.IndexByte(unescaped, byte(r)) >= 0 {
+			builder.WriteRune(r)
+			continue
+		}
+		if utf16.IsSurrogate(r) {
+			ThrowURIError(interpreter, "URI malformed")
+		}
+		var buffer [utf8.UTFMax]byte
+		for _, b := range buffer[:utf8.EncodeRune(buffer[:], r)] {
+			builder.WriteByte('%')
+			builder.WriteByte(hex[b>>4])
+			builder.WriteByte(hex[b&15])
+		}
+	}
+	return builder.String()
+}
+
+func unhex(c byte) (byte, bool) {
+	switch {
+	case c >= '0' && c <= '9':
+		return c - '0', true
+	case c >= 'a' && c <= 'f':
+		return c - 'a' + 10, true
+	case c >= 'A' && c <= 'F':
+		return c - 'A' + 10, true
+	}
+	return 0, false
+}
+
+// escapedByte reads the byte a %XX escape at index encodes.
+func escapedByte(interpreter types.Interpreter, text string, index int) byte {
+	if index+2 >= len(text) || text[index] != '%' {
+		ThrowURIError(interpreter, "URI malformed")
+	}
+	high, ok1 := unhex(text[index+1])
+	low, ok2 := unhex(text[index+2])
+	if !ok1 || !ok2 {
+		ThrowURIError(interpreter, "URI malformed")
+	}
+	return high<<4 | low
+}
+
+// decodeURI replaces escapes of UTF-8 sequences with their characters,
+// except escapes of the ASCII characters in reserved.
+func decodeURI(interpreter types.Interpreter, text string, reserved string) string {
+	var builder strings.Builder
+	for i := 0; i < len(text); {
+		if text[i] != '%' {
+			builder.WriteByte(text[i])
+			i++
+			continue
+		}
+		b := escapedByte(interpreter, text, i)
+		if b < utf8.RuneSelf {
+			if strings.IndexByte(reserved, b) >= 0 {
+				builder.WriteString(text[i : i+3])
+			} else {
+				builder.WriteByte(b)
+			}
+			i += 3
+			continue
+		}
+		var size int
+		switch {
+		case b&0xE0 == 0xC0:
+			size = 2
+		case b&0xF0 == 0xE0:
+			size = 3
+		case b&0xF8 == 0xF0:
+			size = 4
+		default:
+			ThrowURIError(interpreter, "URI malformed")
+		}
+		sequence := []byte{b}
+		for k := 1; k < size; k++ {
+			sequence = append(sequence, escapedByte(interpreter, text, i+3*k))
+		}
+		r, n := utf8.DecodeRune(sequence)
+		if n != size {
+			ThrowURIError(interpreter, "URI malformed")
+		}
+		builder.WriteRune(r)
+		i += 3 * size
+	}
+	return builder.String()
+}
+
+func newURIFunction(realm *realm, name string, fn func(interpreter types.Interpreter, text string) string) types.Function {
+	return newNative(realm, name, func(interpreter types.Interpreter, this any, params []any) any {
+		return fn(interpreter, ToString(interpreter, GetArgument(params, 0)))
+	})
+}
+
+func newURIFunctions(realm *realm) []types.Function {
+	return []types.Function{
+		newURIFunction(realm, "encodeURI", func(interpreter types.Interpreter, text string) string {
+			return encodeURI(interpreter, text, uriUnescaped+uriReserved)
+		}),
+		newURIFunction(realm, "encodeURIComponent", func(interpreter types.Interpreter, text string) string {
+			return encodeURI(interpreter, text, uriUnescaped)
+		}),
+		newURIFunction(realm, "decodeURI", func(interpreter types.Interpreter, text string) string {
+			return decodeURI(interpreter, text, uriReserved)
+		}),
+		newURIFunction(realm, "decodeURIComponent", func(interpreter types.Interpreter, text string) string {
+			return decodeURI(interpreter, text, "")
+		}),
+	}
+}
@@ -0,0 +1,257 @@
+package call
+
+import (
+	"runtime"
+	"sync"
+	"weak"
+
+	"github.com/nusr/gojs/types"
+)
+
+// weakKey refers to an object or symbol without keeping it alive. Only one
+// of the pointers is set: an object is held by the instanceImpl it embeds,
+// a proxy by its proxyImpl. Keys made from the same value are equal.
+type weakKey struct {
+	instance weak.Pointer[instanceImpl]
+	proxy    weak.Pointer[proxyImpl]
+	symbol   weak.Pointer[types.Symbol]
+}
+
+// weakObject is an object embedding an instanceImpl.
+type weakObject interface {
+	types.Object
+	base() *instanceImpl
+}
+
+func (instance *instanceImpl) base() *instanceImpl {
+	return instance
+}
+
+// makeWeakKey reports false for values that cannot be held weakly:
+// primitives and symbols from Symbol.for.
+func makeWeakKey(value any) (weakKey, bool) {
+	if proxy, ok := asProxy(value); ok {
+		return weakKey{proxy: weak.Make(proxy)}, true
+	}
+	switch data := value.(type) {
+	case *types.Symbol:
+		if data.Registered {
+			return weakKey{}, false
+		}
+		return weakKey{symbol: weak.Make(data)}, true
+	case weakObject:
+		// the instance leads back to the object for WeakRef
+		instance := data.base()
+		instance.self = data
+		return weakKey{instance: weak.Make(instance)}, true
+	}
+	return weakKey{}, false
+}
+
+// value returns the value the key was made from, or nil once it has been
+// collected.
+func (key weakKey) value() any {
+	if instance := key.instance.Value(); instance != nil {
+		return instance.self
+	}
+	if proxy := key.proxy.Value(); proxy != nil {
+		return proxy.self
+	}
+	if symbol := key.symbol.Value(); symbol != nil {
+		return symbol
+	}
+	return nil
+}
+
+// addCleanup calls cleanup once the value of the key is collected.
+// It must be called while the value is still reachable.
+func (key weakKey) addCleanup(cleanup func()) runtime.Cleanup {
+	run := func(cleanup func()) { cleanup() }
+	if instance := key.instance.Value(); instance != nil {
+		return runtime.AddCleanup(instance, run, cleanup)
+	}
+	if proxy := key.proxy.Value(); proxy != nil {
+		return runtime.AddCleanup(proxy, run, cleanup)
+	}
+	return runtime.AddCleanup(key.symbol.Value(), run, cleanup)
+}
+
+// weakCollection holds the entries of a WeakMap or WeakSet. An entry is
+// removed by a cleanup once its key is collected; a value that refers to its
+// own key still keeps the key alive while the collection is reachable.
+type weakCollection struct {
+	mutex   sync.Mutex
+	entries map[weakKey]weakEntry
+}
+
+type weakEntry struct {
+	value   any
+	cleanup runtime.Cleanup
+}
+
+func newWeakCollection() *weakCollection {
+	return &weakCollection{entries: make(map[weakKey]weakEntry)}
+}
+
+func (collection *weakCollection) get(key weakKey) (any, bool) {
+	collection.mutex.Lock()
+	defer collection.mutex.Unlock()
+	entry, ok := collection.entries[key]
+	return entry.value, ok
+}
+
+// set must be called while the key is still reachable.
+func (collection *weakCollection) set(key weakKey, value any) {
+	collection.mutex.Lock()
+	defer collection.mutex.Unlock()
+	if entry, ok := collection.entries[key]; ok {
+		entry.value = value
+		collection.entries[key] = entry
+		return
+	}
+	// the cleanup must not keep the collection alive either
+	self := weak.Make(collection)
+	cleanup := key.addCleanup(func() {
+		if collection := self.Value(); collection != nil {
+			collection.delete(key)
+		}
+	})
+	collection.entries[key] = weakEntry{value: value, cleanup: cleanup}
+}
+
+func (collection *weakCollection) delete(key weakKey) bool {
+	collection.mutex.Lock()
+	defer collection.mutex.Unlock()
+	entry, ok := collection.entries[key]
+	if ok {
+		entry.cleanup.Stop()
+		delete(collection.entries, key)
+	}
+	return ok
+}
+
+// weakMapImpl is a WeakMap or a WeakSet.
+type weakMapImpl struct {
+	*instanceImpl
+	data  *weakCollection
+	isSet bool
+}
+
+// weakRefImpl is a WeakRef.
+type weakRefImpl struct {
+	*instanceImpl
+	target weakKey
+}
+
+func thisWeakMap(interpreter types.Interpreter, this any, name string, isSet bool) *weakMapImpl {
+	object, ok := this.(*weakMapImpl)
+	if !ok || object.isSet != isSet {
+		kind := "WeakMap"
+		if isSet {
+			kind = "WeakSet"
+		}
+		ThrowTypeError(interpreter, "Method %s.prototype.%s called on incompatible receiver %s", kind, name, describe(this))
+	}
+	return object
+}
+
+func newWeakMapPrototype(realm *realm, isSet bool) *instanceImpl {
+	prototype := NewObject(realm.objectPrototype).(*instanceImpl)
+	method := func(name string, fn func(interpreter types.Interpreter, object *weakMapImpl, key weakKey, ok bool, params []any) any) {
+		prototype.define(name, newNative(realm, name, func(interpreter types.Interpreter, this any, params []any) any {
+			object := thisWeakMap(interpreter, this, name, isSet)
+			key, ok := makeWeakKey(GetArgument(params, 0))
+			return fn(interpreter, object, key, ok, params)
+		}), false)
+	}
+	method("has", func(interpreter types.Interpreter, object *weakMapImpl, key weakKey, ok bool, params []any) any {
+		if !ok {
+			return false
+		}
+		_, ok = object.data.get(key)
+		return ok
+	})
+	method("delete", func(interpreter types.Interpreter, object *weakMapImpl, key weakKey, ok bool, params []any) any {
+		return ok && object.data.delete(key)
+	})
+	if isSet {
+		method("add", func(interpreter types.Interpreter, object *weakMapImpl, key weakKey, ok bool, params []any) any {
+			if !ok {
+				ThrowTypeError(interpreter, "Invalid value used in weak set")
+			}
+			object.data.set(key, nil)
+			return object
+		})
+		prototype.define(SymbolToStringTag, "WeakSet", false)
+		return prototype
+	}
+	method("get", func(interpreter types.Interpreter, object *weakMapImpl, key weakKey, ok bool, params []any) any {
+		if !ok {
+			return nil
+		}
+		value, _ := object.data.get(key)
+		return value
+	})
+	method("set", func(interpreter types.Interpreter, object *weakMapImpl, key weakKey, ok bool, params []any) any {
+		if !ok {
+			ThrowTypeError(interpreter, "Invalid value used as weak map key")
+		}
+		object.data.set(key, GetArgument(params, 1))
+		return object
+	})
+	prototype.define(SymbolToStringTag, "WeakMap", false)
+	return prototype
+}
+
+// newWeakMapConstructor creates WeakMap or WeakSet. Without ephemerons, a
+// value that refers to its own key keeps the entry until the collection is
+// itself collected.
+func newWeakMapConstructor(realm *realm, isSet bool) types.Object {
+	name, adder, prototype := "WeakMap", "set", newWeakMapPrototype(realm, isSet)
+	if isSet {
+		name, adder = "WeakSet", "add"
+	}
+	constructor := newConstructor(realm, name, func(interpreter types.Interpreter, this any, params []any) any {
+		ThrowTypeError(interpreter, "Constructor %s requires 'new'", name)
+		return nil
+	}, func(interpreter types.Interpreter, params []any) any {
+		object := &weakMapImpl{
+			instanceImpl: NewObject(prototype).(*instanceImpl),
+			data:         newWeakCollection(),
+			isSet:        isSet,
+		}
+		addFromIterable(interpreter, object, GetArgument(params, 0), adder, !isSet)
+		return object
+	}).(*nativeImpl)
+	constructor.define("prototype", prototype, false)
+	prototype.define("constructor", constructor, false)
+	return constructor
+}
+
+func newWeakRefConstructor(realm *realm) types.Object {
+	prototype := NewObject(realm.objectPrototype).(*instanceImpl)
+	prototype.define("deref", newNative(realm, "deref", func(interpreter types.Interpreter, this any, params []any) any {
+		object, ok := this.(*weakRefImpl)
+		if !ok {
+			ThrowTypeError(interpreter, "Method WeakRef.prototype.deref called on incompatible receiver %s", describe(this))
+		}
+		return object.target.value()
+	}), false)
+	prototype.define(SymbolToStringTag, "WeakRef", false)
+	constructor := newConstructor(realm, "WeakRef", func(interpreter types.Interpreter, this any, params []any) any {
+		ThrowTypeError(interpreter, "Constructor WeakRef requires 'new'")
+		return nil
+	}, func(interpreter types.Interpreter, params []any) any {
+		target, ok := makeWeakKey(GetArgument(params, 0))
+		if !ok {
+			ThrowTypeError(interpreter, "WeakRef: invalid target")
+		}
+		return &weakRefImpl{
+			instanceImpl: NewObject(prototype).(*instanceImpl),
+			target:       target,
+		}
+	}).(*nativeImpl)
+	constructor.define("prototype", prototype, false)
+	prototype.define("constructor", constructor, false)
+	return constructor
+}
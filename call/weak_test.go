@@ -0,0 +1,69 @@
+package call
+
+import (
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/nusr/gojs/types"
+)
+
+// collect runs the garbage collector until done reports true, giving the
+// cleanups a chance to run.
+func collect(done func() bool) bool {
+	for i := 0; i < 50; i++ {
+		runtime.GC()
+		if done() {
+			return true
+		}
+		time.Sleep(time.Millisecond)
+	}
+	return false
+}
+
+func TestWeakCollection(t *testing.T) {
+	collection := newWeakCollection()
+	kept := NewInstance(nil)
+	keptKey, _ := makeWeakKey(kept)
+	collection.set(keptKey, "kept")
+	func() {
+		key, _ := makeWeakKey(NewInstance(nil))
+		collection.set(key, "dropped")
+	}()
+	if len(collection.entries) != 2 {
+		t.Fatalf("expect 2 entries, actual= %d", len(collection.entries))
+	}
+	if !collect(func() bool {
+		collection.mutex.Lock()
+		defer collection.mutex.Unlock()
+		return len(collection.entries) == 1
+	}) {
+		t.Fatalf("entry of a collected key was not removed")
+	}
+	if value, ok := collection.get(keptKey); !ok || value != "kept" {
+		t.Errorf("expect= kept, actual= %v", value)
+	}
+	runtime.KeepAlive(kept)
+}
+
+func TestWeakKey(t *testing.T) {
+	for _, value := range []any{NewArray(nil), types.NewSymbol("local"), newProxy(nil, NewInstance(nil), NewInstance(nil))} {
+		a, _ := makeWeakKey(value)
+		b, _ := makeWeakKey(value)
+		if a != b || a.value() != value {
+			t.Errorf("keys of %v differ", value)
+		}
+	}
+	for _, value := range []any{nil, int64(1), "x", true, symbolFor(realmOf(nil), "registered")} {
+		if _, ok := makeWeakKey(value); ok {
+			t.Errorf("expect %v not to be held weakly", value)
+		}
+	}
+	var key weakKey
+	func() {
+		key, _ = makeWeakKey(NewInstance(nil))
+	}()
+	if !collect(func() bool { return key.value() == nil }) {
+		t.Errorf("weak key kept its value alive")
+	}
+}
@@ -0,0 +1,50 @@
+package clock
+
+import (
+	"time"
+
+	"github.com/nusr/gojs/types"
+)
+
+type realClock struct {
+}
+
+// New returns the wall clock.
+func New() types.Clock {
+	return realClock{}
+}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) Sleep(duration time.Duration) {
+	time.Sleep(duration)
+}
+
+// Virtual is a clock that only moves when it is told to. Sleeping advances
+// it at once, so timers fire in order without waiting.
+type Virtual struct {
+	now time.Time
+}
+
+func NewVirtual(start time.Time) *Virtual {
+	return &Virtual{
+		now: start,
+	}
+}
+
+func (clock *Virtual) Now() time.Time {
+	return clock.now
+}
+
+func (clock *Virtual) Sleep(duration time.Duration) {
+	clock.Advance(duration)
+}
+
+// Advance moves the clock forward by duration.
+func (clock *Virtual) Advance(duration time.Duration) {
+	if duration > 0 {
+		clock.now = clock.now.Add(duration)
+	}
+}
@@ -0,0 +1,17 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestVirtual(t *testing.T) {
+	start := time.Unix(0, 0)
+	clock := NewVirtual(start)
+	clock.Sleep(time.Second)
+	clock.Advance(time.Minute)
+	clock.Advance(-time.Hour)
+	if actual := clock.Now().Sub(start); actual != time.Minute+time.Second {
+		t.Errorf("expect %v, actual: %v", time.Minute+time.Second, actual)
+	}
+}
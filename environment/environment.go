@@ -1,12 +1,20 @@
 package environment
 
 import (
+	"weak"
+
 	"github.com/nusr/gojs/types"
 )
 
 type environmentImpl struct {
 	parent types.Environment
-	values map[string]any
+	// detached is the parent of a scope made by NewDetached, which does not
+	// keep it alive.
+	detached weak.Pointer[environmentImpl]
+	values   map[string]any
+	// block is set for a scope of let, const and class bindings only, whose
+	// var and function bindings belong to the parent.
+	block bool
 }
 
 func New(parent types.Environment) types.Environment {
@@ -17,27 +25,89 @@ func New(parent types.Environment) types.Environment {
 	}
 }
 
+// NewBlock creates a scope for lexical bindings: Define passes var and
+// function bindings on to the nearest enclosing scope that is not a block.
+func NewBlock(parent types.Environment) types.Environment {
+	return &environmentImpl{
+		parent: parent,
+		values: make(map[string]any),
+		block:  true,
+	}
+}
+
+// NewDetached creates a scope that refers to parent weakly, for code that
+// runs on a goroutine of its own: the goroutine is a root of the garbage
+// collector, and must not keep alive the scope an owner of the goroutine
+// may be stored in. The owner keeps parent alive instead, and closures
+// created in the scope keep it alive through Anchors.
+func NewDetached(parent types.Environment) types.Environment {
+	scope, ok := parent.(*environmentImpl)
+	if !ok {
+		// the global environment lives as long as the interpreter
+		return New(parent)
+	}
+	return &environmentImpl{
+		detached: weak.Make(scope),
+		values:   make(map[string]any),
+	}
+}
+
+// Anchors returns the scopes that environment reaches through the weak
+// links of detached scopes, which something capturing environment must
+// keep alive.
+func Anchors(environment types.Environment) []types.Environment {
+	var anchors []types.Environment
+	for scope, ok := environment.(*environmentImpl); ok; scope, ok = scope.outer().(*environmentImpl) {
+		if scope.parent == nil {
+			if parent := scope.detached.Value(); parent != nil {
+				anchors = append(anchors, parent)
+			}
+		}
+	}
+	return anchors
+}
+
+// outer returns the parent scope, or nil at the root.
+func (environment *environmentImpl) outer() types.Environment {
+	if environment.parent != nil {
+		return environment.parent
+	}
+	if parent := environment.detached.Value(); parent != nil {
+		return parent
+	}
+	return nil
+}
+
 func (environment *environmentImpl) Get(key string) any {
 	if val, ok := environment.values[key]; ok {
 		return val
 	}
-	if environment.parent != nil {
-		return environment.parent.Get(key)
+	if parent := environment.outer(); parent != nil {
+		return parent.Get(key)
 	}
 	return nil
 }
 func (environment *environmentImpl) Define(name string, value any) {
+	if environment.block {
+		environment.parent.Define(name, value)
+		return
+	}
 	environment.values[name] = value
 }
 
 func (environment *environmentImpl) Assign(key string, value any) {
 	if _, ok := environment.values[key]; ok {
-		environment.Define(key, value)
+		environment.values[key] = value
 		return
 	}
-	if environment.parent != nil {
-		environment.parent.Assign(key, value)
+	if parent := environment.outer(); parent != nil {
+		parent.Assign(key, value)
 		return
 	}
 	environment.Define(key, value)
 }
+
+// DefineLexical binds name in this scope, even in a block.
+func (environment *environmentImpl) DefineLexical(name string, value any) {
+	environment.values[name] = value
+}
@@ -50,3 +50,39 @@ func TestEnvironment(t *testing.T) {
 		}
 	}
 }
+
+func TestNewDetached(t *testing.T) {
+	parent := New(New(nil))
+	parent.Define("a", 1.0)
+	env := New(NewDetached(parent))
+	if env.Get("a") != 1.0 {
+		t.Errorf("env.Get(a) actual = %v, expect= %v", env.Get("a"), 1.0)
+	}
+	env.Assign("a", 2.0)
+	if parent.Get("a") != 2.0 {
+		t.Errorf("parent.Get(a) actual = %v, expect= %v", parent.Get("a"), 2.0)
+	}
+	if anchors := Anchors(env); len(anchors) != 1 || anchors[0] != parent {
+		t.Errorf("Anchors(env) actual = %v, expect= %v", anchors, []any{parent})
+	}
+	if anchors := Anchors(parent); len(anchors) != 0 {
+		t.Errorf("Anchors(parent) actual = %v, expect= none", anchors)
+	}
+}
+
+func TestNewBlock(t *testing.T) {
+	parent := New(nil)
+	env := NewBlock(NewBlock(parent))
+	env.Define("a", 1.0)
+	env.DefineLexical("b", 2.0)
+	if parent.Get("a") != 1.0 {
+		t.Errorf("parent.Get(a) actual = %v, expect= %v", parent.Get("a"), 1.0)
+	}
+	if parent.Get("b") != nil || env.Get("b") != 2.0 {
+		t.Errorf("env.Get(b) actual = %v %v, expect= %v %v", parent.Get("b"), env.Get("b"), nil, 2.0)
+	}
+	env.Assign("b", 3.0)
+	if env.Get("b") != 3.0 || parent.Get("b") != nil {
+		t.Errorf("env.Assign(b) actual = %v %v, expect= %v %v", parent.Get("b"), env.Get("b"), nil, 3.0)
+	}
+}
@@ -0,0 +1,53 @@
+package environment
+
+import (
+	"github.com/nusr/gojs/types"
+)
+
+// globalImpl keeps var and function bindings as properties of the global
+// object, and let, const and class bindings in a scope of their own that
+// shadows it.
+type globalImpl struct {
+	object  types.Object
+	declare func(name string, value any)
+	lexical map[string]any
+}
+
+// NewGlobal creates a root environment backed by object. declare creates
+// the property of a var or function declaration, which unlike one made by
+// assignment can not be deleted.
+func NewGlobal(object types.Object, declare func(name string, value any)) types.GlobalEnvironment {
+	return &globalImpl{
+		object:  object,
+		declare: declare,
+		lexical: make(map[string]any),
+	}
+}
+
+func (global *globalImpl) GlobalObject() types.Object {
+	return global.object
+}
+
+func (global *globalImpl) Get(key string) any {
+	if value, ok := global.lexical[key]; ok {
+		return value
+	}
+	return global.object.Get(key)
+}
+
+func (global *globalImpl) Define(name string, value any) {
+	global.declare(name, value)
+}
+
+func (global *globalImpl) DefineLexical(name string, value any) {
+	global.lexical[name] = value
+}
+
+// Assign creates a property of the global object for an undeclared name.
+func (global *globalImpl) Assign(key string, value any) {
+	if _, ok := global.lexical[key]; ok {
+		global.lexical[key] = value
+		return
+	}
+	global.object.Set(key, value)
+}
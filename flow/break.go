@@ -0,0 +1,15 @@
+package flow
+
+type Break struct {
+}
+
+func (b Break) String() string {
+	return "break"
+}
+
+type Continue struct {
+}
+
+func (c Continue) String() string {
+	return "continue"
+}
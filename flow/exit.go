@@ -0,0 +1,10 @@
+package flow
+
+// Exit is the panic value that unwinds a closed coroutine without running
+// any more script code.
+type Exit struct {
+}
+
+func (e Exit) String() string {
+	return "exit"
+}
@@ -0,0 +1,17 @@
+package flow
+
+import "github.com/nusr/gojs/token"
+
+// Rejection is the panic value reporting a promise rejected without a
+// handler once the microtask queue is empty.
+type Rejection struct {
+	Value any
+}
+
+func (r Rejection) String() string {
+	return token.ConvertAnyToString(r.Value)
+}
+
+func (r Rejection) Error() string {
+	return "Uncaught (in promise) " + r.String()
+}
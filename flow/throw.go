@@ -0,0 +1,49 @@
+package flow
+
+import (
+	"runtime"
+
+	"github.com/nusr/gojs/token"
+)
+
+// Throw is the panic value carrying a JavaScript exception.
+type Throw struct {
+	Value any
+}
+
+func NewThrow(value any) Throw {
+	return Throw{
+		Value: value,
+	}
+}
+
+func (t Throw) String() string {
+	return token.ConvertAnyToString(t.Value)
+}
+
+func (t Throw) Error() string {
+	return "Uncaught " + t.String()
+}
+
+// Recover converts a recovered panic into the thrown JavaScript value.
+func Recover(err any) any {
+	switch data := err.(type) {
+	case Throw:
+		return data.Value
+	case error:
+		return data.Error()
+	default:
+		return err
+	}
+}
+
+// Catchable reports whether a recovered panic may be handled by a script.
+func Catchable(err any) bool {
+	switch err.(type) {
+	case runtime.Error:
+		return false
+	case Throw, error, string:
+		return true
+	}
+	return false
+}
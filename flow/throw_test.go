@@ -0,0 +1,22 @@
+package flow
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestThrow(t *testing.T) {
+	r := NewThrow("test")
+	if r.Error() != "Uncaught test" {
+		t.Errorf("expect = Uncaught test, actual=%v", r.Error())
+	}
+	if Recover(r) != "test" {
+		t.Errorf("expect = test, actual=%v", Recover(r))
+	}
+	if Recover(errors.New("error")) != "error" {
+		t.Errorf("expect = error, actual=%v", Recover(errors.New("error")))
+	}
+	if Recover("message") != "message" {
+		t.Errorf("expect = message, actual=%v", Recover("message"))
+	}
+}
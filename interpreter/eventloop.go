@@ -0,0 +1,137 @@
+package interpreter
+
+import (
+	"time"
+
+	"github.com/nusr/gojs/clock"
+	"github.com/nusr/gojs/flow"
+	"github.com/nusr/gojs/types"
+)
+
+type rejection struct {
+	promise any
+	reason  any
+}
+
+type eventLoop struct {
+	microtasks []func()
+	tasks      []func()
+	rejections []rejection
+	clock      types.Clock
+	timers     timerHeap
+	timerIDs   map[int64]*timer
+	nextID     int64
+	seq        int64
+	refs       int // scheduled timers that keep the loop running
+}
+
+func newEventLoop() *eventLoop {
+	return &eventLoop{
+		clock:    clock.New(),
+		timerIDs: map[int64]*timer{},
+	}
+}
+
+func (loop *eventLoop) SetClock(clock types.Clock) {
+	loop.clock = clock
+}
+
+func (loop *eventLoop) Now() time.Time {
+	return loop.clock.Now()
+}
+
+func (loop *eventLoop) AddTimer(delay time.Duration, repeat bool, job func()) types.Timer {
+	loop.nextID++
+	t := &timer{
+		loop:   loop,
+		id:     loop.nextID,
+		delay:  delay,
+		repeat: repeat,
+		job:    job,
+		ref:    true,
+		index:  -1,
+	}
+	loop.schedule(t)
+	return t
+}
+
+func (loop *eventLoop) GetTimer(id int64) types.Timer {
+	if t, ok := loop.timerIDs[id]; ok {
+		return t
+	}
+	return nil
+}
+
+func (loop *eventLoop) EnqueueMicrotask(job func()) {
+	loop.microtasks = append(loop.microtasks, job)
+}
+
+func (loop *eventLoop) EnqueueTask(job func()) {
+	loop.tasks = append(loop.tasks, job)
+}
+
+func (loop *eventLoop) TrackRejection(promise any, reason any) {
+	loop.rejections = append(loop.rejections, rejection{promise: promise, reason: reason})
+}
+
+func (loop *eventLoop) HandleRejection(promise any) {
+	for i, item := range loop.rejections {
+		if item.promise == promise {
+			loop.rejections = append(loop.rejections[:i], loop.rejections[i+1:]...)
+			return
+		}
+	}
+}
+
+// runMicrotask runs the next microtask, reporting false if there is none.
+func (loop *eventLoop) runMicrotask() bool {
+	if len(loop.microtasks) == 0 {
+		return false
+	}
+	job := loop.microtasks[0]
+	loop.microtasks[0] = nil
+	loop.microtasks = loop.microtasks[1:]
+	job()
+	return true
+}
+
+// runTask runs the next task, reporting false if there is none.
+func (loop *eventLoop) runTask() bool {
+	if len(loop.tasks) == 0 {
+		return false
+	}
+	job := loop.tasks[0]
+	loop.tasks[0] = nil
+	loop.tasks = loop.tasks[1:]
+	job()
+	return true
+}
+
+// checkRejections reports the first rejection left unhandled once the
+// microtask queue is empty.
+func (loop *eventLoop) checkRejections() {
+	if len(loop.rejections) > 0 {
+		reason := loop.rejections[0].reason
+		loop.rejections = nil
+		panic(flow.Rejection{Value: reason})
+	}
+}
+
+func (loop *eventLoop) Run() {
+	loop.RunUntil(func() bool {
+		return false
+	})
+}
+
+func (loop *eventLoop) RunUntil(done func() bool) bool {
+	for !done() {
+		if loop.runMicrotask() {
+			continue
+		}
+		loop.checkRejections()
+		if !loop.runTask() && !loop.runTimer() {
+			return false
+		}
+	}
+	return true
+}
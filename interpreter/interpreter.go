@@ -3,7 +3,10 @@ package interpreter
 import (
 	"fmt"
 	"math"
+	"math/rand"
 	"strconv"
+	"sync"
+	"time"
 
 	"github.com/nusr/gojs/call"
 	"github.com/nusr/gojs/environment"
@@ -36,10 +39,10 @@ func convertLtoF(left any, right any) (float64, float64, bool) {
 	if left == nil && right == nil {
 		count = 2
 	}
-	if left == nil {
+	if types.IsNullish(left) {
 		count++
 	}
-	if right == nil {
+	if types.IsNullish(right) {
 		count++
 	}
 	if val, ok := left.(float64); ok {
@@ -69,26 +72,188 @@ func convertLtoF(left any, right any) (float64, float64, bool) {
 	return a, b, count >= 2
 }
 
+// coroutines tracks the suspended coroutines started by an interpreter and
+// its forks.
+type coroutines struct {
+	mutex sync.Mutex
+	list  map[types.Coroutine]struct{}
+}
+
 type interpreterImpl struct {
-	environment     types.Environment
-	globals         types.Environment
-	lastObjectKey   any
-	lastObjectValue types.Property
+	environment types.Environment
+	globals     types.Environment
+	coroutine   types.Coroutine
+	coroutines  *coroutines
+	eventLoop   types.EventLoop
+	settings    *settings
+	fileName    string
+	frames      []types.Frame // the outermost frame comes first
+}
+
+// settings are the host settings shared by an interpreter and its forks.
+type settings struct {
+	mutex          sync.Mutex
+	random         types.Random   // the source of Math.random
+	location       *time.Location // the local time zone
+	locales        []string       // the Intl locales
+	codeGeneration bool           // whether eval and the Function constructor work
 }
 
 func New(environment types.Environment) types.Interpreter {
 	return &interpreterImpl{
-		environment:     environment,
-		globals:         environment,
-		lastObjectKey:   "",
-		lastObjectValue: nil,
+		environment: environment,
+		globals:     environment,
+		coroutines: &coroutines{
+			list: map[types.Coroutine]struct{}{},
+		},
+		eventLoop: newEventLoop(),
+		settings: &settings{
+			random:         rand.New(rand.NewSource(time.Now().UnixNano())),
+			location:       time.Local,
+			locales:        call.BundledLocales(),
+			codeGeneration: true,
+		},
+		fileName: "<anonymous>",
+		frames:   []types.Frame{{}},
+	}
+}
+
+func (interpreter *interpreterImpl) Fork(coroutine types.Coroutine) types.Interpreter {
+	return &interpreterImpl{
+		// the body of the coroutine enters the scope of its function; the
+		// scope of the caller may hold the object that owns the coroutine
+		environment: interpreter.globals,
+		globals:     interpreter.globals,
+		coroutine:   coroutine,
+		coroutines:  interpreter.coroutines,
+		eventLoop:   interpreter.eventLoop,
+		settings:    interpreter.settings,
+		// the body of the coroutine pushes the frame of its function
+		fileName: interpreter.fileName,
+	}
+}
+
+func (interpreter *interpreterImpl) GetEventLoop() types.EventLoop {
+	return interpreter.eventLoop
+}
+
+func (interpreter *interpreterImpl) SetRandom(random types.Random) {
+	interpreter.settings.mutex.Lock()
+	defer interpreter.settings.mutex.Unlock()
+	interpreter.settings.random = random
+}
+
+func (interpreter *interpreterImpl) Random() float64 {
+	interpreter.settings.mutex.Lock()
+	defer interpreter.settings.mutex.Unlock()
+	return interpreter.settings.random.Float64()
+}
+
+func (interpreter *interpreterImpl) SetLocation(location *time.Location) {
+	interpreter.settings.mutex.Lock()
+	defer interpreter.settings.mutex.Unlock()
+	interpreter.settings.location = location
+}
+
+func (interpreter *interpreterImpl) Location() *time.Location {
+	interpreter.settings.mutex.Lock()
+	defer interpreter.settings.mutex.Unlock()
+	return interpreter.settings.location
+}
+
+func (interpreter *interpreterImpl) SetLocales(locales []string) {
+	interpreter.settings.mutex.Lock()
+	defer interpreter.settings.mutex.Unlock()
+	interpreter.settings.locales = locales
+}
+
+func (interpreter *interpreterImpl) Locales() []string {
+	interpreter.settings.mutex.Lock()
+	defer interpreter.settings.mutex.Unlock()
+	return interpreter.settings.locales
+}
+
+func (interpreter *interpreterImpl) SetCodeGeneration(allowed bool) {
+	interpreter.settings.mutex.Lock()
+	defer interpreter.settings.mutex.Unlock()
+	interpreter.settings.codeGeneration = allowed
+}
+
+func (interpreter *interpreterImpl) CodeGeneration() bool {
+	interpreter.settings.mutex.Lock()
+	defer interpreter.settings.mutex.Unlock()
+	return interpreter.settings.codeGeneration
+}
+
+func (interpreter *interpreterImpl) PushFrame(name string) {
+	interpreter.frames = append(interpreter.frames, types.Frame{Name: name})
+}
+
+func (interpreter *interpreterImpl) PopFrame() {
+	interpreter.frames = interpreter.frames[:len(interpreter.frames)-1]
+}
+
+func (interpreter *interpreterImpl) SetPosition(line int, column int) {
+	if len(interpreter.frames) == 0 {
+		return
+	}
+	frame := &interpreter.frames[len(interpreter.frames)-1]
+	frame.Line = line
+	frame.Column = column
+}
+
+func (interpreter *interpreterImpl) StackTrace() []types.Frame {
+	list := make([]types.Frame, len(interpreter.frames))
+	for i, frame := range interpreter.frames {
+		frame.File = interpreter.fileName
+		list[len(list)-1-i] = frame
+	}
+	return list
+}
+
+func (interpreter *interpreterImpl) SetFileName(name string) {
+	interpreter.fileName = name
+}
+
+func (interpreter *interpreterImpl) GetCoroutine() types.Coroutine {
+	return interpreter.coroutine
+}
+
+func (interpreter *interpreterImpl) AddCoroutine(coroutine types.Coroutine) {
+	interpreter.coroutines.mutex.Lock()
+	defer interpreter.coroutines.mutex.Unlock()
+	interpreter.coroutines.list[coroutine] = struct{}{}
+}
+
+func (interpreter *interpreterImpl) RemoveCoroutine(coroutine types.Coroutine) {
+	interpreter.coroutines.mutex.Lock()
+	defer interpreter.coroutines.mutex.Unlock()
+	delete(interpreter.coroutines.list, coroutine)
+}
+
+func (interpreter *interpreterImpl) Close() {
+	interpreter.coroutines.mutex.Lock()
+	list := make([]types.Coroutine, 0, len(interpreter.coroutines.list))
+	for coroutine := range interpreter.coroutines.list {
+		list = append(list, coroutine)
+	}
+	interpreter.coroutines.mutex.Unlock()
+	for _, coroutine := range list {
+		coroutine.Close()
 	}
 }
 func (interpreter *interpreterImpl) GetGlobal() types.Environment {
 	return interpreter.globals
 }
-func (interpreter *interpreterImpl) Interpret(list []statement.Statement) any {
-	var result any
+func (interpreter *interpreterImpl) Interpret(list []statement.Statement) (result any) {
+	defer func() {
+		if err := recover(); err != nil {
+			// a top-level await that never settles ends the script
+			if _, ok := err.(flow.Exit); !ok {
+				panic(err)
+			}
+		}
+	}()
 	for _, item := range list {
 		result = interpreter.Execute(item)
 		if val, ok := result.(flow.Return); ok {
@@ -96,6 +261,8 @@ func (interpreter *interpreterImpl) Interpret(list []statement.Statement) any {
 			break
 		}
 	}
+	// callbacks run later are not called from the top level
+	interpreter.SetPosition(0, 0)
 	return result
 }
 
@@ -118,85 +285,115 @@ func (interpreter *interpreterImpl) Evaluate(expression statement.Expression) an
 }
 
 func (interpreter *interpreterImpl) isTruth(value any) bool {
-	if value == true {
+	return call.ToBoolean(value)
+}
+
+// isAbrupt reports whether a statement result ends the enclosing block early.
+func isAbrupt(result any) bool {
+	switch result.(type) {
+	case flow.Return, flow.Break, flow.Continue:
 		return true
 	}
-	list := []interface{}{
-		nil,
-		"",
-		int64(0),
-		float64(0),
-		false,
-	}
-	for _, item := range list {
-		if item == value {
-			return false
-		}
-	}
-	text := token.ConvertAnyToString(value)
-	result := text != ""
-	return result
+	return false
 }
 
 func (interpreter *interpreterImpl) ExecuteBlock(statement statement.BlockStatement, environment types.Environment) (result any) {
 	previous := interpreter.environment
 	interpreter.environment = environment
+	defer func() {
+		interpreter.environment = previous
+	}()
 	for _, t := range statement.Statements {
 		result = interpreter.Execute(t)
-		if val, ok := result.(flow.Return); ok {
-			interpreter.environment = previous
-			return val
+		if isAbrupt(result) {
+			return result
 		}
 	}
-	interpreter.environment = previous
 	return result
 }
 
+// executeWith runs a single statement in the given environment.
+func (interpreter *interpreterImpl) executeWith(statement statement.Statement, environment types.Environment) any {
+	previous := interpreter.environment
+	interpreter.environment = environment
+	defer func() {
+		interpreter.environment = previous
+	}()
+	return interpreter.Execute(statement)
+}
+
 func (interpreter *interpreterImpl) VisitExpressionStatement(statement statement.ExpressionStatement) any {
 	return interpreter.Evaluate(statement.Expression)
 }
 func (interpreter *interpreterImpl) VisitVariableStatement(statement statement.VariableStatement) any {
+	var value any
 	if statement.Initializer != nil {
-		value := interpreter.Evaluate(statement.Initializer)
-		interpreter.environment.Define(statement.Name.Lexeme, value)
-	} else {
-		interpreter.environment.Define(statement.Name.Lexeme, nil)
+		value = interpreter.Evaluate(statement.Initializer)
+		if isAnonymousFunction(statement.Initializer) {
+			call.SetFunctionName(value, statement.Name.Lexeme)
+		}
 	}
+	interpreter.declare(&statement.Kind, statement.Name.Lexeme, value)
 	return nil
 }
+
+// declare binds a name in the current environment; let and const
+// bindings stay off the global object.
+func (interpreter *interpreterImpl) declare(kind *token.Token, name string, value any) {
+	if kind.Type == token.Let || kind.Type == token.Const {
+		interpreter.environment.DefineLexical(name, value)
+	} else {
+		interpreter.environment.Define(name, value)
+	}
+}
 func (interpreter *interpreterImpl) VisitBlockStatement(statement statement.BlockStatement) any {
-	return interpreter.ExecuteBlock(statement, environment.New(interpreter.environment))
+	return interpreter.ExecuteBlock(statement, environment.NewBlock(interpreter.environment))
 }
 
-func (interpreter *interpreterImpl) getClassBody(methods []statement.Statement) types.Class {
-	class := call.NewClass([]statement.Statement{})
+// isAnonymousFunction reports whether an initializer takes its name from
+// the variable it is assigned to.
+func isAnonymousFunction(expression statement.Expression) bool {
+	switch data := expression.(type) {
+	case statement.FunctionExpression:
+		return data.Name == nil
+	case statement.ClassExpression:
+		return data.Name == nil
+	}
+	return false
+}
+
+func (interpreter *interpreterImpl) getClassBody(name string, source string, methods []statement.Statement) types.Class {
 	var result []statement.Statement
 	for _, item := range methods {
-		if val, ok := item.(statement.VariableStatement); ok {
-			if val.Static {
-				class.Set(val.Name.Lexeme, interpreter.Evaluate(val.Initializer))
-			} else {
-				result = append(result, val)
-			}
-		} else if val, ok := item.(statement.FunctionStatement); ok {
-			if val.Static {
-				class.Set(val.Name.Lexeme, call.NewFunction(val.Body, val.Params, interpreter.environment))
-			} else {
-				result = append(result, val)
-			}
+		if val, ok := item.(statement.VariableStatement); ok && !val.Static {
+			result = append(result, val)
+		} else if val, ok := item.(statement.FunctionStatement); ok && !val.Static {
+			result = append(result, val)
+		}
+	}
+	class := call.NewClass(interpreter, name, source, result, interpreter.environment)
+	object := class.(types.Object)
+	for _, item := range methods {
+		if val, ok := item.(statement.VariableStatement); ok && val.Static {
+			call.DefineField(object, val.Name.Lexeme, interpreter.Evaluate(val.Initializer), true)
+		} else if val, ok := item.(statement.FunctionStatement); ok && val.Static {
+			var key any = val.Name.Lexeme
+			if val.Key != nil {
+				key = interpreter.Evaluate(val.Key)
+			}
+			call.DefineField(object, key, call.NewMethod(interpreter, val, interpreter.environment), false)
 		}
 	}
-	class.SetMethods(result)
 	return class
 }
 
 func (interpreter *interpreterImpl) VisitClassStatement(statement statement.ClassStatement) any {
-	class := interpreter.getClassBody(statement.Methods)
-	interpreter.environment.Define(statement.Name.Lexeme, class)
+	class := interpreter.getClassBody(statement.Name.Lexeme, statement.Source, statement.Methods)
+	interpreter.environment.DefineLexical(statement.Name.Lexeme, class)
 	return nil
 }
 func (interpreter *interpreterImpl) VisitFunctionStatement(statement statement.FunctionStatement) any {
-	interpreter.environment.Define(statement.Name.Lexeme, call.NewFunction(statement.Body, statement.Params, interpreter.environment))
+	interpreter.environment.Define(statement.Name.Lexeme, call.NewMethod(interpreter, statement, interpreter.environment))
 	return nil
 }
 
@@ -206,8 +403,8 @@ func (interpreter *interpreterImpl) VisitIfStatement(statement statement.IfState
 	} else if statement.ElseBranch != nil {
 		result = interpreter.Execute(statement.ElseBranch)
 	}
-	if val, ok := result.(flow.Return); ok {
-		return val
+	if isAbrupt(result) {
+		return result
 	}
 	return nil
 }
@@ -217,8 +414,63 @@ func (interpreter *interpreterImpl) VisitReturnStatement(statement statement.Ret
 	return flow.NewReturnValue(value)
 }
 func (interpreter *interpreterImpl) VisitWhileStatement(statement statement.WhileStatement) any {
-	for interpreter.isTruth(interpreter.Evaluate(statement.Condition)) {
+	first := statement.Name.Type == token.Do
+	for first || interpreter.isTruth(interpreter.Evaluate(statement.Condition)) {
+		first = false
 		t := interpreter.Execute(statement.Body)
+		if _, ok := t.(flow.Break); ok {
+			break
+		}
+		if val, ok := t.(flow.Return); ok {
+			return val
+		}
+		interpreter.Evaluate(statement.Increment)
+	}
+	return nil
+}
+
+// bindTarget stores the value of the current iteration into the target of a
+// for...in or for...of statement.
+func (interpreter *interpreterImpl) bindTarget(kind *token.Token, target statement.Expression, value any) {
+	switch data := target.(type) {
+	case statement.VariableExpression:
+		if kind != nil {
+			interpreter.declare(kind, data.Name.Lexeme, value)
+		} else {
+			interpreter.environment.Assign(data.Name.Lexeme, value)
+		}
+	case statement.GetExpression:
+		object := interpreter.Evaluate(data.Object)
+		if val, ok := object.(types.Property); ok {
+			val.Set(interpreter.Evaluate(data.Property), value)
+		}
+	}
+}
+
+// executeIteration runs the body of a for...in or for...of statement with a
+// fresh binding for the current value; a var binding belongs to the
+// enclosing scope.
+func (interpreter *interpreterImpl) executeIteration(kind *token.Token, target statement.Expression, body statement.Statement, value any) any {
+	env := environment.NewBlock(interpreter.environment)
+	previous := interpreter.environment
+	interpreter.environment = env
+	defer func() {
+		interpreter.environment = previous
+	}()
+	interpreter.bindTarget(kind, target, value)
+	return interpreter.Execute(body)
+}
+
+func (interpreter *interpreterImpl) VisitForInStatement(statement statement.ForInStatement) any {
+	object := interpreter.Evaluate(statement.Object)
+	for _, key := range call.ForInKeys(object) {
+		if !call.ForInPresent(object, key) {
+			continue
+		}
+		t := interpreter.executeIteration(statement.Kind, statement.Target, statement.Body, key)
+		if _, ok := t.(flow.Break); ok {
+			break
+		}
 		if val, ok := t.(flow.Return); ok {
 			return val
 		}
@@ -226,13 +478,103 @@ func (interpreter *interpreterImpl) VisitWhileStatement(statement statement.Whil
 	return nil
 }
 
+func (interpreter *interpreterImpl) VisitForOfStatement(statement statement.ForOfStatement) (result any) {
+	var iterator *call.Iterator
+	if statement.Await {
+		iterator = call.GetAsyncIterator(interpreter, interpreter.Evaluate(statement.Iterable))
+	} else {
+		iterator = call.GetIterator(interpreter, interpreter.Evaluate(statement.Iterable))
+	}
+	defer func() {
+		if err := recover(); err != nil {
+			if _, ok := err.(flow.Exit); ok {
+				panic(err)
+			}
+			// errors from return() are ignored when the body threw
+			func() {
+				defer func() {
+					recover()
+				}()
+				iterator.Close(interpreter)
+			}()
+			panic(err)
+		}
+	}()
+	for {
+		value, ok := iterator.Step(interpreter)
+		if !ok {
+			return nil
+		}
+		t := interpreter.executeIteration(statement.Kind, statement.Target, statement.Body, value)
+		if _, ok := t.(flow.Break); ok {
+			iterator.Close(interpreter)
+			return nil
+		}
+		if val, ok := t.(flow.Return); ok {
+			iterator.Close(interpreter)
+			return val
+		}
+	}
+}
+
+func (interpreter *interpreterImpl) VisitBreakStatement(statement statement.BreakStatement) any {
+	return flow.Break{}
+}
+
+func (interpreter *interpreterImpl) VisitContinueStatement(statement statement.ContinueStatement) any {
+	return flow.Continue{}
+}
+
+func (interpreter *interpreterImpl) VisitThrowStatement(statement statement.ThrowStatement) any {
+	panic(flow.NewThrow(interpreter.Evaluate(statement.Value)))
+}
+
+func (interpreter *interpreterImpl) VisitTryStatement(statement statement.TryStatement) (result any) {
+	if statement.Finalizer != nil {
+		defer func() {
+			err := recover()
+			if _, ok := err.(flow.Exit); ok {
+				panic(err)
+			}
+			t := interpreter.VisitBlockStatement(*statement.Finalizer)
+			if isAbrupt(t) {
+				result = t
+				return
+			}
+			if err != nil {
+				panic(err)
+			}
+		}()
+	}
+	return interpreter.executeTry(statement)
+}
+
+func (interpreter *interpreterImpl) executeTry(statement statement.TryStatement) (result any) {
+	if statement.Handler == nil {
+		return interpreter.VisitBlockStatement(statement.Block)
+	}
+	defer func() {
+		if err := recover(); err != nil {
+			if !flow.Catchable(err) {
+				panic(err)
+			}
+			env := environment.NewBlock(interpreter.environment)
+			if statement.Param != nil {
+				env.DefineLexical(statement.Param.Lexeme, flow.Recover(err))
+			}
+			result = interpreter.ExecuteBlock(*statement.Handler, env)
+		}
+	}()
+	return interpreter.VisitBlockStatement(statement.Block)
+}
+
 func (interpreter *interpreterImpl) VisitVariableExpression(expression statement.VariableExpression) any {
 	return interpreter.environment.Get(expression.Name.Lexeme)
 }
 func (interpreter *interpreterImpl) VisitLiteralExpression(expr statement.LiteralExpression) any {
 	switch expr.Type {
 	case token.Null:
-		return nil
+		return types.Null{}
 	case token.String:
 		return expr.Value
 	case token.Float64:
@@ -263,14 +605,35 @@ func (interpreter *interpreterImpl) VisitBinaryExpression(expression statement.B
 	left := interpreter.Evaluate(expression.Left)
 	right := interpreter.Evaluate(expression.Right)
 	switch expression.Operator.Type {
+	case token.In:
+		if _, ok := right.(types.Property); !ok {
+			call.ThrowTypeError(interpreter, "Cannot use 'in' operator to search for '%s' in %s", token.ConvertAnyToString(call.ToPropertyKey(left)), token.ConvertAnyToString(right))
+		}
+		return call.HasProperty(right, call.ToPropertyKey(left))
+	case token.Instanceof:
+		return call.InstanceOf(interpreter, left, right)
 	case token.EqualEqual:
-		return token.ConvertAnyToString(left) == token.ConvertAnyToString(right)
+		return call.LooseEquals(interpreter, left, right)
 	case token.EqualEqualEqual:
-		return left == right
+		return call.StrictEquals(left, right)
 	case token.BangEqual:
-		return token.ConvertAnyToString(left) != token.ConvertAnyToString(right)
+		return !call.LooseEquals(interpreter, left, right)
 	case token.BangEqualEqual:
-		return left != right
+		return !call.StrictEquals(left, right)
+	}
+	hint := "number"
+	if expression.Operator.Type == token.Plus {
+		hint = "default"
+	}
+	left = call.ToPrimitive(interpreter, left, hint)
+	right = call.ToPrimitive(interpreter, right, hint)
+	if types.IsSymbol(left) || types.IsSymbol(right) {
+		if expression.Operator.Type == token.Plus {
+			call.ThrowTypeError(interpreter, "Cannot convert a Symbol value to a string")
+		}
+		call.ThrowTypeError(interpreter, "Cannot convert a Symbol value to a number")
+	}
+	switch expression.Operator.Type {
 	case token.Less:
 		{
 			_, stringType1 := left.(string)
@@ -334,7 +697,7 @@ func (interpreter *interpreterImpl) VisitBinaryExpression(expression statement.B
 			_, stringType1 := left.(string)
 			_, stringType2 := right.(string)
 			if stringType1 || stringType2 {
-				return token.ConvertAnyToString(left) + token.ConvertAnyToString(right)
+				return call.Concat(call.ToString(interpreter, left), call.ToString(interpreter, right))
 			}
 			if a, b, check := convertLtoI(left, right); check {
 				return a + b
@@ -553,39 +916,76 @@ func (interpreter *interpreterImpl) VisitBinaryExpression(expression statement.B
 }
 
 func (interpreter *interpreterImpl) VisitCallExpression(expression statement.CallExpression) any {
-	callable := interpreter.Evaluate(expression.Callee)
+	var this any
+	var callable any
+	if val, ok := expression.Callee.(statement.GetExpression); ok {
+		this, callable = interpreter.getProperty(val)
+	} else {
+		callable = interpreter.Evaluate(expression.Callee)
+	}
 	var params []any
 	for _, item := range expression.Arguments {
 		params = append(params, interpreter.Evaluate(item))
 	}
-	val, ok := callable.(types.Function)
-	if ok {
-		return val.Call(interpreter, params)
+	interpreter.SetPosition(expression.Token.Line, expression.Token.Column)
+	if _, ok := callable.(types.Function); ok {
+		// a direct eval sees the scope of the call
+		if val, ok := expression.Callee.(statement.VariableExpression); ok && val.Name.Lexeme == "eval" && call.IsEval(interpreter, callable) {
+			return call.Eval(interpreter, call.GetArgument(params, 0), interpreter.environment)
+		}
+		return call.Invoke(interpreter, callable, this, params)
+	}
+	text := calleeText(expression.Callee)
+	if text == "" {
+		text = "undefined"
+		if callable != nil {
+			text = token.ConvertAnyToString(callable)
+		}
+	}
+	call.ThrowTypeError(interpreter, "%s is not a function", text)
+	return nil
+}
+
+// calleeText names the callee of a call the way V8 quotes it when it is
+// not a function, or returns "" for a callee that is not a name or a chain
+// of property names.
+func calleeText(expression statement.Expression) string {
+	switch data := expression.(type) {
+	case statement.VariableExpression:
+		return data.Name.Lexeme
+	case statement.GetExpression:
+		if property, ok := data.Property.(statement.TokenExpression); ok && !data.IsSquare {
+			if object := calleeText(data.Object); object != "" {
+				return object + "." + property.Name.Lexeme
+			}
+		}
 	}
-	panic("can only call function and call")
+	return ""
 }
 func (interpreter *interpreterImpl) VisitGetExpression(expression statement.GetExpression) any {
-	result := interpreter.Evaluate(expression.Object)
+	_, result := interpreter.getProperty(expression)
+	return result
+}
+
+// getProperty evaluates the object of expression and reads the property
+// from it. Reading a property of null or undefined is a TypeError.
+func (interpreter *interpreterImpl) getProperty(expression statement.GetExpression) (any, any) {
+	object := interpreter.Evaluate(expression.Object)
 	key := interpreter.Evaluate(expression.Property)
-	if val, ok := result.(types.Property); ok {
-		interpreter.lastObjectKey = key
-		interpreter.lastObjectValue = val
-		return val.Get(key)
+	if types.IsNullish(object) {
+		call.ThrowTypeError(interpreter, "Cannot read properties of %s (reading '%s')", call.ToString(interpreter, object), token.ConvertAnyToString(call.ToPropertyKey(key)))
 	}
-	return nil
+	return object, call.GetProperty(interpreter, object, key)
 }
 func (interpreter *interpreterImpl) VisitSetExpression(expression statement.SetExpression) any {
-	interpreter.lastObjectKey = ""
-	interpreter.lastObjectValue = nil
-	interpreter.Evaluate(expression.Object)
-	key := interpreter.lastObjectKey
-	object := interpreter.lastObjectValue
-	if object != nil {
-		value := interpreter.Evaluate(expression.Value)
-		object.Set(key, value)
-		return value
+	// the target is not read, so a proxy only sees the set
+	object := interpreter.Evaluate(expression.Object.Object)
+	key := interpreter.Evaluate(expression.Object.Property)
+	value := interpreter.Evaluate(expression.Value)
+	if val, ok := object.(types.Property); ok {
+		val.Set(key, value)
 	}
-	return nil
+	return value
 }
 func (interpreter *interpreterImpl) VisitLogicalExpression(expression statement.LogicalExpression) any {
 	left := interpreter.Evaluate(expression.Left)
@@ -607,7 +1007,25 @@ func (interpreter *interpreterImpl) VisitGroupingExpression(expression statement
 	return result
 }
 
+// deleteProperty implements the delete operator. Only properties can be
+// deleted; a variable stays and any other operand is just evaluated.
+func (interpreter *interpreterImpl) deleteProperty(target statement.Expression) any {
+	switch val := target.(type) {
+	case statement.GetExpression:
+		object := interpreter.Evaluate(val.Object)
+		key := call.ToPropertyKey(interpreter.Evaluate(val.Property))
+		return call.ToObject(interpreter, object).Delete(key)
+	case statement.VariableExpression:
+		return false
+	}
+	interpreter.Evaluate(target)
+	return true
+}
+
 func (interpreter *interpreterImpl) VisitUnaryExpression(expression statement.UnaryExpression) any {
+	if expression.Operator.Type == token.Delete {
+		return interpreter.deleteProperty(expression.Right)
+	}
 	result := interpreter.Evaluate(expression.Right)
 	switch expression.Operator.Type {
 	case token.PlusPlus:
@@ -648,22 +1066,24 @@ func (interpreter *interpreterImpl) VisitUnaryExpression(expression statement.Un
 
 		}
 	case token.Plus:
-		return result
+		return interpreter.toNumeric(result)
 	case token.Minus:
 		{
-			if result == nil {
-				return -0
-			}
-			if val, ok := result.(bool); ok {
-				return convertBtoF(val)
-			}
-			if val, ok := result.(float64); ok {
+			switch val := interpreter.toNumeric(result).(type) {
+			case int64:
+				if val == 0 {
+					return math.Copysign(0, -1)
+				}
+				return -val
+			case float64:
 				return -val
 			}
 			return types.NaN{}
 		}
 	case token.Bang:
 		return !interpreter.isTruth(result)
+	case token.Typeof:
+		return call.TypeOf(result)
 	case token.BitNot:
 		{
 			var temp int64
@@ -683,6 +1103,20 @@ func (interpreter *interpreterImpl) VisitUnaryExpression(expression statement.Un
 	return nil
 }
 
+// toNumeric converts the operand of unary + and - to a number the way
+// call.ToNumber does, keeping integers and NaN in their own types.
+func (interpreter *interpreterImpl) toNumeric(value any) any {
+	switch value.(type) {
+	case int64, float64, types.NaN:
+		return value
+	}
+	number := call.ToNumber(interpreter, value)
+	if math.IsNaN(number) {
+		return types.NaN{}
+	}
+	return number
+}
+
 func (interpreter *interpreterImpl) VisitPostUnaryExpression(expression statement.PostUnaryExpression) any {
 	result := interpreter.Evaluate(expression.Left)
 	switch expression.Operator.Type {
@@ -733,36 +1167,66 @@ func (interpreter *interpreterImpl) VisitTokenExpression(expression statement.To
 }
 
 func (interpreter *interpreterImpl) VisitFunctionExpression(expression statement.FunctionExpression) any {
-	return call.NewFunction(expression.Body, expression.Params, interpreter.environment)
+	return call.NewFunctionExpression(interpreter, expression, interpreter.environment)
 }
 
 func (interpreter *interpreterImpl) VisitClassExpression(expression statement.ClassExpression) any {
-	return interpreter.getClassBody(expression.Methods)
+	var name string
+	if expression.Name != nil {
+		name = expression.Name.Lexeme
+	}
+	return interpreter.getClassBody(name, expression.Source, expression.Methods)
 }
 
 func (interpreter *interpreterImpl) VisitArrayLiteralExpression(expression statement.ArrayLiteralExpression) any {
-	instance := call.NewArray()
+	instance := call.NewArray(interpreter)
 	for i, item := range expression.Elements {
-		value := interpreter.Evaluate(item)
-		instance.Set(i, value)
+		if item != nil {
+			instance.Set(i, interpreter.Evaluate(item))
+		}
 	}
+	instance.Set("length", int64(len(expression.Elements)))
 	return instance
 }
 
+func (interpreter *interpreterImpl) VisitRegExpLiteralExpression(expression statement.RegExpLiteralExpression) any {
+	return call.NewRegExp(interpreter, expression.Pattern, expression.Flags)
+}
+
 func (interpreter *interpreterImpl) VisitObjectLiteralExpression(expression statement.ObjectLiteralExpression) any {
-	instance := call.NewInstance()
+	instance := call.NewInstance(interpreter)
 	for _, item := range expression.Properties {
 		key := interpreter.Evaluate(item.Key)
 		value := interpreter.Evaluate(item.Value)
+		if name, ok := key.(string); ok && isAnonymousFunction(item.Value) {
+			call.SetFunctionName(value, name)
+		}
 		instance.Set(key, value)
 	}
 	return instance
 }
 
 func (interpreter *interpreterImpl) VisitNewExpression(expression statement.NewExpression) any {
-	if _, ok := expression.Expression.(statement.CallExpression); ok {
-		result := interpreter.Evaluate(expression.Expression)
-		return result
+	if val, ok := expression.Expression.(statement.CallExpression); ok {
+		callee := interpreter.Evaluate(val.Callee)
+		var params []any
+		for _, item := range val.Arguments {
+			params = append(params, interpreter.Evaluate(item))
+		}
+		interpreter.SetPosition(val.Token.Line, val.Token.Column)
+		return call.Construct(interpreter, callee, params)
 	}
 	panic(`Class constructor cannot be invoked without 'new'`)
 }
+
+func (interpreter *interpreterImpl) VisitYieldExpression(expression statement.YieldExpression) any {
+	value := interpreter.Evaluate(expression.Value)
+	if expression.Delegate {
+		return call.YieldDelegate(interpreter, value)
+	}
+	return call.Yield(interpreter, value)
+}
+
+func (interpreter *interpreterImpl) VisitAwaitExpression(expression statement.AwaitExpression) any {
+	return call.Await(interpreter, interpreter.Evaluate(expression.Value))
+}
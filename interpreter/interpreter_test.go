@@ -1,16 +1,22 @@
 package interpreter
 
 import (
+	"bytes"
 	"fmt"
+	"math"
+	"math/rand"
+	"runtime"
 	"testing"
+	"time"
 
 	"github.com/nusr/gojs/call"
-	"github.com/nusr/gojs/environment"
+	"github.com/nusr/gojs/clock"
+	"github.com/nusr/gojs/flow"
+	"github.com/nusr/gojs/types"
 )
 
 func interpret(source string) any {
-	env := environment.New(nil)
-	call.RegisterGlobal(env)
+	env := call.NewGlobalEnvironment()
 	actual := Interpret(source, env)
 	if val, ok := actual.(fmt.Stringer); ok {
 		return val.String()
@@ -18,6 +24,38 @@ func interpret(source string) any {
 	return actual
 }
 
+// interpretResult runs source with its event loop on a virtual clock and
+// returns the global variable result.
+func interpretResult(source string) any {
+	env := call.NewGlobalEnvironment()
+	i := New(env)
+	defer i.Close()
+	i.GetEventLoop().SetClock(clock.NewVirtual(time.Unix(0, 0)))
+	i.Interpret(Parse(source))
+	i.GetEventLoop().Run()
+	actual := env.Get("result")
+	if val, ok := actual.(fmt.Stringer); ok {
+		return val.String()
+	}
+	return actual
+}
+
+// interpretDate runs source in the America/New_York time zone with the
+// clock stopped at 2024-03-10T06:30:00Z, half an hour before daylight
+// saving time starts.
+func interpretDate(t *testing.T, source string) any {
+	location, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip(err)
+	}
+	env := call.NewGlobalEnvironment()
+	i := New(env)
+	defer i.Close()
+	i.SetLocation(location)
+	i.GetEventLoop().SetClock(clock.NewVirtual(time.Date(2024, 3, 10, 6, 30, 0, 0, time.UTC)))
+	return i.Interpret(Parse(source))
+}
+
 func Test_interpret_primary(t *testing.T) {
 	tests := []struct {
 		name   string
@@ -27,7 +65,7 @@ func Test_interpret_primary(t *testing.T) {
 		{
 			"null",
 			"null",
-			nil,
+			"null",
 		},
 		{
 			"int",
@@ -54,6 +92,11 @@ func Test_interpret_primary(t *testing.T) {
 			"'str'",
 			"str",
 		},
+		{
+			"typeof null",
+			"typeof null + (null == undefined) + (null === undefined) + (null + 1)",
+			"objecttruefalse1",
+		},
 		{
 			"string 2",
 			`"string"`,
@@ -251,6 +294,21 @@ func Test_interpret_unary(t *testing.T) {
 		// `,
 		// int64(3),
 		// },
+		{
+			"unary plus string",
+			"+'3' === 3 && +' 1.5 ' === 1.5 && +true === 1 && typeof +'3'",
+			"number",
+		},
+		{
+			"unary minus string",
+			"-'2' === -2 && -'' === 0 && -'x'",
+			"NaN",
+		},
+		{
+			"unary plus object",
+			"+{ valueOf() { return '4' } } + -[5]",
+			float64(-1),
+		},
 		{
 			"post unary ++",
 			`
@@ -355,6 +413,239 @@ func Test_interpret_array(t *testing.T) {
 			"var a = [,,];a[0];",
 			nil,
 		},
+		{
+			"holes",
+			"var a = [1,,3];a.length + ':' + (1 in a)",
+			"3:false",
+		},
+		{
+			"length truncates",
+			"var a = [1,2,3];a.length = 1;a.length = 2;a.join()",
+			"1,",
+		},
+		{
+			"sparse",
+			"var a = [1];a[1000000000] = 2;a.length + ':' + a[1000000000] + ':' + (5 in a)",
+			"1000000001:2:false",
+		},
+		{
+			"sparse truncate",
+			"var a = [1];a[1000000000] = 2;a.length = 1;a.push(3) + ':' + a.join()",
+			"2:1,3",
+		},
+		{
+			"sparse search",
+			"var a = [];a[1000000000] = 1;[a.indexOf(1), a.includes(undefined), a.includes(1), a.slice(999999999).length, a.slice(999999999)[1]].join()",
+			"1000000000,true,true,2,1",
+		},
+		{
+			"sparse reverse",
+			"var a = [];a[1000000000] = 1;a.reverse();a[0] + ':' + (1000000000 in a) + ':' + a.length",
+			"1:false:1000000001",
+		},
+		{
+			"sparse sort",
+			"var a = [3];a[3000] = 1;a[2000] = 2;a.sort();a.length + ':' + a[0] + a[1] + a[2] + ':' + (3 in a)",
+			"3001:123:false",
+		},
+		{
+			"sparse join",
+			"var a = [];a[4294967294] = 1;a[3] = null;a[2] = 'x';var r = [a.join(''), a.slice(0, 5).join('-')];\ntry { a.join() } catch (e) { r.push(String(e)) }\nr.join()",
+			"x1,--x--,RangeError: Invalid string length",
+		},
+		{
+			"sparse copy",
+			"var a = [];a[4294967294] = 1;var r = [];\ntry { a.toSorted() } catch (e) { r.push(String(e)) }\ntry { a.toReversed() } catch (e) { r.push(e.name) }\ntry { a.with(0, 1) } catch (e) { r.push(e.name) }\ntry { a.toSpliced(0, 1) } catch (e) { r.push(e.name) }\nr.join()",
+			"RangeError: Invalid array length,RangeError,RangeError,RangeError",
+		},
+		{
+			"sparse filled",
+			"var a = [];a[5000] = 1;for (var i = 0; i < 5000; i++) a[i] = i;a.push(7);a.length + ':' + a[4999] + ':' + a[5001] + ':' + a.indexOf(7)",
+			"5002:4999:7:7",
+		},
+		{
+			"named properties",
+			"var a = [1];a[-1] = 2;a.foo = 3;a['-1'] * 10 + a.foo + a.length",
+			int64(24),
+		},
+		{
+			"for in",
+			`
+			var a = [1,,3]
+			a[5000] = 4
+			a.foo = 5
+			var result = ''
+			for (var key in a) result += key + ' '
+			result
+			`,
+			"0 2 5000 foo ",
+		},
+		{
+			"invalid length",
+			"var a = [];try { a.length = -1 } catch (e) { a = e }\na",
+			"RangeError: Invalid array length",
+		},
+		{
+			"push pop",
+			"var a = [1];a.push(2, 3) * 10 + a.pop()",
+			int64(33),
+		},
+		{
+			"shift unshift",
+			"var a = [1,2];a.unshift(0);a.shift() + a.join()",
+			"01,2",
+		},
+		{
+			"splice",
+			"var a = [1,2,3,4];a.splice(1, 2, 'x') + ':' + a",
+			"2,3:1,x,4",
+		},
+		{
+			"slice",
+			"[1,2,3,4].slice(-3, -1).join()",
+			"2,3",
+		},
+		{
+			"concat",
+			"[1].concat([2,[3]], 4).length",
+			int64(4),
+		},
+		{
+			"reverse holes",
+			"var a = [1,,3].reverse();a.join() + (1 in a)",
+			"3,,1false",
+		},
+		{
+			"indexOf skips holes",
+			"[1,,3].indexOf(undefined)",
+			int64(-1),
+		},
+		{
+			"includes visits holes",
+			"[1,,3].includes(undefined)",
+			true,
+		},
+		{
+			"find",
+			"[1,2,3].find(x => x > 1) * 10 + [1,2,3].findLast(x => x > 1)",
+			int64(23),
+		},
+		{
+			"findIndex",
+			"[1,2,3].findIndex(x => x > 5) + [1,2,3].findLastIndex(x => x < 3)",
+			int64(0),
+		},
+		{
+			"filter map",
+			"[1,2,3].filter(x => x != 2).map((x, i) => x * i).join()",
+			"0,3",
+		},
+		{
+			"map keeps holes",
+			"var a = [1,,3].map(x => x * 2);a.length + ':' + (1 in a)",
+			"3:false",
+		},
+		{
+			"forEach",
+			"var sum = 0;[1,,3].forEach(x => sum += x);sum",
+			int64(4),
+		},
+		{
+			"reduce",
+			"[1,2,3].reduce((a, b) => a + b) + [1,2,3].reduceRight((a, b) => a + b, '')",
+			"6321",
+		},
+		{
+			"reduce empty",
+			"var a;try { [].reduce((a, b) => a) } catch (e) { a = String(e) }\na",
+			"TypeError: Reduce of empty array with no initial value",
+		},
+		{
+			"some every",
+			"[1,2].some(x => x > 1) && ![1,2].every(x => x > 1)",
+			true,
+		},
+		{
+			"flat",
+			"[1,[2,[3,[4]]]].flat().length * 10 + [1,[2,[3,[4]]]].flat(10).length",
+			int64(34),
+		},
+		{
+			"flatMap",
+			"[1,2].flatMap(x => [x, x * 2]).join()",
+			"1,2,2,4",
+		},
+		{
+			"fill at",
+			"[1,2,3].fill(0, 1).join() + [1,2,3].at(-1)",
+			"1,0,03",
+		},
+		{
+			"keys values entries",
+			`
+			var result = ''
+			for (var key of [5,6].keys()) result += key
+			for (var value of [5,6].values()) result += value
+			for (var entry of [5,6].entries()) result += entry
+			result
+			`,
+			"01560,51,6",
+		},
+		{
+			"sort",
+			"[5,1,10,2].sort().join() + ':' + [5,1,10,2].sort((a, b) => a - b).join()",
+			"1,10,2,5:1,2,5,10",
+		},
+		{
+			"sort undefined and holes",
+			"var a = [3,undefined,1,,2].sort();a.join() + (4 in a)",
+			"1,2,3,,false",
+		},
+		{
+			"sort stable",
+			"[['b',1],['a',1],['c',0]].sort((x, y) => x[1] - y[1]).map(x => x[0]).join('')",
+			"cba",
+		},
+		{
+			"sort comparator",
+			"var a;try { [1].sort(1) } catch (e) { a = String(e) }\na",
+			"TypeError: The comparison function must be either a function or undefined",
+		},
+		{
+			"change by copy",
+			"var a = [3,1,2];a.toSorted().join() + a.toReversed().join() + a.with(-1, 9).join() + a.join()",
+			"1,2,32,1,33,1,93,1,2",
+		},
+		{
+			"with out of range",
+			"var a;try { [1].with(5, 1) } catch (e) { a = String(e) }\na",
+			"RangeError: Invalid index : 5",
+		},
+		{
+			"join cycle",
+			"var a = [1];a.push(a);a.join()",
+			"1,",
+		},
+		{
+			"callback",
+			"var a;try { [1].map() } catch (e) { a = String(e) }\na",
+			"TypeError: undefined is not a function",
+		},
+		{
+			"Array.isArray",
+			"Array.isArray([]) && !Array.isArray({})",
+			true,
+		},
+		{
+			"Array.from",
+			"Array.from('abc').join() + Array.from({length: 3}, (v, i) => i * i).join()",
+			"a,b,c0,1,4",
+		},
+		{
+			"Array.of",
+			"Array.of(7).length + Array(7).length + new Array(1, 2).length",
+			int64(10),
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -384,6 +675,25 @@ func Test_interpret_object(t *testing.T) {
 			"var a = {};a.b=2;",
 			int64(2),
 		},
+		{"keys order", "Object.keys({b: 1, 2: 1, a: 1, 1: 1}).join()", "1,2,b,a"},
+		{"keys array", "var a = [1, 2];\na.x = 3\nObject.keys(a).join() + '|' + Object.keys('ab').join()", "0,1,x|0,1"},
+		{"keys class instance", "class A { constructor() { this.y = 1; this.x = 2 } m() {} }\nObject.keys(new A()).join()", "y,x"},
+		{"values", "Object.values({b: 1, a: 'x', [Symbol()]: 2}).join()", "1,x"},
+		{"entries", "JSON.stringify(Object.entries({a: 1, 0: [2]}))", `[["0",[2]],["a",1]]`},
+		{"fromEntries", "JSON.stringify(Object.fromEntries([['a', 1], ['b', 2], ['a', 3]]))", `{"a":3,"b":2}`},
+		{"fromEntries entries", "var o = Object.fromEntries(Object.entries({x: 1, y: 2}).map((e) => [e[0], e[1] * 2]))\no.x + o.y", int64(6)},
+		{"fromEntries invalid", "var r\ntry { Object.fromEntries([1]) } catch (e) { r = String(e) }\nr", "TypeError: Iterator value 1 is not an entry object"},
+		{"assign", "var s = Symbol('s')\nvar t = Object.assign({a: 1}, null, {b: 2}, {a: 3, [s]: 4})\nJSON.stringify(t) + t[s]", `{"a":3,"b":2}4`},
+		{"assign returns target", "var t = {}\nObject.assign(t, {a: 1}) === t", true},
+		{"is", "[Object.is(Math.sqrt(-1), Math.sqrt(-1)), Object.is(0, -0), Object.is(1, 1.0), Object.is('a', 'a'), Object.is({}, {})].join()", "true,false,true,true,false"},
+		{"hasOwn", "var a = {x: 1}\n;[Object.hasOwn(a, 'x'), Object.hasOwn(a, 'toString'), Object.hasOwn([1], 0)].join()", "true,false,true"},
+		{"groupBy", "var g = Object.groupBy([1, 2, 3, 4, 5], (n, i) => { if (n > 2 && i > 1) { return 'big' } return 'small' })\nJSON.stringify(g) + (g.hasOwnProperty === undefined)", `{"small":[1,2],"big":[3,4,5]}true`},
+		{"hasOwnProperty", "var a = {x: 1}\n;[a.hasOwnProperty('x'), a.hasOwnProperty('y'), a.hasOwnProperty('hasOwnProperty'), [1].hasOwnProperty(0)].join()", "true,false,false,true"},
+		{"isPrototypeOf", "class A {}\nclass B {}\n[A.prototype.isPrototypeOf(new A()), Object.prototype.isPrototypeOf(new A()), Object.prototype.isPrototypeOf([]), B.prototype.isPrototypeOf(new A())].join()", "true,true,true,false"},
+		{"propertyIsEnumerable", "var a = [1]\n;[a.propertyIsEnumerable(0), a.propertyIsEnumerable('length'), ({x: 1}).propertyIsEnumerable('x')].join()", "true,false,true"},
+		{"toString", "var t = Object.prototype.toString\nvar o = {toString: t}\nvar tagged = {[Symbol.toStringTag]: 'Tagged'}\n;[String({}), String(tagged), [].toString === t, ({}).toString()].join()", "[object Object],[object Tagged],false,[object Object]"},
+		{"valueOf", "var a = {}\na.valueOf() === a", true},
+		{"constructor", "({}).constructor === Object && Object.prototype.constructor === Object", true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -421,6 +731,38 @@ func Test_interpret_function(t *testing.T) {
 			add(1,3.0)`,
 			float64(4.0),
 		},
+		{
+			"toString",
+			`function add(a, b) { return a + b }
+			class K { static  m(x) {} }
+			var f = async x => x + 1;
+			[String(add), K.toString(), K.m.toString(), f.toString(), ({ *g() {} }).g.toString(), String(Math.max), typeof Function.prototype].join('|')`,
+			"function add(a, b) { return a + b }|class K { static  m(x) {} }|m(x) {}|async x => x + 1|*g() {}|function max() { [native code] }|function",
+		},
+		{
+			"name and length",
+			`var f = function (a, b, c) {}
+			class K { constructor(x) {} }
+			f.name = 'g';
+			[f.name, f.length, (() => 1).name, K.name, K.length, Reflect.ownKeys(function (a) {}).join(), Reflect.getOwnPropertyDescriptor(f, 'length').writable].join()`,
+			"f,3,,K,1,length,name,prototype,false",
+		},
+		{
+			"prototype",
+			`function f() {}
+			var r = []
+			try { Reflect.apply(Function.prototype.toString, {}, []) } catch (e) { r.push(e) }
+			[Reflect.getPrototypeOf(f) === Function.prototype, f instanceof Function, Math.max instanceof Function, Function.prototype.constructor === Function, r[0]].join()`,
+			"true,true,true,true,TypeError: Function.prototype.toString requires that 'this' be a Function",
+		},
+		{
+			"constructor",
+			`var add = new Function('a', 'b', 'return a + b')
+			var r = []
+			try { Function('a', '}\nfunction anonymous() {') } catch (e) { r.push(e) }
+			[add(2, 3), add.name, add.length, Function('return 1')(), r[0], add.toString()].join('|')`,
+			"5|anonymous|2|1|SyntaxError: Single function literal required|function anonymous(a,b\n) {\nreturn a + b\n}",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -434,6 +776,44 @@ func Test_interpret_function(t *testing.T) {
 	}
 }
 
+func Test_interpret_eval(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   any
+	}{
+		{"value", "[eval('1 + 2'), eval(5), eval('1; var x = 2'), x, eval('3; if (true) {}')].join()", "3,5,1,2,"},
+		{"direct", "var g = 'global'\nfunction f() { var g = 'local'; return eval('g') }\nf()", "local"},
+		{"indirect", "var g = 'global'\nfunction f() { var g = 'local'; var e = eval; return e('g') }\nf()", "global"},
+		{"declaration", "function f() { eval('var inner = 7'); return inner }\n[f(), typeof inner].join()", "7,undefined"},
+		{"syntax error", "var r\ntry { eval('1 +') } catch (e) { r = String(e) }\nString(r).slice(0, 12)", "SyntaxError:"},
+		{"lexical", "eval('let a = 1; const b = 2; class C {} a + b');\n[typeof a, typeof b, typeof C].join()", "undefined,undefined,undefined"},
+		{"lexical closure", "var f = eval('let a = 1; function g() { return a } g');\n[f(), typeof a, typeof g].join()", "1,undefined,function"},
+		{"lexical in function", "function f() { eval('let inner = 7; var outer = inner'); return [typeof inner, outer].join() }\nf()", "undefined,7"},
+		{"global this", "Function('return this')() === globalThis && new Function('return this')() === globalThis", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := interpret(tt.source)
+			if actual != tt.want {
+				t.Errorf("expect= %v, actual= %v", tt.want, actual)
+			}
+		})
+	}
+}
+
+func Test_interpret_code_generation(t *testing.T) {
+	env := call.NewGlobalEnvironment()
+	i := New(env)
+	defer i.Close()
+	i.SetCodeGeneration(false)
+	i.Interpret(Parse("var result = []\nfor (var f of [() => eval('1'), () => { var e = eval; return e('1') }, () => new Function('return 1')]) {\ntry { f() } catch (e) { result.push(e) }\n}\nresult = [eval(1), result.join('|')].join('|')"))
+	want := "1|EvalError: Code generation from strings disallowed for this context|EvalError: Code generation from strings disallowed for this context|EvalError: Code generation from strings disallowed for this context"
+	if actual := env.Get("result"); actual != want {
+		t.Errorf("expect %v, actual: %v", want, actual)
+	}
+}
+
 func Test_interpret_class(t *testing.T) {
 	tests := []struct {
 		name   string
@@ -486,6 +866,44 @@ func Test_interpret_class(t *testing.T) {
 			`,
 			float64(1.0),
 		},
+		{
+			"computed key",
+			`
+			var n = 0
+			function key() {
+				n++
+				return 'x'
+			}
+			function make() {
+				var k = 'm'
+				return class {
+					[k]() { return 1 }
+					[key()]() { return 2 }
+				}
+			}
+			var A = make()
+			var a = new A()
+			var b = new A()
+			a.m() + ':' + b.x() + ':' + n
+			`,
+			"1:2:1",
+		},
+		{
+			"prototype methods",
+			`
+			function make() {
+				var secret = 7
+				return class A {
+					constructor(x) { this.x = x }
+					get() { return this.x + secret }
+				}
+			}
+			var A = make()
+			var a = new A(1);
+			[a.hasOwnProperty('get'), A.prototype.hasOwnProperty('get'), Object.keys(a).join(), a.get === new A(2).get, a.get()].join()
+			`,
+			"false,true,x,true,8",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -497,3 +915,2322 @@ func Test_interpret_class(t *testing.T) {
 		})
 	}
 }
+
+func Test_interpret_for_in(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   any
+	}{
+		{
+			"order",
+			`
+			var a = {b: 1, a: 2}
+			a[2] = 3
+			a['1'] = 4
+			var result = ''
+			for (var key in a) {
+				result += key
+			}
+			result
+			`,
+			"12ba",
+		},
+		{
+			"array",
+			`
+			var result = ''
+			for (let key in [1, 2]) result += key
+			result
+			`,
+			"01",
+		},
+		{
+			"string",
+			`
+			var result = ''
+			for (const key in 'ab') result += key
+			result
+			`,
+			"01",
+		},
+		{
+			"class instance skips methods",
+			`
+			class Base {
+				a = 1
+				method() {}
+			}
+			var result = ''
+			for (var key in new Base()) result += key
+			result
+			`,
+			"a",
+		},
+		{
+			"existing binding",
+			`
+			var key
+			for (key in {a: 1, b: 2}) {}
+			key
+			`,
+			"b",
+		},
+		{
+			"in operator",
+			`
+			var a = {b: 1}
+			'b' in a && !('c' in a)
+			`,
+			true,
+		},
+		{
+			"deleted during the loop",
+			`
+			var o = {a: 1, b: 2, c: 3}
+			var p = {}
+			Reflect.setPrototypeOf(p, {x: 1, y: 2})
+			var result = ''
+			for (var key in o) {
+				result += key
+				delete o.b
+			}
+			for (var key in p) {
+				result += key
+				delete Reflect.getPrototypeOf(p).y
+			}
+			result
+			`,
+			"acx",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := interpret(tt.source)
+			if actual != tt.want {
+				t.Errorf("expect= %v, actual= %v", tt.want, actual)
+			}
+		})
+	}
+}
+
+func Test_interpret_for_of(t *testing.T) {
+	iterator := `
+	var closed = 0
+	var list = {}
+	list[Symbol.iterator] = function() {
+		var i = 0
+		return {
+			next: function() {
+				i++
+				return {value: i, done: i > 3}
+			},
+			return: function() {
+				closed++
+				return {}
+			}
+		}
+	}
+	`
+	tests := []struct {
+		name   string
+		source string
+		want   any
+	}{
+		{
+			"array",
+			`
+			var result = 0
+			for (const item of [1, 2, 3]) result += item
+			result
+			`,
+			int64(6),
+		},
+		{
+			"string",
+			`
+			var result = ''
+			for (var item of 'héllo') result = item + result
+			result
+			`,
+			"olléh",
+		},
+		{
+			"continue",
+			`
+			var result = 0
+			for (let item of [1, 2, 3]) {
+				if (item == 2) {
+					continue
+				}
+				result += item
+			}
+			result
+			`,
+			int64(4),
+		},
+		{
+			"user defined iterator",
+			iterator + `
+			var result = 0
+			for (var item of list) result += item
+			result * 10 + closed
+			`,
+			int64(60),
+		},
+		{
+			"break calls return",
+			iterator + `
+			for (var item of list) {
+				break
+			}
+			closed
+			`,
+			int64(1),
+		},
+		{
+			"return calls return",
+			iterator + `
+			function first() {
+				for (var item of list) {
+					return item
+				}
+			}
+			first() + closed
+			`,
+			int64(2),
+		},
+		{
+			"throw calls return",
+			iterator + `
+			try {
+				for (var item of list) {
+					throw 'error'
+				}
+			} catch (e) {
+				closed
+			}
+			`,
+			int64(1),
+		},
+		{
+			"not iterable",
+			`
+			var result
+			try {
+				for (var item of {}) {}
+			} catch (e) {
+				result = e
+			}
+			result
+			`,
+			"TypeError: object is not iterable",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := interpret(tt.source)
+			if actual != tt.want {
+				t.Errorf("expect= %v, actual= %v", tt.want, actual)
+			}
+		})
+	}
+}
+
+func Test_interpret_loop_control(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   any
+	}{
+		{
+			"for",
+			`
+			var result = ''
+			for (var i = 0; i < 6; i++) {
+				if (i == 1) {
+					continue
+				}
+				if (i == 4) {
+					break
+				}
+				result += i
+			}
+			result
+			`,
+			"023",
+		},
+		{
+			"do while",
+			`
+			var i = 0
+			do {
+				i++
+				if (i == 2) {
+					continue
+				}
+			} while (i < 5)
+			i
+			`,
+			int64(5),
+		},
+		{
+			"try finally",
+			`
+			var result = ''
+			function test() {
+				try {
+					return 'try'
+				} finally {
+					result += 'finally'
+				}
+			}
+			test() + result
+			`,
+			"tryfinally",
+		},
+		{
+			"catch without binding",
+			`
+			var result
+			try {
+				throw 1
+			} catch {
+				result = 2
+			}
+			result
+			`,
+			int64(2),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := interpret(tt.source)
+			if actual != tt.want {
+				t.Errorf("expect= %v, actual= %v", tt.want, actual)
+			}
+		})
+	}
+}
+
+func Test_interpret_string(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   any
+	}{
+		{"index", "'abc'[1]", "b"},
+		{"length", "'😀a'.length", int64(3)},
+		{"charAt", "'abc'.charAt(1) + 'abc'.charAt(5)", "b"},
+		{"charCodeAt", "'😀'.charCodeAt(0)", int64(55357)},
+		{"codePointAt", "'😀'.codePointAt(0)", int64(128512)},
+		{"lone surrogate", "var s = String.fromCharCode(55296);\n[s.length, s.charCodeAt(0), String.fromCodePoint(55296).charCodeAt(0), (s + 'a').toUpperCase().charCodeAt(0), Array.from('a' + s + '😀').length].join()", "1,55296,55296,55296,3"},
+		{"split surrogates", "var a = '😀'.slice(0, 1)\nvar b = '😀'.slice(1);\n[a.length, a.charCodeAt(0), a + b === '😀', [a, b].join('') === '😀', a.concat(b) === '😀'].join()", "1,55357,true,true,true"},
+		{"at", "'abc'.at(-1)", "c"},
+		{"slice", "'hello world'.slice(-5, -1)", "worl"},
+		{"substring", "'hello'.substring(3, 1)", "el"},
+		{"substr", "'hello'.substr(-3, 2)", "ll"},
+		{"indexOf", "'hello'.indexOf('l') * 10 + 'hello'.lastIndexOf('l')", int64(23)},
+		{"includes", "'hello'.includes('ell') && 'hello'.startsWith('ell', 1) && 'hello'.endsWith('ell', 4)", true},
+		{"split", "'a,b,,c'.split(',').length * 10 + 'a,b,,c'.split(',', 2).length", int64(42)},
+		{"split empty", "'abc'.split('').join('-')", "a-b-c"},
+		{"replace", "'aaa'.replace('a', 'b') + 'aaa'.replaceAll('a', 'b')", "baabbb"},
+		{"replace patterns", "'abc'.replace('b', '[$&$`$$]')", "a[ba$]c"},
+		{"replace function", "'abc'.replace('b', (m, i, s) => m + i + s)", "ab1abcc"},
+		{"replaceAll empty", "'abc'.replaceAll('', '-')", "-a-b-c-"},
+		{"trim", "'  hi  '.trim() + '  hi  '.trimStart() + '  hi  '.trimEnd()", "hihi    hi"},
+		{"pad", "'5'.padStart(3, '0') + '5'.padEnd(4, 'ab')", "0055aba"},
+		{"repeat", "'ab'.repeat(3)", "ababab"},
+		{"repeat negative", "var a\ntry { 'a'.repeat(-1) } catch (e) { a = String(e) }\na", "RangeError: Invalid count value: -1"},
+		{"case", "'Straße'.toUpperCase() + 'ΟΔΟΣ'.toLowerCase()", "STRASSEοδος"},
+		{"normalize", "'Å'.normalize('NFD').length", int64(2)},
+		{"localeCompare", "['b', 'a', 'C'].sort((a, b) => a.localeCompare(b)).join()", "a,b,C"},
+		{"fromCharCode", "String.fromCharCode(72, 105) + String.fromCodePoint(128512)", "Hi😀"},
+		{"raw", "String.raw({raw: ['a', 'b', 'c']}, 1, 2)", "a1b2c"},
+		{"String", "String(123) + String(Symbol('x'))", "123Symbol(x)"},
+		{"String object", "var s = new String('xy');typeof s + s.length + s[1]", "object2y"},
+		{"constructor", "'x'.constructor === String", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := interpret(tt.source)
+			if actual != tt.want {
+				t.Errorf("expect= %v, actual= %v", tt.want, actual)
+			}
+		})
+	}
+}
+
+func Test_interpret_regexp(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   any
+	}{
+		{"test", "/b+/.test('abbc')", true},
+		{"division", "var a = 8\nvar g = 2\na / 2 / g", int64(2)},
+		{"exec", "var m = /(\\d+)-(\\d+)/.exec('on 10-20')\nm[0] + m[1] + m[2] + m.index + m.input", "10-2010203on 10-20"},
+		{"no match", "/x/.exec('abc')", "null"},
+		{"lastIndex", "var r = /o/g\nr.test('foo')\nvar a = r.lastIndex\nr.test('foo')\nvar b = r.lastIndex\nr.test('foo')\na * 100 + b * 10 + r.lastIndex", int64(230)},
+		{"sticky", "var r = /a/y\nr.lastIndex = 1\nr.test('ba') + '' + r.test('ba')", "truefalse"},
+		{"flags", "var r = /a/gimsuyd\nr.flags + r.global + r.ignoreCase + r.multiline + r.dotAll + r.unicode + r.sticky + r.hasIndices", "dgimsuytruetruetruetruetruetruetrue"},
+		{"source", "new RegExp('a/b', 'g').toString() + new RegExp('').source", "/a\\/b/g(?:)"},
+		{"copy", "var r = /a/g\nnew RegExp(r, 'i').flags + new RegExp(r).flags + (RegExp(r) === r)", "igtrue"},
+		{"named groups", "var m = '2020-12'.match(/(?<year>\\d{4})-(?<month>\\d\\d)/)\nm.groups.year + m.groups.month", "202012"},
+		{"indices", "var m = /b(c)/d.exec('abc')\nm.indices[0].join() + ';' + m.indices[1].join()", "1,3;2,3"},
+		{"lookbehind", "'$10 €20'.match(/(?<=\\$)\\d+/)[0]", "10"},
+		{"backreference", "/(a+)b\\1/.exec('xaabaa')[0]", "aabaa"},
+		{"ignore case", "/STRASSE/i.test('strasse') && /ſ/i.test('s') === false && /ſ/iu.test('s')", true},
+		{"unicode", "'😀'.match(/./gu).length * 10 + '😀'.match(/./g).length", int64(12)},
+		{"property escape", "'aβc'.replace(/\\p{Script=Greek}/u, 'b')", "abc"},
+		{"match global", "'a1b22c333'.match(/\\d+/g).join()", "1,22,333"},
+		{"matchAll", "var s = ''\nfor (var m of 'a1b22'.matchAll(/\\d+/g)) { s += m[0] + m.index }\ns", "11223"},
+		{"matchAll non-global", "var a\ntry { 'a'.matchAll(/a/) } catch (e) { a = String(e) }\na", "TypeError: String.prototype.matchAll called with a non-global RegExp argument"},
+		{"search", "'abc'.search(/c/) * 10 + 'abc'.search(/x/)", int64(19)},
+		{"replace", "'10-20 30-40'.replace(/(\\d+)-(\\d+)/g, '$2-$1')", "20-10 40-30"},
+		{"replace named", "'ab'.replace(/(?<x>a)/, '[$<x>]')", "[a]b"},
+		{"replace function", "'a1b2'.replace(/\\d/g, (m, i) => '<' + m + i + '>')", "a<11>b<23>"},
+		{"replace empty", "'abc'.replace(/(?:)/g, '-')", "-a-b-c-"},
+		{"replaceAll", "var a\ntry { 'a'.replaceAll(/a/, 'b') } catch (e) { a = String(e) }\na + 'aa'.replaceAll(/a/g, 'b')", "TypeError: String.prototype.replaceAll called with a non-global RegExp argumentbb"},
+		{"split", "'a1b2c3'.split(/\\d/).join('|') + ';' + 'a1b2c3'.split(/(\\d)/, 4).join('|')", "a|b|c|;a|1|b|2"},
+		{"includes regexp", "var a\ntry { 'a'.includes(/a/) } catch (e) { a = String(e) }\na", "TypeError: First argument to String.prototype.includes must not be a regular expression"},
+		{"syntax error", "var a\ntry { new RegExp('(', 'g') } catch (e) { a = String(e) }\na", "SyntaxError: Invalid regular expression: /(/g: Unterminated group"},
+		{"flags error", "var a\ntry { new RegExp('a', 'gg') } catch (e) { a = String(e) }\na", "SyntaxError: Invalid flags supplied to RegExp constructor 'gg'"},
+		{"step limit", "var a\ntry { /(a+)+b/.test('aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa') } catch (e) { a = String(e) }\na", "RangeError: Maximum regular expression backtracking steps exceeded"},
+		{"constructor", "/a/.constructor === RegExp", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := interpret(tt.source)
+			if actual != tt.want {
+				t.Errorf("expect= %v, actual= %v", tt.want, actual)
+			}
+		})
+	}
+}
+
+func Test_interpret_math(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   any
+	}{
+		{"constants", "[Math.PI, Math.E, Math.SQRT1_2, Math.LN2].join()", "3.141592653589793,2.718281828459045,0.7071067811865476,0.6931471805599453"},
+		{"floor", "Math.floor(-3.5) + Math.ceil(3.2) + Math.trunc(-4.7)", float64(-4 + 4 - 4)},
+		{"round", "[Math.round(2.5), Math.round(-2.5), Math.round(0.49999999999999994), Math.round('7.6')].join()", "3,-2,0,8"},
+		{"max", "Math.max(1, 3, 2) + Math.min(4, -1)", float64(2)},
+		{"max empty", "String(Math.max()) + String(Math.min())", "-InfinityInfinity"},
+		{"max NaN", "String(Math.max(1, 'x'))", "NaN"},
+		{"pow", "Math.pow(2, 10) + Math.sqrt(16) + Math.cbrt(-27)", float64(1025)},
+		{"pow NaN", "String(Math.pow(1, Math.pow(2, 2000)))", "NaN"},
+		{"hypot", "Math.hypot(3, 4) + Math.hypot()", float64(5)},
+		{"clz32", "Math.clz32(1) * 100 + Math.clz32(0)", float64(3132)},
+		{"imul", "Math.imul(4294967295, 5) * 100 + Math.imul(3, 4)", float64(-488)},
+		{"fround", "Math.fround(5.05)", 5.050000190734863},
+		{"sign", "Math.sign(-3) * 10 + Math.sign('2')", float64(-9)},
+		{"log", "Math.log10(1000) + Math.log2(8) + Math.log1p(0) + Math.expm1(0)", float64(6)},
+		{"trigonometry", "Math.atan2(1, 1) * 4", math.Pi},
+		{"random", "var r = Math.random()\nr >= 0 && r < 1", true},
+		{"valueOf", "Math.abs({ valueOf() { return -2 } })", float64(2)},
+		{"toStringTag", "String(Math)", "[object Math]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := interpret(tt.source)
+			if actual != tt.want {
+				t.Errorf("expect= %v, actual= %v", tt.want, actual)
+			}
+		})
+	}
+}
+
+func Test_interpret_math_random_seed(t *testing.T) {
+	run := func() any {
+		env := call.NewGlobalEnvironment()
+		i := New(env)
+		defer i.Close()
+		i.SetRandom(rand.New(rand.NewSource(42)))
+		i.Interpret(Parse("var result = [Math.random(), Math.random(), Math.random()].join()"))
+		return env.Get("result")
+	}
+	first := run()
+	if second := run(); first != second {
+		t.Errorf("expect %v, actual: %v", first, second)
+	}
+}
+
+func Test_interpret_number(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   any
+	}{
+		{"toFixed", "var n = 1.005\nn.toFixed(2) + ' ' + (2.5).toFixed(0) + ' ' + (1234.5678).toFixed(2)", "1.00 3 1234.57"},
+		{"toFixed integer", "var n = 5\nn.toFixed(2)", "5.00"},
+		{"toFixed range", "var a\ntry { (1).toFixed(101) } catch (e) { a = String(e) }\na", "RangeError: toFixed() digits argument must be between 0 and 100"},
+		{"toPrecision", "(123.456).toPrecision(4) + ' ' + (0.00001).toPrecision(1) + ' ' + (123.456).toPrecision()", "123.5 0.00001 123.456"},
+		{"toExponential", "(123456).toExponential(2) + ' ' + (0.5).toExponential()", "1.23e+5 5e-1"},
+		{"toString radix", "(255).toString(16) + ' ' + (-255).toString(2) + ' ' + (0.5).toString(2)", "ff -11111111 0.1"},
+		{"toString range", "var a\ntry { (1).toString(1) } catch (e) { a = String(e) }\na", "RangeError: toString() radix argument must be between 2 and 36"},
+		{"Number", "Number('  12  ') + Number('0x1F') + Number('') + Number(true)", float64(44)},
+		{"Number NaN", "isNaN(Number('12px')) && isNaN(Number('1_0')) && Number() === 0", true},
+		{"Number object", "var n = new Number(5)\ntypeof n + (n + 1) + n.toFixed(1)", "object65.0"},
+		{"parseInt", "parseInt('  42px') + parseInt('-0x1F') + parseInt('z', 36) + parseInt('101', 2)", float64(51)},
+		{"parseInt NaN", "isNaN(parseInt('px')) && isNaN(parseInt('1', 37))", true},
+		{"parseFloat", "parseFloat('3.14abc') + parseFloat('  -.5e1x')", -1.8599999999999999},
+		{"isFinite", "isFinite('12') && !isFinite('x') && !Number.isFinite('12') && Number.isFinite(12)", true},
+		{"isNaN", "isNaN('x') && !Number.isNaN('x') && Number.isNaN(Math.sqrt(-1))", true},
+		{"isInteger", "Number.isInteger(5) && Number.isInteger(5.0) && !Number.isInteger(5.5) && !Number.isInteger('5')", true},
+		{"isSafeInteger", "Number.isSafeInteger(Number.MAX_SAFE_INTEGER) && !Number.isSafeInteger(Number.MAX_SAFE_INTEGER + 1)", true},
+		{"constants", "Number.EPSILON === Math.pow(2, -52) && Number.MIN_SAFE_INTEGER === -Number.MAX_SAFE_INTEGER", true},
+		{"shared functions", "Number.parseInt === parseInt && Number.parseFloat === parseFloat", true},
+		{"this", "var o = {toFixed: Number.prototype.toFixed}\nvar a\ntry { o.toFixed() } catch (e) { a = String(e) }\na", "TypeError: Number.prototype.toFixed requires that 'this' be a Number"},
+		{"constructor", "var n = 1\nn.constructor === Number", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := interpret(tt.source)
+			if actual != tt.want {
+				t.Errorf("expect= %v, actual= %v", tt.want, actual)
+			}
+		})
+	}
+}
+
+func Test_interpret_json(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   any
+	}{
+		{"parse", `var a = JSON.parse('{"a":[1,2,{"b":true}],"c":null,"d":"x","e":-1.5e3}')` + "\na.a[2].b + a.d + a.e + a.a.length", "truex-15003"},
+		{"parse escapes", "var b = String.fromCharCode(92)\n" + `JSON.parse('"' + b + 'u0041' + b + 't' + b + '/"') === 'A' + String.fromCharCode(9) + '/'`, true},
+		{"parse duplicate", `JSON.stringify(JSON.parse('{"b":1,"a":2,"b":3}'))`, `{"b":3,"a":2}`},
+		{"reviver", `JSON.stringify(JSON.parse('[1, [2], {"a": 3}]', (k, v) => { if (typeof v === 'number') { return v * 10 } return v }))`, `[10,[20],{"a":30}]`},
+		{"reviver delete", `JSON.stringify(JSON.parse('{"a": 1, "b": 2, "c": null}', (k, v) => { if (k === 'a') { return undefined } return v }))`, `{"b":2,"c":null}`},
+		{"reviver holder", `var keys = ''` + "\n" + `JSON.parse('{"a": {"b": 1}}', (k, v) => { keys += '[' + k + ']'; return v })` + "\nkeys", "[b][a][]"},
+		{"unexpected end", "var a\ntry { JSON.parse('[') } catch (e) { a = String(e) }\na", "SyntaxError: Unexpected end of JSON input"},
+		{"expected property", "var a\ntry { JSON.parse('{a:1}') } catch (e) { a = String(e) }\na", "SyntaxError: Expected property name or '}' in JSON at position 1"},
+		{"expected comma", "var a\ntry { JSON.parse('[1 2]') } catch (e) { a = String(e) }\na", "SyntaxError: Expected ',' or ']' after array element in JSON at position 3"},
+		{"unexpected token", "var a\ntry { JSON.parse('[1,]') } catch (e) { a = String(e) }\na", `SyntaxError: Unexpected token ']', "[1,]" is not valid JSON`},
+		{"unexpected token context", "var a\ntry { JSON.parse('[1, 2, 3, 4, 5, 6, x, 7, 8, 9, 10, 11, 12]') } catch (e) { a = String(e) }\na", `SyntaxError: Unexpected token 'x', ..." 4, 5, 6, x, 7, 8, 9"... is not valid JSON`},
+		{"not valid", "var a\ntry { JSON.parse('NaN') } catch (e) { a = String(e) }\na", `SyntaxError: "NaN" is not valid JSON`},
+		{"leading zero", "var a\ntry { JSON.parse('01') } catch (e) { a = String(e) }\na", "SyntaxError: Unexpected number in JSON at position 1"},
+		{"fraction", "var a\ntry { JSON.parse('1.e5') } catch (e) { a = String(e) }\na", "SyntaxError: Unterminated fractional number in JSON at position 2"},
+		{"trailing", "var a\ntry { JSON.parse('true x') } catch (e) { a = String(e) }\na", "SyntaxError: Unexpected non-whitespace character after JSON at position 5"},
+		{"control character", "var a\ntry { JSON.parse('\"a' + String.fromCharCode(10) + '\"') } catch (e) { a = String(e) }\na", "SyntaxError: Bad control character in string literal in JSON at position 2"},
+		{"stringify", `JSON.stringify({a: 1, b: [1, 'x', null, true], c: {}, d: []})`, `{"a":1,"b":[1,"x",null,true],"c":{},"d":[]}`},
+		{"stringify skipped", `JSON.stringify({f() {}, s: Symbol('x'), n: null}) + JSON.stringify([function () {}, Symbol()])`, `{"n":null}[null,null]`},
+		{"stringify undefined", `JSON.stringify({a: undefined, b: null}) + JSON.stringify([undefined, null])`, `{"b":null}[null,null]`},
+		{"stringify lone surrogate", `JSON.stringify(String.fromCharCode(55296) + String.fromCharCode(55357, 56832))`, `"\ud800😀"`},
+		{"parse null", `var a = JSON.parse('{"a":null}').a;
+(a === null) + typeof a`, "trueobject"},
+		{"stringify numbers", `JSON.stringify([Math.max(), 0.1, 1.5, Math.pow(10, 21), Math.sqrt(-1)])`, `[null,0.1,1.5,1e+21,null]`},
+		{"stringify escapes", `JSON.stringify(String.fromCharCode(0, 31, 8, 10, 34, 92) + 'é😀')`, `"\u0000\u001f\b\n\"\\é😀"`},
+		{"stringify key order", `JSON.stringify({2: 'two', b: 1, 1: 'one'})`, `{"1":"one","2":"two","b":1}`},
+		{"indent", `JSON.stringify({a: 1, b: [1, {c: 3}], d: {}}, null, 2)`, "{\n  \"a\": 1,\n  \"b\": [\n    1,\n    {\n      \"c\": 3\n    }\n  ],\n  \"d\": {}\n}"},
+		{"indent string", `JSON.stringify({a: [1]}, null, 'abcdefghijklmno')`, "{\nabcdefghij\"a\": [\nabcdefghijabcdefghij1\nabcdefghij]\n}"},
+		{"replacer function", `JSON.stringify({a: 1, b: 'x'}, (k, v) => { if (typeof v === 'number') { return undefined } return v })`, `{"b":"x"}`},
+		{"replacer array", `JSON.stringify({a: 1, b: 2, c: {a: 5, z: 6}}, ['a', 'c', 1, 'a'])`, `{"a":1,"c":{"a":5}}`},
+		{"toJSON", `JSON.stringify({a: {toJSON(k) { return 'key:' + k }}})`, `{"a":"key:a"}`},
+		{"String object", `JSON.stringify([new String('abc')])`, `["abc"]`},
+		{"circular", "var a = {x: {y: []}}\na.x.y.push(a)\nvar r\ntry { JSON.stringify(a) } catch (e) { r = String(e) }\nr", "TypeError: Converting circular structure to JSON\n    --> starting at object with constructor 'Object'\n    |     property 'x' -> object with constructor 'Object'\n    |     property 'y' -> object with constructor 'Array'\n    --- index 0 closes the circle"},
+		{"circular ellipsis", "var a = {b: {c: {d: {e: {f: {}}}}}}\na.b.c.d.e.f.g = a\nvar r\ntry { JSON.stringify(a) } catch (e) { r = String(e) }\nr", "TypeError: Converting circular structure to JSON\n    --> starting at object with constructor 'Object'\n    |     property 'b' -> object with constructor 'Object'\n    |     property 'c' -> object with constructor 'Object'\n    |     ...\n    |     property 'f' -> object with constructor 'Object'\n    --- property 'g' closes the circle"},
+		{"toStringTag", "String(JSON)", "[object JSON]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := interpret(tt.source)
+			if actual != tt.want {
+				t.Errorf("expect= %v, actual= %v", tt.want, actual)
+			}
+		})
+	}
+}
+
+func Test_interpret_error(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   any
+	}{
+		{"message", "var e = new Error('boom')\ne.message + '|' + e.name + '|' + String(e)", "boom|Error|Error: boom"},
+		{"empty", "String(new Error()) + '|' + String(new RangeError('')) + '|' + new Error().message", "Error|RangeError|"},
+		{"without new", "var e = TypeError('x');\n(e instanceof TypeError) + String(e)", "trueTypeError: x"},
+		{"hierarchy", "var e = new SyntaxError('s');\n[e instanceof SyntaxError, e instanceof Error, e instanceof TypeError, SyntaxError.prototype.isPrototypeOf(e)].join()", "true,true,false,true"},
+		{"names", "[TypeError, RangeError, SyntaxError, ReferenceError, AggregateError].map((C) => C.prototype.name + ':' + C.prototype.constructor.name).join()", "TypeError:TypeError,RangeError:RangeError,SyntaxError:SyntaxError,ReferenceError:ReferenceError,AggregateError:AggregateError"},
+		{"cause", "var c = {}\nvar e = new Error('x', {cause: c});\n[e.cause === c, 'cause' in new Error('y'), 'cause' in new Error('z', {cause: undefined})].join()", "true,false,true"},
+		{"not enumerable", "JSON.stringify(new Error('x', {cause: 1})) + Object.keys(new TypeError('y')).length", "{}0"},
+		{"toString", "var e = new Error('m')\ne.name = 'Custom'\nString(e) + '|' + e.stack", "Custom: m|Error: m\n    at <anonymous>:1:9"},
+		{"tag", "Object.prototype.toString === Error.prototype.toString", false},
+		{"aggregate", "var e = new AggregateError(new Set([1, 2]), 'many')\ne.errors.join() + '|' + String(e)", "1,2|AggregateError: many"},
+		{"throw", "var r\ntry { throw new RangeError('out') } catch (e) { r = e.name + ':' + e.message }\nr", "RangeError:out"},
+		{"stack", "function inner(x) {\n  return new Error('boom ' + x)\n}\nfunction outer() {\n  var a = 1\n  return inner(a)\n}\nouter().stack", "Error: boom 1\n    at inner (<anonymous>:2:10)\n    at outer (<anonymous>:6:10)\n    at <anonymous>:8:1"},
+		{"stack class", "class A {\n  constructor() {\n    this.e = new TypeError('c')\n  }\n}\nnew A().e.stack", "TypeError: c\n    at new A (<anonymous>:3:14)\n    at <anonymous>:6:1"},
+		{"stack method", "var o = {\n  m() { return Error('m') },\n  f: () => new Error('f')\n}\no.m().stack + '|' + o.f().stack", "Error: m\n    at m (<anonymous>:2:16)\n    at <anonymous>:5:3|Error: f\n    at f (<anonymous>:3:12)\n    at <anonymous>:5:23"},
+		{"stack anonymous", "var a = [1].map(function () { return new Error('a') })\na[0].stack", "Error: a\n    at <anonymous>:1:38\n    at <anonymous>:1:13"},
+		{"stack generator", "function* g() {\n  yield new Error('g')\n}\ng().next().value.stack", "Error: g\n    at g (<anonymous>:2:9)"},
+		{"stack timer", "var s\nsetTimeout(function tick() { s = new Error('t').stack }, 0)\nawait new Promise((resolve) => setTimeout(resolve, 1))\ns", "Error: t\n    at tick (<anonymous>:2:34)"},
+		{"promise any", "var r\nPromise.any([Promise.reject(1)]).catch((e) => { r = e })\nawait null\nawait null;\n(r instanceof AggregateError) + String(r) + r.errors.join()", "trueAggregateError: All promises were rejected1"},
+		{"built-in", "var r = []\ntry { [].reduce() } catch (e) { r.push(e instanceof TypeError, e.name, e.message, e.stack) }\ntry { JSON.parse('[') } catch (e) { r.push(e instanceof SyntaxError, e.message) }\ntry { new RegExp('(') } catch (e) { r.push(e.constructor === SyntaxError) }\ntry { 'a'.repeat(-1) } catch (e) { r.push(e instanceof RangeError) }\nr.join('|')", "true|TypeError|undefined is not a function|TypeError: undefined is not a function\n    at <anonymous>:2:10|true|Unexpected end of JSON input|true|true"},
+		{"not a function", "var r = []\nvar o = {a: {}}\ntry { o.a.b() } catch (e) { r.push(e instanceof TypeError, e.message) }\ntry { [][0]() } catch (e) { r.push(e.message) }\nr.join('|')", "true|o.a.b is not a function|undefined is not a function"},
+		{"not a function position", "function f() {\n  var g\n  g()\n}\nvar r\ntry { f() } catch (e) { r = e.stack }\nr", "TypeError: g is not a function\n    at f (<anonymous>:3:3)\n    at <anonymous>:6:7"},
+		{"read null", "var r = []\nvar o\ntry { null.foo() } catch (e) { r.push(e instanceof TypeError, e.message) }\ntry { o.x } catch (e) { r.push(e.message) }\ntry { o[0] } catch (e) { r.push(e.message) }\nr.join('|')", "true|Cannot read properties of null (reading 'foo')|Cannot read properties of undefined (reading 'x')|Cannot read properties of undefined (reading '0')"},
+		{"revoked proxy", "var p = Proxy.revocable({}, {})\np.revoke()\nvar r\ntry { p.proxy.x } catch (e) { r = e instanceof TypeError }\nr", true},
+		{"code generation", "var r\ntry { eval('1 +') } catch (e) { r = e instanceof SyntaxError }\nr", true},
+		{"DOMException", "var r = []\ntry { btoa('€') } catch (e) { r.push(e instanceof DOMException, e instanceof Error, e.name, e.code, e.message) }\ntry { structuredClone(() => 1) } catch (e) { r.push(e.name, e.code, String(e)) }\nr.join('|')", "true|true|InvalidCharacterError|5|Invalid character|DataCloneError|25|DataCloneError: () => 1 could not be cloned."},
+		{"DOMException constructor", "var e = new DOMException('m', 'NotFoundError')\nvar d = new DOMException();\n[e.name, e.message, e.code, DOMException.NOT_FOUND_ERR, d.name, d.code, Reflect.ownKeys(e).join(), Reflect.getPrototypeOf(e) === DOMException.prototype].join()", "NotFoundError,m,8,8,Error,0,stack,true"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := interpret(tt.source)
+			if actual != tt.want {
+				t.Errorf("expect= %q, actual= %q", tt.want, actual)
+			}
+		})
+	}
+}
+
+func Test_interpret_date(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   any
+	}{
+		{"now", "Date.now() === new Date().getTime()", true},
+		{"toString", "new Date().toString()", "Sun Mar 10 2024 01:30:00 GMT-0500 (EST)"},
+		{"utc", "new Date().toUTCString() + '|' + new Date().toISOString()", "Sun, 10 Mar 2024 06:30:00 GMT|2024-03-10T06:30:00.000Z"},
+		{"call", "typeof Date() + '|' + (Date(0) === new Date().toString())", "string|true"},
+		{"fields", "var d = new Date(2024, 0, 31, 13, 4, 5, 6);\n[d.getFullYear(), d.getMonth(), d.getDate(), d.getDay(), d.getHours(), d.getMinutes(), d.getSeconds(), d.getMilliseconds(), d.getTimezoneOffset(), d.getUTCHours()].join()", "2024,0,31,3,13,4,5,6,300,18"},
+		{"two digit years", "new Date(99, 11).getFullYear() + '|' + Date.UTC(1, 0) + '|' + Date.UTC(2024, 1, 30)", "1999|-2177452800000|1709251200000"},
+		{"skipped hour", "var d = new Date(2024, 2, 10, 2, 30)\nd.getHours() + ':' + d.getMinutes() + '|' + d.getTimezoneOffset()", "3:30|240"},
+		{"repeated hour", "new Date(2024, 10, 3, 1, 30).toISOString()", "2024-11-03T05:30:00.000Z"},
+		{"parse iso", "[Date.parse('2024-03-10'), Date.parse('2024-03-10T12:00'), Date.parse('2024-03-10T12:00Z'), Date.parse('2024-13-01')].join()", "1710028800000,1710086400000,1710072000000,NaN"},
+		{"parse rfc 2822", "Date.parse('Sun, 10 Mar 2024 06:30:00 GMT') + '|' + Date.parse('Sun, 10 Mar 2024 01:30:00 -0500')", "1710052200000|1710052200000"},
+		{"round trip", "var d = new Date(2024, 5, 1, 9);\n[Date.parse(d.toString()), Date.parse(d.toUTCString()), Date.parse(d.toISOString())].join() + '|' + d.getTime()", "1717246800000,1717246800000,1717246800000|1717246800000"},
+		{"setters", "var d = new Date(2024, 0, 31);\n[d.setMonth(1), d.getDate(), d.setHours(25, 61), d.getDay(), d.setUTCDate(0), d.setMilliseconds(1500), d.getSeconds()].join()", "1709355600000,2,1709449260000,0,1709190060000,1709190061500,1"},
+		{"set invalid", "var d = new Date(NaN);\n[d.setHours(1), d.setFullYear(2024), d.getMonth()].join()", "NaN,1704085200000,0"},
+		{"toISOString", "[new Date(0).toISOString(), new Date(-62198755200000).toISOString(), new Date(8640000000000000).toISOString(), new Date(8640000000000001).getTime()].join()", "1970-01-01T00:00:00.000Z,-000001-01-01T00:00:00.000Z,+275760-09-13T00:00:00.000Z,NaN"},
+		{"invalid", "var d = new Date('nope')\nvar r\ntry { d.toISOString() } catch (e) { r = String(e) }\n[String(d), isNaN(d.getTime()), d.toJSON(), r].join('|')", "Invalid Date|true||RangeError: Invalid time value"},
+		{"toLocaleString", "var d = new Date(2024, 6, 4, 0, 5, 9)\nd.toLocaleString() + '|' + d.toLocaleDateString() + '|' + d.toLocaleTimeString() + '|' + d.toLocaleTimeString('en-US', {timeZone: 'Asia/Tokyo'})", "7/4/2024, 12:05:09 AM|7/4/2024|12:05:09 AM|1:05:09 PM"},
+		{"toDateString", "var d = new Date(2024, 6, 4, 18)\nd.toDateString() + '|' + d.toTimeString()", "Thu Jul 04 2024|18:00:00 GMT-0400 (EDT)"},
+		{"json", "JSON.stringify({at: new Date(Date.UTC(2024, 0, 1))})", `{"at":"2024-01-01T00:00:00.000Z"}`},
+		{"operators", "var d = new Date(2024, 0, 1);\n[typeof (d + 1), d - new Date(2023, 11, 31), d < new Date(2024, 0, 2), new Date(5) == 5].join()", "string,86400000,true,false"},
+		{"copy", "var d = new Date(2024, 0, 1)\nvar c = new Date(d)\nc.setFullYear(2000)\nd.getFullYear() + '|' + c.getFullYear() + '|' + new Date(true).getTime()", "2024|2000|1"},
+		{"tag", "var d = new Date(0)\nd.toString = Object.prototype.toString\nd.toString()", "[object Date]"},
+		{"generator", "function* g() {\n  yield new Date(0).getHours()\n}\ng().next().value", float64(19)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := interpretDate(t, tt.source)
+			if actual != tt.want {
+				t.Errorf("expect= %v, actual= %v", tt.want, actual)
+			}
+		})
+	}
+}
+
+// interpretConsole runs source on a virtual clock with console writing to
+// buffers, and returns what was written to stdout and stderr.
+func interpretConsole(source string) (string, string) {
+	var stdout, stderr bytes.Buffer
+	env := call.NewGlobalEnvironment()
+	i := New(env)
+	env.Define("console", call.NewConsole(i, call.NewConsoleOutput(&stdout, &stderr)))
+	defer i.Close()
+	i.SetFileName("test.js")
+	i.GetEventLoop().SetClock(clock.NewVirtual(time.Unix(0, 0)))
+	i.Interpret(Parse(source))
+	i.GetEventLoop().Run()
+	return stdout.String(), stderr.String()
+}
+
+func Test_interpret_console(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		stdout string
+		stderr string
+	}{
+		{"primitives", "console.log('a', 1, -0, 1.5, true, undefined, Symbol('s'))", "a 1 -0 1.5 true undefined Symbol(s)\n", ""},
+		{"strings", `console.log(["it's", 'say "hi"', 'a' + "'" + '"' + 'b'])`, "[ \"it's\", 'say \"hi\"', `a'\"b` ]\n", ""},
+		{"objects", "console.log({a: [1, 'x', {b: {c: {}}}], 'a-b': 2}, [1, , 3], [], {})", "{ a: [ 1, 'x', { b: [Object] } ], 'a-b': 2 } [ 1, <1 empty item>, 3 ] [] {}\n", ""},
+		{"functions", "class A { constructor() { this.x = 1 } }\nconsole.log(new A(), A, class {}, function f() {}, async () => 1, function* g() {})", "A { x: 1 } [class A] [class (anonymous)] [Function: f] [AsyncFunction (anonymous)] [GeneratorFunction: g]\n", ""},
+		{"collections", "console.log(new Map([['a', {b: 1}]]), new Set([1, 'x']), new Map([[1, 2]]).entries(), new WeakMap())", "Map(1) { 'a' => { b: 1 } } Set(2) { 1, 'x' } [Map Entries] { [ 1, 2 ] } WeakMap { <items unknown> }\n", ""},
+		{"builtins", "console.log(new Date(0), /a+/g, new Number(3), new String('s'), Promise.resolve(2), new Promise(() => {}))", "1970-01-01T00:00:00.000Z /a+/g [Number: 3] [String: 's'] Promise { 2 } Promise { <pending> }\n", ""},
+		{"proxy", "var p = new Proxy({a: 1}, { get() { throw new Error('trap') } })\nvar r = Proxy.revocable({}, {})\nr.revoke()\nconsole.log(p, r.proxy, new Proxy(function f() {}, {}))", "{ a: 1 } <Revoked Proxy> [Function: f]\n", ""},
+		{"typed arrays", "console.log(new Uint8Array([1, 2, 300]), new Float32Array(2), new Int16Array(0), new ArrayBuffer(3), new DataView(new ArrayBuffer(2), 1))", "Uint8Array(3) [ 1, 2, 44 ] Float32Array(2) [ 0, 0 ] Int16Array(0) [] ArrayBuffer { [Uint8Contents]: <00 00 00>, byteLength: 3 } DataView {\n  byteLength: 1,\n  byteOffset: 1,\n  buffer: ArrayBuffer { [Uint8Contents]: <00 00>, byteLength: 2 }\n}\n", ""},
+		{"typed array items", "var u = new Uint8Array([1])\nu.x = 1\nvar b = new ArrayBuffer(4)\nb.transfer()\nconsole.log(new Uint16Array(1).buffer, b, u)\nconsole.log(new Uint8Array(101))", "ArrayBuffer { [Uint8Contents]: <00 00>, byteLength: 2 } ArrayBuffer { (detached), byteLength: 0 } Uint8Array(1) [ 1, x: 1 ]\nUint8Array(101) [\n  0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,\n  0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,\n  0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,\n  0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,\n  0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,\n  0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,\n  0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,\n  0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,\n  0, 0, 0, 0,\n  ... 1 more item\n]\n", ""},
+		{"circular", "var o = {n: 1}\no.self = o\nconsole.log(o, [o])", "<ref *1> { n: 1, self: [Circular *1] } [ <ref *1> { n: 1, self: [Circular *1] } ]\n", ""},
+		{"break lines", "console.log({a: 1, b: 'two', c: [3], d: {e: 4}, f: 5, g: true, h: 'eight', i: 1.5})", "{\n  a: 1,\n  b: 'two',\n  c: [ 3 ],\n  d: { e: 4 },\n  f: 5,\n  g: true,\n  h: 'eight',\n  i: 1.5\n}\n", ""},
+		{"group array", "console.log(Array.from({length: 30}, (_, i) => i))", "[\n   0,  1,  2,  3,  4,  5,  6,  7,  8,\n   9, 10, 11, 12, 13, 14, 15, 16, 17,\n  18, 19, 20, 21, 22, 23, 24, 25, 26,\n  27, 28, 29\n]\n", ""},
+		{"more items", "console.log(Array.from({length: 102}, (_, i) => 0).length, Array.from({length: 120}, (_, i) => 'ab').slice(100))", "102 [\n  'ab', 'ab', 'ab', 'ab',\n  'ab', 'ab', 'ab', 'ab',\n  'ab', 'ab', 'ab', 'ab',\n  'ab', 'ab', 'ab', 'ab',\n  'ab', 'ab', 'ab', 'ab'\n]\n", ""},
+		{"error", "console.log({e: new RangeError('x', {cause: 1})})", "{\n  e: RangeError: x\n      at test.js:1:17 {\n    [cause]: 1\n  }\n}\n", ""},
+		{"format", "console.log('%s=%d %i %f %j %% %c|', 'n', '42', 4.7, '1.5x', {a: [1]}, 'color: red', 'rest', {b: 2})", "n=42 4 1.5 {\"a\":[1]} % | rest { b: 2 }\n", ""},
+		{"format objects", "var o = {a: 1}\no.o = o\nconsole.log('%s %o %O %j', {a: {b: 1}}, [1], {a: {b: {c: {}}}}, o)", "{ a: [Object] } [ 1, [length]: 1 ] { a: { b: { c: {} } } } [Circular]\n", ""},
+		{"format missing", "console.log('%s %d', 'a')\nconsole.log('%s %x', 1, 2)", "a %d\n1 %x 2\n", ""},
+		{"levels", "console.log('log')\nconsole.info('info')\nconsole.debug('debug')\nconsole.warn('warn')\nconsole.error('error', 1)", "log\ninfo\ndebug\n", "warn\nerror 1\n"},
+		{"group", "console.group('a')\nconsole.log({x: [1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20, 21, 22, 23, 24, 25, 26, 27]})\nconsole.group()\nconsole.warn('w')\nconsole.groupEnd()\nconsole.groupEnd()\nconsole.groupEnd()\nconsole.log('b')", "a\n  {\n    x: [\n       1,  2,  3,  4,  5,  6,  7,  8,\n       9, 10, 11, 12, 13, 14, 15, 16,\n      17, 18, 19, 20, 21, 22, 23, 24,\n      25, 26, 27\n    ]\n  }\nb\n", "    w\n"},
+		{"count", "console.count()\nconsole.count()\nconsole.count('x')\nconsole.countReset()\nconsole.count()\nconsole.countReset('y')", "default: 1\ndefault: 2\nx: 1\ndefault: 1\n", "Warning: Count for 'y' does not exist\n"},
+		{"time", "console.time()\nconsole.time('t')\nconsole.time('t')\nsetTimeout(() => console.timeLog('t', 'at', 1), 250)\nsetTimeout(() => console.timeEnd('t'), 1500)\nsetTimeout(() => console.timeEnd(), 61000)\nsetTimeout(() => console.timeEnd('t'), 62000)", "t: 250ms at 1\nt: 1.500s\ndefault: 1:01.000 (m:ss.mmm)\n", "Warning: Label 't' already exists for console.time()\nWarning: No such label 't' for console.timeEnd()\n"},
+		{"assert", "console.assert(true, 'no')\nconsole.assert(0)\nconsole.assert(false, 'x %s', 'y', 1)", "", "Assertion failed\nAssertion failed: x y 1\n"},
+		{"trace", "function f() {\n  console.trace('in %s', 'f')\n}\nf()\nconsole.trace()", "", "Trace: in f\n    at f (test.js:2:11)\n    at test.js:4:1\nTrace\n    at test.js:5:9\n"},
+		{"dir", "console.dir({a: {b: {c: {d: {}}}}}, {depth: 0})\nconsole.dir({a: {b: {c: {d: {}}}}}, {depth: null})\nconsole.dir('s')", "{ a: [Object] }\n{\n  a: { b: { c: { d: {} } } }\n}\n's'\n", ""},
+		{"table", "console.table([{a: 1, b: 'x'}, {a: 2, c: true}])\nconsole.table([1, 'two'], ['a'])\nconsole.table(5)", "┌─────────┬───┬─────┬──────┐\n│ (index) │ a │ b   │ c    │\n├─────────┼───┼─────┼──────┤\n│ 0       │ 1 │ 'x' │      │\n│ 1       │ 2 │     │ true │\n└─────────┴───┴─────┴──────┘\n┌─────────┬───┐\n│ (index) │ a │\n├─────────┼───┤\n│ 0       │   │\n│ 1       │   │\n└─────────┴───┘\n5\n", ""},
+		{"table collections", "console.table(new Map([['k', {v: 1}]]))\nconsole.table(new Set([[1, 2, 3, 4]]))", "┌───────────────────┬─────┬──────────┐\n│ (iteration index) │ Key │ Values   │\n├───────────────────┼─────┼──────────┤\n│ 0                 │ 'k' │ { v: 1 } │\n└───────────────────┴─────┴──────────┘\n┌───────────────────┬──────────────────────────────┐\n│ (iteration index) │ Values                       │\n├───────────────────┼──────────────────────────────┤\n│ 0                 │ [ 1, 2, 3, ... 1 more item ] │\n└───────────────────┴──────────────────────────────┘\n", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, stderr := interpretConsole(tt.source)
+			if stdout != tt.stdout {
+				t.Errorf("expect stdout= %q, actual= %q", tt.stdout, stdout)
+			}
+			if stderr != tt.stderr {
+				t.Errorf("expect stderr= %q, actual= %q", tt.stderr, stderr)
+			}
+		})
+	}
+}
+
+func Test_interpret_map(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   any
+	}{
+		{"get", "var m = new Map([[1, 'a'], ['1', 'b'], [Math.sqrt(-1), 'n']]);\n[m.size, m.get(1), m.get(1.0), m.get('1'), m.get(Math.sqrt(-1)), m.has(2)].join()", "3,a,a,b,n,false"},
+		{"zero", "var m = new Map().set(-0, 'zero');\n[m.get(0), Object.is(Array.from(m.keys())[0], 0)].join()", "zero,true"},
+		{"object keys", "var o = {}\nvar m = new Map([[o, 1]]);\n[m.get(o), m.get({}), m.delete(o), m.delete(o), m.size].join()", "1,,true,false,0"},
+		{"order", "var m = new Map([['b', 1], [2, 2], ['a', 3]])\nm.set('b', 4)\nJSON.stringify(Array.from(m))", `[["b",4],[2,2],["a",3]]`},
+		{"live iterator", "var m = new Map([[1, 'a'], [2, 'b'], [3, 'c']])\nvar it = m.entries()\nit.next()\nm.delete(2)\nm.set(4, 'd')\nJSON.stringify(Array.from(it))", `[[3,"c"],[4,"d"]]`},
+		{"cleared iterator", "var s = new Set([1, 2, 3])\nvar it = s.values()\nit.next()\ns.clear()\ns.add(9)\nit.next().value + ',' + it.next().done + ',' + it.next().done", "9,true,true"},
+		{"set", "var s = new Set([1, 2, 2, 3, Math.sqrt(-1), Math.sqrt(-1)])\ns.size + ' ' + Array.from(s).join()", "4 1,2,3,NaN"},
+		{"forEach", "var s = new Set([1, 2, 3])\nvar log = []\ns.forEach(function (v, k, set) { log.push(v + ':' + k + ':' + (set === s)); if (v === 1) { s.delete(2); s.add(4) } })\nlog.join()", "1:1:true,3:3:true,4:4:true"},
+		{"iterator aliases", "Set.prototype.keys === Set.prototype.values && Set.prototype[Symbol.iterator] === Set.prototype.values && Map.prototype[Symbol.iterator] === Map.prototype.entries", true},
+		{"for of", "var r = ''\nfor (var e of new Map([['a', 1], ['b', 2]])) { r += e[0] + e[1] }\nr", "a1b2"},
+		{"toStringTag", "String(new Map()) + String(new Set().values()) + String(new WeakMap()) + String(new WeakSet()) + String(new WeakRef({}))", "[object Map][object Set Iterator][object WeakMap][object WeakSet][object WeakRef]"},
+		{"requires new", "var r\ntry { Map() } catch (e) { r = String(e) }\nr", "TypeError: Constructor Map requires 'new'"},
+		{"entry object", "var r\ntry { new Map([1]) } catch (e) { r = String(e) }\nr", "TypeError: Iterator value 1 is not an entry object"},
+		{"receiver", "var r\ntry { ({get: Map.prototype.get}).get(1) } catch (e) { r = String(e) }\nr", "TypeError: Method Map.prototype.get called on incompatible receiver object"},
+		{"groupBy", "var g = Map.groupBy([1, 2, 3, 4], (n) => { if (n > 2) { return 'big' } return 'small' })\nArray.from(g.keys()).join() + '|' + g.get('big').join()", "small,big|3,4"},
+		{"set methods", "var a = new Set([1, 2, 3, 4])\nvar b = new Set([3, 4, 5])\nvar j = (s) => Array.from(s).join();\n[j(a.union(b)), j(a.intersection(b)), j(a.difference(b)), j(a.symmetricDifference(b)), j(b.intersection(a))].join('|')", "1,2,3,4,5|3,4|1,2|1,2,5|3,4"},
+		{"set predicates", "var a = new Set([1, 2, 3, 4])\nvar b = new Set([3, 4, 5]);\n[a.isSubsetOf(b), new Set([3]).isSubsetOf(b), a.isSupersetOf(new Set([1, 2])), a.isDisjointFrom(new Set([9])), a.isDisjointFrom(b)].join()", "false,true,true,true,false"},
+		{"set like", "var like = {size: 2, has(v) { return v === 1 }, keys() { return [1, 7].values() }}\nvar a = new Set([1, 2, 3, 4])\nvar j = (s) => Array.from(s).join();\n[j(a.union(like)), j(a.intersection(like)), j(a.difference(like)), j(new Set(['x', 'y']).intersection(new Map([['x', 1]])))].join('|')", "1,2,3,4,7|1|2,3,4|x"},
+		{"set record", "var r\ntry { new Set().union({size: 'x', has() {}, keys() {}}) } catch (e) { r = String(e) }\nr", "TypeError: The .size property is NaN"},
+		{"weak map", "var k = {}\nvar s = Symbol('s')\nvar w = new WeakMap([[k, 1]])\nw.set(s, 2);\n[w.get(k), w.has(k), w.get(s), w.get({}), w.has(1), w.delete(k), w.has(k)].join()", "1,true,2,,false,true,false"},
+		{"weak map key", "var r\ntry { new WeakMap().set(Symbol.for('x'), 1) } catch (e) { r = String(e) }\nr", "TypeError: Invalid value used as weak map key"},
+		{"weak set", "var k = {}\nvar w = new WeakSet([k]);\n[w.has(k), w.has({}), w.delete(k), w.has(k)].join()", "true,false,true,false"},
+		{"weak set value", "var r\ntry { new WeakSet().add(1) } catch (e) { r = String(e) }\nr", "TypeError: Invalid value used in weak set"},
+		{"weak ref", "var k = [1]\nnew WeakRef(k).deref() === k", true},
+		{"weak ref target", "var r\ntry { new WeakRef('x') } catch (e) { r = String(e) }\nr", "TypeError: WeakRef: invalid target"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := interpret(tt.source)
+			if actual != tt.want {
+				t.Errorf("expect= %v, actual= %v", tt.want, actual)
+			}
+		})
+	}
+}
+
+func Test_interpret_proxy(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   any
+	}{
+		{"traps", "var log = []\nvar target = {a: 1, b: 2}\nvar p = new Proxy(target, {\n  get(t, k, r) { log.push('get ' + k); return Reflect.get(t, k, r) },\n  set(t, k, v, r) { log.push('set ' + k); return Reflect.set(t, k, v, r) },\n  has(t, k) { log.push('has ' + k); return k in t },\n  deleteProperty(t, k) { log.push('delete ' + k); return Reflect.deleteProperty(t, k) },\n  defineProperty(t, k, d) { log.push('define ' + k); return Reflect.defineProperty(t, k, d) }\n})\np.c = 3;\n[p.a, 'a' in p, delete p.b, JSON.stringify(target), log.join()].join('|')", `1|true|true|{"a":1,"c":3}|set c,define c,get a,has a,delete b`},
+		{"no traps", "var t = {}\nvar p = new Proxy(t, {})\np.x = 1\ndelete t.y;\n[t.x, p.x, 'x' in p, typeof p, p instanceof Object].join()", "1,1,true,object,true"},
+		{"enumeration", "var p = new Proxy({}, {\n  ownKeys() { return ['b', 'a', Symbol.iterator] },\n  getOwnPropertyDescriptor(t, k) { return {value: k, enumerable: k !== 'a', configurable: true} }\n})\nvar keys = []\nfor (var k in p) { keys.push(k) }\nkeys.join() + '|' + Object.keys(p).join() + '|' + Reflect.ownKeys(p).length", "b|b|3"},
+		{"apply and construct", "var f = new Proxy(function (a, b) { return a + b }, {\n  apply(t, self, args) { return t(args[0], args[1]) * 10 },\n  construct(t, args) { return {made: args[0]} }\n});\n[typeof f, f(1, 2), new f(5).made].join()", "function,30,5"},
+		{"method receiver", "var o = new Proxy({name: 'o', hi() { return this === o }}, {})\no.hi()", true},
+		{"observable array", "var changes = []\nvar a = new Proxy([], { set(t, k, v, r) { changes.push(k + '=' + v); return Reflect.set(t, k, v, r) } })\na.push(7, 8);\n[changes.join(), a.length, Array.isArray(a), JSON.stringify(a)].join('|')", "0=7,1=8,length=2|2|true|[7,8]"},
+		{"validation", "var p = new Proxy({}, { set(t, k, v) { if (typeof v !== 'number') { throw new TypeError(k + ' must be a number') } t[k] = v; return true } })\np.age = 3\nvar r\ntry { p.age = 'x' } catch (e) { r = String(e) }\np.age + '|' + r", "3|TypeError: age must be a number"},
+		{"prototype", "var proto = {}\nvar p = new Proxy({}, { getPrototypeOf() { return proto } })\nvar r = Reflect.getPrototypeOf(p) === proto\nvar q = new Proxy({}, {})\nReflect.setPrototypeOf(q, proto);\n[r, Reflect.getPrototypeOf(q) === proto, proto.isPrototypeOf(p)].join()", "true,true,true"},
+		{"extensibility", "var t = {}\nvar p = new Proxy(t, {})\nvar r = [Reflect.isExtensible(p), Reflect.preventExtensions(p), Reflect.isExtensible(t)]\np.x = 1\nr.push(t.x, Reflect.defineProperty(p, 'y', {value: 1}))\nr.join()", "true,true,false,,false"},
+		{"proxy as prototype", "var p = new Proxy({}, { get(t, k) { return 'from ' + String(k) } })\nclass A {}\nReflect.setPrototypeOf(A.prototype, p)\nnew A().anything", "from anything"},
+		{"revoked", "var r = Proxy.revocable({}, {})\nr.revoke()\nvar e\ntry { r.proxy.x } catch (err) { e = String(err) }\ne", "TypeError: Cannot perform 'get' on a proxy that has been revoked"},
+		{"requires new", "var r\ntry { Proxy({}, {}) } catch (e) { r = String(e) }\nr", "TypeError: Constructor Proxy requires 'new'"},
+		{"non-object", "var r\ntry { new Proxy(1, {}) } catch (e) { r = String(e) }\nr", "TypeError: Cannot create proxy with a non-object as target or handler"},
+		{"trap not callable", "var r\ntry { new Proxy({}, {get: 1}).x } catch (e) { r = String(e) }\nr", "TypeError: '1' returned for property 'get' of object '#<Object>' is not a function"},
+		{"get invariant", "var t = {}\nReflect.defineProperty(t, 'x', {value: 1})\nvar r\ntry { new Proxy(t, { get() { return 2 } }).x } catch (e) { r = String(e) }\nr", "TypeError: 'get' on proxy: property 'x' is a read-only and non-configurable data property on the proxy target but the proxy did not return its actual value (expected '1' but got '2')"},
+		{"set invariant", "var t = {}\nReflect.defineProperty(t, 'x', {value: 1})\nvar r\ntry { new Proxy(t, { set() { return true } }).x = 2 } catch (e) { r = String(e) }\nr", "TypeError: 'set' on proxy: trap returned truish for property 'x' which exists in the proxy target as a non-configurable and non-writable data property with a different value"},
+		{"has invariant", "var t = {}\nReflect.defineProperty(t, 'x', {value: 1})\nvar r\ntry { 'x' in new Proxy(t, { has() { return false } }) } catch (e) { r = String(e) }\nr", "TypeError: 'has' on proxy: trap returned falsish for property 'x' which exists in the proxy target as non-configurable"},
+		{"deleteProperty invariant", "var t = {y: 1}\nReflect.preventExtensions(t)\nvar r\ntry { delete new Proxy(t, { deleteProperty() { return true } }).y } catch (e) { r = String(e) }\nr", "TypeError: 'deleteProperty' on proxy: trap returned truish for property 'y' but the proxy target is non-extensible"},
+		{"ownKeys invariant", "var t = {}\nReflect.defineProperty(t, 'x', {value: 1})\nvar r = []\ntry { Reflect.ownKeys(new Proxy(t, { ownKeys() { return [] } })) } catch (e) { r.push(String(e)) }\ntry { Reflect.ownKeys(new Proxy({}, { ownKeys() { return ['a', 'a'] } })) } catch (e) { r.push(String(e)) }\nr.join('|')", "TypeError: 'ownKeys' on proxy: trap result did not include 'x'|TypeError: 'ownKeys' on proxy: trap returned duplicate entries"},
+		{"getOwnPropertyDescriptor invariant", "var r\ntry { Reflect.getOwnPropertyDescriptor(new Proxy({y: 1}, { getOwnPropertyDescriptor() { return {value: 1, configurable: false} } }), 'y') } catch (e) { r = String(e) }\nr", "TypeError: 'getOwnPropertyDescriptor' on proxy: trap reported non-configurability for property 'y' which is either non-existent or configurable in the proxy target"},
+		{"defineProperty invariant", "var t = {}\nReflect.preventExtensions(t)\nvar r\ntry { Reflect.defineProperty(new Proxy(t, { defineProperty() { return true } }), 'x', {value: 1}) } catch (e) { r = String(e) }\nr", "TypeError: 'defineProperty' on proxy: trap returned truish for adding property 'x'  to the non-extensible proxy target"},
+		{"prototype invariants", "var t = {}\nReflect.preventExtensions(t)\nvar r = []\ntry { Reflect.getPrototypeOf(new Proxy(t, { getPrototypeOf() { return null } })) } catch (e) { r.push(String(e)) }\ntry { Reflect.setPrototypeOf(new Proxy(t, { setPrototypeOf() { return true } }), null) } catch (e) { r.push(String(e)) }\nr.join('|')", "TypeError: 'getPrototypeOf' on proxy: proxy target is non-extensible but the trap did not return its actual prototype|TypeError: 'setPrototypeOf' on proxy: trap returned truish for setting a new prototype on the non-extensible proxy target"},
+		{"extensibility invariants", "var r = []\ntry { Reflect.isExtensible(new Proxy({}, { isExtensible() { return false } })) } catch (e) { r.push(String(e)) }\ntry { Reflect.preventExtensions(new Proxy({}, { preventExtensions() { return true } })) } catch (e) { r.push(String(e)) }\nr.join('|')", "TypeError: 'isExtensible' on proxy: trap result does not reflect extensibility of proxy target (which is 'true')|TypeError: 'preventExtensions' on proxy: trap returned truish but the proxy target is extensible"},
+		{"construct invariant", "var r\ntry { new (new Proxy(function () {}, { construct() { return 1 } }))() } catch (e) { r = String(e) }\nr", "TypeError: 'construct' on proxy: trap returned non-object ('1')"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := interpret(tt.source)
+			if actual != tt.want {
+				t.Errorf("expect= %v, actual= %v", tt.want, actual)
+			}
+		})
+	}
+}
+
+func Test_interpret_reflect(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   any
+	}{
+		{"defineProperty", "var o = {}\nvar ok = Reflect.defineProperty(o, 'x', {value: 1})\no.x = 2;\n[ok, o.x, delete o.x, o.x, Object.keys(o).length, Reflect.defineProperty(o, 'x', {value: 3})].join()", "true,1,false,1,0,false"},
+		{"getOwnPropertyDescriptor", "var d = Reflect.getOwnPropertyDescriptor({a: 1}, 'a')\nvar l = Reflect.getOwnPropertyDescriptor([1], 'length')\nJSON.stringify(d) + '|' + JSON.stringify(l) + '|' + (Reflect.getOwnPropertyDescriptor({}, 'a') === undefined)", `{"value":1,"writable":true,"enumerable":true,"configurable":true}|{"value":1,"writable":true,"enumerable":false,"configurable":false}|true`},
+		{"get and set", "var o = {a: 1};\n[Reflect.get(o, 'a'), Reflect.get([5], 0), Reflect.set(o, 'b', 2), o.b, Reflect.has(o, 'b'), Reflect.has(o, 'toString')].join()", "1,5,true,2,true,true"},
+		{"set receiver", "var a = {}\nvar b = {}\nReflect.set(a, 'x', 1, b);\n[a.x, b.x].join()", ",1"},
+		{"ownKeys", "var s = Symbol('s')\nvar o = {b: 1, 2: 1, a: 1}\no[s] = 1\nvar k = Reflect.ownKeys(o)\nk.length + '|' + k.slice(0, 3).join() + '|' + (k[3] === s) + '|' + Reflect.ownKeys([1]).join()", "4|2,b,a|true|0,length"},
+		{"prototype", "var proto = {hi() { return 'hi ' + this.name }}\nvar o = {name: 'x'};\n[Reflect.setPrototypeOf(o, proto), o.hi(), Reflect.getPrototypeOf(o) === proto, Reflect.setPrototypeOf(proto, o), Reflect.getPrototypeOf(Reflect) === Object.prototype].join()", "true,hi x,true,false,true"},
+		{"preventExtensions", "var o = {a: 1}\nReflect.preventExtensions(o)\no.b = 1\no.a = 2;\n[Reflect.isExtensible(o), o.b, o.a, Reflect.setPrototypeOf(o, null), delete o.a].join()", "false,,2,false,true"},
+		{"apply", "Reflect.apply(Math.max, null, [1, 3, 2]) + Reflect.apply(function (a) { return this.x + a }, {x: 1}, [2])", float64(6)},
+		{"construct", "class A { constructor(x) { this.x = x } }\nReflect.construct(A, [4]).x + Reflect.construct(Date, [0]).getTime()", float64(4)},
+		{"deleteProperty", "var o = {a: 1};\n[Reflect.deleteProperty(o, 'a'), 'a' in o, Reflect.deleteProperty([1], 'length')].join()", "true,false,false"},
+		{"delete operator", "var o = {a: 1, b: {c: 2}}\nvar s = 'str';\n[delete o.a, delete o['b'].c, delete o.missing, delete s[0], delete s.length, JSON.stringify(o)].join('|')", `true|true|true|false|false|{"b":{}}`},
+		{"array holes", "var a = [1, 2, 3];\n[delete a[1], a.length, 1 in a, a.join()].join('|')", "true|3|false|1,,3"},
+		{"non-object", "var r\ntry { Reflect.get(1, 'x') } catch (e) { r = String(e) }\nr", "TypeError: Reflect.get called on non-object"},
+		{"descriptor", "var r\ntry { Reflect.defineProperty({}, 'x', 1) } catch (e) { r = String(e) }\nr", "TypeError: Property description must be an object: 1"},
+		{"toStringTag", "String(Reflect)", "[object Reflect]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := interpret(tt.source)
+			if actual != tt.want {
+				t.Errorf("expect= %v, actual= %v", tt.want, actual)
+			}
+		})
+	}
+}
+
+func Test_interpret_typed_array(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   any
+	}{
+		{"conversion", "[new Uint8Array([1, 256, -1]).join(), new Int8Array([200, -129, 1.9]).join(), new Uint8ClampedArray([1.5, 2.5, -1, 300]).join(), new Float32Array([0.5, 0.1])[1] === 0.1].join('|')", "1,0,255|-56,127,1|2,2,0,255|false"},
+		{"shared buffer", "var b = new ArrayBuffer(4)\nvar u8 = new Uint8Array(b)\nvar u16 = new Uint16Array(b)\nu16[1] = 258;\n[u8.join(), u16.length, u16.byteLength, b.byteLength, u16.buffer === b].join('|')", "0,0,2,1|2|4|4|true"},
+		{"keys", "var a = new Uint8Array([1, 2])\na[5] = 1\na.x = 1;\n[a[5], a['1.5'], a['-0'], 5 in a, '1' in a, Object.keys(a).join(), a.length].join('|')", "|||false|true|0,1,x|2"},
+		{"dataview", "var v = new DataView(new ArrayBuffer(8))\nv.setUint16(0, 4660)\nv.setUint16(2, 4660, true)\nv.setFloat64(0, v.getFloat64(0));\n[v.getUint8(0), v.getUint8(2), v.getUint16(0, true), v.getInt32(0), v.byteLength].join()", "18,52,13330,305411090,8"},
+		{"dataview offset", "var b = new ArrayBuffer(8)\nvar v = new DataView(b, 2, 4)\nv.setInt8(0, -1);\n[new Int8Array(b)[2], v.byteOffset, v.byteLength, v.getInt8(0), v.getUint8(0)].join()", "-1,2,4,-1,255"},
+		{"resizable", "var b = new ArrayBuffer(4, {maxByteLength: 16})\nvar track = new Uint16Array(b)\nvar fixed = new Uint16Array(b, 0, 2)\nb.resize(8)\nvar r = [b.resizable, b.maxByteLength, track.length, fixed.length]\nb.resize(2)\nr.push(track.length, fixed.length, fixed[0])\nr.join()", "true,16,4,2,1,0,"},
+		{"transfer", "var b = new ArrayBuffer(4)\nvar v = new Uint8Array(b)\nv[0] = 9\nvar moved = b.transfer(8);\n[b.detached, b.byteLength, v.length, moved.byteLength, new Uint8Array(moved)[0]].join()", "true,0,0,8,9"},
+		{"shared array buffer", "var b = new SharedArrayBuffer(2, {maxByteLength: 4})\nb.grow(4);\n[b.byteLength, b.growable, new Int8Array(b).length, String(b), String(new ArrayBuffer(1).slice(0))].join()", "4,true,4,[object SharedArrayBuffer],[object ArrayBuffer]"},
+		{"subarray", "var a = new Uint8Array([1, 2, 3, 4])\nvar s = a.subarray(1, 3)\ns[0] = 20;\n[a.join(), s.join(), s.byteOffset, s.buffer === a.buffer, a.slice(-2).join()].join('|')", "1,20,3,4|20,3|1|true|3,4"},
+		{"methods", "var a = new Int16Array([3, 1, 2]);\n[a.map((x) => x * 2).join(), a.filter((x) => x > 1).join(), a.reduce((x, y) => x + y), a.indexOf(2), a.includes(4), a.at(-1), Array.from(a.entries()).join(';')].join('|')", "6,2,4|3,2|6|2|false|2|0,3;1,1;2,2"},
+		{"sort", "var f = new Float64Array([3, Math.sqrt(-1), -0, 0, -1]);\n[f.sort().join(), Object.is(f[1], -0), new Int8Array([1, 3, 2]).sort((a, b) => b - a).join(), new Uint8Array([2, 1]).toSorted().join()].join('|')", "-1,0,0,3,NaN|true|3,2,1|1,2"},
+		{"set", "var a = new Uint8Array(4)\na.set([1, 2], 1)\na.set(new Int8Array([-1]));\n[a.join(), a.with(0, 7).join(), a.toReversed().join(), a.fill(5, 2).join()].join('|')", "255,1,2,0|7,1,2,0|0,2,1,255|255,1,5,5"},
+		{"from and of", "[Uint8Array.from([1, 2], (x) => x * 3).join(), Int8Array.of(1, -2).join(), Uint16Array.from({length: 2, 0: 7}).join(), Uint8Array.from(new Set([4, 5])).join()].join('|')", "3,6|1,-2|7,0|4,5"},
+		{"iteration", "var r = ''\nfor (var x of new Int8Array([5, 6])) { r += x }\nr + new Float32Array([1.5, 2])", "561.5,2"},
+		{"constructors", "var T = Reflect.getPrototypeOf(Int8Array);\n[T.name, Int32Array.BYTES_PER_ELEMENT, Float64Array.prototype.BYTES_PER_ELEMENT, Reflect.getPrototypeOf(Uint8Array.prototype) === T.prototype, new Uint8Array(1) instanceof Uint8Array, ArrayBuffer.isView(new DataView(new ArrayBuffer(1))), ArrayBuffer.isView([]), String(new Uint8Array(1))].join()", "TypedArray,4,8,true,true,true,false,0"},
+		{"element descriptor", "var a = new Uint8Array(2);\n[JSON.stringify(Reflect.getOwnPropertyDescriptor(a, '0')), Reflect.defineProperty(a, '5', {value: 1}), Reflect.defineProperty(a, '0', {value: 3, writable: false}), Reflect.defineProperty(a, '1', {value: 4}), a.join()].join('|')", `{"value":0,"writable":true,"enumerable":true,"configurable":true}|false|false|true|0,4`},
+		{"alignment", "var r = []\ntry { new Int32Array(new ArrayBuffer(8), 1) } catch (e) { r.push(String(e)) }\ntry { new Int32Array(new ArrayBuffer(7)) } catch (e) { r.push(String(e)) }\ntry { new Int8Array(new ArrayBuffer(4), 5) } catch (e) { r.push(String(e)) }\ntry { new Int8Array(new ArrayBuffer(4), 1, 5) } catch (e) { r.push(String(e)) }\nr.join('|')", "RangeError: start offset of Int32Array should be a multiple of 4|RangeError: byte length of Int32Array should be a multiple of 4|RangeError: Start offset 5 is outside the bounds of the buffer|RangeError: Invalid typed array length: 5"},
+		{"lengths", "var r = []\ntry { new ArrayBuffer(-1) } catch (e) { r.push(String(e)) }\ntry { new ArrayBuffer(2, {maxByteLength: 1}) } catch (e) { r.push(String(e)) }\ntry { new Int8Array(-1) } catch (e) { r.push(String(e)) }\ntry { new ArrayBuffer(2, {maxByteLength: 4}).resize(5) } catch (e) { r.push(String(e)) }\nr.join('|')", "RangeError: Invalid array buffer length|RangeError: Invalid array buffer max length|RangeError: Invalid typed array length: -1|RangeError: ArrayBuffer.prototype.resize: Invalid length parameter"},
+		{"dataview bounds", "var r = []\ntry { new DataView(new ArrayBuffer(2)).getInt16(1) } catch (e) { r.push(String(e)) }\ntry { new DataView(new ArrayBuffer(2), 3) } catch (e) { r.push(String(e)) }\ntry { new DataView(1) } catch (e) { r.push(String(e)) }\nr.join('|')", "RangeError: Offset is outside the bounds of the DataView|RangeError: Start offset 3 is outside the bounds of the buffer|TypeError: First argument to DataView constructor must be an ArrayBuffer"},
+		{"detached", "var b = new ArrayBuffer(2)\nvar v = new Uint8Array(b)\nb.transfer()\nvar r = []\ntry { v.fill(1) } catch (e) { r.push(String(e)) }\ntry { b.slice() } catch (e) { r.push(String(e)) }\nr.join('|')", "TypeError: Cannot perform %TypedArray%.prototype.fill on a detached ArrayBuffer|TypeError: Cannot perform ArrayBuffer.prototype.slice on a detached ArrayBuffer"},
+		{"receiver", "var r = []\ntry { Reflect.apply(Uint8Array.prototype.fill, [], [1]) } catch (e) { r.push(String(e)) }\ntry { Reflect.construct(Reflect.getPrototypeOf(Int8Array), []) } catch (e) { r.push(String(e)) }\ntry { Int8Array(2) } catch (e) { r.push(String(e)) }\ntry { new Uint8Array(2).set([1, 2, 3]) } catch (e) { r.push(String(e)) }\nr.join('|')", "TypeError: this is not a typed array.|TypeError: Abstract class TypedArray not directly constructable|TypeError: Constructor Int8Array requires 'new'|RangeError: offset is out of bounds"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := interpret(tt.source)
+			if actual != tt.want {
+				t.Errorf("expect= %v, actual= %v", tt.want, actual)
+			}
+		})
+	}
+}
+
+func Test_interpret_global(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   any
+	}{
+		{"declarations", "var a = 1\nlet b = 2\nconst c = 3\nfunction f() {}\nclass K {}\nglobalThis.d = 4;\n[globalThis.a, globalThis.b, globalThis.c, typeof globalThis.f, globalThis.K, d, globalThis.globalThis === globalThis].join()", "1,,,function,,4,true"},
+		{"nested declarations", "{ var a = 1; let b = 2; function f() {} }\nfor (var i = 0; i < 2; i++) { var j = i }\nfor (var k in { x: 1 }) {}\nfor (var v of [3]) {}\ntry { throw 4 } catch (e) { var c = e }\n[globalThis.a, globalThis.b, typeof globalThis.f, globalThis.i, globalThis.j, globalThis.k, globalThis.v, globalThis.c, typeof e].join()", "1,,function,2,1,x,3,4,undefined"},
+		{"function scope", "function f() { { var a = 1 } for (var i = 0; i < 2; i++) {} return [a, i].join() }\n[f(), typeof a, typeof i].join()", "1,2,undefined,undefined"},
+		{"builtins", "[typeof globalThis.Map, Object.keys(globalThis).includes('Map'), 'Map' in globalThis, Reflect.getOwnPropertyDescriptor(globalThis, 'Map').writable].join()", "function,false,true,true"},
+		{"delete", "var a = 1\nb = 2;\n[delete globalThis.a, delete globalThis.b, typeof a, typeof b].join()", "false,true,number,undefined"},
+		{"constants", "NaN = 1\nInfinity = 2\nundefined = 3;\n[Number.isNaN(NaN), Infinity, -Infinity, typeof undefined, Reflect.getOwnPropertyDescriptor(globalThis, 'NaN').writable].join()", "true,Infinity,-Infinity,undefined,false"},
+		{"encode", "[encodeURIComponent('a b&c/d?é€😀'), encodeURI('http://x.com/a b?q=1&r=é#h')].join(' ')", "a%20b%26c%2Fd%3F%C3%A9%E2%82%AC%F0%9F%98%80 http://x.com/a%20b?q=1&r=%C3%A9#h"},
+		{"encode lone surrogate", "var r = []\nfor (var s of [String.fromCharCode(55296), String.fromCharCode(56320) + 'a', 'a' + String.fromCharCode(55296)]) {\ntry { encodeURIComponent(s) } catch (e) { r.push(String(e)) }\ntry { encodeURI(s) } catch (e) { r.push(e.name) }\n}\nr.push(encodeURI(String.fromCharCode(55357, 56832)))\nr.join('|')", "URIError: URI malformed|URIError|URIError: URI malformed|URIError|URIError: URI malformed|URIError|%F0%9F%98%80"},
+		{"decode", "[decodeURIComponent('%41%20%e2%82%ac%2F'), decodeURI('%41%20%2F%23%E2%82%AC')].join(' ')", "A €/ A %2F%23€"},
+		{"malformed", "var r = []\nfor (var s of ['%', '%E2%82', '%C0%80', '%ZZ', '%ED%A0%80']) {\ntry { decodeURIComponent(s) } catch (e) { r.push(String(e)) }\n}\nr.join('|')", "URIError: URI malformed|URIError: URI malformed|URIError: URI malformed|URIError: URI malformed|URIError: URI malformed"},
+		{"clone cycle", "var o = {a: [1, {b: 2}]}\no.self = o\nvar c = structuredClone(o);\n[c !== o, c.self === c, c.a !== o.a, c.a[1].b].join()", "true,true,true,2"},
+		{"clone collections", "var v = {x: 1}\nvar m = new Map([[v, v]])\nvar c = structuredClone({m: m, s: new Set([v]), d: new Date(0)})\nvar k = c.m.keys().next().value;\n[c.m.get(k) === k, k !== v, c.s.size, c.d.getTime(), c.d instanceof Date].join()", "true,true,1,0,true"},
+		{"clone typed arrays", "var a = new Uint16Array([1, 2, 3]).subarray(1)\nvar c = structuredClone({a: a, b: a.buffer})\nc.b[0] = 9;\n[c.a.join(), c.a.byteOffset, c.a.buffer === c.b, c.a.buffer !== a.buffer, c.a instanceof Uint16Array].join()", "2,3,2,true,true,true"},
+		{"clone error", "var e = structuredClone(new RangeError('boom', {cause: 1}));\n[e instanceof RangeError, e.message, e.cause, String(e)].join()", "true,boom,1,RangeError: boom"},
+		{"transfer", "var b = new ArrayBuffer(2)\nvar c = structuredClone({b: b, v: new Uint8Array(b)}, {transfer: [b]});\n[b.detached, c.b.byteLength, c.v.buffer === c.b].join()", "true,2,true"},
+		{"clone errors", "var r = []\nfor (var f of [() => structuredClone(Symbol('x')), () => structuredClone(new WeakMap()), () => structuredClone({p: Promise.resolve()}), () => structuredClone(new Proxy({}, {})), () => structuredClone(), () => structuredClone(1, {transfer: [1]})]) {\ntry { f() } catch (e) { r.push(String(e)) }\n}\nr.join('|')", "DataCloneError: Symbol(x) could not be cloned.|DataCloneError: #<WeakMap> could not be cloned.|DataCloneError: #<Promise> could not be cloned.|DataCloneError: #<Object> could not be cloned.|TypeError: The value argument must be specified|TypeError: Found invalid object in transferList"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := interpret(tt.source)
+			if actual != tt.want {
+				t.Errorf("expect= %v, actual= %v", tt.want, actual)
+			}
+		})
+	}
+}
+
+func Test_interpret_intl(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   any
+	}{
+		{"default locale", "var d = new Date(2024, 6, 4, 0, 5, 9);\n[d.toLocaleString(), new Intl.DateTimeFormat().format(d), (1234.5).toLocaleString(), new Intl.DateTimeFormat().resolvedOptions().timeZone].join('|')", "7/4/2024, 12:05:09 AM|7/4/2024|1,234.5|America/New_York"},
+		{"number", "[(1234567.891).toLocaleString(), (1234567.891).toLocaleString('de'), (1234567.891).toLocaleString('fr'), (0.5).toLocaleString('ja'), NaN.toLocaleString('de'), (-Infinity).toLocaleString()].join('|')", "1,234,567.891|1.234.567,891|1\u202f234\u202f567,891|0.5|NaN|-∞"},
+		{"digits", "var f = (o, n) => new Intl.NumberFormat('en', o).format(n);\n[f({maximumSignificantDigits: 3}, 1234.5), f({minimumIntegerDigits: 3, minimumFractionDigits: 2}, 1.005), f({maximumFractionDigits: 0}, 2.5), f({signDisplay: 'always'}, 0), f({useGrouping: false}, 12345)].join('|')", "1,230|001.005|3|+0|12345"},
+		{"percent", "[new Intl.NumberFormat('en', {style: 'percent'}).format(-0.256), new Intl.NumberFormat('de', {style: 'percent'}).format(-0.256)].join('|')", "-26%|-26\u00a0%"},
+		{"currency", "var f = (l, o) => new Intl.NumberFormat(l, Object.assign({style: 'currency'}, o)).format(-1234.5);\n[f('en', {currency: 'USD'}), f('de', {currency: 'EUR'}), f('ja', {currency: 'JPY'}), f('zh', {currency: 'USD'}), f('en', {currency: 'EUR', currencyDisplay: 'name'}), f('en', {currency: 'USD', currencySign: 'accounting'})].join('|')", "-$1,234.50|-1.234,50\u00a0€|-￥1,235|-US$1,234.50|-1,234.50 euros|($1,234.50)"},
+		{"formatToParts", "new Intl.NumberFormat('de').formatToParts(-1234.5).map(p => p.type + ':' + p.value).join()", "minusSign:-,integer:1,group:.,integer:234,decimal:,,fraction:5"},
+		{"number options", "var o = new Intl.NumberFormat('fr-CA', {style: 'currency', currency: 'eur'}).resolvedOptions();\n[o.locale, o.currency, o.minimumFractionDigits, o.useGrouping, o.roundingMode].join()", "fr-CA,EUR,2,auto,halfExpand"},
+		{"date", "var d = new Date(Date.UTC(2024, 0, 5, 15, 4, 5))\nvar r = []\nfor (var l of ['en', 'de', 'fr', 'ja', 'zh']) r.push(d.toLocaleString(l, {timeZone: 'UTC'}));\nr.join('|')", "1/5/2024, 3:04:05 PM|5.1.2024, 15:04:05|05/01/2024 15:04:05|2024/1/5 15:04:05|2024/1/5 15:04:05"},
+		{"date styles", "var d = new Date(Date.UTC(2024, 0, 5, 15, 4, 5))\nvar r = []\nfor (var l of ['en', 'de', 'fr', 'ja', 'zh']) r.push(d.toLocaleDateString(l, {dateStyle: 'full', timeZone: 'UTC'}));\nr.join('|')", "Friday, January 5, 2024|Freitag, 5. Januar 2024|vendredi 5 janvier 2024|2024年1月5日金曜日|2024年1月5日星期五"},
+		{"date time styles", "new Date(Date.UTC(2024, 0, 5, 15, 4, 5)).toLocaleString('en', {dateStyle: 'long', timeStyle: 'full', timeZone: 'UTC'})", "January 5, 2024 at 3:04:05 PM Coordinated Universal Time"},
+		{"date components", "var d = new Date(Date.UTC(2024, 0, 5, 15, 4, 5))\nvar f = (l, o) => new Intl.DateTimeFormat(l, Object.assign({timeZone: 'UTC'}, o)).format(d);\n[f('en', {month: 'long', day: 'numeric'}), f('de', {weekday: 'short', month: 'short', day: 'numeric'}), f('fr', {hour: 'numeric'}), f('zh', {month: 'long'}), f('en', {year: '2-digit', month: '2-digit', day: '2-digit'})].join('|')", "January 5|Fr., 5. Jan.|15 h|一月|01/05/24"},
+		{"hour cycles", "var d = new Date(Date.UTC(2024, 0, 5, 0, 4))\nvar f = (l, o) => new Intl.DateTimeFormat(l, Object.assign({timeZone: 'UTC', hour: 'numeric', minute: '2-digit'}, o)).format(d);\n[f('en', {hour12: false}), f('de', {hour12: true}), f('ja', {hour12: true}), f('zh', {hourCycle: 'h12'}), f('en', {hourCycle: 'h23'})].join('|')", "24:04|0:04 AM|午前0:04|上午12:04|00:04"},
+		{"time zones", "var d = new Date(Date.UTC(2024, 0, 5, 15, 4))\nvar f = (l, z) => d.toLocaleTimeString(l, {timeZone: z, hour: 'numeric', minute: '2-digit', timeZoneName: 'short'});\n[f('en', 'UTC'), f('zh', 'UTC'), f('en', 'Asia/Kolkata'), f('fr', 'America/New_York')].join('|')", "3:04 PM UTC|UTC 15:04|8:34 PM GMT+5:30|10:04 UTC\u22125"},
+		{"date formatToParts", "new Intl.DateTimeFormat('en', {dateStyle: 'medium', timeZone: 'UTC'}).formatToParts(0).map(p => p.type + ':' + p.value).join()", "month:Jan,literal: ,day:1,literal:, ,year:1970"},
+		{"date options", "var o = new Intl.DateTimeFormat('de', {timeZone: 'Asia/Tokyo', hour: 'numeric', hour12: true}).resolvedOptions();\n[o.locale, o.timeZone, o.hourCycle, o.hour12, o.hour, o.minute].join()", "de,Asia/Tokyo,h11,true,numeric,"},
+		{"plural", "var r = []\nfor (var n of [0, 1, 2, 3, 11, 21, 1.5]) r.push(new Intl.PluralRules('en').select(n) + '/' + new Intl.PluralRules('en', {type: 'ordinal'}).select(n) + '/' + new Intl.PluralRules('fr').select(n));\nr.join()", "other/other/one,one/one/one,other/two/other,other/few/other,other/other/other,other/one/other,other/other/one"},
+		{"plural options", "[new Intl.PluralRules('en', {minimumFractionDigits: 1}).select(1), new Intl.PluralRules('fr').resolvedOptions().pluralCategories.join(' ')].join('|')", "other|many one other"},
+		{"collator", "var a = ['a', 'B', 'b', 'A', 'á', 'item10', 'item2'];\n[a.slice().sort(new Intl.Collator('en').compare).join(), a.slice().sort(new Intl.Collator('en', {numeric: true, caseFirst: 'upper'}).compare).join()].join('|')", "a,A,á,b,B,item10,item2|A,a,á,B,b,item2,item10"},
+		{"sensitivity", "var c = (s, a, b) => new Intl.Collator('en', {sensitivity: s}).compare(a, b);\n[c('base', 'a', 'Á'), c('accent', 'a', 'A'), c('accent', 'a', 'á'), c('case', 'a', 'A'), 'a'.localeCompare('A', 'en', {sensitivity: 'base'})].join()", "0,0,-1,-1,0"},
+		{"locales", "[Intl.getCanonicalLocales(['EN-us', 'zh-hans-cn', 'de']).join(), Intl.NumberFormat.supportedLocalesOf(['de-AT', 'ko', 'fr']).join(), new Intl.Collator('ko').resolvedOptions().locale, String(Intl), String(new Intl.PluralRules())].join('|')", "en-US,zh-Hans-CN,de|de-AT,fr|en-US|[object Intl]|[object Intl.PluralRules]"},
+		{"array", "[1234.5, new Date(0)].toLocaleString('de', {timeZone: 'UTC'}) + '|' + [{}, null, 'x'].toLocaleString()", "1.234,5,1.1.1970, 00:00:00|[object Object],,x"},
+		{"errors", "var r = []\nfor (var f of [() => Intl.getCanonicalLocales('a_b'), () => new Intl.NumberFormat('en', {style: 'currency'}), () => new Intl.NumberFormat('en', {style: 'bad'}), () => new Intl.NumberFormat('en', {maximumFractionDigits: 101}), () => new Intl.DateTimeFormat('en', {timeZone: 'Mars/X'}), () => new Intl.DateTimeFormat('en', {dateStyle: 'full', year: 'numeric'}), () => new Intl.DateTimeFormat('en').format(NaN), () => new Date(0).toLocaleDateString('en', {timeStyle: 'short'}), () => Intl.PluralRules()]) {\ntry { f() } catch (e) { r.push(String(e)) }\n}\nr.join('|')", "RangeError: Incorrect locale information provided|TypeError: Currency code is required with currency style.|RangeError: Value bad out of range for Intl.NumberFormat options property style|RangeError: maximumFractionDigits value is out of range.|RangeError: Invalid time zone specified: Mars/X|TypeError: Can't set option year when dateStyle is used|RangeError: Invalid time value|TypeError: Invalid option : timeStyle|TypeError: Constructor Intl.PluralRules requires 'new'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := interpretDate(t, tt.source)
+			if actual != tt.want {
+				t.Errorf("expect= %v, actual= %v", tt.want, actual)
+			}
+		})
+	}
+}
+
+func Test_interpret_intl_locales(t *testing.T) {
+	env := call.NewGlobalEnvironment()
+	i := New(env)
+	defer i.Close()
+	i.SetLocales([]string{"de", "fr"})
+	i.Interpret(Parse("var result = [(1234.5).toLocaleString(), new Intl.NumberFormat('en').resolvedOptions().locale, Intl.Collator.supportedLocalesOf(['en', 'fr-CA', 'ja']).join()].join('|')"))
+	if actual, want := env.Get("result"), "1.234,5|de|fr-CA"; actual != want {
+		t.Errorf("expect %v, actual: %v", want, actual)
+	}
+}
+
+func Test_interpret_encoding(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   any
+	}{
+		{"encode", "var e = new TextEncoder();\n[e.encoding, String(e), Array.from(e.encode('h€😀')).join(' '), e.encode().length].join()", "utf-8,[object TextEncoder],104 226 130 172 240 159 152 128,0"},
+		{"encodeInto", "var d = new Uint8Array(5)\nvar r = new TextEncoder().encodeInto('a€😀', d);\n[r.read, r.written, d.join(' ')].join()", "2,4,97 226 130 172 0"},
+		{"decode", "var d = new TextDecoder(' UTF8 ');\n[d.encoding, d.fatal, d.decode(new Uint8Array([239, 187, 191, 104, 105]).buffer), d.decode(new DataView(new Uint8Array([106]).buffer)), d.decode()].join()", "utf-8,false,hi,j,"},
+		{"replacement", "var d = new TextDecoder();\n[d.decode(new Uint8Array([104, 226, 130, 65, 240, 159, 152])), d.decode(new Uint8Array([192, 128, 237, 160, 128, 244, 144, 128, 128, 255])).length].join()", "h\ufffdA\ufffd,10"},
+		{"ignoreBOM", "new TextDecoder('utf-8', {ignoreBOM: true}).decode(new Uint8Array([239, 187, 191, 104])).length", int64(2)},
+		{"stream", "var d = new TextDecoder()\nvar r = []\nfor (var c of [[239, 187], [191, 226], [130], [172, 240, 159], [152, 128, 226]]) r.push(d.decode(new Uint8Array(c), {stream: true}));\nr.push(d.decode());\nr.join('|')", "|||€|😀|\ufffd"},
+		{"fatal", "var d = new TextDecoder('utf-8', {fatal: true})\nvar r = []\ntry { d.decode(new Uint8Array([226, 130]), {stream: true}); d.decode(new Uint8Array([65])) } catch (e) { r.push(String(e)) }\nr.push(d.decode(new Uint8Array([65])));\nr.join('|')", "TypeError: The encoded data was not valid for encoding utf-8|A"},
+		{"base64", "[btoa('hello'), btoa('ÿ'), btoa(null), atob(' aGVs bG8= '), atob('aGVsbG8'), atob('/w'), atob('YR')].join()", "aGVsbG8=,/w==,bnVsbA==,hello,hello,ÿ,a"},
+		{"errors", "var r = []\nfor (var f of [() => TextEncoder(), () => new TextDecoder('latin1'), () => new TextEncoder().encodeInto('a', [1]), () => new TextDecoder().decode('ab'), () => btoa('€'), () => btoa(), () => atob('aGVsbG8==='), () => atob('a')]) {\ntry { f() } catch (e) { r.push(String(e)) }\n}\nr.join('|')", "TypeError: Class constructor TextEncoder cannot be invoked without 'new'|RangeError: The \"latin1\" encoding is not supported|TypeError: The \"dest\" argument must be an instance of Uint8Array. Received an instance of Object|TypeError: The \"list\" argument must be an instance of SharedArrayBuffer, ArrayBuffer or ArrayBufferView.|InvalidCharacterError: Invalid character|TypeError: The \"input\" argument must be specified|InvalidCharacterError: Invalid character|InvalidCharacterError: The string to be decoded is not correctly encoded."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := interpret(tt.source)
+			if actual != tt.want {
+				t.Errorf("expect= %v, actual= %v", tt.want, actual)
+			}
+		})
+	}
+}
+
+func Test_interpret_crypto(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   any
+	}{
+		{"getRandomValues", "var a = new Uint32Array(4)\nvar result = [crypto.getRandomValues(a) === a, a.some(x => x !== 0), String(crypto), String(crypto.subtle)].join()", "true,true,[object Crypto],[object SubtleCrypto]"},
+		{"randomUUID", "var result = /^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$/.test(crypto.randomUUID())", true},
+		{"digest", "var result = ''\nvar hex = b => Array.from(new Uint8Array(b)).map(x => x.toString(16).padStart(2, '0')).join('')\nvar data = new TextEncoder().encode('abc')\ncrypto.subtle.digest('SHA-1', data).then(b => result += hex(b) + '|')\ncrypto.subtle.digest({name: 'sha-256'}, data.buffer).then(b => result += hex(b) + '|')\ncrypto.subtle.digest('SHA-512', data).then(b => result += b.byteLength)", "a9993e364706816aba3e25717850c26c9cd0d89d|ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad|64"},
+		{"digest errors", "var result = ''\nvar data = new Uint8Array(1)\nfor (var p of [crypto.subtle.digest('MD5', data), crypto.subtle.digest({}, data), crypto.subtle.digest('SHA-1', 'x'), crypto.subtle.digest('SHA-1')]) p.catch(e => result += e + '|')", "NotSupportedError: Unrecognized algorithm name|TypeError: Failed to normalize algorithm: passed algorithm can not be converted to 'Algorithm' because 'name' is required in 'Algorithm'.|TypeError: Failed to execute 'digest' on 'SubtleCrypto': 2nd argument is not instance of ArrayBuffer, Buffer, TypedArray, or DataView.|TypeError: Failed to execute 'digest' on 'SubtleCrypto': 2 arguments required, but only 1 present.|"},
+		{"errors", "var result = ''\nfor (var f of [() => crypto.getRandomValues(new Float32Array(1)), () => crypto.getRandomValues(new Uint8Array(65537)), () => crypto.getRandomValues()]) {\ntry { f() } catch (e) { result += e + '|' }\n}", "TypeMismatchError: The data argument must be an integer-type TypedArray|QuotaExceededError: The requested length exceeds 65,536 bytes|TypeError: Failed to execute 'getRandomValues' on 'Crypto': 1 argument required, but only 0 present.|"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := interpretResult(tt.source)
+			if actual != tt.want {
+				t.Errorf("expect= %v, actual= %v", tt.want, actual)
+			}
+		})
+	}
+}
+
+func Test_interpret_symbol(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   any
+	}{
+		{
+			"typeof",
+			"typeof Symbol('a')",
+			"symbol",
+		},
+		{
+			"unique",
+			"Symbol('a') == Symbol('a')",
+			false,
+		},
+		{
+			"description",
+			"Symbol('a').description + Symbol('b').toString()",
+			"aSymbol(b)",
+		},
+		{
+			"no description",
+			"[typeof Symbol().description, Symbol('').description === '', Symbol().toString()].join()",
+			"undefined,true,Symbol()",
+		},
+		{
+			"registry",
+			"Symbol.for('a') === Symbol.for('a') && Symbol.keyFor(Symbol.for('a')) == 'a'",
+			true,
+		},
+		{
+			"keyFor unregistered",
+			"Symbol.keyFor(Symbol('a'))",
+			nil,
+		},
+		{
+			"hidden from for in",
+			`
+			var a = Symbol('a')
+			var b = {b: 1, [a]: 2}
+			var result = ''
+			for (var key in b) result += key
+			result + b[a]
+			`,
+			"b2",
+		},
+		{
+			"getOwnPropertySymbols",
+			`
+			var a = Symbol('a')
+			var b = {b: 1, [a]: 2}
+			Object.getOwnPropertySymbols(b)[0] === a
+			`,
+			true,
+		},
+		{
+			"not a constructor",
+			`
+			var result
+			try {
+				new Symbol()
+			} catch (e) {
+				result = String(e)
+			}
+			result
+			`,
+			"TypeError: Symbol is not a constructor",
+		},
+		{
+			"no string conversion",
+			`
+			var result
+			try {
+				'' + Symbol()
+			} catch (e) {
+				result = String(e)
+			}
+			result
+			`,
+			"TypeError: Cannot convert a Symbol value to a string",
+		},
+		{
+			"hasInstance",
+			`
+			class Two {
+				static [Symbol.hasInstance](value) {
+					return value == 2
+				}
+			}
+			2 instanceof Two
+			`,
+			true,
+		},
+		{
+			"instanceof",
+			`
+			class Base {}
+			function Point() {}
+			new Base() instanceof Base && new Point() instanceof Point && !({} instanceof Base)
+			`,
+			true,
+		},
+		{
+			"toPrimitive",
+			`
+			var a = {
+				[Symbol.toPrimitive](hint) {
+					if (hint == 'number') {
+						return 2
+					}
+					return 'a'
+				}
+			}
+			a * 3 + a
+			`,
+			"6a",
+		},
+		{
+			"toStringTag",
+			"'' + {[Symbol.toStringTag]: 'Test'}",
+			"[object Test]",
+		},
+		{
+			"class iterator",
+			`
+			class Range {
+				[Symbol.iterator]() {
+					var i = 0
+					return {
+						next() {
+							i++
+							return {value: i, done: i > 3}
+						}
+					}
+				}
+			}
+			var result = 0
+			for (var item of new Range()) result += item
+			result
+			`,
+			int64(6),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := interpret(tt.source)
+			if actual != tt.want {
+				t.Errorf("expect= %v, actual= %v", tt.want, actual)
+			}
+		})
+	}
+}
+
+func Test_interpret_generator(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   any
+	}{
+		{
+			"next",
+			`
+			function* count() {
+				var a = yield 1
+				yield a + 1
+				return 'end'
+			}
+			var it = count()
+			var result = ''
+			result += it.next().value
+			result += it.next(5).value
+			var last = it.next()
+			result + last.value + last.done + it.next().done
+			`,
+			"16endtruetrue",
+		},
+		{
+			"lazy start",
+			`
+			var result = 'a'
+			function* gen() {
+				result += 'c'
+				yield 1
+			}
+			var it = gen()
+			result += 'b'
+			it.next()
+			result
+			`,
+			"abc",
+		},
+		{
+			"return runs finally",
+			`
+			var result = ''
+			function* gen() {
+				try {
+					yield 1
+					yield 2
+				} finally {
+					result += 'finally'
+				}
+			}
+			var it = gen()
+			it.next()
+			var r = it.return(3)
+			result + r.value + r.done + it.next().done
+			`,
+			"finally3truetrue",
+		},
+		{
+			"throw",
+			`
+			function* gen() {
+				try {
+					yield 1
+				} catch (e) {
+					yield 'caught ' + e
+				}
+			}
+			var it = gen()
+			it.next()
+			it.throw('boom').value
+			`,
+			"caught boom",
+		},
+		{
+			"throw before start",
+			`
+			function* gen() {
+				yield 1
+			}
+			var it = gen()
+			var result
+			try {
+				it.throw('boom')
+			} catch (e) {
+				result = e
+			}
+			result + it.next().done
+			`,
+			"boomtrue",
+		},
+		{
+			"yield delegate",
+			`
+			function* inner() {
+				yield 2
+				yield 3
+				return 4
+			}
+			function* outer() {
+				yield 1
+				var a = yield* inner()
+				yield* [a, 5]
+			}
+			var result = ''
+			for (var item of outer()) result += item
+			result
+			`,
+			"12345",
+		},
+		{
+			"break closes generator",
+			`
+			var result = ''
+			function* gen() {
+				try {
+					yield 1
+					yield 2
+				} finally {
+					result += 'closed'
+				}
+			}
+			for (var item of gen()) {
+				result += item
+				break
+			}
+			result
+			`,
+			"1closed",
+		},
+		{
+			"expression and methods",
+			`
+			var gen = function* () {
+				yield 1
+			}
+			var object = {
+				*items() {
+					yield 2
+				}
+			}
+			class List {
+				*[Symbol.iterator]() {
+					yield 3
+				}
+				static *of() {
+					yield 4
+				}
+			}
+			var result = 0
+			for (var a of gen()) result += a
+			for (var b of object.items()) result += b
+			for (var c of new List()) result += c
+			for (var d of List.of()) result += d
+			result
+			`,
+			int64(10),
+		},
+		{
+			"running",
+			`
+			var it
+			function* gen() {
+				it.next()
+			}
+			it = gen()
+			var result
+			try {
+				it.next()
+			} catch (e) {
+				result = String(e)
+			}
+			result
+			`,
+			"TypeError: Generator is already running",
+		},
+		{
+			"not a constructor",
+			`
+			function* gen() {}
+			var result
+			try {
+				new gen()
+			} catch (e) {
+				result = String(e)
+			}
+			result
+			`,
+			"TypeError: generator function is not a constructor",
+		},
+		{
+			"toStringTag",
+			`
+			function* gen() {}
+			'' + gen()
+			`,
+			"[object Generator]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := interpret(tt.source)
+			if actual != tt.want {
+				t.Errorf("expect= %v, actual= %v", tt.want, actual)
+			}
+		})
+	}
+}
+
+func Test_interpret_generator_leak(t *testing.T) {
+	before := runtime.NumGoroutine()
+	interpret(`
+	var result = ''
+	function* gen() {
+		try {
+			yield 1
+			yield 2
+		} finally {
+			result += 'finally'
+		}
+	}
+	for (var i = 0; i < 10; i++) {
+		gen().next()
+	}
+	var kept = gen()
+	kept.next()
+	`)
+	for i := 0; i < 100 && runtime.NumGoroutine() > before; i++ {
+		runtime.GC()
+		time.Sleep(time.Millisecond)
+	}
+	if after := runtime.NumGoroutine(); after > before {
+		t.Errorf("expect %d goroutines, actual: %d", before, after)
+	}
+}
+
+func Test_interpret_generator_collect(t *testing.T) {
+	env := call.NewGlobalEnvironment()
+	i := New(env)
+	defer i.Close()
+	before := runtime.NumGoroutine()
+	i.Interpret(Parse(`
+	function* gen() {
+		yield 1
+	}
+	for (var i = 0; i < 10; i++) {
+		gen().next()
+	}
+	`))
+	for i := 0; i < 100 && runtime.NumGoroutine() > before; i++ {
+		runtime.GC()
+		time.Sleep(time.Millisecond)
+	}
+	if after := runtime.NumGoroutine(); after > before {
+		t.Errorf("expect %d goroutines, actual: %d", before, after)
+	}
+}
+
+// Test_interpret_generator_scope checks that a generator held only by a
+// variable of a finished call, or of the scope it was declared in, is
+// collected while the interpreter still runs.
+func Test_interpret_generator_scope(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+	}{
+		{
+			"caller scope",
+			`
+			function* g() {
+				yield 1
+				yield 2
+			}
+			function f() {
+				const it = g()
+				it.next()
+			}
+			for (var i = 0; i < 100; i++) {
+				f()
+			}
+			`,
+		},
+		{
+			"closure scope",
+			`
+			function f() {
+				function* g() {
+					yield 1
+					yield 2
+				}
+				const it = g()
+				it.next()
+			}
+			for (var i = 0; i < 100; i++) {
+				f()
+			}
+			`,
+		},
+		{
+			"async generator",
+			`
+			function f() {
+				async function* g() {
+					yield 1
+					yield 2
+				}
+				const it = g()
+				it.next()
+			}
+			for (var i = 0; i < 100; i++) {
+				f()
+			}
+			`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := call.NewGlobalEnvironment()
+			i := New(env)
+			defer i.Close()
+			before := runtime.NumGoroutine()
+			i.Interpret(Parse(tt.source))
+			i.GetEventLoop().Run()
+			for i := 0; i < 100 && runtime.NumGoroutine() > before; i++ {
+				runtime.GC()
+				time.Sleep(time.Millisecond)
+			}
+			if after := runtime.NumGoroutine(); after > before {
+				t.Errorf("expect %d goroutines, actual: %d", before, after)
+			}
+		})
+	}
+}
+
+// Test_interpret_generator_closure checks that a closure created by a
+// generator keeps the scopes it refers to after the generator is collected.
+func Test_interpret_generator_closure(t *testing.T) {
+	env := call.NewGlobalEnvironment()
+	i := New(env)
+	defer i.Close()
+	i.Interpret(Parse(`
+	function f() {
+		var outer = 'outer'
+		function* g(a) {
+			var inner = 'inner'
+			yield () => outer + ' ' + inner + ' ' + a
+		}
+		return g('param').next().value
+	}
+	var closure = f()
+	`))
+	for i := 0; i < 5; i++ {
+		runtime.GC()
+	}
+	want := "outer inner param"
+	if actual := i.Interpret(Parse("closure()")); actual != want {
+		t.Errorf("expect %v, actual: %v", want, actual)
+	}
+}
+
+// Test_interpret_generator_running checks that the scopes of a generator
+// stay alive while its body runs, even when nothing else refers to the
+// generator object.
+func Test_interpret_generator_running(t *testing.T) {
+	env := call.NewGlobalEnvironment()
+	i := New(env)
+	defer i.Close()
+	env.Define("gc", call.NewNative(i, "gc", func(interpreter types.Interpreter, this any, params []any) any {
+		runtime.GC()
+		return nil
+	}))
+	actual := i.Interpret(Parse(`
+	function make() {
+		var secret = 42
+		return function* () {
+			var list = []
+			for (var i = 0; i < 100000; i++) {
+				list = [i, { a: i }]
+			}
+			gc()
+			yield secret
+		}
+	}
+	make()().next().value
+	`))
+	if actual != int64(42) {
+		t.Errorf("expect 42, actual: %v", actual)
+	}
+}
+
+func Test_interpret_realm(t *testing.T) {
+	polluted := interpret("Array.prototype.foo = 1; Object.prototype.bar = 2; String.prototype.trim = null; [].foo")
+	if polluted != int64(1) {
+		t.Fatalf("expect= 1, actual= %v", polluted)
+	}
+	first := Interpret("Symbol.for('realm')", call.NewGlobalEnvironment())
+	second := Interpret("Symbol.for('realm')", call.NewGlobalEnvironment())
+	if first == second {
+		t.Errorf("expect each realm to have its own Symbol.for registry")
+	}
+	tests := []struct {
+		name   string
+		source string
+		expect any
+	}{
+		{"array prototype", "[].foo", nil},
+		{"object prototype", "({}).bar", nil},
+		{"string prototype", "' a '.trim()", "a"},
+		{"constructor", "[].constructor === Array && Reflect.getPrototypeOf(function () {}) === Function.prototype", true},
+		{"error", "var r\ntry { null() } catch (e) { r = e instanceof TypeError }\nr", true},
+		{"registry", "Symbol.for('realm') === Symbol.for('realm')", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if actual := interpret(tt.source); actual != tt.expect {
+				t.Errorf("expect= %v, actual= %v", tt.expect, actual)
+			}
+		})
+	}
+}
+
+func Test_interpret_parallel(t *testing.T) {
+	source := `
+	Array.prototype.count = (Array.prototype.count || 0) + 1
+	var key = Symbol.for('shared')
+	var list = [3, 1, 2].sort().map(String)
+	Symbol.keyFor(key) + list.join('') + [].count
+	`
+	t.Run("group", func(t *testing.T) {
+		for i := 0; i < 8; i++ {
+			t.Run(fmt.Sprint(i), func(t *testing.T) {
+				t.Parallel()
+				if actual := interpret(source); actual != "shared1231" {
+					t.Errorf("expect= shared1231, actual= %v", actual)
+				}
+			})
+		}
+	})
+}
+
+func Test_interpret_promise(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   any
+	}{
+		{
+			"then",
+			`
+			var result = ''
+			new Promise(function (resolve) {
+				result += 'a'
+				resolve('c')
+			}).then(function (value) {
+				result += value
+			})
+			result += 'b'
+			`,
+			"abc",
+		},
+		{
+			"microtask order",
+			`
+			var result = ''
+			Promise.resolve().then(function () { result += 'a1' }).then(function () { result += 'a2' })
+			Promise.resolve().then(function () { result += 'b1' }).then(function () { result += 'b2' })
+			`,
+			"a1b1a2b2",
+		},
+		{
+			"thenable adoption",
+			`
+			var result = ''
+			Promise.resolve().then(function () { return Promise.resolve('x') }).then(function (v) { result += v })
+			Promise.resolve().then(function () { result += 1 }).then(function () { result += 2 }).then(function () { result += 3 }).then(function () { result += 4 })
+			`,
+			"123x4",
+		},
+		{
+			"catch",
+			`
+			var result
+			Promise.resolve().then(function () {
+				throw 'error'
+			}).then(function () {
+				result = 'skipped'
+			}).catch(function (e) {
+				result = e
+			})
+			`,
+			"error",
+		},
+		{
+			"finally",
+			`
+			var result = ''
+			Promise.resolve('a').finally(function () {
+				result += 'f'
+				return 'ignored'
+			}).then(function (value) {
+				result += value
+			})
+			`,
+			"fa",
+		},
+		{
+			"executor throws",
+			`
+			var result
+			new Promise(function () {
+				throw 'error'
+			}).catch(function (e) {
+				result = e
+			})
+			`,
+			"error",
+		},
+		{
+			"all",
+			`
+			var result
+			Promise.all([1, Promise.resolve(2), { then(resolve) { resolve(3) } }]).then(function (values) {
+				result = values[0] + values[1] + values[2]
+			})
+			`,
+			int64(6),
+		},
+		{
+			"all rejects",
+			`
+			var result
+			Promise.all([Promise.resolve(1), Promise.reject('no')]).catch(function (e) {
+				result = e
+			})
+			`,
+			"no",
+		},
+		{
+			"allSettled",
+			`
+			var result
+			Promise.allSettled([Promise.reject('x'), 1]).then(function (values) {
+				result = values[0].status + values[0].reason + values[1].status + values[1].value
+			})
+			`,
+			"rejectedxfulfilled1",
+		},
+		{
+			"race",
+			`
+			var result
+			Promise.race([new Promise(function () {}), Promise.resolve('b')]).then(function (value) {
+				result = value
+			})
+			`,
+			"b",
+		},
+		{
+			"any",
+			`
+			var result
+			Promise.any([Promise.reject(1), Promise.resolve(2)]).then(function (value) {
+				result = value
+			})
+			`,
+			int64(2),
+		},
+		{
+			"any rejects",
+			`
+			var result
+			Promise.any([Promise.reject(1), Promise.reject(2)]).catch(function (e) {
+				result = e.message + e.errors[1]
+			})
+			`,
+			"All promises were rejected2",
+		},
+		{
+			"chaining cycle",
+			`
+			var result
+			var p = Promise.resolve().then(function () {
+				return p
+			})
+			p.catch(function (e) {
+				result = e
+			})
+			`,
+			"TypeError: Chaining cycle detected for promise #<Promise>",
+		},
+		{
+			"handled later in same tick",
+			`
+			var result
+			var p = Promise.reject('late')
+			Promise.resolve().then(function () {
+				p.catch(function (e) {
+					result = e
+				})
+			})
+			`,
+			"late",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := interpretResult(tt.source)
+			if actual != tt.want {
+				t.Errorf("expect= %v, actual= %v", tt.want, actual)
+			}
+		})
+	}
+}
+
+func Test_interpret_unhandled_rejection(t *testing.T) {
+	defer func() {
+		err := recover()
+		if val, ok := err.(flow.Rejection); !ok || val.Error() != "Uncaught (in promise) oops" {
+			t.Errorf("expect unhandled rejection, actual: %v", err)
+		}
+	}()
+	interpret(`Promise.reject('oops')`)
+}
+
+func Test_interpret_arrow_function(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   any
+	}{
+		{
+			"expression body",
+			`
+			var add = (a, b) => a + b
+			var double = a => a * 2
+			add(1, 2) + double(3)
+			`,
+			int64(9),
+		},
+		{
+			"block body",
+			`
+			var f = () => {
+				return 'a'
+			}
+			f()
+			`,
+			"a",
+		},
+		{
+			"lexical this",
+			`
+			var object = {
+				value: 'b',
+				get() {
+					var inner = () => this.value
+					return inner()
+				}
+			}
+			object.get()
+			`,
+			"b",
+		},
+		{
+			"not a constructor",
+			`
+			var f = () => {}
+			var result
+			try {
+				new f()
+			} catch (e) {
+				result = String(e)
+			}
+			result
+			`,
+			"TypeError: arrow function is not a constructor",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := interpret(tt.source)
+			if actual != tt.want {
+				t.Errorf("expect= %v, actual= %v", tt.want, actual)
+			}
+		})
+	}
+}
+
+func Test_interpret_async(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   any
+	}{
+		{
+			"returns promise",
+			`
+			var result = ''
+			async function f() {
+				result += 'a'
+				return 'c'
+			}
+			f().then(function (value) {
+				result += value
+			})
+			result += 'b'
+			`,
+			"abc",
+		},
+		{
+			"await order",
+			`
+			var result = ''
+			async function f() {
+				result += 1
+				await null
+				result += 3
+				await null
+				result += 5
+			}
+			f()
+			Promise.resolve().then(() => { result += 4 })
+			result += 2
+			`,
+			"12345",
+		},
+		{
+			"await rejection",
+			`
+			var result
+			async function f() {
+				try {
+					await Promise.reject('bad')
+				} catch (e) {
+					result = 'caught ' + e
+				}
+			}
+			f()
+			`,
+			"caught bad",
+		},
+		{
+			"throw rejects",
+			`
+			var result
+			async function f() {
+				throw 'error'
+			}
+			f().catch(e => {
+				result = e
+			})
+			`,
+			"error",
+		},
+		{
+			"arrows and methods",
+			`
+			var result = ''
+			var a = async x => x
+			var b = async (x, y) => {
+				return await a(x) + y
+			}
+			var object = {
+				async c() {
+					return 'c'
+				}
+			}
+			class D {
+				async d() {
+					return 'd'
+				}
+				static async e() {
+					return 'e'
+				}
+			}
+			var f = async function () {
+				return 'f'
+			}
+			async function run() {
+				result += await b('a', 'b')
+				result += await object.c()
+				result += await new D().d()
+				result += await D.e()
+				result += await f()
+			}
+			run()
+			`,
+			"abcdef",
+		},
+		{
+			"thenable",
+			`
+			var result
+			async function f() {
+				result = await {
+					then(resolve) {
+						resolve('thenable')
+					}
+				}
+			}
+			f()
+			`,
+			"thenable",
+		},
+		{
+			"top-level await",
+			`
+			var result = ''
+			Promise.resolve().then(() => { result += 'b' })
+			result += 'a'
+			var c = await 'c'
+			result += c
+			`,
+			"abc",
+		},
+		{
+			"top-level await rejection",
+			`
+			var result
+			try {
+				await Promise.reject('bad')
+			} catch (e) {
+				result = e
+			}
+			`,
+			"bad",
+		},
+		{
+			"unsettled top-level await",
+			`
+			var result = 'a'
+			await new Promise(function () {})
+			result = 'b'
+			`,
+			"a",
+		},
+		{
+			"async is an identifier",
+			`
+			var async = 1
+			var result = async
+			`,
+			int64(1),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := interpretResult(tt.source)
+			if actual != tt.want {
+				t.Errorf("expect= %v, actual= %v", tt.want, actual)
+			}
+		})
+	}
+}
+
+func Test_interpret_async_leak(t *testing.T) {
+	before := runtime.NumGoroutine()
+	interpret(`
+	async function wait() {
+		await new Promise(function () {})
+	}
+	for (var i = 0; i < 10; i++) {
+		wait()
+	}
+	`)
+	if after := runtime.NumGoroutine(); after > before {
+		t.Errorf("expect %d goroutines, actual: %d", before, after)
+	}
+}
+
+func Test_interpret_async_generator(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   any
+	}{
+		{
+			"for await",
+			`
+			var result = ''
+			async function* pages() {
+				for (var i = 1; i <= 3; i++) {
+					await null
+					yield 'page' + i
+				}
+			}
+			async function main() {
+				for await (var page of pages()) {
+					result += page
+				}
+			}
+			main()
+			`,
+			"page1page2page3",
+		},
+		{
+			"early exit cleanup",
+			`
+			var result = ''
+			async function* pages() {
+				try {
+					yield 1
+					yield 2
+				} finally {
+					result += 'cleanup'
+				}
+			}
+			async function main() {
+				for await (var page of pages()) {
+					result += page
+					break
+				}
+			}
+			main()
+			`,
+			"1cleanup",
+		},
+		{
+			"sync iterator of promises",
+			`
+			var result = ''
+			async function main() {
+				for await (var item of [Promise.resolve('a'), 'b', Promise.resolve('c')]) {
+					result += item
+				}
+			}
+			main()
+			`,
+			"abc",
+		},
+		{
+			"asyncIterator protocol",
+			`
+			var result = ''
+			var source = {
+				[Symbol.asyncIterator]() {
+					var i = 0
+					return {
+						next() {
+							i++
+							return Promise.resolve({value: i, done: i > 2})
+						}
+					}
+				}
+			}
+			for await (var item of source) {
+				result += item
+			}
+			`,
+			"12",
+		},
+		{
+			"yield delegate",
+			`
+			var result = ''
+			async function* inner() {
+				yield 'a'
+				return 'c'
+			}
+			async function* outer() {
+				var value = yield* inner()
+				yield 'b'
+				yield value
+				yield* ['d']
+			}
+			for await (var item of outer()) {
+				result += item
+			}
+			`,
+			"abcd",
+		},
+		{
+			"queued requests",
+			`
+			var result = ''
+			async function* gen() {
+				try {
+					yield 1
+					yield 2
+				} finally {
+					result += 'finally'
+				}
+			}
+			var it = gen()
+			var a = it.next()
+			var b = it.return('done')
+			var c = it.next()
+			result += 'queued'
+			var ra = await a
+			var rb = await b
+			var rc = await c
+			result += ra.value + rb.value + rb.done + rc.done
+			`,
+			"queuedfinally1donetruetrue",
+		},
+		{
+			"throw",
+			`
+			var result
+			async function* gen() {
+				try {
+					yield 1
+				} catch (e) {
+					yield 'caught ' + e
+				}
+			}
+			var it = gen()
+			await it.next()
+			result = (await it.throw('boom')).value
+			`,
+			"caught boom",
+		},
+		{
+			"rejects",
+			`
+			var result
+			async function* gen() {
+				throw 'error'
+			}
+			gen().next().catch(e => {
+				result = e
+			})
+			`,
+			"error",
+		},
+		{
+			"methods",
+			`
+			var result = ''
+			class List {
+				async *[Symbol.asyncIterator]() {
+					yield 'a'
+				}
+			}
+			var object = {
+				async *items() {
+					yield await Promise.resolve('b')
+				}
+			}
+			for await (var a of new List()) result += a
+			for await (var b of object.items()) result += b
+			`,
+			"ab",
+		},
+		{
+			"toStringTag",
+			`
+			async function* gen() {}
+			var result = '' + gen()
+			`,
+			"[object AsyncGenerator]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := interpretResult(tt.source)
+			if actual != tt.want {
+				t.Errorf("expect= %v, actual= %v", tt.want, actual)
+			}
+		})
+	}
+}
+
+func Test_interpret_timer(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   any
+	}{
+		{
+			"order",
+			`
+			var result = ''
+			setTimeout(() => result += 'd', 10)
+			setTimeout(() => result += 'b', 0)
+			setTimeout(() => result += 'c', 1)
+			setTimeout(() => result += 'e', 10)
+			queueMicrotask(() => result += 'a')
+			`,
+			"abcde",
+		},
+		{
+			"microtasks after each timer",
+			`
+			var result = ''
+			setTimeout(() => {
+				Promise.resolve().then(() => result += 'b')
+				result += 'a'
+			}, 5)
+			setTimeout(() => result += 'c', 5)
+			`,
+			"abc",
+		},
+		{
+			"arguments",
+			`
+			var result
+			setTimeout((a, b) => result = a + b, 1, 'a', 'b')
+			`,
+			"ab",
+		},
+		{
+			"interval",
+			`
+			var result = ''
+			var count = 0
+			var id = setInterval(() => {
+				count++
+				result += count
+				if (count == 3) {
+					clearInterval(id)
+				}
+			}, 10)
+			setTimeout(() => result += 'a', 25)
+			`,
+			"12a3",
+		},
+		{
+			"clear",
+			`
+			var result = 'a'
+			var a = setTimeout(() => result += 'b', 1)
+			var b = setTimeout(() => result += 'c', 1)
+			clearTimeout(a)
+			clearTimeout(+b)
+			`,
+			"a",
+		},
+		{
+			"unref",
+			`
+			var result = 'a'
+			setTimeout(() => result += 'b', 1).unref()
+			`,
+			"a",
+		},
+		{
+			"refresh",
+			`
+			var result = ''
+			var a = setTimeout(() => result += 'a', 10)
+			setTimeout(() => a.refresh(), 5)
+			setTimeout(() => result += 'b', 12)
+			`,
+			"ba",
+		},
+		{
+			"await timer",
+			`
+			var result = ''
+			function sleep(ms) {
+				return new Promise(resolve => setTimeout(resolve, ms))
+			}
+			async function main() {
+				await sleep(10)
+				result += 'b'
+			}
+			main()
+			setTimeout(() => result += 'a', 5)
+			`,
+			"ab",
+		},
+		{
+			"invalid callback",
+			`
+			var result
+			try {
+				setTimeout(1)
+			} catch (e) {
+				result = String(e)
+			}
+			`,
+			"TypeError: The \"callback\" argument must be of type function. Received type number (1)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := interpretResult(tt.source)
+			if actual != tt.want {
+				t.Errorf("expect= %v, actual= %v", tt.want, actual)
+			}
+		})
+	}
+}
+
+func Test_interpret_virtual_clock(t *testing.T) {
+	env := call.NewGlobalEnvironment()
+	i := New(env)
+	defer i.Close()
+	start := time.Unix(0, 0)
+	virtual := clock.NewVirtual(start)
+	i.GetEventLoop().SetClock(virtual)
+	i.Interpret(Parse(`
+	var result = 0
+	setTimeout(() => result = 1, 60000)
+	`))
+	if !i.GetEventLoop().RunUntil(func() bool {
+		return env.Get("result") == int64(1)
+	}) {
+		t.Fatal("expect the timer to run")
+	}
+	if elapsed := virtual.Now().Sub(start); elapsed != time.Minute {
+		t.Errorf("expect %v, actual: %v", time.Minute, elapsed)
+	}
+}
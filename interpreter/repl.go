@@ -3,16 +3,33 @@ package interpreter
 import (
 	"github.com/nusr/gojs/parser"
 	"github.com/nusr/gojs/scanner"
+	"github.com/nusr/gojs/statement"
 	"github.com/nusr/gojs/types"
 )
 
-func Interpret(source string, env types.Environment) any {
+// Parse scans and parses source into statements.
+func Parse(source string) []statement.Statement {
 	s := scanner.New(source)
 	tokens := s.Scan()
 
-	p := parser.New(tokens)
-	statements := p.Parse()
+	p := parser.NewWithSource(tokens, source)
+	return p.Parse()
+}
+
+// Interpret runs source and then the event loop until no work remains, and
+// stops any generator left suspended.
+func Interpret(source string, env types.Environment) any {
+	return InterpretFile("<anonymous>", source, env)
+}
+
+// InterpretFile is Interpret for a script named fileName in stack traces.
+func InterpretFile(fileName string, source string, env types.Environment) any {
+	statements := Parse(source)
 
 	i := New(env)
-	return i.Interpret(statements)
+	i.SetFileName(fileName)
+	defer i.Close()
+	result := i.Interpret(statements)
+	i.GetEventLoop().Run()
+	return result
 }
@@ -0,0 +1,126 @@
+package interpreter
+
+import (
+	"container/heap"
+	"time"
+)
+
+type timer struct {
+	loop      *eventLoop
+	id        int64
+	seq       int64 // timers due at the same time run in the order scheduled
+	when      time.Time
+	delay     time.Duration
+	repeat    bool
+	job       func()
+	ref       bool
+	cancelled bool
+	index     int // position in the heap, -1 when not scheduled
+}
+
+func (t *timer) ID() int64 {
+	return t.id
+}
+
+func (t *timer) Cancel() {
+	t.cancelled = true
+	t.loop.unschedule(t)
+}
+
+func (t *timer) SetRef(ref bool) {
+	if t.index >= 0 && t.ref != ref {
+		if ref {
+			t.loop.refs++
+		} else {
+			t.loop.refs--
+		}
+	}
+	t.ref = ref
+}
+
+func (t *timer) HasRef() bool {
+	return t.ref
+}
+
+func (t *timer) Refresh() {
+	if t.cancelled {
+		return
+	}
+	t.loop.unschedule(t)
+	t.loop.schedule(t)
+}
+
+type timerHeap []*timer
+
+func (h timerHeap) Len() int {
+	return len(h)
+}
+
+func (h timerHeap) Less(i, j int) bool {
+	if h[i].when.Equal(h[j].when) {
+		return h[i].seq < h[j].seq
+	}
+	return h[i].when.Before(h[j].when)
+}
+
+func (h timerHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *timerHeap) Push(x any) {
+	t := x.(*timer)
+	t.index = len(*h)
+	*h = append(*h, t)
+}
+
+func (h *timerHeap) Pop() any {
+	old := *h
+	n := len(old)
+	t := old[n-1]
+	old[n-1] = nil
+	t.index = -1
+	*h = old[:n-1]
+	return t
+}
+
+func (loop *eventLoop) schedule(t *timer) {
+	loop.seq++
+	t.seq = loop.seq
+	t.when = loop.clock.Now().Add(t.delay)
+	heap.Push(&loop.timers, t)
+	loop.timerIDs[t.id] = t
+	if t.ref {
+		loop.refs++
+	}
+}
+
+func (loop *eventLoop) unschedule(t *timer) {
+	if t.index < 0 {
+		return
+	}
+	heap.Remove(&loop.timers, t.index)
+	delete(loop.timerIDs, t.id)
+	if t.ref {
+		loop.refs--
+	}
+}
+
+// runTimer waits for the earliest timer and runs it, reporting false when
+// no timer keeps the loop running.
+func (loop *eventLoop) runTimer() bool {
+	if loop.refs == 0 {
+		return false
+	}
+	t := loop.timers[0]
+	if wait := t.when.Sub(loop.clock.Now()); wait > 0 {
+		loop.clock.Sleep(wait)
+	}
+	loop.unschedule(t)
+	t.job()
+	if t.repeat && !t.cancelled && t.index < 0 {
+		loop.schedule(t)
+	}
+	return true
+}
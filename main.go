@@ -7,35 +7,75 @@ import (
 	"os"
 
 	"github.com/nusr/gojs/call"
-	"github.com/nusr/gojs/environment"
+	"github.com/nusr/gojs/flow"
 	"github.com/nusr/gojs/interpreter"
+	"github.com/nusr/gojs/types"
 )
 
 func RunCommand(in io.Reader, out io.Writer) {
 	input := bufio.NewScanner(in)
-	env := environment.New(nil)
-	call.RegisterGlobal(env)
+	env := call.NewGlobalEnvironment()
+	i := interpreter.New(env)
+	env.Define("console", call.NewConsole(i, call.NewConsoleOutput(out, out)))
+	defer i.Close()
 	for {
 		fmt.Fprintf(out, "> ")
 		scanned := input.Scan()
 		if !scanned {
 			return
 		}
-		line := input.Text()
-		result := interpreter.Interpret(line, env)
-		fmt.Println(out, result)
+		result, err := runLine(i, input.Text())
+		if err != nil {
+			fmt.Fprintln(out, err)
+		} else {
+			fmt.Fprintln(out, call.Inspect(i, result))
+		}
 	}
 }
 
-func RunFile(fileName string) (any, error) {
+// runLine runs one line of the REPL. An uncaught exception or unhandled
+// promise rejection is returned as the error so the session keeps going.
+func runLine(i types.Interpreter, line string) (result any, err error) {
+	defer func() {
+		if data := recover(); data != nil {
+			switch data := data.(type) {
+			case flow.Throw:
+				err = data
+			case flow.Rejection:
+				err = data
+			default:
+				panic(data)
+			}
+		}
+	}()
+	result = i.Interpret(interpreter.Parse(line))
+	i.GetEventLoop().Run()
+	return result, nil
+}
+
+// RunFile runs a script to completion. Like node, it does not print the
+// completion value. An uncaught exception or unhandled promise rejection is
+// returned as the error.
+func RunFile(fileName string) (err error) {
 	content, err := os.ReadFile(fileName)
 	if err != nil {
-		return nil, err
+		return fmt.Errorf("can not open file \"%s\", error: %w", fileName, err)
 	}
-	env := environment.New(nil)
-	call.RegisterGlobal(env)
-	result := interpreter.Interpret(string(content), env)
-	return result, nil
+	defer func() {
+		if data := recover(); data != nil {
+			switch data := data.(type) {
+			case flow.Throw:
+				err = data
+			case flow.Rejection:
+				err = data
+			default:
+				panic(data)
+			}
+		}
+	}()
+	env := call.NewGlobalEnvironment()
+	interpreter.InterpretFile(fileName, string(content), env)
+	return nil
 }
 
 func main() {
@@ -44,11 +84,9 @@ func main() {
 		RunCommand(os.Stdin, os.Stdout)
 	} else if argc == 2 {
 		fileName := os.Args[1]
-		result, err := RunFile(fileName)
-		if err != nil {
-			fmt.Printf("can not open file \"%s\", error: %v", fileName, err)
-		} else {
-			fmt.Println(result)
+		if err := RunFile(fileName); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
 		}
 	} else {
 		fmt.Println("Usage: node [path]")
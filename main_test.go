@@ -0,0 +1,37 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunCommand(t *testing.T) {
+	var out bytes.Buffer
+	RunCommand(strings.NewReader("throw new Error('x')\nPromise.reject(2)\n1 + 1\n"), &out)
+	expect := "> Uncaught Error: x\n    at <anonymous>:1:7\n> Uncaught (in promise) 2\n> 2\n> "
+	if out.String() != expect {
+		t.Errorf("expect %q, actual: %q", expect, out.String())
+	}
+}
+
+func TestRunFile(t *testing.T) {
+	dir := t.TempDir()
+	fileName := filepath.Join(dir, "ok.js")
+	if err := os.WriteFile(fileName, []byte("1 + 1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := RunFile(fileName); err != nil {
+		t.Errorf("expect no error, actual: %v", err)
+	}
+	fileName = filepath.Join(dir, "throw.js")
+	if err := os.WriteFile(fileName, []byte("throw new Error('x')\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	expect := "Uncaught Error: x\n    at " + fileName + ":1:7"
+	if err := RunFile(fileName); err == nil || err.Error() != expect {
+		t.Errorf("expect %q, actual: %v", expect, err)
+	}
+}
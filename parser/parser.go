@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/nusr/gojs/regex"
 	"github.com/nusr/gojs/statement"
 	"github.com/nusr/gojs/token"
 )
@@ -30,15 +31,28 @@ var assignmentMap = map[token.Type]token.Type{
 type Parser struct {
 	tokens  []token.Token
 	current int
+	noIn    bool // the head of a for statement can not use the in operator
+	yield   bool // inside a generator function
+	await   bool // inside an async function or at the top level
+	source  []rune
 }
 
 func New(tokens []token.Token) *Parser {
 	return &Parser{
 		current: 0,
 		tokens:  tokens,
+		await:   true,
 	}
 }
 
+// NewWithSource creates a parser of the tokens scanned from source, whose
+// functions and classes keep their text for toString.
+func NewWithSource(tokens []token.Token, source string) *Parser {
+	parser := New(tokens)
+	parser.source = []rune(source)
+	return parser
+}
+
 func (parser *Parser) Parse() []statement.Statement {
 	var statements []statement.Statement
 	for !parser.isAtEnd() {
@@ -62,6 +76,15 @@ func (parser *Parser) previous() token.Token {
 	return parser.tokens[parser.current-1]
 }
 
+// text returns the source from the token at index start to the last token
+// consumed.
+func (parser *Parser) text(start int) string {
+	if parser.source == nil {
+		return ""
+	}
+	return string(parser.source[parser.tokens[start].Start:parser.previous().End])
+}
+
 func (parser *Parser) consume(tokenType token.Type, message string) token.Token {
 	if parser.peek().Type != tokenType {
 		panic(any(message))
@@ -84,6 +107,52 @@ func (parser *Parser) checkNext(tokenType token.Type) bool {
 	return false
 }
 
+// checkAsync reports whether the next token is an async modifier, which is
+// only a keyword when followed on the same line by a token of tokenTypes.
+func (parser *Parser) checkAsync(tokenTypes ...token.Type) bool {
+	t := parser.peek()
+	if t.Type != token.Identifier || t.Lexeme != "async" {
+		return false
+	}
+	next := parser.tokens[parser.current+1]
+	if next.Line != t.Line {
+		return false
+	}
+	for _, tokenType := range tokenTypes {
+		if next.Type == tokenType {
+			return true
+		}
+	}
+	return false
+}
+
+// isArrow reports whether the tokens from index start the parameters of an
+// arrow function.
+func (parser *Parser) isArrow(index int) bool {
+	t := parser.tokens[index]
+	if t.Type == token.Identifier {
+		return parser.tokens[index+1].Type == token.Arrow
+	}
+	if t.Type != token.LeftParen {
+		return false
+	}
+	depth := 0
+	for i := index; i < len(parser.tokens); i++ {
+		switch parser.tokens[i].Type {
+		case token.LeftParen:
+			depth++
+		case token.RightParen:
+			depth--
+			if depth == 0 {
+				return i+1 < len(parser.tokens) && parser.tokens[i+1].Type == token.Arrow
+			}
+		case token.EOF:
+			return false
+		}
+	}
+	return false
+}
+
 func (parser *Parser) match(tokenTypes ...token.Type) bool {
 	for _, tokenType := range tokenTypes {
 		if parser.check(tokenType) {
@@ -94,7 +163,20 @@ func (parser *Parser) match(tokenTypes ...token.Type) bool {
 	return false
 }
 
-func (parser *Parser) varDeclaration(isStatic bool) statement.Statement {
+// identifierName consumes an identifier or a reserved word used as a property name.
+func (parser *Parser) identifierName(message string) token.Token {
+	t := parser.peek()
+	if t.Type != token.String && t.Type != token.EOF && t.Lexeme != "" {
+		c := t.Lexeme[0]
+		if c == '_' || c == '$' || c >= 0x80 || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') {
+			parser.advance()
+			return t
+		}
+	}
+	panic(any(message))
+}
+
+func (parser *Parser) varDeclaration(kind token.Token, isStatic bool) statement.Statement {
 	name := parser.consume(token.Identifier, "expect identifier after var")
 	var initializer statement.Expression
 	if parser.match(token.Equal) {
@@ -102,6 +184,7 @@ func (parser *Parser) varDeclaration(isStatic bool) statement.Statement {
 	}
 	parser.match(token.Semicolon)
 	return statement.VariableStatement{
+		Kind:        kind,
 		Name:        name,
 		Initializer: initializer,
 		Static:      isStatic,
@@ -115,6 +198,14 @@ func (parser *Parser) primary() statement.Expression {
 			Type:  t.Type,
 		}
 	}
+	if parser.match(token.RegExp) {
+		return parser.regExpLiteral(parser.previous().Lexeme)
+	}
+	if parser.checkAsync(token.Function) {
+		parser.advance()
+		parser.advance()
+		return parser.functionExpression(true)
+	}
 	if parser.match(token.Identifier) {
 		return statement.VariableExpression{
 			Name: parser.previous(),
@@ -128,7 +219,18 @@ func (parser *Parser) primary() statement.Expression {
 		}
 	}
 	if parser.match(token.LeftSquare) {
-		list := parser.getExpressionList(token.RightSquare)
+		var list []statement.Expression
+		for !parser.check(token.RightSquare) && !parser.isAtEnd() {
+			// an elision leaves a hole
+			if parser.match(token.Comma) {
+				list = append(list, nil)
+				continue
+			}
+			list = append(list, parser.expression())
+			if !parser.check(token.RightSquare) {
+				parser.consume(token.Comma, "expect ,")
+			}
+		}
 		parser.consume(token.RightSquare, "expect ]")
 		return statement.ArrayLiteralExpression{
 			Elements: list,
@@ -141,15 +243,7 @@ func (parser *Parser) primary() statement.Expression {
 				if parser.check(token.RightBrace) {
 					break
 				}
-				key := parser.consume(token.Identifier, "expect object key")
-				parser.consume(token.Colon, "expect :")
-				value := parser.expression()
-				properties = append(properties, statement.ObjectLiteralItem{
-					Key: statement.TokenExpression{
-						Name: key,
-					},
-					Value: value,
-				})
+				properties = append(properties, parser.objectLiteralItem())
 			}
 		}
 
@@ -159,28 +253,167 @@ func (parser *Parser) primary() statement.Expression {
 		}
 	}
 	if parser.match(token.Function) {
-		name := parser.getPartialName()
-		parser.consume(token.LeftParen, "expect (")
-		parameters := parser.getTokenList()
-		parser.consume(token.RightParen, "expect )")
-		parser.consume(token.LeftBrace, "expect {")
-		body := parser.block()
-		return statement.FunctionExpression{
-			Name:   name,
-			Body:   body,
-			Params: parameters,
-		}
+		return parser.functionExpression(false)
 	}
 	if parser.match(token.Class) {
+		start := parser.current - 1
 		name := parser.getPartialName()
 		methods := parser.getClassBody()
 		return statement.ClassExpression{
 			Name:    name,
 			Methods: methods,
+			Source:  parser.text(start),
 		}
 	}
 	panic(fmt.Sprintf("parser can not handle token: %s", parser.peek()))
 }
+
+// regExpLiteral splits /pattern/flags and rejects an invalid expression
+// early, as a syntax error.
+func (parser *Parser) regExpLiteral(lexeme string) statement.Expression {
+	end := strings.LastIndexByte(lexeme, '/')
+	pattern := lexeme[1:end]
+	flags := lexeme[end+1:]
+	if !regex.ValidFlags(flags) {
+		panic("Invalid regular expression flags")
+	}
+	if _, err := regex.Compile(pattern, flags); err != nil {
+		panic(err.Error())
+	}
+	return statement.RegExpLiteralExpression{
+		Pattern: pattern,
+		Flags:   flags,
+	}
+}
+
+func (parser *Parser) functionExpression(async bool) statement.Expression {
+	start := parser.current - 1
+	if async {
+		start--
+	}
+	generator := parser.match(token.Star)
+	name := parser.getPartialName()
+	parser.consume(token.LeftParen, "expect (")
+	parameters := parser.getTokenList()
+	parser.consume(token.RightParen, "expect )")
+	parser.consume(token.LeftBrace, "expect {")
+	body := parser.functionBody(generator, async)
+	return statement.FunctionExpression{
+		Name:      name,
+		Body:      body,
+		Params:    parameters,
+		Generator: generator,
+		Async:     async,
+		Source:    parser.text(start),
+	}
+}
+
+// arrowFunction parses an arrow function; a body that is an expression is
+// returned by an implicit return statement.
+func (parser *Parser) arrowFunction(async bool) statement.Expression {
+	start := parser.current
+	if async {
+		start--
+	}
+	var parameters []token.Token
+	if parser.match(token.LeftParen) {
+		parameters = parser.getTokenList()
+		parser.consume(token.RightParen, "expect )")
+	} else {
+		parameters = append(parameters, parser.consume(token.Identifier, "expect parameter name"))
+	}
+	parser.consume(token.Arrow, "expect =>")
+	var body statement.BlockStatement
+	if parser.match(token.LeftBrace) {
+		body = parser.functionBody(false, async)
+	} else {
+		yield, await := parser.yield, parser.await
+		parser.yield, parser.await = false, async
+		body = statement.BlockStatement{
+			Statements: []statement.Statement{
+				statement.ReturnStatement{
+					Value: parser.assignment(),
+				},
+			},
+		}
+		parser.yield, parser.await = yield, await
+	}
+	return statement.FunctionExpression{
+		Body:   body,
+		Params: parameters,
+		Async:  async,
+		Arrow:  true,
+		Source: parser.text(start),
+	}
+}
+
+// functionBody parses the block of a function, in which yield and await are
+// only valid for generator and async functions.
+func (parser *Parser) functionBody(generator bool, async bool) statement.BlockStatement {
+	yield, await := parser.yield, parser.await
+	parser.yield, parser.await = generator, async
+	defer func() {
+		parser.yield, parser.await = yield, await
+	}()
+	return parser.block()
+}
+
+// matchMethodAsync consumes the async modifier of a method definition.
+func (parser *Parser) matchMethodAsync() bool {
+	t := parser.peek()
+	if t.Type != token.Identifier || t.Lexeme != "async" {
+		return false
+	}
+	next := parser.tokens[parser.current+1]
+	if next.Line != t.Line {
+		return false
+	}
+	switch next.Type {
+	case token.LeftParen, token.Equal, token.Colon, token.Comma, token.RightBrace, token.Semicolon, token.EOF:
+		return false
+	}
+	parser.advance()
+	return true
+}
+
+func (parser *Parser) objectLiteralItem() statement.ObjectLiteralItem {
+	var item statement.ObjectLiteralItem
+	start := parser.current
+	async := parser.matchMethodAsync()
+	generator := parser.match(token.Star)
+	if parser.match(token.LeftSquare) {
+		item.Key = parser.assignment()
+		item.Computed = true
+		parser.consume(token.RightSquare, "expect ]")
+	} else {
+		var key token.Token
+		if parser.match(token.String, token.Int64, token.Float64) {
+			key = parser.previous()
+		} else {
+			key = parser.identifierName("expect object key")
+		}
+		item.Key = statement.TokenExpression{
+			Name: key,
+		}
+	}
+	if parser.match(token.LeftParen) {
+		parameters := parser.getTokenList()
+		parser.consume(token.RightParen, "expect )")
+		parser.consume(token.LeftBrace, "expect {")
+		item.Value = statement.FunctionExpression{
+			Body:      parser.functionBody(generator, async),
+			Params:    parameters,
+			Generator: generator,
+			Async:     async,
+			Source:    parser.text(start),
+		}
+		return item
+	}
+	parser.consume(token.Colon, "expect :")
+	item.Value = parser.expression()
+	return item
+}
+
 func (parser *Parser) getExpressionList(tokenType token.Type) []statement.Expression {
 	var params []statement.Expression
 	if parser.check(tokenType) {
@@ -206,18 +439,81 @@ func (parser *Parser) getExpressionList(tokenType token.Type) []statement.Expres
 	return params
 }
 func (parser *Parser) finishCall(callee statement.Expression) statement.Expression {
+	position := parser.previous()
+	switch expr := callee.(type) {
+	case statement.VariableExpression:
+		position = expr.Name
+	case statement.GetExpression:
+		if name, ok := expr.Property.(statement.TokenExpression); ok {
+			position = name.Name
+		}
+	}
 	params := parser.getExpressionList(token.RightParen)
 	parser.consume(token.RightParen, "expect )")
 	return statement.CallExpression{
 		Callee:    callee,
 		Arguments: params,
+		Token:     position,
+	}
+}
+
+// member parses the callee of a new expression, which stops before any call.
+func (parser *Parser) member() statement.Expression {
+	var expr statement.Expression
+	if parser.match(token.New) {
+		expr = parser.newExpression()
+	} else {
+		expr = parser.primary()
+	}
+	for {
+		if parser.match(token.Dot) {
+			expr = statement.GetExpression{
+				Object: expr,
+				Property: statement.TokenExpression{
+					Name: parser.identifierName("expect name"),
+				},
+			}
+		} else if parser.match(token.LeftSquare) {
+			name := parser.expression()
+			parser.consume(token.RightSquare, "expect ]")
+			expr = statement.GetExpression{
+				Object:   expr,
+				Property: name,
+				IsSquare: true,
+			}
+		} else {
+			return expr
+		}
 	}
 }
+
+func (parser *Parser) newExpression() statement.Expression {
+	keyword := parser.previous()
+	callee := parser.member()
+	var params []statement.Expression
+	if parser.match(token.LeftParen) {
+		params = parser.getExpressionList(token.RightParen)
+		parser.consume(token.RightParen, "expect )")
+	}
+	return statement.NewExpression{
+		Expression: statement.CallExpression{
+			Callee:    callee,
+			Arguments: params,
+			Token:     keyword,
+		},
+	}
+}
+
 func (parser *Parser) call() statement.Expression {
-	expr := parser.primary()
+	var expr statement.Expression
+	if parser.match(token.New) {
+		expr = parser.newExpression()
+	} else {
+		expr = parser.primary()
+	}
 	for {
 		if parser.match(token.Dot) {
-			name := parser.consume(token.Identifier, "expect name")
+			name := parser.identifierName("expect name")
 			expr = statement.GetExpression{
 				Object: expr,
 				Property: statement.TokenExpression{
@@ -242,18 +538,8 @@ func (parser *Parser) call() statement.Expression {
 	return expr
 }
 
-func (parser *Parser) new() statement.Expression {
-	if parser.match(token.New) {
-		call := parser.call()
-		return statement.NewExpression{
-			Expression: call,
-		}
-	}
-	return parser.call()
-}
-
 func (parser *Parser) postUnary() statement.Expression {
-	expr := parser.new()
+	expr := parser.call()
 	if parser.match(token.PlusPlus, token.MinusMinus) {
 		operator := parser.previous()
 		return statement.PostUnaryExpression{
@@ -265,7 +551,15 @@ func (parser *Parser) postUnary() statement.Expression {
 }
 
 func (parser *Parser) unary() statement.Expression {
-	if parser.match(token.Minus, token.Plus, token.Bang, token.MinusMinus, token.PlusPlus, token.BitNot) {
+	if parser.match(token.Await) {
+		if !parser.await {
+			panic(any("await is only valid in async functions and the top level bodies of modules"))
+		}
+		return statement.AwaitExpression{
+			Value: parser.unary(),
+		}
+	}
+	if parser.match(token.Minus, token.Plus, token.Bang, token.MinusMinus, token.PlusPlus, token.BitNot, token.Typeof, token.Delete) {
 		operator := parser.previous()
 		value := parser.unary()
 		return statement.UnaryExpression{
@@ -334,7 +628,7 @@ func (parser *Parser) bitShift() statement.Expression {
 
 func (parser *Parser) comparison() statement.Expression {
 	term := parser.bitShift()
-	for parser.match(token.Greater, token.GreaterEqual, token.Less, token.LessEqual) {
+	for parser.match(token.Greater, token.GreaterEqual, token.Less, token.LessEqual, token.Instanceof) || (!parser.noIn && parser.match(token.In)) {
 		operator := parser.previous()
 		right := parser.bitShift()
 		term = statement.BinaryExpression{
@@ -428,7 +722,38 @@ func (parser *Parser) or() statement.Expression {
 	}
 	return expr
 }
+
+// checkYieldEnd reports whether a yield has no operand.
+func (parser *Parser) checkYieldEnd() bool {
+	switch parser.peek().Type {
+	case token.RightParen, token.RightSquare, token.RightBrace, token.Comma, token.Semicolon, token.Colon, token.EOF:
+		return true
+	}
+	return parser.peek().Line != parser.previous().Line
+}
+
 func (parser *Parser) assignment() statement.Expression {
+	if parser.isArrow(parser.current) {
+		return parser.arrowFunction(false)
+	}
+	if parser.checkAsync(token.Identifier, token.LeftParen) && parser.isArrow(parser.current+1) {
+		parser.advance()
+		return parser.arrowFunction(true)
+	}
+	if parser.match(token.Yield) {
+		if !parser.yield {
+			panic(any("yield is only valid in generator functions"))
+		}
+		delegate := parser.match(token.Star)
+		var value statement.Expression
+		if delegate || !parser.checkYieldEnd() {
+			value = parser.assignment()
+		}
+		return statement.YieldExpression{
+			Value:    value,
+			Delegate: delegate,
+		}
+	}
 	expr := parser.or()
 	operatorType, check := assignmentMap[parser.peek().Type]
 	if parser.match(token.Equal) || check {
@@ -518,28 +843,55 @@ func (parser *Parser) block() statement.BlockStatement {
 	}
 }
 
+func (parser *Parser) checkOf() bool {
+	return parser.check(token.Identifier) && parser.peek().Lexeme == "of"
+}
+
 func (parser *Parser) forStatement() statement.Statement {
 	name := parser.previous()
+	isAwait := parser.match(token.Await)
+	if isAwait && !parser.await {
+		panic(any("for await is only valid in async functions and the top level bodies of modules"))
+	}
 	parser.consume(token.LeftParen, "expect (")
 
 	var initializer statement.Statement
 	if parser.match(token.Semicolon) {
 		initializer = nil
-	} else if parser.match(token.Var) {
-		initializer = parser.varDeclaration(false)
+	} else if parser.match(token.Var, token.Let, token.Const) {
+		kind := parser.previous()
+		if parser.check(token.Identifier) && (parser.checkNext(token.In) || (parser.checkNext(token.Identifier) && parser.tokens[parser.current+1].Lexeme == "of")) {
+			target := statement.VariableExpression{
+				Name: parser.consume(token.Identifier, "expect identifier"),
+			}
+			return parser.forInOfStatement(&kind, target, isAwait)
+		}
+		initializer = parser.varDeclaration(kind, false)
 	} else {
-		initializer = parser.expressionStatement()
+		parser.noIn = true
+		expr := parser.expression()
+		parser.noIn = false
+		if parser.check(token.In) || parser.checkOf() {
+			return parser.forInOfStatement(nil, expr, isAwait)
+		}
+		parser.match(token.Semicolon)
+		initializer = statement.ExpressionStatement{
+			Expression: expr,
+		}
 	}
 
+	if isAwait {
+		panic(any("for await requires an of loop"))
+	}
 	var condition statement.Expression
 	if !parser.check(token.Semicolon) {
 		condition = parser.expression()
 	}
 	parser.consume(token.Semicolon, "expect ;")
 
-	var increment statement.Statement
+	var increment statement.Expression
 	if !parser.check(token.RightParen) {
-		increment = parser.expressionStatement()
+		increment = parser.expression()
 	}
 	parser.consume(token.RightParen, "expect )")
 
@@ -552,19 +904,11 @@ func (parser *Parser) forStatement() statement.Statement {
 		}
 	}
 
-	if increment != nil {
-		body = statement.BlockStatement{
-			Statements: []statement.Statement{
-				body,
-				increment,
-			},
-		}
-	}
-
 	body = statement.WhileStatement{
 		Body:      body,
 		Condition: condition,
 		Name:      name,
+		Increment: increment,
 	}
 
 	if initializer != nil {
@@ -577,6 +921,38 @@ func (parser *Parser) forStatement() statement.Statement {
 	}
 	return body
 }
+
+func (parser *Parser) forInOfStatement(kind *token.Token, target statement.Expression, isAwait bool) statement.Statement {
+	switch target.(type) {
+	case statement.VariableExpression, statement.GetExpression:
+	default:
+		panic(fmt.Sprintf("invalid left-hand side in for loop: %s", target))
+	}
+	if parser.match(token.In) {
+		if isAwait {
+			panic(any("for await requires an of loop"))
+		}
+		object := parser.expression()
+		parser.consume(token.RightParen, "expect )")
+		return statement.ForInStatement{
+			Kind:   kind,
+			Target: target,
+			Object: object,
+			Body:   parser.statement(),
+		}
+	}
+	parser.advance() // skip of
+	iterable := parser.assignment()
+	parser.consume(token.RightParen, "expect )")
+	return statement.ForOfStatement{
+		Kind:     kind,
+		Target:   target,
+		Iterable: iterable,
+		Body:     parser.statement(),
+		Await:    isAwait,
+	}
+}
+
 func (parser *Parser) doWhile() statement.Statement {
 	name := parser.previous()
 	parser.consume(token.LeftBrace, "expect {")
@@ -585,15 +961,11 @@ func (parser *Parser) doWhile() statement.Statement {
 	parser.consume(token.LeftParen, "expect (")
 	condition := parser.expression()
 	parser.consume(token.RightParen, "expect )")
-	return statement.BlockStatement{
-		Statements: []statement.Statement{
-			body,
-			statement.WhileStatement{
-				Body:      body,
-				Condition: condition,
-				Name:      name,
-			},
-		},
+	parser.match(token.Semicolon)
+	return statement.WhileStatement{
+		Body:      body,
+		Condition: condition,
+		Name:      name,
 	}
 }
 func (parser *Parser) while() statement.Statement {
@@ -620,6 +992,55 @@ func (parser *Parser) returnStatement() statement.Statement {
 	}
 }
 
+func (parser *Parser) breakStatement() statement.Statement {
+	name := parser.previous()
+	parser.match(token.Semicolon)
+	if name.Type == token.Continue {
+		return statement.ContinueStatement{
+			Name: name,
+		}
+	}
+	return statement.BreakStatement{
+		Name: name,
+	}
+}
+
+func (parser *Parser) throwStatement() statement.Statement {
+	name := parser.previous()
+	value := parser.expression()
+	parser.match(token.Semicolon)
+	return statement.ThrowStatement{
+		Name:  name,
+		Value: value,
+	}
+}
+
+func (parser *Parser) tryStatement() statement.Statement {
+	parser.consume(token.LeftBrace, "expect { after try")
+	result := statement.TryStatement{
+		Block: parser.block(),
+	}
+	if parser.match(token.Catch) {
+		if parser.match(token.LeftParen) {
+			param := parser.consume(token.Identifier, "expect catch parameter")
+			result.Param = &param
+			parser.consume(token.RightParen, "expect ) after catch parameter")
+		}
+		parser.consume(token.LeftBrace, "expect { after catch")
+		handler := parser.block()
+		result.Handler = &handler
+	}
+	if parser.match(token.Finally) {
+		parser.consume(token.LeftBrace, "expect { after finally")
+		finalizer := parser.block()
+		result.Finalizer = &finalizer
+	}
+	if result.Handler == nil && result.Finalizer == nil {
+		panic("missing catch or finally after try")
+	}
+	return result
+}
+
 func (parser *Parser) statement() statement.Statement {
 	if parser.match(token.If) {
 		return parser.ifStatement()
@@ -639,6 +1060,15 @@ func (parser *Parser) statement() statement.Statement {
 	if parser.match(token.While) {
 		return parser.while()
 	}
+	if parser.match(token.Break, token.Continue) {
+		return parser.breakStatement()
+	}
+	if parser.match(token.Throw) {
+		return parser.throwStatement()
+	}
+	if parser.match(token.Try) {
+		return parser.tryStatement()
+	}
 	return parser.expressionStatement()
 }
 
@@ -663,18 +1093,31 @@ func (parser *Parser) getTokenList() []token.Token {
 	return parameters
 }
 
-func (parser *Parser) functionDeclaration(isStatic bool) statement.FunctionStatement {
+func (parser *Parser) functionDeclaration(async bool) statement.FunctionStatement {
+	start := parser.current - 1
+	if async {
+		start--
+	}
+	generator := parser.match(token.Star)
 	name := parser.consume(token.Identifier, "expect name")
+	function := parser.functionRest(name, false, generator, async)
+	function.Source = parser.text(start)
+	return function
+}
+
+func (parser *Parser) functionRest(name token.Token, isStatic bool, generator bool, async bool) statement.FunctionStatement {
 	parser.consume(token.LeftParen, "expect (")
 	parameters := parser.getTokenList()
 	parser.consume(token.RightParen, "expect )")
 	parser.consume(token.LeftBrace, "expect {")
-	body := parser.block()
+	body := parser.functionBody(generator, async)
 	return statement.FunctionStatement{
-		Name:   name,
-		Params: parameters,
-		Body:   body,
-		Static: isStatic,
+		Name:      name,
+		Params:    parameters,
+		Body:      body,
+		Static:    isStatic,
+		Generator: generator,
+		Async:     async,
 	}
 }
 
@@ -683,10 +1126,23 @@ func (parser *Parser) getClassBody() []statement.Statement {
 	var methods []statement.Statement
 	for !parser.check(token.RightBrace) && !parser.isAtEnd() {
 		isStatic := parser.match(token.Static)
-		if parser.checkNext(token.LeftParen) {
-			methods = append(methods, parser.functionDeclaration(isStatic))
+		start := parser.current
+		async := parser.matchMethodAsync()
+		generator := parser.match(token.Star)
+		if parser.match(token.LeftSquare) {
+			name := parser.previous()
+			key := parser.assignment()
+			parser.consume(token.RightSquare, "expect ]")
+			method := parser.functionRest(name, isStatic, generator, async)
+			method.Key = key
+			method.Source = parser.text(start)
+			methods = append(methods, method)
+		} else if parser.checkNext(token.LeftParen) {
+			method := parser.functionRest(parser.identifierName("expect name"), isStatic, generator, async)
+			method.Source = parser.text(start)
+			methods = append(methods, method)
 		} else {
-			methods = append(methods, parser.varDeclaration(isStatic))
+			methods = append(methods, parser.varDeclaration(token.Token{}, isStatic))
 		}
 	}
 	parser.consume(token.RightBrace, "expect }")
@@ -704,22 +1160,29 @@ func (parser *Parser) getPartialName() *token.Token {
 }
 
 func (parser *Parser) classDeclaration() statement.ClassStatement {
+	start := parser.current - 1
 	name := parser.consume(token.Identifier, "expect call name")
 	methods := parser.getClassBody()
 	return statement.ClassStatement{
 		Methods: methods,
 		Name:    name,
+		Source:  parser.text(start),
 	}
 }
 func (parser *Parser) declaration() statement.Statement {
 	if parser.match(token.Class) {
 		return parser.classDeclaration()
 	}
+	if parser.checkAsync(token.Function) {
+		parser.advance()
+		parser.advance()
+		return parser.functionDeclaration(true)
+	}
 	if parser.match(token.Function) {
 		return parser.functionDeclaration(false)
 	}
-	if parser.match(token.Var) {
-		return parser.varDeclaration(false)
+	if parser.match(token.Var, token.Let, token.Const) {
+		return parser.varDeclaration(parser.previous(), false)
 	}
 
 	return parser.statement()
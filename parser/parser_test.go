@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/nusr/gojs/scanner"
+	"github.com/nusr/gojs/statement"
 )
 
 func TestFunction(t *testing.T) {
@@ -125,3 +126,185 @@ func TestFunction(t *testing.T) {
 		}
 	}
 }
+
+func TestLoopStatement(t *testing.T) {
+	source := `
+	for (var key in a) b[key] = 1
+	for (let item of list) {
+		break
+	}
+	for (key in a) {}
+	for (a.b of list) continue
+	for (var i = 0; i < 10; i++) {}
+	do {} while (i)
+	try {
+		throw 'error'
+	} catch (e) {
+	} finally {
+	}
+	'a' in b
+	`
+	s := scanner.New(source)
+	tokens := s.Scan()
+	p := New(tokens)
+	list := p.Parse()
+
+	expects := []string{
+		"for(var key in a)b[key]=1;",
+		"for(let item of list){break;}",
+		"for(key in a){}",
+		"for(a.b of list)continue;",
+		"{var i=0;for(;i<10;i++){}}",
+		"do{}while(i);",
+		"try{throw error;}catch(e){}finally{}",
+		"a in b;",
+	}
+	if len(list) != len(expects) {
+		t.Fatalf("expect %d statements, actual: %d", len(expects), len(list))
+	}
+	for i, item := range list {
+		if item.String() != expects[i] {
+			t.Errorf("expect: %v,actual: %v", expects[i], item)
+		}
+	}
+}
+
+func TestGeneratorStatement(t *testing.T) {
+	source := `
+	function* gen(a) {
+		yield
+		yield a
+		yield* list
+	}
+	var b = function* () {}
+	`
+	s := scanner.New(source)
+	tokens := s.Scan()
+	p := New(tokens)
+	list := p.Parse()
+
+	expects := []string{
+		"function* gen(a){yield;yield a;yield* list;}",
+		"var b=function*(){};",
+	}
+	if len(list) != len(expects) {
+		t.Fatalf("expect %d statements, actual: %d", len(expects), len(list))
+	}
+	for i, item := range list {
+		if item.String() != expects[i] {
+			t.Errorf("expect: %v,actual: %v", expects[i], item)
+		}
+	}
+}
+
+func TestAsyncStatement(t *testing.T) {
+	source := `
+	async function a() {
+		await b
+	}
+	var c = async () => d
+	var e = (f, g) => {}
+	var h = async function () {}
+	await i
+	async function* j() {
+		for await (var k of l) {}
+	}
+	`
+	s := scanner.New(source)
+	tokens := s.Scan()
+	p := New(tokens)
+	list := p.Parse()
+
+	expects := []string{
+		"async function a(){await b;}",
+		"var c=async ()=>{return d;};",
+		"var e=(f,g)=>{};",
+		"var h=async function(){};",
+		"await i;",
+		"async function* j(){for await(var k of l){}}",
+	}
+	if len(list) != len(expects) {
+		t.Fatalf("expect %d statements, actual: %d", len(expects), len(list))
+	}
+	for i, item := range list {
+		if item.String() != expects[i] {
+			t.Errorf("expect: %v,actual: %v", expects[i], item)
+		}
+	}
+}
+
+func TestArrayLiteral(t *testing.T) {
+	source := `
+	var a = [1, , 3]
+	var b = [, ,]
+	var c = [1, 2,]
+	`
+	s := scanner.New(source)
+	tokens := s.Scan()
+	p := New(tokens)
+	list := p.Parse()
+
+	expects := []string{
+		"var a=[1,,3];",
+		"var b=[,,];",
+		"var c=[1,2];",
+	}
+	if len(list) != len(expects) {
+		t.Fatalf("expect %d statements, actual: %d", len(expects), len(list))
+	}
+	for i, item := range list {
+		if item.String() != expects[i] {
+			t.Errorf("expect: %v,actual: %v", expects[i], item)
+		}
+	}
+}
+
+func TestRegExpLiteral(t *testing.T) {
+	list := New(scanner.New("var a = /[a-z]+\\/x/gi").Scan()).Parse()
+	if len(list) != 1 || list[0].String() != "var a=/[a-z]+\\/x/gi;" {
+		t.Errorf("expect a regular expression literal, actual: %v", list)
+	}
+	tests := []struct {
+		source string
+		want   string
+	}{
+		{"/(/", "Invalid regular expression: /(/: Unterminated group"},
+		{"/a/gg", "Invalid regular expression flags"},
+	}
+	for _, tt := range tests {
+		func() {
+			defer func() {
+				if err := recover(); err != tt.want {
+					t.Errorf("expect %s, actual: %v", tt.want, err)
+				}
+			}()
+			New(scanner.New(tt.source).Scan()).Parse()
+		}()
+	}
+}
+
+func TestAwaitOutsideAsync(t *testing.T) {
+	defer func() {
+		if err := recover(); err != "await is only valid in async functions and the top level bodies of modules" {
+			t.Errorf("expect await error, actual: %v", err)
+		}
+	}()
+	New(scanner.New("function a() { await b }").Scan()).Parse()
+}
+
+func TestSource(t *testing.T) {
+	source := "async  function a(b) { return b }\nclass C { static d() {} }\nvar e = x =>  x\n"
+	list := NewWithSource(scanner.New(source).Scan(), source).Parse()
+	actuals := []string{
+		list[0].(statement.FunctionStatement).Source,
+		list[1].(statement.ClassStatement).Source,
+		list[1].(statement.ClassStatement).Methods[0].(statement.FunctionStatement).Source,
+		list[2].(statement.VariableStatement).Initializer.(statement.FunctionExpression).Source,
+	}
+	wants := []string{"async  function a(b) { return b }", "class C { static d() {} }", "d() {}", "x =>  x"}
+	for i, want := range wants {
+		if actuals[i] != want {
+			t.Errorf("expect: %q,actual: %q", want, actuals[i])
+		}
+	}
+}
@@ -0,0 +1,550 @@
+package regex
+
+import (
+	"errors"
+	"unicode"
+)
+
+// ErrDepthLimit is returned when a match nests too deeply, which happens
+// when a quantified group repeats very many times.
+var ErrDepthLimit = errors.New("Maximum call stack size exceeded")
+
+// maxDepth bounds the nesting of quantifier iterations, which all live on
+// the Go stack.
+const maxDepth = 200000
+
+type limitError struct {
+	err error
+}
+
+// machine holds the state of one call of Exec.
+type machine struct {
+	re    *Regexp
+	input []uint16
+	caps  []int
+	steps int
+	limit int
+	depth int
+}
+
+// node is a compiled piece of a pattern. match tries to match at pos and
+// calls k with the position after the match, backtracking into the node
+// whenever k fails.
+type node interface {
+	match(m *machine, pos int, k func(int) bool) bool
+}
+
+// single is a node that matches in at most one way, which lets quantifiers
+// iterate it without recursion.
+type single interface {
+	next(m *machine, pos int) (int, bool)
+}
+
+func (m *machine) exec(start int) (result []int, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if limit, ok := r.(limitError); ok {
+				result, err = nil, limit.err
+				return
+			}
+			panic(r)
+		}
+	}()
+	for ; start <= len(m.input); start = m.re.AdvanceIndex(m.input, start) {
+		for i := range m.caps {
+			m.caps[i] = -1
+		}
+		if m.re.root.match(m, start, func(end int) bool {
+			m.caps[0], m.caps[1] = start, end
+			return true
+		}) {
+			return m.caps, nil
+		}
+		if m.re.sticky {
+			break
+		}
+	}
+	return nil, nil
+}
+
+func (m *machine) step() {
+	m.steps++
+	if m.steps > m.limit {
+		panic(limitError{ErrStepLimit})
+	}
+}
+
+// read returns the character after pos and the position past it.
+func (m *machine) read(pos int) (rune, int, bool) {
+	if pos >= len(m.input) {
+		return 0, pos, false
+	}
+	c := m.input[pos]
+	if m.re.unicode && isLead(c) && pos+1 < len(m.input) && isTrail(m.input[pos+1]) {
+		return (rune(c)-0xD800)<<10 + rune(m.input[pos+1]) - 0xDC00 + 0x10000, pos + 2, true
+	}
+	return rune(c), pos + 1, true
+}
+
+// readBack returns the character before pos and the position before it.
+func (m *machine) readBack(pos int) (rune, int, bool) {
+	if pos <= 0 {
+		return 0, pos, false
+	}
+	c := m.input[pos-1]
+	if m.re.unicode && isTrail(c) && pos-2 >= 0 && isLead(m.input[pos-2]) {
+		return (rune(m.input[pos-2])-0xD800)<<10 + rune(c) - 0xDC00 + 0x10000, pos - 2, true
+	}
+	return rune(c), pos - 1, true
+}
+
+func (m *machine) readIn(pos int, backward bool) (rune, int, bool) {
+	if backward {
+		return m.readBack(pos)
+	}
+	return m.read(pos)
+}
+
+// canonicalize maps a character to the representative of its case class.
+func (m *machine) canonicalize(c rune) rune {
+	if !m.re.ignoreCase {
+		return c
+	}
+	return canonicalize(c, m.re.unicode)
+}
+
+func canonicalize(c rune, unicodeMode bool) rune {
+	if unicodeMode {
+		// The smallest member of the simple case folding orbit.
+		least := c
+		for r := unicode.SimpleFold(c); r != c; r = unicode.SimpleFold(r) {
+			if r < least {
+				least = r
+			}
+		}
+		return least
+	}
+	if hasFullUpperCase(c) {
+		return c
+	}
+	upper := unicode.ToUpper(c)
+	if c >= 128 && upper < 128 {
+		return c
+	}
+	return upper
+}
+
+// hasFullUpperCase reports whether the uppercase of c is several characters
+// although its simple uppercase mapping is one, as for the Greek letters
+// with ypogegrammeni.
+func hasFullUpperCase(c rune) bool {
+	switch c {
+	case 0x1FB3, 0x1FBC, 0x1FC3, 0x1FCC, 0x1FF3, 0x1FFC:
+		return true
+	}
+	return c >= 0x1F80 && c <= 0x1FAF
+}
+
+func isLineTerminator(c rune) bool {
+	return c == '\n' || c == '\r' || c == 0x2028 || c == 0x2029
+}
+
+func isSpace(c rune) bool {
+	switch c {
+	case '\t', '\n', '\v', '\f', '\r', ' ', 0xA0, 0x1680, 0x2028, 0x2029, 0x202F, 0x205F, 0x3000, 0xFEFF:
+		return true
+	}
+	return c >= 0x2000 && c <= 0x200A
+}
+
+type runeRange struct {
+	from rune
+	to   rune
+}
+
+var (
+	digitRanges       = []runeRange{{'0', '9'}}
+	wordRanges        = []runeRange{{'0', '9'}, {'A', 'Z'}, {'_', '_'}, {'a', 'z'}}
+	unicodeWordRanges = []runeRange{{'0', '9'}, {'A', 'Z'}, {'_', '_'}, {'a', 'z'}, {0x017F, 0x017F}, {0x212A, 0x212A}}
+)
+
+// charSet is a character class.
+type charSet struct {
+	ranges          []runeRange
+	properties      []property
+	sets            []*charSet
+	space           bool
+	lineTerminators bool
+	negate          bool
+}
+
+func (set *charSet) add(c rune, other *charSet) {
+	if other != nil {
+		set.sets = append(set.sets, other)
+		return
+	}
+	set.ranges = append(set.ranges, runeRange{c, c})
+}
+
+func (set *charSet) has(c rune) bool {
+	return set.contains(c) != set.negate
+}
+
+func (set *charSet) contains(c rune) bool {
+	for _, item := range set.ranges {
+		if c >= item.from && c <= item.to {
+			return true
+		}
+	}
+	for _, has := range set.properties {
+		if has(c) {
+			return true
+		}
+	}
+	for _, other := range set.sets {
+		if other.has(c) {
+			return true
+		}
+	}
+	return set.space && isSpace(c) || set.lineTerminators && isLineTerminator(c)
+}
+
+// matches reports whether c is in the set, ignoring case when asked to.
+func (set *charSet) matches(m *machine, c rune) bool {
+	if !m.re.ignoreCase {
+		return set.has(c)
+	}
+	if set.contains(c) {
+		return !set.negate
+	}
+	canonical := m.canonicalize(c)
+	for r := unicode.SimpleFold(c); r != c; r = unicode.SimpleFold(r) {
+		if m.canonicalize(r) == canonical && set.contains(r) {
+			return !set.negate
+		}
+	}
+	return set.negate
+}
+
+type charNode struct {
+	c        rune
+	backward bool
+}
+
+func (n *charNode) next(m *machine, pos int) (int, bool) {
+	c, next, ok := m.readIn(pos, n.backward)
+	if !ok || (c != n.c && m.canonicalize(c) != m.canonicalize(n.c)) {
+		return pos, false
+	}
+	return next, true
+}
+
+func (n *charNode) match(m *machine, pos int, k func(int) bool) bool {
+	m.step()
+	next, ok := n.next(m, pos)
+	return ok && k(next)
+}
+
+type classNode struct {
+	set      *charSet
+	backward bool
+}
+
+func (n *classNode) next(m *machine, pos int) (int, bool) {
+	c, next, ok := m.readIn(pos, n.backward)
+	if !ok || !n.set.matches(m, c) {
+		return pos, false
+	}
+	return next, true
+}
+
+func (n *classNode) match(m *machine, pos int, k func(int) bool) bool {
+	m.step()
+	next, ok := n.next(m, pos)
+	return ok && k(next)
+}
+
+type sequenceNode struct {
+	items    []node
+	backward bool
+}
+
+func (n *sequenceNode) match(m *machine, pos int, k func(int) bool) bool {
+	if n.backward {
+		return n.matchFrom(m, len(n.items)-1, -1, pos, k)
+	}
+	return n.matchFrom(m, 0, 1, pos, k)
+}
+
+func (n *sequenceNode) matchFrom(m *machine, i int, direction int, pos int, k func(int) bool) bool {
+	if i < 0 || i >= len(n.items) {
+		return k(pos)
+	}
+	return n.items[i].match(m, pos, func(next int) bool {
+		return n.matchFrom(m, i+direction, direction, next, k)
+	})
+}
+
+// stringNode is a sequence of characters, which needs no backtracking.
+type stringNode struct {
+	chars    []single
+	backward bool
+}
+
+func (n *stringNode) next(m *machine, pos int) (int, bool) {
+	for i := range n.chars {
+		if n.backward {
+			i = len(n.chars) - 1 - i
+		}
+		next, ok := n.chars[i].next(m, pos)
+		if !ok {
+			return pos, false
+		}
+		pos = next
+	}
+	return pos, true
+}
+
+func (n *stringNode) match(m *machine, pos int, k func(int) bool) bool {
+	m.step()
+	next, ok := n.next(m, pos)
+	return ok && k(next)
+}
+
+type alternationNode struct {
+	alternatives []node
+}
+
+func (n *alternationNode) match(m *machine, pos int, k func(int) bool) bool {
+	for _, alternative := range n.alternatives {
+		m.step()
+		if alternative.match(m, pos, k) {
+			return true
+		}
+	}
+	return false
+}
+
+type groupNode struct {
+	index    int
+	body     node
+	backward bool
+}
+
+func (n *groupNode) match(m *machine, pos int, k func(int) bool) bool {
+	m.step()
+	return n.body.match(m, pos, func(end int) bool {
+		start, stop := m.caps[2*n.index], m.caps[2*n.index+1]
+		if n.backward {
+			m.caps[2*n.index], m.caps[2*n.index+1] = end, pos
+		} else {
+			m.caps[2*n.index], m.caps[2*n.index+1] = pos, end
+		}
+		if k(end) {
+			return true
+		}
+		m.caps[2*n.index], m.caps[2*n.index+1] = start, stop
+		return false
+	})
+}
+
+type backrefNode struct {
+	name     string
+	indices  []int
+	backward bool
+}
+
+func (n *backrefNode) match(m *machine, pos int, k func(int) bool) bool {
+	m.step()
+	start, end := -1, -1
+	for _, index := range n.indices {
+		if m.caps[2*index] >= 0 {
+			start, end = m.caps[2*index], m.caps[2*index+1]
+			break
+		}
+	}
+	if start < 0 {
+		return k(pos)
+	}
+	length := end - start
+	from := pos
+	if n.backward {
+		from = pos - length
+		if from < 0 {
+			return false
+		}
+	} else if pos+length > len(m.input) {
+		return false
+	}
+	for i := 0; i < length; {
+		a, next, _ := m.read(start + i)
+		b, _, _ := m.read(from + i)
+		if a != b && m.canonicalize(a) != m.canonicalize(b) {
+			return false
+		}
+		i = next - start
+	}
+	if n.backward {
+		return k(from)
+	}
+	return k(pos + length)
+}
+
+type assertionKind int
+
+const (
+	assertStart assertionKind = iota
+	assertEnd
+	assertBoundary
+	assertNotBoundary
+)
+
+type assertionNode struct {
+	kind assertionKind
+}
+
+func (m *machine) isWordChar(pos int) bool {
+	if pos < 0 || pos >= len(m.input) {
+		return false
+	}
+	c := rune(m.input[pos])
+	for _, item := range wordRanges {
+		if c >= item.from && c <= item.to {
+			return true
+		}
+	}
+	return m.re.unicode && m.re.ignoreCase && (c == 0x017F || c == 0x212A)
+}
+
+func (n *assertionNode) match(m *machine, pos int, k func(int) bool) bool {
+	m.step()
+	var ok bool
+	switch n.kind {
+	case assertStart:
+		ok = pos == 0 || m.re.multiline && isLineTerminator(rune(m.input[pos-1]))
+	case assertEnd:
+		ok = pos == len(m.input) || m.re.multiline && isLineTerminator(rune(m.input[pos]))
+	case assertBoundary:
+		ok = m.isWordChar(pos-1) != m.isWordChar(pos)
+	case assertNotBoundary:
+		ok = m.isWordChar(pos-1) == m.isWordChar(pos)
+	}
+	return ok && k(pos)
+}
+
+// lookaroundNode is atomic: once its body matches, the matcher never
+// backtracks into it.
+type lookaroundNode struct {
+	body   node
+	negate bool
+}
+
+func (n *lookaroundNode) match(m *machine, pos int, k func(int) bool) bool {
+	m.step()
+	saved := append([]int(nil), m.caps...)
+	matched := n.body.match(m, pos, func(int) bool {
+		return true
+	})
+	if n.negate {
+		copy(m.caps, saved)
+		return !matched && k(pos)
+	}
+	if !matched {
+		return false
+	}
+	if k(pos) {
+		return true
+	}
+	copy(m.caps, saved)
+	return false
+}
+
+type quantifierNode struct {
+	body     node
+	min      int
+	max      int // -1 when unbounded
+	greedy   bool
+	capStart int // the groups inside the body, which reset on every iteration
+	capEnd   int
+}
+
+func (n *quantifierNode) match(m *machine, pos int, k func(int) bool) bool {
+	if body, ok := n.body.(single); ok {
+		return n.matchSingle(m, body, pos, k)
+	}
+	return n.repeat(m, pos, 0, k)
+}
+
+// matchSingle iterates a body that can not backtrack without recursion.
+func (n *quantifierNode) matchSingle(m *machine, body single, pos int, k func(int) bool) bool {
+	positions := []int{pos}
+	if !n.greedy {
+		for count := 0; ; count++ {
+			m.step()
+			if count >= n.min && k(pos) {
+				return true
+			}
+			if n.max >= 0 && count >= n.max {
+				return false
+			}
+			next, ok := body.next(m, pos)
+			if !ok {
+				return false
+			}
+			pos = next
+		}
+	}
+	for n.max < 0 || len(positions) <= n.max {
+		m.step()
+		next, ok := body.next(m, pos)
+		if !ok {
+			break
+		}
+		pos = next
+		positions = append(positions, pos)
+	}
+	for count := len(positions) - 1; count >= n.min; count-- {
+		m.step()
+		if k(positions[count]) {
+			return true
+		}
+	}
+	return false
+}
+
+func (n *quantifierNode) repeat(m *machine, pos int, count int, k func(int) bool) bool {
+	m.step()
+	if n.max >= 0 && count >= n.max {
+		return k(pos)
+	}
+	iterate := func() bool {
+		m.depth++
+		if m.depth > maxDepth {
+			panic(limitError{ErrDepthLimit})
+		}
+		defer func() {
+			m.depth--
+		}()
+		saved := append([]int(nil), m.caps[2*n.capStart:2*n.capEnd]...)
+		for i := 2 * n.capStart; i < 2*n.capEnd; i++ {
+			m.caps[i] = -1
+		}
+		if n.body.match(m, pos, func(next int) bool {
+			if next == pos && count >= n.min {
+				return false
+			}
+			return n.repeat(m, next, count+1, k)
+		}) {
+			return true
+		}
+		copy(m.caps[2*n.capStart:], saved)
+		return false
+	}
+	if count < n.min {
+		return iterate()
+	}
+	if n.greedy {
+		return iterate() || k(pos)
+	}
+	return k(pos) || iterate()
+}
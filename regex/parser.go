@@ -0,0 +1,757 @@
+package regex
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+)
+
+// parser turns a pattern into a tree of nodes. In unicode mode the pattern
+// is read as code points, otherwise as UTF-16 code units.
+type parser struct {
+	re       *Regexp
+	src      []rune
+	pos      int
+	total    int  // number of capture groups in the whole pattern
+	named    bool // whether the pattern has named groups
+	backward bool // whether the nodes being built match right to left
+	refs     []*backrefNode
+}
+
+func parse(re *Regexp) error {
+	p := &parser{re: re}
+	if re.unicode {
+		p.src = []rune(re.source)
+	} else {
+		for _, c := range utf16.Encode([]rune(re.source)) {
+			p.src = append(p.src, rune(c))
+		}
+	}
+	p.total, p.named = p.scanGroups()
+	var err error
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				if message, ok := r.(patternError); ok {
+					err = errors.New(string(message))
+					return
+				}
+				panic(r)
+			}
+		}()
+		root := p.disjunction()
+		if p.more() {
+			p.fail("Unmatched ')'")
+		}
+		for _, ref := range p.refs {
+			if ref.name == "" {
+				continue
+			}
+			for i, name := range re.names {
+				if name == ref.name {
+					ref.indices = append(ref.indices, i+1)
+				}
+			}
+			if len(ref.indices) == 0 {
+				p.fail("Invalid named capture referenced")
+			}
+		}
+		re.root = root
+	}()
+	return err
+}
+
+type patternError string
+
+func (p *parser) fail(message string) {
+	panic(patternError(message))
+}
+
+func (p *parser) more() bool {
+	return p.pos < len(p.src)
+}
+
+func (p *parser) peek() rune {
+	if p.pos < len(p.src) {
+		return p.src[p.pos]
+	}
+	return -1
+}
+
+func (p *parser) peekAt(offset int) rune {
+	if p.pos+offset < len(p.src) {
+		return p.src[p.pos+offset]
+	}
+	return -1
+}
+
+func (p *parser) lookingAt(text string) bool {
+	i := p.pos
+	for _, c := range text {
+		if i >= len(p.src) || p.src[i] != c {
+			return false
+		}
+		i++
+	}
+	return true
+}
+
+// scanGroups counts the capture groups ahead of parsing, since a
+// backreference may come before the group it names.
+func (p *parser) scanGroups() (int, bool) {
+	count := 0
+	named := false
+	inClass := false
+	for i := 0; i < len(p.src); i++ {
+		switch p.src[i] {
+		case '\\':
+			i++
+		case '[':
+			inClass = true
+		case ']':
+			inClass = false
+		case '(':
+			if inClass {
+				continue
+			}
+			if i+1 < len(p.src) && p.src[i+1] == '?' {
+				if i+2 < len(p.src) && p.src[i+2] == '<' && i+3 < len(p.src) && p.src[i+3] != '=' && p.src[i+3] != '!' {
+					count++
+					named = true
+				}
+				continue
+			}
+			count++
+		}
+	}
+	return count, named
+}
+
+func (p *parser) disjunction() node {
+	alternatives := []node{p.alternative()}
+	for p.peek() == '|' {
+		p.pos++
+		alternatives = append(alternatives, p.alternative())
+	}
+	if len(alternatives) == 1 {
+		return alternatives[0]
+	}
+	return &alternationNode{alternatives: alternatives}
+}
+
+func (p *parser) alternative() node {
+	var items []node
+	for p.more() && p.peek() != '|' && p.peek() != ')' {
+		items = append(items, p.term())
+	}
+	if len(items) == 1 {
+		return items[0]
+	}
+	chars := make([]single, 0, len(items))
+	for _, item := range items {
+		if char, ok := item.(single); ok {
+			chars = append(chars, char)
+		}
+	}
+	if len(items) > 1 && len(chars) == len(items) {
+		return &stringNode{chars: chars, backward: p.backward}
+	}
+	return &sequenceNode{items: items, backward: p.backward}
+}
+
+func (p *parser) term() node {
+	switch {
+	case p.peek() == '^':
+		p.pos++
+		return p.noQuantifier(&assertionNode{kind: assertStart})
+	case p.peek() == '$':
+		p.pos++
+		return p.noQuantifier(&assertionNode{kind: assertEnd})
+	case p.lookingAt(`\b`):
+		p.pos += 2
+		return p.noQuantifier(&assertionNode{kind: assertBoundary})
+	case p.lookingAt(`\B`):
+		p.pos += 2
+		return p.noQuantifier(&assertionNode{kind: assertNotBoundary})
+	case p.lookingAt("(?<=") || p.lookingAt("(?<!"):
+		negate := p.src[p.pos+3] == '!'
+		p.pos += 4
+		look := p.lookaround(false, negate)
+		if p.isQuantifier() {
+			p.fail("Invalid quantifier")
+		}
+		return look
+	case p.lookingAt("(?=") || p.lookingAt("(?!"):
+		negate := p.src[p.pos+2] == '!'
+		p.pos += 3
+		start := p.groupCount()
+		look := p.lookaround(true, negate)
+		if p.re.unicode {
+			if p.isQuantifier() {
+				p.fail("Invalid quantifier")
+			}
+			return look
+		}
+		return p.quantifier(look, start)
+	}
+	start := p.groupCount()
+	atom := p.atom()
+	return p.quantifier(atom, start)
+}
+
+func (p *parser) groupCount() int {
+	return len(p.re.names)
+}
+
+// noQuantifier rejects a quantifier after an assertion.
+func (p *parser) noQuantifier(item node) node {
+	if p.isQuantifier() {
+		p.fail("Nothing to repeat")
+	}
+	return item
+}
+
+func (p *parser) isQuantifier() bool {
+	switch p.peek() {
+	case '*', '+', '?':
+		return true
+	case '{':
+		save := p.pos
+		_, _, ok := p.braces()
+		p.pos = save
+		return ok
+	}
+	return false
+}
+
+func (p *parser) lookaround(ahead bool, negate bool) node {
+	backward := p.backward
+	p.backward = !ahead
+	body := p.disjunction()
+	p.backward = backward
+	if p.peek() != ')' {
+		p.fail("Unterminated group")
+	}
+	p.pos++
+	return &lookaroundNode{body: body, negate: negate}
+}
+
+// braces reads a {n}, {n,} or {n,m} quantifier.
+func (p *parser) braces() (int, int, bool) {
+	p.pos++
+	min, ok := p.decimal()
+	if !ok {
+		return 0, 0, false
+	}
+	max := min
+	if p.peek() == ',' {
+		p.pos++
+		max = -1
+		if value, ok := p.decimal(); ok {
+			max = value
+		}
+	}
+	if p.peek() != '}' {
+		return 0, 0, false
+	}
+	p.pos++
+	return min, max, true
+}
+
+// decimal reads a decimal number, saturating at math.MaxInt32.
+func (p *parser) decimal() (int, bool) {
+	start := p.pos
+	value := 0
+	for p.peek() >= '0' && p.peek() <= '9' {
+		if value < math.MaxInt32 {
+			value = value*10 + int(p.peek()-'0')
+		}
+		if value > math.MaxInt32 {
+			value = math.MaxInt32
+		}
+		p.pos++
+	}
+	return value, p.pos > start
+}
+
+func (p *parser) quantifier(atom node, start int) node {
+	var min, max int
+	switch p.peek() {
+	case '*':
+		p.pos++
+		min, max = 0, -1
+	case '+':
+		p.pos++
+		min, max = 1, -1
+	case '?':
+		p.pos++
+		min, max = 0, 1
+	case '{':
+		save := p.pos
+		var ok bool
+		min, max, ok = p.braces()
+		if !ok {
+			if p.re.unicode {
+				p.fail("Incomplete quantifier")
+			}
+			p.pos = save
+			return atom
+		}
+		if max != -1 && min > max {
+			p.fail("numbers out of order in {} quantifier")
+		}
+	default:
+		return atom
+	}
+	greedy := true
+	if p.peek() == '?' {
+		p.pos++
+		greedy = false
+	}
+	return &quantifierNode{
+		body:     atom,
+		min:      min,
+		max:      max,
+		greedy:   greedy,
+		capStart: start + 1,
+		capEnd:   p.groupCount() + 1,
+	}
+}
+
+func (p *parser) atom() node {
+	c := p.peek()
+	switch c {
+	case '.':
+		p.pos++
+		return p.class(&charSet{lineTerminators: !p.re.dotAll, negate: true})
+	case '(':
+		return p.group()
+	case ')':
+		p.fail("Unmatched ')'")
+	case '[':
+		return p.class(p.classBody())
+	case '\\':
+		return p.atomEscape()
+	case '*', '+', '?':
+		p.fail("Nothing to repeat")
+	case '{':
+		if p.re.unicode {
+			p.fail("Lone quantifier brackets")
+		}
+		if p.isQuantifier() {
+			p.fail("Nothing to repeat")
+		}
+	case '}', ']':
+		if p.re.unicode {
+			p.fail("Lone quantifier brackets")
+		}
+	}
+	p.pos++
+	return p.char(c)
+}
+
+func (p *parser) char(c rune) node {
+	return &charNode{c: c, backward: p.backward}
+}
+
+func (p *parser) class(set *charSet) node {
+	return &classNode{set: set, backward: p.backward}
+}
+
+func (p *parser) group() node {
+	p.pos++
+	name := ""
+	capture := true
+	if p.peek() == '?' {
+		switch {
+		case p.lookingAt("?:"):
+			p.pos += 2
+			capture = false
+		case p.lookingAt("?<"):
+			p.pos += 2
+			name = p.groupName()
+			for _, existing := range p.re.names {
+				if existing == name {
+					p.fail("Duplicate capture group name")
+				}
+			}
+		default:
+			p.fail("Invalid group")
+		}
+	}
+	index := 0
+	if capture {
+		p.re.names = append(p.re.names, name)
+		p.re.groups++
+		index = p.re.groups
+	}
+	body := p.disjunction()
+	if p.peek() != ')' {
+		p.fail("Unterminated group")
+	}
+	p.pos++
+	if !capture {
+		return body
+	}
+	return &groupNode{index: index, body: body, backward: p.backward}
+}
+
+// groupName reads an identifier up to the closing '>'.
+func (p *parser) groupName() string {
+	var name []rune
+	for {
+		c := p.peek()
+		if c == '>' && len(name) > 0 {
+			p.pos++
+			break
+		}
+		if c == '\\' && p.peekAt(1) == 'u' {
+			p.pos += 2
+			value, ok := p.unicodeEscape(true)
+			if !ok {
+				p.fail("Invalid Unicode escape sequence")
+			}
+			c = value
+		} else {
+			p.pos++
+			if !p.re.unicode && isLeadRune(c) && isTrailRune(p.peek()) {
+				c = utf16.DecodeRune(c, p.peek())
+				p.pos++
+			}
+		}
+		if c < 0 || !isIdentifierPart(c, len(name) == 0) {
+			p.fail("Invalid capture group name")
+		}
+		name = append(name, c)
+	}
+	return string(name)
+}
+
+func isIdentifierPart(c rune, first bool) bool {
+	if c == '$' || c == '_' || unicode.IsLetter(c) || unicode.Is(unicode.Nl, c) || unicode.Is(unicode.Other_ID_Start, c) {
+		return true
+	}
+	if first {
+		return false
+	}
+	return c == 0x200C || c == 0x200D || unicode.In(c, unicode.Mn, unicode.Mc, unicode.Nd, unicode.Pc, unicode.Other_ID_Continue)
+}
+
+func isLeadRune(c rune) bool {
+	return c >= 0xD800 && c <= 0xDBFF
+}
+
+func isTrailRune(c rune) bool {
+	return c >= 0xDC00 && c <= 0xDFFF
+}
+
+func isSyntaxCharacter(c rune) bool {
+	return strings.ContainsRune(`^$\.*+?()[]{}|/`, c)
+}
+
+func (p *parser) atomEscape() node {
+	p.pos++
+	if !p.more() {
+		p.fail(`\ at end of pattern`)
+	}
+	c := p.peek()
+	if c >= '1' && c <= '9' {
+		save := p.pos
+		n, _ := p.decimal()
+		if n <= p.total {
+			ref := &backrefNode{indices: []int{n}, backward: p.backward}
+			return ref
+		}
+		if p.re.unicode {
+			p.fail("Invalid escape")
+		}
+		p.pos = save
+	}
+	if c == 'k' && (p.re.unicode || p.named) {
+		p.pos++
+		if p.peek() != '<' {
+			p.fail("Invalid named reference")
+		}
+		p.pos++
+		name := p.groupName()
+		ref := &backrefNode{name: name, backward: p.backward}
+		p.refs = append(p.refs, ref)
+		return ref
+	}
+	if set := p.classEscape(); set != nil {
+		return p.class(set)
+	}
+	return p.char(p.characterEscape(false))
+}
+
+// classEscape reads \d \D \s \S \w \W and, in unicode mode, \p{...} and
+// \P{...}. It returns nil for any other escape.
+func (p *parser) classEscape() *charSet {
+	c := p.peek()
+	switch c {
+	case 'd', 'D':
+		p.pos++
+		return &charSet{ranges: digitRanges, negate: c == 'D'}
+	case 's', 'S':
+		p.pos++
+		return &charSet{space: true, negate: c == 'S'}
+	case 'w', 'W':
+		p.pos++
+		ranges := wordRanges
+		if p.re.unicode && p.re.ignoreCase {
+			ranges = unicodeWordRanges
+		}
+		return &charSet{ranges: ranges, negate: c == 'W'}
+	case 'p', 'P':
+		if !p.re.unicode {
+			return nil
+		}
+		p.pos++
+		if p.peek() != '{' {
+			p.fail("Invalid property name")
+		}
+		p.pos++
+		start := p.pos
+		for p.more() && p.peek() != '}' {
+			p.pos++
+		}
+		if !p.more() {
+			p.fail("Invalid property name")
+		}
+		name := string(p.src[start:p.pos])
+		p.pos++
+		has := propertyOf(name)
+		if has == nil {
+			p.fail("Invalid property name")
+		}
+		return &charSet{properties: []property{has}, negate: c == 'P'}
+	}
+	return nil
+}
+
+// characterEscape reads the escape after a backslash that stands for a
+// single character. inClass allows \b and \- of character classes.
+func (p *parser) characterEscape(inClass bool) rune {
+	c := p.peek()
+	p.pos++
+	switch c {
+	case 't':
+		return '\t'
+	case 'n':
+		return '\n'
+	case 'v':
+		return '\v'
+	case 'f':
+		return '\f'
+	case 'r':
+		return '\r'
+	case 'b':
+		if inClass {
+			return '\b'
+		}
+	case '-':
+		if inClass {
+			return '-'
+		}
+	case 'c':
+		letter := p.peek()
+		if letter >= 'a' && letter <= 'z' || letter >= 'A' && letter <= 'Z' {
+			p.pos++
+			return letter % 32
+		}
+		if !p.re.unicode && inClass && (letter >= '0' && letter <= '9' || letter == '_') {
+			p.pos++
+			return letter % 32
+		}
+		if p.re.unicode {
+			p.fail("Invalid Unicode escape")
+		}
+		p.pos--
+		return '\\'
+	case '0':
+		if next := p.peek(); next < '0' || next > '9' {
+			return 0
+		}
+		if p.re.unicode {
+			if inClass {
+				p.fail("Invalid class escape")
+			}
+			p.fail("Invalid decimal escape")
+		}
+		p.pos--
+		return p.octal()
+	case 'x':
+		if value, ok := p.hex(2); ok {
+			return value
+		}
+		if p.re.unicode {
+			p.fail("Invalid escape")
+		}
+		return 'x'
+	case 'u':
+		if value, ok := p.unicodeEscape(p.re.unicode); ok {
+			return value
+		}
+		if p.re.unicode {
+			p.fail("Invalid Unicode escape")
+		}
+		return 'u'
+	}
+	if p.re.unicode {
+		if isSyntaxCharacter(c) {
+			return c
+		}
+		p.fail("Invalid escape")
+	}
+	if c >= '1' && c <= '7' {
+		p.pos--
+		return p.octal()
+	}
+	return c
+}
+
+// octal reads a legacy octal escape of at most three digits below 0400.
+func (p *parser) octal() rune {
+	value := rune(0)
+	for i := 0; i < 3; i++ {
+		c := p.peek()
+		if c < '0' || c > '7' || value*8+(c-'0') > 0377 {
+			break
+		}
+		value = value*8 + (c - '0')
+		p.pos++
+	}
+	return value
+}
+
+func (p *parser) hex(digits int) (rune, bool) {
+	value := rune(0)
+	for i := 0; i < digits; i++ {
+		digit := hexDigit(p.peekAt(i))
+		if digit < 0 {
+			return 0, false
+		}
+		value = value*16 + digit
+	}
+	p.pos += digits
+	return value, true
+}
+
+func hexDigit(c rune) rune {
+	switch {
+	case c >= '0' && c <= '9':
+		return c - '0'
+	case c >= 'a' && c <= 'f':
+		return c - 'a' + 10
+	case c >= 'A' && c <= 'F':
+		return c - 'A' + 10
+	}
+	return -1
+}
+
+// unicodeEscape reads the part of a \u escape after the u. With full set it
+// accepts \u{...} and joins an escaped surrogate pair.
+func (p *parser) unicodeEscape(full bool) (rune, bool) {
+	if full && p.peek() == '{' {
+		save := p.pos
+		p.pos++
+		value := rune(0)
+		digits := 0
+		for hexDigit(p.peek()) >= 0 {
+			value = value*16 + hexDigit(p.peek())
+			if value > unicode.MaxRune {
+				p.pos = save
+				return 0, false
+			}
+			digits++
+			p.pos++
+		}
+		if digits == 0 || p.peek() != '}' {
+			p.pos = save
+			return 0, false
+		}
+		p.pos++
+		return value, true
+	}
+	value, ok := p.hex(4)
+	if !ok {
+		return 0, false
+	}
+	if full && isLeadRune(value) && p.lookingAt(`\u`) {
+		save := p.pos
+		p.pos += 2
+		if trail, ok := p.hex(4); ok && isTrailRune(trail) {
+			return utf16.DecodeRune(value, trail), true
+		}
+		p.pos = save
+	}
+	return value, true
+}
+
+// classBody reads a character class from its opening bracket.
+func (p *parser) classBody() *charSet {
+	p.pos++
+	set := &charSet{}
+	if p.peek() == '^' {
+		p.pos++
+		set.negate = true
+	}
+	for {
+		if !p.more() {
+			p.fail("Unterminated character class")
+		}
+		if p.peek() == ']' {
+			p.pos++
+			return set
+		}
+		from, fromSet := p.classAtom()
+		if p.peek() != '-' || p.peekAt(1) == ']' || p.peekAt(1) == -1 {
+			set.add(from, fromSet)
+			continue
+		}
+		p.pos++
+		to, toSet := p.classAtom()
+		if fromSet != nil || toSet != nil {
+			if p.re.unicode {
+				p.fail("Invalid character class")
+			}
+			set.add(from, fromSet)
+			set.add('-', nil)
+			set.add(to, toSet)
+			continue
+		}
+		if from > to {
+			p.fail("Range out of order in character class")
+		}
+		set.ranges = append(set.ranges, runeRange{from, to})
+	}
+}
+
+// classAtom reads one character of a class, or a class escape.
+func (p *parser) classAtom() (rune, *charSet) {
+	c := p.peek()
+	if c == -1 {
+		p.fail("Unterminated character class")
+	}
+	if c != '\\' {
+		p.pos++
+		return c, nil
+	}
+	p.pos++
+	if !p.more() {
+		p.fail(`\ at end of pattern`)
+	}
+	if set := p.classEscape(); set != nil {
+		return 0, set
+	}
+	if c := p.peek(); !p.re.unicode && c >= '8' && c <= '9' {
+		p.pos++
+		return c, nil
+	}
+	if p.re.unicode && p.peek() >= '1' && p.peek() <= '9' {
+		p.fail("Invalid class escape")
+	}
+	return p.characterEscape(true), nil
+}
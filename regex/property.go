@@ -0,0 +1,147 @@
+package regex
+
+import (
+	"strings"
+	"unicode"
+)
+
+// categoryAliases maps the long General_Category names to the short ones
+// the unicode package uses.
+var categoryAliases = map[string]string{
+	"Letter":                "L",
+	"Cased_Letter":          "LC",
+	"Uppercase_Letter":      "Lu",
+	"Lowercase_Letter":      "Ll",
+	"Titlecase_Letter":      "Lt",
+	"Modifier_Letter":       "Lm",
+	"Other_Letter":          "Lo",
+	"Mark":                  "M",
+	"Combining_Mark":        "M",
+	"Nonspacing_Mark":       "Mn",
+	"Spacing_Mark":          "Mc",
+	"Enclosing_Mark":        "Me",
+	"Number":                "N",
+	"Decimal_Number":        "Nd",
+	"digit":                 "Nd",
+	"Letter_Number":         "Nl",
+	"Other_Number":          "No",
+	"Punctuation":           "P",
+	"punct":                 "P",
+	"Connector_Punctuation": "Pc",
+	"Dash_Punctuation":      "Pd",
+	"Open_Punctuation":      "Ps",
+	"Close_Punctuation":     "Pe",
+	"Initial_Punctuation":   "Pi",
+	"Final_Punctuation":     "Pf",
+	"Other_Punctuation":     "Po",
+	"Symbol":                "S",
+	"Math_Symbol":           "Sm",
+	"Currency_Symbol":       "Sc",
+	"Modifier_Symbol":       "Sk",
+	"Other_Symbol":          "So",
+	"Separator":             "Z",
+	"Space_Separator":       "Zs",
+	"Line_Separator":        "Zl",
+	"Paragraph_Separator":   "Zp",
+	"Other":                 "C",
+	"Control":               "Cc",
+	"cntrl":                 "Cc",
+	"Format":                "Cf",
+	"Surrogate":             "Cs",
+	"Private_Use":           "Co",
+	"Unassigned":            "Cn",
+}
+
+// scriptAliases maps the four letter script codes to script names.
+var scriptAliases = map[string]string{
+	"Adlm": "Adlam", "Arab": "Arabic", "Armn": "Armenian", "Beng": "Bengali",
+	"Bopo": "Bopomofo", "Brai": "Braille", "Cher": "Cherokee", "Copt": "Coptic",
+	"Cyrl": "Cyrillic", "Deva": "Devanagari", "Ethi": "Ethiopic", "Geor": "Georgian",
+	"Goth": "Gothic", "Grek": "Greek", "Gujr": "Gujarati", "Guru": "Gurmukhi",
+	"Hang": "Hangul", "Hani": "Han", "Hebr": "Hebrew", "Hira": "Hiragana",
+	"Kana": "Katakana", "Khmr": "Khmer", "Knda": "Kannada", "Laoo": "Lao",
+	"Latn": "Latin", "Mlym": "Malayalam", "Mong": "Mongolian", "Mymr": "Myanmar",
+	"Orya": "Oriya", "Runr": "Runic", "Sinh": "Sinhala", "Syrc": "Syriac",
+	"Taml": "Tamil", "Telu": "Telugu", "Tfng": "Tifinagh", "Thaa": "Thaana",
+	"Thai": "Thai", "Tibt": "Tibetan", "Zinh": "Inherited", "Zyyy": "Common",
+	"Qaai": "Inherited", "Zzzz": "Unknown",
+}
+
+// property reports whether a character has a Unicode property.
+type property func(rune) bool
+
+func inTables(tables ...*unicode.RangeTable) property {
+	return func(c rune) bool {
+		return unicode.In(c, tables...)
+	}
+}
+
+var assigned = inTables(unicode.L, unicode.M, unicode.N, unicode.P, unicode.S, unicode.Z, unicode.C)
+
+// derivedProperties are the binary properties the unicode package has no
+// table for.
+var derivedProperties = map[string]property{
+	"Any": func(c rune) bool {
+		return true
+	},
+	"ASCII": func(c rune) bool {
+		return c < 0x80
+	},
+	"Assigned":    assigned,
+	"Alphabetic":  inTables(unicode.L, unicode.Nl, unicode.Other_Alphabetic),
+	"Alpha":       inTables(unicode.L, unicode.Nl, unicode.Other_Alphabetic),
+	"Lowercase":   inTables(unicode.Ll, unicode.Other_Lowercase),
+	"Lower":       inTables(unicode.Ll, unicode.Other_Lowercase),
+	"Uppercase":   inTables(unicode.Lu, unicode.Other_Uppercase),
+	"Upper":       inTables(unicode.Lu, unicode.Other_Uppercase),
+	"Cased":       inTables(unicode.Lu, unicode.Ll, unicode.Lt, unicode.Other_Lowercase, unicode.Other_Uppercase),
+	"Math":        inTables(unicode.Sm, unicode.Other_Math),
+	"White_Space": inTables(unicode.White_Space),
+	"space":       inTables(unicode.White_Space),
+}
+
+// propertyOf resolves the name of a \p{...} escape.
+func propertyOf(name string) property {
+	if key, value, ok := strings.Cut(name, "="); ok {
+		switch key {
+		case "General_Category", "gc":
+			return category(value)
+		case "Script", "sc", "Script_Extensions", "scx":
+			if alias, ok := scriptAliases[value]; ok {
+				value = alias
+			}
+			if table, ok := unicode.Scripts[value]; ok {
+				return inTables(table)
+			}
+		}
+		return nil
+	}
+	if result := category(name); result != nil {
+		return result
+	}
+	if result, ok := derivedProperties[name]; ok {
+		return result
+	}
+	if table, ok := unicode.Properties[name]; ok && !strings.HasPrefix(name, "Other_") {
+		return inTables(table)
+	}
+	return nil
+}
+
+func category(name string) property {
+	if alias, ok := categoryAliases[name]; ok {
+		name = alias
+	}
+	switch name {
+	case "LC":
+		return inTables(unicode.Lu, unicode.Ll, unicode.Lt)
+	case "Cn":
+		return func(c rune) bool {
+			return !assigned(c)
+		}
+	}
+	if table, ok := unicode.Categories[name]; ok {
+		return inTables(table)
+	}
+	return nil
+}
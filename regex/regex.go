@@ -0,0 +1,161 @@
+// Package regex implements JavaScript regular expressions with a
+// backtracking matcher over UTF-16 code units.
+//
+// Unlike the RE2 engine of the standard library it supports backreferences
+// and lookbehind, so a match can take exponential time. Every match runs on
+// a step budget and fails with ErrStepLimit once the budget is spent.
+package regex
+
+import (
+	"errors"
+	"strings"
+)
+
+// StepLimit is the default number of steps one call of Exec may take,
+// counting every start position it tries.
+var StepLimit = 10000000
+
+// ErrStepLimit is returned when a match exceeds its step budget.
+var ErrStepLimit = errors.New("Maximum regular expression backtracking steps exceeded")
+
+// SyntaxError reports an invalid pattern or invalid flags.
+type SyntaxError struct {
+	Message string
+}
+
+func (err *SyntaxError) Error() string {
+	return err.Message
+}
+
+// Regexp is a compiled regular expression. It is safe to use from several
+// goroutines.
+type Regexp struct {
+	source     string
+	flags      string
+	global     bool
+	ignoreCase bool
+	multiline  bool
+	dotAll     bool
+	unicode    bool
+	sticky     bool
+	hasIndices bool
+	root       node
+	groups     int
+	names      []string // names[i] is the name of group i+1, or ""
+	// StepLimit overrides the package StepLimit when it is positive.
+	StepLimit int
+}
+
+// flagOrder is the order in which the flags property lists the flags.
+const flagOrder = "dgimsuy"
+
+// ValidFlags reports whether flags is a valid flags string.
+func ValidFlags(flags string) bool {
+	for i, c := range flags {
+		if !strings.ContainsRune(flagOrder, c) || strings.ContainsRune(flags[i+1:], c) {
+			return false
+		}
+	}
+	return true
+}
+
+// Compile parses a pattern with the given flags.
+func Compile(source string, flags string) (*Regexp, error) {
+	if !ValidFlags(flags) {
+		return nil, &SyntaxError{Message: "Invalid flags supplied to RegExp constructor '" + flags + "'"}
+	}
+	re := &Regexp{
+		source:     source,
+		flags:      flags,
+		global:     strings.Contains(flags, "g"),
+		ignoreCase: strings.Contains(flags, "i"),
+		multiline:  strings.Contains(flags, "m"),
+		dotAll:     strings.Contains(flags, "s"),
+		unicode:    strings.Contains(flags, "u"),
+		sticky:     strings.Contains(flags, "y"),
+		hasIndices: strings.Contains(flags, "d"),
+	}
+	if err := parse(re); err != nil {
+		return nil, &SyntaxError{Message: "Invalid regular expression: /" + source + "/" + flags + ": " + err.Error()}
+	}
+	return re, nil
+}
+
+// Source returns the pattern text.
+func (re *Regexp) Source() string {
+	return re.source
+}
+
+// Flags returns the flags in canonical order.
+func (re *Regexp) Flags() string {
+	var result strings.Builder
+	for _, c := range flagOrder {
+		if strings.ContainsRune(re.flags, c) {
+			result.WriteRune(c)
+		}
+	}
+	return result.String()
+}
+
+func (re *Regexp) Global() bool     { return re.global }
+func (re *Regexp) IgnoreCase() bool { return re.ignoreCase }
+func (re *Regexp) Multiline() bool  { return re.multiline }
+func (re *Regexp) DotAll() bool     { return re.dotAll }
+func (re *Regexp) Unicode() bool    { return re.unicode }
+func (re *Regexp) Sticky() bool     { return re.sticky }
+func (re *Regexp) HasIndices() bool { return re.hasIndices }
+
+// Groups returns the number of capture groups.
+func (re *Regexp) Groups() int {
+	return re.groups
+}
+
+// GroupNames returns the name of every capture group, "" when it has none.
+func (re *Regexp) GroupNames() []string {
+	return re.names
+}
+
+// HasNamedGroups reports whether any capture group has a name.
+func (re *Regexp) HasNamedGroups() bool {
+	for _, name := range re.names {
+		if name != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Exec searches input from index start, or only at start for a sticky
+// expression. The result holds the start and end of the match and of every
+// capture group, with -1 for groups that did not participate, or nil when
+// there is no match.
+func (re *Regexp) Exec(input []uint16, start int) ([]int, error) {
+	limit := re.StepLimit
+	if limit <= 0 {
+		limit = StepLimit
+	}
+	m := &machine{
+		re:    re,
+		input: input,
+		caps:  make([]int, 2*(re.groups+1)),
+		limit: limit,
+	}
+	return m.exec(start)
+}
+
+// AdvanceIndex returns the index after the character at index, which is a
+// whole surrogate pair in unicode mode.
+func (re *Regexp) AdvanceIndex(input []uint16, index int) int {
+	if re.unicode && index+1 < len(input) && isLead(input[index]) && isTrail(input[index+1]) {
+		return index + 2
+	}
+	return index + 1
+}
+
+func isLead(c uint16) bool {
+	return c >= 0xD800 && c <= 0xDBFF
+}
+
+func isTrail(c uint16) bool {
+	return c >= 0xDC00 && c <= 0xDFFF
+}
@@ -0,0 +1,116 @@
+package regex
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf16"
+)
+
+func TestExec(t *testing.T) {
+	tests := []struct {
+		pattern string
+		flags   string
+		input   string
+		want    []int
+	}{
+		{"a+", "", "baaab", []int{1, 4}},
+		{"a*?b", "", "aaab", []int{0, 4}},
+		{"(a|ab)(c|bcd)(d*)", "", "abcd", []int{0, 4, 0, 1, 1, 4, 4, 4}},
+		{"(z)((a+)?(b+)?(c))*", "", "zaacbbbcac", []int{0, 10, 0, 1, 8, 10, 8, 9, -1, -1, 9, 10}},
+		{"(a*)*b", "", "aab", []int{0, 3, 0, 2}},
+		{"(?=(a+))a*b\\1", "", "baaabac", []int{3, 6, 3, 4}},
+		{"(.*?)a(?!(a+)b\\2c)\\2(.*)", "", "baaabaac", []int{0, 8, 0, 2, -1, -1, 3, 8}},
+		{"^abc$", "m", "x\nabc\ny", []int{2, 5}},
+		{"\\bfoo\\b", "", "a foo b", []int{2, 5}},
+		{"(?<=\\$)\\d+(\\.\\d*)?", "", "cost $10.53", []int{6, 11, 8, 11}},
+		{"(?<=(\\d+)(\\d+))$", "", "1053", []int{4, 4, 0, 1, 1, 4}},
+		{"(?<=\\1(a))b", "", "aab", []int{2, 3, 1, 2}},
+		{"(?<a>.)(?<b>.)\\k<b>\\k<a>", "", "xyyx", []int{0, 4, 0, 1, 1, 2}},
+		{"(a)\\1", "i", "aA", []int{0, 2, 0, 1}},
+		{"\\1(a)", "", "aa", []int{0, 1, 0, 1}},
+		{"(\\2two|(one))+", "", "oneonetwo", []int{0, 9, 6, 9, -1, -1}},
+		{"a.c", "", "a😀c", nil},
+		{"a.c", "u", "a😀c", []int{0, 4}},
+		{"^.$", "u", "😀", []int{0, 2}},
+		{".", "", "\n", nil},
+		{".", "s", "\n", []int{0, 1}},
+		{"\\p{Lu}+", "u", "abcDEFg", []int{3, 6}},
+		{"\\P{L}+", "u", "ab12cd", []int{2, 4}},
+		{"[😀-🙏]", "u", "hi 😃", []int{3, 5}},
+		{"ſ", "i", "s", nil},
+		{"ſ", "iu", "S", []int{0, 1}},
+		{"[^k]", "iu", "K", nil},
+		{"\\w", "iu", "ſ", []int{0, 1}},
+		{"[\\d-z]+", "", "a-z1", []int{1, 4}},
+		{"a{,3}", "", "a{,3}", []int{0, 5}},
+		{"\\8\\101", "", "8A", []int{0, 2}},
+		{"\\c", "", "\\c", []int{0, 2}},
+		{"a", "y", "ba", nil},
+	}
+	for _, tt := range tests {
+		re, err := Compile(tt.pattern, tt.flags)
+		if err != nil {
+			t.Errorf("Compile(%q, %q) error: %v", tt.pattern, tt.flags, err)
+			continue
+		}
+		got, err := re.Exec(utf16.Encode([]rune(tt.input)), 0)
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("/%s/%s.Exec(%q) actual = %v %v, expect= %v", tt.pattern, tt.flags, tt.input, got, err, tt.want)
+		}
+	}
+}
+
+func TestCompileError(t *testing.T) {
+	tests := []struct {
+		pattern string
+		flags   string
+		want    string
+	}{
+		{"(a", "", "Unterminated group"},
+		{"a)", "", "Unmatched ')'"},
+		{"^*", "", "Nothing to repeat"},
+		{"a{2,1}", "", "numbers out of order in {} quantifier"},
+		{"[b-a]", "", "Range out of order in character class"},
+		{"[a", "", "Unterminated character class"},
+		{"(?<a>x)(?<a>y)", "", "Duplicate capture group name"},
+		{"(?<a>x)\\k<b>", "", "Invalid named capture referenced"},
+		{"(?<1a>x)", "", "Invalid capture group name"},
+		{"(?x)", "", "Invalid group"},
+		{"\\", "", "\\ at end of pattern"},
+		{"(?<=a)*", "", "Invalid quantifier"},
+		{"\\p{Foo}", "u", "Invalid property name"},
+		{"\\u{110000}", "u", "Invalid Unicode escape"},
+		{"\\q", "u", "Invalid escape"},
+		{"{", "u", "Lone quantifier brackets"},
+		{"a{1", "u", "Incomplete quantifier"},
+		{"[\\d-z]", "u", "Invalid character class"},
+	}
+	for _, tt := range tests {
+		_, err := Compile(tt.pattern, tt.flags)
+		want := "Invalid regular expression: /" + tt.pattern + "/" + tt.flags + ": " + tt.want
+		if err == nil || err.Error() != want {
+			t.Errorf("Compile(%q, %q) actual = %v, expect= %s", tt.pattern, tt.flags, err, want)
+		}
+	}
+	if _, err := Compile("a", "gg"); err == nil || err.Error() != "Invalid flags supplied to RegExp constructor 'gg'" {
+		t.Errorf("Compile with repeated flags actual = %v", err)
+	}
+}
+
+func TestStepLimit(t *testing.T) {
+	re, err := Compile("(a+)+b", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	re.StepLimit = 10000
+	input := utf16.Encode([]rune(strings.Repeat("a", 30)))
+	if _, err := re.Exec(input, 0); err != ErrStepLimit {
+		t.Errorf("expect step limit error, actual: %v", err)
+	}
+	re, _ = Compile("(?:ab)*c", "")
+	input = utf16.Encode([]rune(strings.Repeat("ab", 300000) + "c"))
+	if got, err := re.Exec(input, 0); err != nil || got[1] != len(input) {
+		t.Errorf("expect a long match, actual: %v %v", got, err)
+	}
+}
@@ -22,12 +22,26 @@ var KeywordMap = map[string]token.Type{
 	"return":   token.Return,
 	"super":    token.Super,
 	// "this":     token.This,
-	"true":   token.True,
-	"var":    token.Var,
-	"while":  token.While,
-	"do":     token.Do,
-	"new":    token.New,
-	"static": token.Static,
+	"true":       token.True,
+	"var":        token.Var,
+	"while":      token.While,
+	"do":         token.Do,
+	"new":        token.New,
+	"static":     token.Static,
+	"in":         token.In,
+	"break":      token.Break,
+	"continue":   token.Continue,
+	"throw":      token.Throw,
+	"try":        token.Try,
+	"catch":      token.Catch,
+	"finally":    token.Finally,
+	"let":        token.Let,
+	"const":      token.Const,
+	"typeof":     token.Typeof,
+	"instanceof": token.Instanceof,
+	"delete":     token.Delete,
+	"yield":      token.Yield,
+	"await":      token.Await,
 }
 
 type Scanner struct {
@@ -36,6 +50,12 @@ type Scanner struct {
 	start   int
 	current int
 	line    int
+	column  int // index of the first character of the line
+	// heads tells for each open parenthesis whether it starts the head of
+	// an if, while or for statement, after which a slash starts a regular
+	// expression.
+	heads   []bool
+	headEnd int // the number of tokens up to the ) closing the last head
 }
 
 func New(source string) *Scanner {
@@ -72,6 +92,12 @@ func (scanner *Scanner) advance() rune {
 	return c
 }
 
+// newLine is called after the newline character has been consumed.
+func (scanner *Scanner) newLine() {
+	scanner.line++
+	scanner.column = scanner.current
+}
+
 func (scanner *Scanner) getSubString(start int, end int) string {
 	text := string(scanner.source[start:end])
 	return text
@@ -82,6 +108,9 @@ func (scanner *Scanner) appendToken(tokenType token.Type, text string) {
 		Type:   tokenType,
 		Lexeme: text,
 		Line:   scanner.line,
+		Column: scanner.start - scanner.column + 1,
+		Start:  scanner.start,
+		End:    scanner.current,
 	})
 }
 
@@ -109,7 +138,7 @@ func (scanner *Scanner) isWhiteSpace(c rune) bool {
 }
 
 func (scanner *Scanner) isIdentifierChar(c rune) bool {
-	return !scanner.isAtEnd() && !scanner.isWhiteSpace(c) && !strings.ContainsRune("[]{}(),.+-*/%;:?&|!=><\"'", c)
+	return !scanner.isWhiteSpace(c) && !strings.ContainsRune("[]{}(),.+-*/%;:?&|!=><\"'", c)
 }
 
 func (scanner *Scanner) number() {
@@ -130,10 +159,9 @@ func (scanner *Scanner) number() {
 
 func (scanner *Scanner) string(end rune) {
 	for scanner.peek() != end && !scanner.isAtEnd() {
-		if scanner.peek() == '\n' {
-			scanner.line++
+		if scanner.advance() == '\n' {
+			scanner.newLine()
 		}
-		scanner.advance()
 	}
 	if scanner.isAtEnd() {
 		panic("unterminated string")
@@ -144,7 +172,7 @@ func (scanner *Scanner) string(end rune) {
 }
 
 func (scanner *Scanner) identifier() {
-	for scanner.isIdentifierChar(scanner.peek()) {
+	for !scanner.isAtEnd() && scanner.isIdentifierChar(scanner.peek()) {
 		scanner.advance()
 	}
 	text := scanner.getSubString(scanner.start, scanner.current)
@@ -155,13 +183,80 @@ func (scanner *Scanner) identifier() {
 	scanner.addToken(tokenType)
 }
 
+// regExpAllowed reports whether a slash starts a regular expression rather
+// than a division, judging by the token before it.
+func (scanner *Scanner) regExpAllowed() bool {
+	if len(scanner.tokens) == 0 {
+		return true
+	}
+	switch scanner.tokens[len(scanner.tokens)-1].Type {
+	case token.RightParen:
+		return scanner.headEnd == len(scanner.tokens)
+	case token.Identifier, token.String, token.Float64, token.Int64, token.True, token.False, token.Null,
+		token.Super, token.RightSquare, token.PlusPlus, token.MinusMinus:
+		return false
+	}
+	return true
+}
+
+// opensHead reports whether a parenthesis scanned next starts the head of
+// an if, while or for statement.
+func (scanner *Scanner) opensHead() bool {
+	n := len(scanner.tokens)
+	if n == 0 {
+		return false
+	}
+	switch scanner.tokens[n-1].Type {
+	case token.If, token.While, token.For:
+		return true
+	case token.Await:
+		return n > 1 && scanner.tokens[n-2].Type == token.For
+	}
+	return false
+}
+
+// regExp scans a regular expression literal; its lexeme keeps both slashes
+// and the flags.
+func (scanner *Scanner) regExp() {
+	inClass := false
+	for {
+		if scanner.isAtEnd() || scanner.peek() == '\n' {
+			panic("Invalid regular expression: missing /")
+		}
+		c := scanner.advance()
+		if c == '\\' {
+			if scanner.isAtEnd() || scanner.peek() == '\n' {
+				panic("Invalid regular expression: missing /")
+			}
+			scanner.advance()
+		} else if c == '[' {
+			inClass = true
+		} else if c == ']' {
+			inClass = false
+		} else if c == '/' && !inClass {
+			break
+		}
+	}
+	for !scanner.isAtEnd() && scanner.isIdentifierChar(scanner.peek()) {
+		scanner.advance()
+	}
+	scanner.addToken(token.RegExp)
+}
+
 func (scanner *Scanner) scanToken() {
 	c := scanner.advance()
 	switch c {
 	case '(':
+		scanner.heads = append(scanner.heads, scanner.opensHead())
 		scanner.addToken(token.LeftParen)
 	case ')':
 		scanner.addToken(token.RightParen)
+		if n := len(scanner.heads); n > 0 {
+			if scanner.heads[n-1] {
+				scanner.headEnd = len(scanner.tokens)
+			}
+			scanner.heads = scanner.heads[:n-1]
+		}
 	case '{':
 		scanner.addToken(token.LeftBrace)
 	case '}':
@@ -263,6 +358,8 @@ func (scanner *Scanner) scanToken() {
 			} else {
 				scanner.addToken(token.EqualEqual)
 			}
+		} else if scanner.match('>') {
+			scanner.addToken(token.Arrow)
 		} else {
 			scanner.addToken(token.Equal)
 		}
@@ -301,14 +398,18 @@ func (scanner *Scanner) scanToken() {
 			for scanner.peek() != '\n' && !scanner.isAtEnd() {
 				scanner.advance()
 			}
-		} else if scanner.match('=') {
-			scanner.addToken(token.SlashEqual)
 		} else if scanner.match('*') {
 			for !((scanner.peek() == '*' && scanner.peekNext() == '/') || scanner.isAtEnd()) {
-				scanner.advance()
+				if scanner.advance() == '\n' {
+					scanner.newLine()
+				}
 			}
 			scanner.advance() // skip *
 			scanner.advance() // skip /
+		} else if scanner.regExpAllowed() {
+			scanner.regExp()
+		} else if scanner.match('=') {
+			scanner.addToken(token.SlashEqual)
 		} else {
 			scanner.addToken(token.Slash)
 		}
@@ -319,7 +420,7 @@ func (scanner *Scanner) scanToken() {
 	case '\t':
 		break
 	case '\n':
-		scanner.line++
+		scanner.newLine()
 	case '\'':
 		scanner.string(c)
 	case '"':
@@ -403,3 +403,52 @@ func TestScanner(t *testing.T) {
 		}
 	}
 }
+
+func TestRegExp(t *testing.T) {
+	tests := []struct {
+		source string
+		want   []token.Type
+	}{
+		{"/a/g", []token.Type{token.RegExp}},
+		{"a / b / c", []token.Type{token.Identifier, token.Slash, token.Identifier, token.Slash, token.Identifier}},
+		{"(1) / 2", []token.Type{token.LeftParen, token.Int64, token.RightParen, token.Slash, token.Int64}},
+		{"a /= 2", []token.Type{token.Identifier, token.SlashEqual, token.Int64}},
+		{"x = /=/", []token.Type{token.Identifier, token.Equal, token.RegExp}},
+		{"f(/[/]\\//)", []token.Type{token.Identifier, token.LeftParen, token.RegExp, token.RightParen}},
+		{"if (x) /a/.test(s)", []token.Type{token.If, token.LeftParen, token.Identifier, token.RightParen, token.RegExp, token.Dot, token.Identifier, token.LeftParen, token.Identifier, token.RightParen}},
+		{"while (f(x)) /a/", []token.Type{token.While, token.LeftParen, token.Identifier, token.LeftParen, token.Identifier, token.RightParen, token.RightParen, token.RegExp}},
+		{"for (;;) /a/", []token.Type{token.For, token.LeftParen, token.Semicolon, token.Semicolon, token.RightParen, token.RegExp}},
+		{"if ((a) / 2) b", []token.Type{token.If, token.LeftParen, token.LeftParen, token.Identifier, token.RightParen, token.Slash, token.Int64, token.RightParen, token.Identifier}},
+		{"f(x) / 2", []token.Type{token.Identifier, token.LeftParen, token.Identifier, token.RightParen, token.Slash, token.Int64}},
+	}
+	for _, tt := range tests {
+		tokens := New(tt.source).Scan()
+		var actual []token.Type
+		for _, item := range tokens[:len(tokens)-1] {
+			actual = append(actual, item.Type)
+		}
+		if len(actual) != len(tt.want) {
+			t.Errorf("%s: expect %v, actual %v", tt.source, tt.want, actual)
+			continue
+		}
+		for i := range actual {
+			if actual[i] != tt.want[i] {
+				t.Errorf("%s: expect %v, actual %v", tt.source, tt.want, actual)
+				break
+			}
+		}
+	}
+	if lexeme := New("/[/]\\//gi").Scan()[0].Lexeme; lexeme != "/[/]\\//gi" {
+		t.Errorf("expect the whole literal, actual: %s", lexeme)
+	}
+}
+
+func TestPosition(t *testing.T) {
+	tokens := New("a = 1\n  /* x\n */ f(b)").Scan()
+	want := [][2]int{{1, 1}, {1, 3}, {1, 5}, {3, 5}, {3, 6}, {3, 7}, {3, 8}}
+	for i, position := range want {
+		if tokens[i].Line != position[0] || tokens[i].Column != position[1] {
+			t.Errorf("token %q expect= %v, actual= %d:%d", tokens[i].Lexeme, position, tokens[i].Line, tokens[i].Column)
+		}
+	}
+}
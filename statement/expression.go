@@ -22,8 +22,11 @@ type ExpressionVisitor interface {
 	VisitFunctionExpression(expression FunctionExpression) any
 	VisitClassExpression(expression ClassExpression) any
 	VisitArrayLiteralExpression(expression ArrayLiteralExpression) any
+	VisitRegExpLiteralExpression(expression RegExpLiteralExpression) any
 	VisitObjectLiteralExpression(expression ObjectLiteralExpression) any
 	VisitNewExpression(expression NewExpression) any
+	VisitYieldExpression(expression YieldExpression) any
+	VisitAwaitExpression(expression AwaitExpression) any
 }
 
 type Expression interface {
@@ -55,12 +58,16 @@ func (expression BinaryExpression) Accept(visitor ExpressionVisitor) any {
 }
 
 func (expression BinaryExpression) String() string {
+	if expression.Operator.Type == token.In || expression.Operator.Type == token.Instanceof {
+		return expression.Left.String() + " " + expression.Operator.String() + " " + expression.Right.String()
+	}
 	return expression.Left.String() + expression.Operator.String() + expression.Right.String()
 }
 
 type CallExpression struct {
 	Callee    Expression
 	Arguments []Expression
+	Token     token.Token // where stack traces place the call
 }
 
 func (expression CallExpression) Accept(visitor ExpressionVisitor) any {
@@ -155,6 +162,9 @@ func (expression UnaryExpression) Accept(visitor ExpressionVisitor) any {
 }
 
 func (expression UnaryExpression) String() string {
+	if expression.Operator.Type == token.Typeof || expression.Operator.Type == token.Delete {
+		return expression.Operator.Lexeme + " " + expression.Right.String()
+	}
 	return expression.Operator.String() + expression.Right.String()
 }
 
@@ -196,9 +206,13 @@ func (expression TokenExpression) String() string {
 }
 
 type FunctionExpression struct {
-	Name   *token.Token
-	Body   BlockStatement
-	Params []token.Token
+	Name      *token.Token
+	Body      BlockStatement
+	Params    []token.Token
+	Generator bool
+	Async     bool
+	Arrow     bool
+	Source    string // the text of the function, for toString
 }
 
 func (expression FunctionExpression) Accept(visitor ExpressionVisitor) any {
@@ -214,13 +228,27 @@ func (expression FunctionExpression) String() string {
 	if expression.Name != nil {
 		name = " " + expression.Name.String()
 	}
-	return "function" + name + "(" + strings.Join(temp, ",") + ")" + expression.Body.String()
+	keyword := "function"
+	if expression.Generator {
+		keyword = "function*"
+	}
+	if expression.Arrow {
+		keyword = ""
+	}
+	if expression.Async {
+		keyword = "async " + keyword
+	}
+	if expression.Arrow {
+		return keyword + "(" + strings.Join(temp, ",") + ")=>" + expression.Body.String()
+	}
+	return keyword + name + "(" + strings.Join(temp, ",") + ")" + expression.Body.String()
 }
 
 type ClassExpression struct {
 	Name       *token.Token
 	SuperClass *VariableExpression
 	Methods    []Statement
+	Source     string // the text of the class, for toString
 }
 
 func (expression ClassExpression) Accept(visitor ExpressionVisitor) any {
@@ -251,14 +279,35 @@ func (expression ArrayLiteralExpression) Accept(visitor ExpressionVisitor) any {
 func (expression ArrayLiteralExpression) String() string {
 	var temp []string
 	for _, item := range expression.Elements {
-		temp = append(temp, item.String())
+		if item == nil {
+			temp = append(temp, "")
+		} else {
+			temp = append(temp, item.String())
+		}
+	}
+	if len(temp) > 0 && temp[len(temp)-1] == "" {
+		temp = append(temp, "")
 	}
 	return "[" + strings.Join(temp, ",") + "]"
 }
 
+type RegExpLiteralExpression struct {
+	Pattern string
+	Flags   string
+}
+
+func (expression RegExpLiteralExpression) Accept(visitor ExpressionVisitor) any {
+	return visitor.VisitRegExpLiteralExpression(expression)
+}
+
+func (expression RegExpLiteralExpression) String() string {
+	return "/" + expression.Pattern + "/" + expression.Flags
+}
+
 type ObjectLiteralItem struct {
-	Key   Expression
-	Value Expression
+	Key      Expression
+	Value    Expression
+	Computed bool // [key]: value
 }
 
 type ObjectLiteralExpression struct {
@@ -272,7 +321,11 @@ func (expression ObjectLiteralExpression) Accept(visitor ExpressionVisitor) any
 func (expression ObjectLiteralExpression) String() string {
 	var temp []string
 	for _, item := range expression.Properties {
-		temp = append(temp, item.Key.String()+":"+item.Value.String())
+		key := item.Key.String()
+		if item.Computed {
+			key = "[" + key + "]"
+		}
+		temp = append(temp, key+":"+item.Value.String())
 	}
 	return "{" + strings.Join(temp, ",") + "}"
 }
@@ -288,3 +341,35 @@ func (expression NewExpression) Accept(visitor ExpressionVisitor) any {
 func (expression NewExpression) String() string {
 	return "new " + expression.Expression.String()
 }
+
+type YieldExpression struct {
+	Value    Expression
+	Delegate bool // yield*
+}
+
+func (expression YieldExpression) Accept(visitor ExpressionVisitor) any {
+	return visitor.VisitYieldExpression(expression)
+}
+
+func (expression YieldExpression) String() string {
+	temp := "yield"
+	if expression.Delegate {
+		temp += "*"
+	}
+	if expression.Value != nil {
+		temp += " " + expression.Value.String()
+	}
+	return temp
+}
+
+type AwaitExpression struct {
+	Value Expression
+}
+
+func (expression AwaitExpression) Accept(visitor ExpressionVisitor) any {
+	return visitor.VisitAwaitExpression(expression)
+}
+
+func (expression AwaitExpression) String() string {
+	return "await " + expression.Value.String()
+}
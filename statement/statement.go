@@ -15,6 +15,12 @@ type StatementVisitor interface {
 	VisitReturnStatement(statement ReturnStatement) any
 	VisitVariableStatement(statement VariableStatement) any
 	VisitWhileStatement(statement WhileStatement) any
+	VisitForInStatement(statement ForInStatement) any
+	VisitForOfStatement(statement ForOfStatement) any
+	VisitBreakStatement(statement BreakStatement) any
+	VisitContinueStatement(statement ContinueStatement) any
+	VisitThrowStatement(statement ThrowStatement) any
+	VisitTryStatement(statement TryStatement) any
 }
 
 type Statement interface {
@@ -42,6 +48,7 @@ type ClassStatement struct {
 	Name       token.Token
 	SuperClass VariableExpression
 	Methods    []Statement
+	Source     string // the text of the class, for toString
 }
 
 func (statement ClassStatement) Accept(visitor StatementVisitor) any {
@@ -71,10 +78,14 @@ func (statement ExpressionStatement) String() string {
 }
 
 type FunctionStatement struct {
-	Name   token.Token
-	Body   BlockStatement
-	Params []token.Token
-	Static bool
+	Name      token.Token
+	Key       Expression // computed method name
+	Body      BlockStatement
+	Params    []token.Token
+	Static    bool
+	Generator bool
+	Async     bool
+	Source    string // the text of the function, for toString
 }
 
 func (statement FunctionStatement) Accept(visitor StatementVisitor) any {
@@ -87,7 +98,18 @@ func (statement FunctionStatement) String() string {
 		temp = append(temp, item.String())
 	}
 
-	return "function " + statement.Name.String() + "(" + strings.Join(temp, ",") + ")" + statement.Body.String()
+	name := statement.Name.String()
+	if statement.Key != nil {
+		name = "[" + statement.Key.String() + "]"
+	}
+	keyword := "function "
+	if statement.Generator {
+		keyword = "function* "
+	}
+	if statement.Async {
+		keyword = "async " + keyword
+	}
+	return keyword + name + "(" + strings.Join(temp, ",") + ")" + statement.Body.String()
 }
 
 type IfStatement struct {
@@ -124,6 +146,7 @@ func (statement ReturnStatement) String() string {
 }
 
 type VariableStatement struct {
+	Kind        token.Token // var, let or const; unset for class fields
 	Name        token.Token
 	Initializer Expression
 	Static      bool
@@ -145,6 +168,7 @@ type WhileStatement struct {
 	Name      token.Token
 	Condition Expression
 	Body      Statement
+	Increment Expression
 }
 
 func (statement WhileStatement) Accept(visitor StatementVisitor) any {
@@ -152,5 +176,117 @@ func (statement WhileStatement) Accept(visitor StatementVisitor) any {
 }
 
 func (statement WhileStatement) String() string {
+	if statement.Name.Type == token.Do {
+		return "do" + statement.Body.String() + "while(" + statement.Condition.String() + ");"
+	}
+	if statement.Increment != nil {
+		return "for(;" + statement.Condition.String() + ";" + statement.Increment.String() + ")" + statement.Body.String()
+	}
 	return "while(" + statement.Condition.String() + ")" + statement.Body.String()
 }
+
+func forHeadString(kind *token.Token, target Expression, operator string, object Expression) string {
+	temp := "for("
+	if kind != nil {
+		temp += kind.String() + " "
+	}
+	return temp + target.String() + " " + operator + " " + object.String() + ")"
+}
+
+type ForInStatement struct {
+	Kind   *token.Token // var, let or const
+	Target Expression
+	Object Expression
+	Body   Statement
+}
+
+func (statement ForInStatement) Accept(visitor StatementVisitor) any {
+	return visitor.VisitForInStatement(statement)
+}
+
+func (statement ForInStatement) String() string {
+	return forHeadString(statement.Kind, statement.Target, "in", statement.Object) + statement.Body.String()
+}
+
+type ForOfStatement struct {
+	Kind     *token.Token // var, let or const
+	Target   Expression
+	Iterable Expression
+	Body     Statement
+	Await    bool // for await
+}
+
+func (statement ForOfStatement) Accept(visitor StatementVisitor) any {
+	return visitor.VisitForOfStatement(statement)
+}
+
+func (statement ForOfStatement) String() string {
+	head := forHeadString(statement.Kind, statement.Target, "of", statement.Iterable)
+	if statement.Await {
+		head = "for await" + head[len("for"):]
+	}
+	return head + statement.Body.String()
+}
+
+type BreakStatement struct {
+	Name token.Token
+}
+
+func (statement BreakStatement) Accept(visitor StatementVisitor) any {
+	return visitor.VisitBreakStatement(statement)
+}
+
+func (statement BreakStatement) String() string {
+	return "break;"
+}
+
+type ContinueStatement struct {
+	Name token.Token
+}
+
+func (statement ContinueStatement) Accept(visitor StatementVisitor) any {
+	return visitor.VisitContinueStatement(statement)
+}
+
+func (statement ContinueStatement) String() string {
+	return "continue;"
+}
+
+type ThrowStatement struct {
+	Name  token.Token
+	Value Expression
+}
+
+func (statement ThrowStatement) Accept(visitor StatementVisitor) any {
+	return visitor.VisitThrowStatement(statement)
+}
+
+func (statement ThrowStatement) String() string {
+	return "throw " + statement.Value.String() + ";"
+}
+
+type TryStatement struct {
+	Block     BlockStatement
+	Param     *token.Token
+	Handler   *BlockStatement
+	Finalizer *BlockStatement
+}
+
+func (statement TryStatement) Accept(visitor StatementVisitor) any {
+	return visitor.VisitTryStatement(statement)
+}
+
+func (statement TryStatement) String() string {
+	temp := "try" + statement.Block.String()
+	if statement.Handler != nil {
+		temp += "catch"
+		if statement.Param != nil {
+			temp += "(" + statement.Param.String() + ")"
+		}
+		temp += statement.Handler.String()
+	}
+	if statement.Finalizer != nil {
+		temp += "finally" + statement.Finalizer.String()
+	}
+	return temp
+}
@@ -4,6 +4,9 @@ type Token struct {
 	Type   Type
 	Lexeme string
 	Line   int
+	Column int // 1-based, counted in characters
+	Start  int // index of the first character in the source
+	End    int // index after the last character
 }
 
 func (token Token) String() string {
@@ -75,12 +75,28 @@ const (
 	Return
 	Super
 	This
-	Static // static
-	Var    // variable
-	Do     // do
-	While  // while
-	New    // new
-	EOF    // end
+	Static     // static
+	Var        // variable
+	Do         // do
+	While      // while
+	New        // new
+	In         // in
+	Break      // break
+	Continue   // continue
+	Throw      // throw
+	Try        // try
+	Catch      // catch
+	Finally    // finally
+	Let        // let
+	Const      // const
+	Typeof     // typeof
+	Instanceof // instanceof
+	Delete     // delete
+	Yield      // yield
+	Await      // await
+	Arrow      // =>
+	RegExp     // /pattern/flags
+	EOF        // end
 )
 
 func ConvertAnyToString(text any) string {
@@ -1,21 +1,37 @@
 package types
 
-import (
-	"github.com/nusr/gojs/statement"
-)
-
 type Function interface {
 	Call(interpreter Interpreter, params []any) any
 	String() string
 }
 
+// Method is a Function that accepts the receiver of a call like `a.b()`.
+type Method interface {
+	Function
+	CallWith(interpreter Interpreter, this any, params []any) any
+}
+
+// Constructor is a Function that can be invoked with `new`.
+type Constructor interface {
+	Construct(interpreter Interpreter, params []any) any
+}
+
 type Property interface {
 	Get(key any) any
 	Set(key any, value any)
 }
 
+// Object is a Property that can enumerate its own keys.
+type Object interface {
+	Property
+	Has(key any) bool
+	Delete(key any) bool
+	OwnKeys() []any
+	IsEnumerable(key any) bool
+	GetPrototype() Property
+}
+
 type Class interface {
 	Property
 	Function
-	SetMethods(methods []statement.Statement)
 }
@@ -0,0 +1,21 @@
+package types
+
+import "time"
+
+// Clock is the time source of an event loop. Sleep is called when the loop
+// has nothing to do until the next timer.
+type Clock interface {
+	Now() time.Time
+	Sleep(duration time.Duration)
+}
+
+// Timer is a job scheduled on an event loop after a delay.
+type Timer interface {
+	ID() int64
+	Cancel()
+	// SetRef sets whether the timer keeps the event loop running.
+	SetRef(ref bool)
+	HasRef() bool
+	// Refresh restarts the delay from now.
+	Refresh()
+}
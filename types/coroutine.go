@@ -0,0 +1,8 @@
+package types
+
+// Coroutine is a function body that runs on its own goroutine and can
+// suspend itself, such as a generator.
+type Coroutine interface {
+	Yield(value any) any
+	Close()
+}
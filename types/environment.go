@@ -3,5 +3,15 @@ package types
 type Environment interface {
 	Get(key string) any
 	Define(name string, value any)
+	// DefineLexical declares a let, const or class binding, which is not a
+	// property of the global object even at the top level.
+	DefineLexical(name string, value any)
 	Assign(key string, value any)
 }
+
+// GlobalEnvironment is the root environment, whose var and function
+// bindings are the properties of the global object.
+type GlobalEnvironment interface {
+	Environment
+	GlobalObject() Object
+}
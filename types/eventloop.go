@@ -0,0 +1,27 @@
+package types
+
+import "time"
+
+// EventLoop runs the jobs queued while a script executes. Microtasks are
+// drained after every task.
+type EventLoop interface {
+	EnqueueMicrotask(job func())
+	EnqueueTask(job func())
+	// TrackRejection records a promise rejected without a handler.
+	TrackRejection(promise any, reason any)
+	// HandleRejection forgets a tracked promise once a handler is attached.
+	HandleRejection(promise any)
+	// AddTimer runs job as a task once delay has passed, and again after
+	// every delay when repeat is set.
+	AddTimer(delay time.Duration, repeat bool, job func()) Timer
+	// GetTimer finds an active timer by its id.
+	GetTimer(id int64) Timer
+	SetClock(clock Clock)
+	// Now reads the clock of the loop.
+	Now() time.Time
+	// Run executes queued work until none remains.
+	Run()
+	// RunUntil executes queued jobs one at a time until done reports true,
+	// and reports false if the work ran out first.
+	RunUntil(done func() bool) bool
+}
@@ -0,0 +1,11 @@
+package types
+
+// Frame is an entry of the call stack an interpreter keeps for Error stack
+// traces. Line and Column locate the call or new expression the frame is
+// executing.
+type Frame struct {
+	Name   string // empty for top-level code and anonymous functions
+	File   string
+	Line   int
+	Column int
+}
@@ -1,6 +1,8 @@
 package types
 
 import (
+	"time"
+
 	"github.com/nusr/gojs/statement"
 )
 
@@ -12,4 +14,37 @@ type Interpreter interface {
 	Execute(statement statement.Statement) any
 	Evaluate(expression statement.Expression) any
 	ExecuteBlock(statement statement.BlockStatement, environment Environment) (result any)
+	// Fork returns an interpreter that runs the body of a coroutine.
+	Fork(coroutine Coroutine) Interpreter
+	GetCoroutine() Coroutine
+	AddCoroutine(coroutine Coroutine)
+	RemoveCoroutine(coroutine Coroutine)
+	GetEventLoop() EventLoop
+	// SetRandom replaces the source of Math.random, e.g. with a seeded one
+	// for reproducible runs.
+	SetRandom(random Random)
+	// Random returns the next number of Math.random.
+	Random() float64
+	// SetLocation sets the time zone of Date, time.Local by default.
+	SetLocation(location *time.Location)
+	Location() *time.Location
+	// SetLocales sets the locales Intl supports, the first of which is the
+	// default. Only the languages with bundled data can be supported.
+	SetLocales(locales []string)
+	Locales() []string
+	// SetCodeGeneration allows or forbids compiling strings with eval and
+	// the Function constructor, as a sandbox may. It is allowed by default.
+	SetCodeGeneration(allowed bool)
+	CodeGeneration() bool
+	// PushFrame enters a call of a script function; PopFrame leaves it.
+	PushFrame(name string)
+	PopFrame()
+	// SetPosition records where the innermost frame is executing.
+	SetPosition(line int, column int)
+	// StackTrace lists the frames from the innermost one outwards.
+	StackTrace() []Frame
+	// SetFileName names the script in stack traces.
+	SetFileName(name string)
+	// Close stops every suspended coroutine.
+	Close()
 }
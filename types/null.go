@@ -0,0 +1,21 @@
+package types
+
+// Null is the value of the null literal. Undefined is nil.
+type Null struct {
+}
+
+func (n Null) String() string {
+	return "null"
+}
+
+func IsNull(value any) bool {
+	if _, ok := value.(Null); ok {
+		return true
+	}
+	return false
+}
+
+// IsNullish reports whether value is null or undefined.
+func IsNullish(value any) bool {
+	return value == nil || IsNull(value)
+}
@@ -0,0 +1,7 @@
+package types
+
+// Random is the source of Math.random. A *rand.Rand satisfies it.
+type Random interface {
+	// Float64 returns a number in [0, 1).
+	Float64() float64
+}
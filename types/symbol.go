@@ -0,0 +1,26 @@
+package types
+
+type Symbol struct {
+	Description string
+	// Anonymous is set for a symbol created without a description, whose
+	// description is undefined rather than "".
+	Anonymous bool
+	// Registered is set for a symbol created by Symbol.for, which stays
+	// reachable through the registry.
+	Registered bool
+}
+
+func NewSymbol(description string) *Symbol {
+	return &Symbol{
+		Description: description,
+	}
+}
+
+func (symbol *Symbol) String() string {
+	return "Symbol(" + symbol.Description + ")"
+}
+
+func IsSymbol(value any) bool {
+	_, ok := value.(*Symbol)
+	return ok
+}